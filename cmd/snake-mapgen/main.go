@@ -0,0 +1,93 @@
+//go:build !android
+
+// Command snake-mapgen writes generated cities to disk for review: the
+// world as a PNG, optional height and shadow images, and a JSON of its
+// roads, blocks, parcels, buildings and trees.
+//
+//	snake-mapgen -seed 42 -level 3 -theme Forest -count 10 -height -shadow -out /tmp/cities
+//
+// Build it with -tags headless to leave out the game's window, renderer and
+// audio, and with them cgo.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"snake/internal/game"
+)
+
+func main() {
+	seed := flag.Uint64("seed", 1, "first run seed")
+	count := flag.Int("count", 1, "number of consecutive seeds to generate")
+	level := flag.Int("level", 1, "level number")
+	theme := flag.String("theme", "", "theme family to pin, e.g. Forest (default: the level's)")
+	levels := flag.String("levels", "", "level pack file (default: the built-in campaign)")
+	out := flag.String("out", ".", "output directory")
+	height := flag.Bool("height", false, "also write the height image")
+	shadow := flag.Bool("shadow", false, "also write the image with sun shadows")
+	flag.Parse()
+
+	if err := run(*seed, *count, *level, *theme, *levels, *out, *height, *shadow); err != nil {
+		fmt.Fprintln(os.Stderr, "snake-mapgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(seed uint64, count, level int, theme, levels, out string, height, shadow bool) error {
+	if levels != "" {
+		if err := game.LoadLevelPack(levels); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		s := seed + uint64(i)
+		w, err := game.GenerateLevelWorld(s, level, theme)
+		if err != nil {
+			return err
+		}
+		base := filepath.Join(out, fmt.Sprintf("city-%d-level%d", s, level))
+		if err := writePNG(base+".png", w.Image(game.ImageColor)); err != nil {
+			return err
+		}
+		if height {
+			if err := writePNG(base+"-height.png", w.Image(game.ImageHeight)); err != nil {
+				return err
+			}
+		}
+		if shadow {
+			if err := writePNG(base+"-shadow.png", w.Image(game.ImageShaded)); err != nil {
+				return err
+			}
+		}
+		data, err := json.MarshalIndent(w.Layout(), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(base+".json", append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s, %dx%d\n", base, w.Theme.Name, w.Width, w.Height)
+	}
+	return nil
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,3 +1,5 @@
+//go:build !headless && !(android && audio_stub)
+
 package game
 
 import (
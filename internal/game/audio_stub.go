@@ -1,4 +1,4 @@
-//go:build android && audio_stub
+//go:build headless || (android && audio_stub)
 
 package game
 
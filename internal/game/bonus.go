@@ -52,6 +52,11 @@ type BonusSystem struct {
 	lastKind   int
 	SpawnTimer float64
 	maxBoxes   int
+
+	// Weights biases kind selection per BonusKind (level packs); nil = uniform.
+	Weights []int
+
+	Events *EventBus // receives gameplay events; may be nil
 }
 
 func NewBonusSystem(seed uint64, maxBoxes int) *BonusSystem {
@@ -63,10 +68,10 @@ func NewBonusSystem(seed uint64, maxBoxes int) *BonusSystem {
 	}
 }
 
-func clampBonusSpawn(x, y float64) (float64, float64) {
+func clampBonusSpawn(w *World, x, y float64) (float64, float64) {
 	const margin = 2.0
-	maxX := float64(WorldWidth - 3)
-	maxY := float64(WorldHeight - 3)
+	maxX := float64(w.Width - 3)
+	maxY := float64(w.Height - 3)
 	if maxX < margin {
 		maxX = margin
 	}
@@ -87,32 +92,32 @@ func (bs *BonusSystem) nextSpawnRand(salt uint64) *Rand {
 	return NewRand(z)
 }
 
-func (bs *BonusSystem) randomSpawnPos(r *Rand) (float64, float64) {
+func (bs *BonusSystem) randomSpawnPos(w *World, r *Rand) (float64, float64) {
 	var x, y float64
 	locType := r.Intn(4)
 	switch locType {
 	case 0:
 		// Intersection.
-		bx := r.Intn(WorldWidth / Pattern)
-		by := r.Intn(WorldHeight / Pattern)
+		bx := r.Intn(w.Width / Pattern)
+		by := r.Intn(w.Height / Pattern)
 		x = float64(bx*Pattern+RoadWidth/2) + r.RangeF(-2, 2)
 		y = float64(by*Pattern+RoadWidth/2) + r.RangeF(-2, 2)
 	case 1:
 		// Horizontal road segment.
-		x = r.RangeF(0, float64(WorldWidth-1))
-		by := r.Intn(WorldHeight / Pattern)
+		x = r.RangeF(0, float64(w.Width-1))
+		by := r.Intn(w.Height / Pattern)
 		y = float64(by*Pattern+RoadWidth/2) + r.RangeF(-1, 1)
 	case 2:
 		// Vertical road segment.
-		bx := r.Intn(WorldWidth / Pattern)
+		bx := r.Intn(w.Width / Pattern)
 		x = float64(bx*Pattern+RoadWidth/2) + r.RangeF(-1, 1)
-		y = r.RangeF(0, float64(WorldHeight-1))
+		y = r.RangeF(0, float64(w.Height-1))
 	default:
 		// Completely random position (can be on sidewalk/grass).
-		x = float64(r.Range(5, WorldWidth-5))
-		y = float64(r.Range(5, WorldHeight-5))
+		x = float64(r.Range(5, w.Width-5))
+		y = float64(r.Range(5, w.Height-5))
 	}
-	return clampBonusSpawn(x, y)
+	return clampBonusSpawn(w, x, y)
 }
 
 func (bs *BonusSystem) hasNearbyAliveBox(x, y float64, minDist float64) bool {
@@ -131,18 +136,21 @@ func (bs *BonusSystem) hasNearbyAliveBox(x, y float64, minDist float64) bool {
 	return false
 }
 
-func (bs *BonusSystem) pickSpawnPos(r *Rand) (float64, float64) {
-	x, y := bs.randomSpawnPos(r)
+func (bs *BonusSystem) pickSpawnPos(w *World, r *Rand) (float64, float64) {
+	x, y := bs.randomSpawnPos(w, r)
 	for tries := 0; tries < 14; tries++ {
 		if !bs.hasNearbyAliveBox(x, y, 8.0) {
 			return x, y
 		}
-		x, y = bs.randomSpawnPos(r)
+		x, y = bs.randomSpawnPos(w, r)
 	}
 	return x, y
 }
 
 func (bs *BonusSystem) pickBonusKind(r *Rand, snakeHP float64) BonusKind {
+	if len(bs.Weights) == int(BonusKindCount) {
+		return bs.pickWeightedKind(r, snakeHP)
+	}
 	kind := BonusKind(r.Intn(int(BonusKindCount)))
 
 	// Bias towards health bonuses when snake is hurt.
@@ -159,11 +167,43 @@ func (bs *BonusSystem) pickBonusKind(r *Rand, snakeHP float64) BonusKind {
 	return kind
 }
 
+// pickWeightedKind is pickBonusKind for levels with bonus weights. Kinds
+// weighted 0 never spawn, not even as the low-health or anti-repeat pick.
+func (bs *BonusSystem) pickWeightedKind(r *Rand, snakeHP float64) BonusKind {
+	total := 0
+	for k, w := range bs.Weights {
+		if k != bs.lastKind {
+			total += w
+		}
+	}
+	if total == 0 {
+		// Only the last kind is allowed; repeating it is fine.
+		return BonusKind(bs.lastKind)
+	}
+	var kind BonusKind
+	roll := r.Intn(total)
+	for k, w := range bs.Weights {
+		if k == bs.lastKind {
+			continue
+		}
+		if roll < w {
+			kind = BonusKind(k)
+			break
+		}
+		roll -= w
+	}
+	if snakeHP < 0.5 && bs.Weights[BonusHealth] > 0 && r.Intn(100) < 40 {
+		kind = BonusHealth
+	}
+	bs.lastKind = int(kind)
+	return kind
+}
+
 // SpawnRandom places count bonus boxes at random road positions.
-func (bs *BonusSystem) SpawnRandom(count int) {
+func (bs *BonusSystem) SpawnRandom(w *World, count int) {
 	for i := 0; i < count; i++ {
 		r := bs.nextSpawnRand(uint64(i+1) * 0xB0105)
-		x, y := bs.pickSpawnPos(r)
+		x, y := bs.pickSpawnPos(w, r)
 		kind := bs.pickBonusKind(r, 1.0)
 		bs.Boxes = append(bs.Boxes, BonusBox{
 			X: x, Y: y,
@@ -174,10 +214,41 @@ func (bs *BonusSystem) SpawnRandom(count int) {
 	}
 }
 
+// SpawnIndoors hides up to count boxes inside buildings, at most one in
+// each, for the snake to find once it breaks in.
+func (bs *BonusSystem) SpawnIndoors(w *World, count int) {
+	cols := floorDiv(w.Width-1, Pattern) + 1
+	rows := floorDiv(w.Height-1, Pattern) + 1
+	used := make(map[*interior]bool)
+	for tries := 0; count > 0 && tries < 64*count; tries++ {
+		r := bs.nextSpawnRand(uint64(tries+1) * 0x1D00B)
+		list := w.blockInteriors(r.Intn(cols), r.Intn(rows))
+		if len(list) == 0 {
+			continue
+		}
+		in := &list[r.Intn(len(list))]
+		if used[in] {
+			continue
+		}
+		x, y, ok := in.floorSpot(w, r)
+		if !ok {
+			continue
+		}
+		used[in] = true
+		bs.Boxes = append(bs.Boxes, BonusBox{
+			X: x, Y: y,
+			Kind:  bs.pickBonusKind(r, 1.0),
+			Alive: true,
+			Timer: r.RangeF(0, 1),
+		})
+		count--
+	}
+}
+
 // Update advances animation timers and respawn logic.
 // pedsAlive: current number of alive pedestrians (speeds up spawns when low).
 // snakeHP: snake health fraction 0-1 (lower health = faster spawns).
-func (bs *BonusSystem) Update(dt float64, pedsAlive int, snakeHP float64) {
+func (bs *BonusSystem) Update(w *World, dt float64, pedsAlive int, snakeHP float64) {
 	for i := range bs.Boxes {
 		bs.Boxes[i].Timer += dt
 	}
@@ -224,7 +295,7 @@ func (bs *BonusSystem) Update(dt float64, pedsAlive int, snakeHP float64) {
 				bs.SpawnTimer = 4.0 + r.RangeF(0, 6.0)
 			}
 
-			x, y := bs.pickSpawnPos(r)
+			x, y := bs.pickSpawnPos(w, r)
 			kind := bs.pickBonusKind(r, snakeHP)
 
 			spawned := false
@@ -261,7 +332,7 @@ func (bs *BonusSystem) collectAt(idx int, s *Snake, hx, hy float64, world *World
 		return
 	}
 	b.Alive = false
-	PlaySound(SoundBonus)
+	bs.Events.Emit(Event{Type: EventBonusCollected, X: hx, Y: hy, Bonus: b.Kind})
 
 	// Per-box RNG: varies by position and seed so each box has unique rolls.
 	r := NewRand(uint64(b.X*71+b.Y*43) ^ bs.seed ^ 0xC011EC7)
@@ -312,7 +383,7 @@ func (bs *BonusSystem) collectAt(idx int, s *Snake, hx, hy float64, world *World
 				break
 			}
 			p := &peds.P[i]
-			if !p.Alive || p.Infection == StateSymptomatic {
+			if !p.Alive || p.Infection.Sick() {
 				continue
 			}
 			s.TeleportQueue = append(s.TeleportQueue, PathPoint{X: p.X, Y: p.Y})
@@ -415,8 +486,8 @@ func (bs *BonusSystem) collectAt(idx int, s *Snake, hx, hy float64, world *World
 
 			tx, ty := pt.X, pt.Y
 			for tries := 0; tries < 60; tries++ {
-				rx := r.Range(2, WorldWidth-3)
-				ry := r.Range(2, WorldHeight-3)
+				rx := r.Range(2, world.Width-3)
+				ry := r.Range(2, world.Height-3)
 				if world.HeightAt(rx, ry) == 0 && math.Hypot(float64(rx)-hx, float64(ry)-hy) > 12 {
 					tx = float64(rx) + 0.5
 					ty = float64(ry) + 0.5
@@ -628,8 +699,8 @@ func (bs *BonusSystem) collectAt(idx int, s *Snake, hx, hy float64, world *World
 			// Spawn at a random offset from the collector position.
 			for tries := range 40 {
 				_ = tries
-				cx := clampF(hx+r.RangeF(-float64(WorldWidth/3), float64(WorldWidth/3)), 0, float64(WorldWidth-1))
-				cy := clampF(hy+r.RangeF(-float64(WorldHeight/3), float64(WorldHeight/3)), 0, float64(WorldHeight-1))
+				cx := clampF(hx+r.RangeF(-float64(world.Width/3), float64(world.Width/3)), 0, float64(world.Width-1))
+				cy := clampF(hy+r.RangeF(-float64(world.Height/3), float64(world.Height/3)), 0, float64(world.Height-1))
 				if world.HeightAt(int(math.Round(cx)), int(math.Round(cy))) == 0 {
 					path := make([]PathPoint, 1, 256)
 					path[0] = PathPoint{X: cx, Y: cy}
@@ -45,24 +45,30 @@ func (c *Camera) EffectivePos() (float64, float64) {
 	return c.X + c.ShakeX, c.Y + c.ShakeY
 }
 
-func (c *Camera) Clamp(fbW, fbH int) {
+// Clamp limits the zoom and keeps the view inside the world.
+func (c *Camera) Clamp(w *World, fbW, fbH int) {
 	if c.Zoom < MinZoom {
 		c.Zoom = MinZoom
 	}
 	if c.Zoom > MaxZoom {
 		c.Zoom = MaxZoom
 	}
+	c.clampToWorld(w, fbW, fbH)
+}
 
+// clampToWorld keeps the view inside the world, centring it on any axis the
+// world does not fill.
+func (c *Camera) clampToWorld(w *World, fbW, fbH int) {
 	halfW := float64(fbW) / (2.0 * c.Zoom)
 	halfH := float64(fbH) / (2.0 * c.Zoom)
 
 	minX := halfW
-	maxX := float64(WorldWidth) - halfW
+	maxX := float64(w.Width) - halfW
 	minY := halfH
-	maxY := float64(WorldHeight) - halfH
+	maxY := float64(w.Height) - halfH
 
 	if minX > maxX {
-		c.X = float64(WorldWidth) * 0.5
+		c.X = float64(w.Width) * 0.5
 	} else {
 		if c.X < minX {
 			c.X = minX
@@ -73,7 +79,7 @@ func (c *Camera) Clamp(fbW, fbH int) {
 	}
 
 	if minY > maxY {
-		c.Y = float64(WorldHeight) * 0.5
+		c.Y = float64(w.Height) * 0.5
 	} else {
 		if c.Y < minY {
 			c.Y = minY
@@ -83,3 +89,22 @@ func (c *Camera) Clamp(fbW, fbH int) {
 		}
 	}
 }
+
+// View is the world rectangle the camera shows.
+func (c *Camera) View(fbW, fbH int) RectF {
+	halfW := float64(fbW) / (2.0 * c.Zoom)
+	halfH := float64(fbH) / (2.0 * c.Zoom)
+	return RectF{X0: c.X - halfW, Y0: c.Y - halfH, X1: c.X + halfW, Y1: c.Y + halfH}
+}
+
+// ShowsWorld reports whether the whole world is on screen.
+func (c *Camera) ShowsWorld(w *World, fbW, fbH int) bool {
+	const slack = 0.5 // world pixels
+	return float64(fbW)/c.Zoom+slack >= float64(w.Width) && float64(fbH)/c.Zoom+slack >= float64(w.Height)
+}
+
+// ScreenCamera maps world coordinates one-to-one onto framebuffer pixels,
+// for drawing HUD elements with the sprite programs.
+func ScreenCamera(fbW, fbH int) Camera {
+	return Camera{X: float64(fbW) / 2, Y: float64(fbH) / 2, Zoom: 1}
+}
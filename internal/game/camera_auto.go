@@ -2,12 +2,60 @@ package game
 
 import "math"
 
-// UpdateAutoCamera fits the entire world on screen at all times.
-// Zoom is computed so the world fills the framebuffer; camera is centered on the world.
-func UpdateAutoCamera(cam *Camera, snake *Snake, dt float64, fbW, fbH int) {
-	zoomW := float64(fbW) / float64(WorldWidth)
-	zoomH := float64(fbH) / float64(WorldHeight)
-	cam.Zoom = math.Min(zoomW, zoomH)
-	cam.X = float64(WorldWidth) / 2
-	cam.Y = float64(WorldHeight) / 2
+const (
+	cameraFollowRate = 6.0  // 1/s; how fast the view catches up with its target
+	cameraHeadMargin = 24.0 // world pixels kept around the heads in versus
+)
+
+// HeadBounds is the box around every living snake head, or around every
+// head when none is alive.
+func HeadBounds(snakes []*Snake) (RectF, bool) {
+	box, ok := RectF{}, false
+	for pass := 0; pass < 2 && !ok; pass++ {
+		for _, s := range snakes {
+			if s == nil || (pass == 0 && !s.Alive) {
+				continue
+			}
+			hx, hy := s.Head()
+			if !ok {
+				box, ok = RectF{X0: hx, Y0: hy, X1: hx, Y1: hy}, true
+				continue
+			}
+			box.X0, box.Y0 = math.Min(box.X0, hx), math.Min(box.Y0, hy)
+			box.X1, box.Y1 = math.Max(box.X1, hx), math.Max(box.Y1, hy)
+		}
+	}
+	return box, ok
+}
+
+// UpdateAutoCamera follows the snakes. The zoom shows as much city as a
+// default-sized world, so default worlds fill the screen exactly and larger
+// ones scroll. In versus it zooms out as far as the whole world to keep
+// every living head in view.
+func UpdateAutoCamera(cam *Camera, world *World, snakes []*Snake, dt float64, fbW, fbH int) {
+	fw, fh := float64(fbW), float64(fbH)
+	viewW, viewH := world.ViewSize()
+	fit := math.Min(fw/float64(world.Width), fh/float64(world.Height))
+	zoom := math.Min(fw/viewW, fh/viewH)
+
+	tx, ty := float64(world.Width)/2, float64(world.Height)/2
+	if box, ok := HeadBounds(snakes); ok {
+		tx, ty = (box.X0+box.X1)/2, (box.Y0+box.Y1)/2
+		boxW := box.X1 - box.X0 + 2*cameraHeadMargin
+		boxH := box.Y1 - box.Y0 + 2*cameraHeadMargin
+		zoom = math.Min(zoom, math.Min(fw/boxW, fh/boxH))
+	}
+	zoom = math.Max(zoom, fit)
+
+	// Ease towards the target, but jump when it is screens away (a new
+	// level or a teleport).
+	if cam.Zoom <= 0 || math.Abs(tx-cam.X) > fw/zoom || math.Abs(ty-cam.Y) > fh/zoom {
+		cam.X, cam.Y, cam.Zoom = tx, ty, zoom
+	} else {
+		k := 1 - math.Exp(-cameraFollowRate*dt)
+		cam.X += (tx - cam.X) * k
+		cam.Y += (ty - cam.Y) * k
+		cam.Zoom += (zoom - cam.Zoom) * k
+	}
+	cam.clampToWorld(world, fbW, fbH)
 }
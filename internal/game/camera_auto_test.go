@@ -0,0 +1,88 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// The test framebuffer shows a default-sized world at exactly 4x.
+const testFbW, testFbH = 4 * DefaultWorldWidth, 4 * DefaultWorldHeight
+
+func TestAutoCameraFraming(t *testing.T) {
+	big := NewWorld(1, 5*DefaultWorldWidth, 5*DefaultWorldHeight)
+	small := NewWorld(1, DefaultWorldWidth, DefaultWorldHeight)
+	halfW, halfH := float64(DefaultWorldWidth)/2, float64(DefaultWorldHeight)/2
+	for _, tc := range []struct {
+		name  string
+		world *World
+		heads [][2]float64
+		x, y  float64
+		zoom  float64
+	}{
+		{"centred", big, [][2]float64{{600, 500}}, 600, 500, 4},
+		{"top-left corner", big, [][2]float64{{5, 5}}, halfW, halfH, 4},
+		{"bottom-right corner", big, [][2]float64{{1315, 985}}, 1320 - halfW, 990 - halfH, 4},
+		{"default world", small, [][2]float64{{10, 190}}, halfW, halfH, 4},
+		{"versus heads apart", big, [][2]float64{{300, 500}, {900, 500}}, 600, 500, testFbW / (600 + 2*cameraHeadMargin)},
+		{"versus at opposite corners", big, [][2]float64{{5, 5}, {1315, 985}}, 660, 495, 0.8},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var snakes []*Snake
+			for _, h := range tc.heads {
+				snakes = append(snakes, NewSnake(h[0], h[1], SnakeBaseSpeed))
+			}
+			var cam Camera
+			UpdateAutoCamera(&cam, tc.world, snakes, SimTickDT, testFbW, testFbH)
+			if math.Abs(cam.X-tc.x) > 1e-9 || math.Abs(cam.Y-tc.y) > 1e-9 || math.Abs(cam.Zoom-tc.zoom) > 1e-9 {
+				t.Errorf("camera at %.2f,%.2f zoom %.3f, want %.2f,%.2f zoom %.3f", cam.X, cam.Y, cam.Zoom, tc.x, tc.y, tc.zoom)
+			}
+		})
+	}
+}
+
+// TestAutoCameraFollowsAtSpeed races a snake across a big world and checks
+// the camera keeps it on screen, lagging no more than the follow rate
+// allows, stops at the world's edge and settles on the head once it stops.
+func TestAutoCameraFollowsAtSpeed(t *testing.T) {
+	w := NewWorld(1, 5*DefaultWorldWidth, 5*DefaultWorldHeight)
+	const speed = 400.0
+	sn := NewSnake(300, 500, speed)
+	cam := Camera{}
+	UpdateAutoCamera(&cam, w, []*Snake{sn}, SimTickDT, testFbW, testFbH)
+	halfW := float64(testFbW) / (2 * cam.Zoom)
+	for range int(2 / SimTickDT) {
+		sn.Path[0].X += speed * SimTickDT
+		UpdateAutoCamera(&cam, w, []*Snake{sn}, SimTickDT, testFbW, testFbH)
+		if lag := sn.Path[0].X - cam.X; lag < 0 || lag > speed/cameraFollowRate+1 {
+			t.Fatalf("head at %.1f, camera at %.1f", sn.Path[0].X, cam.X)
+		}
+		if cam.Zoom != 4 {
+			t.Fatalf("zoom %.3f while following", cam.Zoom)
+		}
+	}
+	for range int(3 / SimTickDT) {
+		sn.Path[0].X = math.Min(sn.Path[0].X+speed*SimTickDT, float64(w.Width)-2)
+		UpdateAutoCamera(&cam, w, []*Snake{sn}, SimTickDT, testFbW, testFbH)
+		if cam.X > float64(w.Width)-halfW {
+			t.Fatalf("camera at %.1f shows past the edge", cam.X)
+		}
+	}
+	if cam.X != float64(w.Width)-halfW {
+		t.Errorf("camera at %.1f, want it held at the edge %.1f", cam.X, float64(w.Width)-halfW)
+	}
+
+	sn.Path[0].X = 700
+	for range int(0.5 / SimTickDT) {
+		UpdateAutoCamera(&cam, w, []*Snake{sn}, SimTickDT, testFbW, testFbH)
+	}
+	if math.Abs(cam.X-700) > 1e-9 {
+		t.Errorf("a head screens away left the camera at %.1f", cam.X)
+	}
+	sn.Path[0].X = 760
+	for range int(2 / SimTickDT) {
+		UpdateAutoCamera(&cam, w, []*Snake{sn}, SimTickDT, testFbW, testFbH)
+	}
+	if math.Abs(cam.X-760) > 0.1 {
+		t.Errorf("camera settled at %.1f, want the head at 760", cam.X)
+	}
+}
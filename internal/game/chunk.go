@@ -5,24 +5,39 @@ package game
 type Chunk struct {
 	CX, CY int
 
+	worldW, worldH int // size of the world the chunk belongs to
+
 	Pixels      []uint8 // RGBA8
 	Height      []uint8 // per-pixel height (0=ground, >0=solid)
 	Unbreakable []uint8 // 1=indestructible
+	Water       []uint8 // 1=open water (see setWater)
 
 	Tex uint32 // OpenGL texture id (created lazily)
 
+	Decals   *decalLayer // marks laid over Pixels; nil until the first
+	DecalTex uint32      // OpenGL texture id of Decals
+
 	NeedsUpload bool
 	NeedsShadow bool
+
+	// Streaming state (see World.GetChunk).
+	ready     bool    // roads repaired and saved edits restored
+	edited    bool    // changed since generation; unloading keeps a delta
+	lastUsed  float64 // World clock at the last GetChunk
+	roadFlags []uint8 // per pixel, the terrain before road repair (roadFlag*)
 }
 
-func NewChunk(cx, cy int) *Chunk {
+func NewChunk(cx, cy, worldW, worldH int) *Chunk {
 	n := ChunkSize * ChunkSize
 	return &Chunk{
 		CX:          cx,
 		CY:          cy,
+		worldW:      worldW,
+		worldH:      worldH,
 		Pixels:      make([]uint8, n*4),
 		Height:      make([]uint8, n),
 		Unbreakable: make([]uint8, n),
+		Water:       make([]uint8, n),
 		NeedsUpload: true,
 		NeedsShadow: true,
 	}
@@ -48,6 +63,15 @@ func (c *Chunk) set(i int, col RGB, height uint8, shade uint8, unbreakable uint8
 	c.Pixels[o+3] = shade
 	c.Height[i] = height
 	c.Unbreakable[i] = unbreakable
+	c.Water[i] = 0
+}
+
+// setWater makes pixel i open water: ground level, which the snake swims
+// through and peds and cars keep out of, and indestructible, so
+// explosions splash on it and nothing paints over it.
+func (c *Chunk) setWater(i int, col RGB) {
+	c.set(i, col, 0, ShadeLit, 1)
+	c.Water[i] = 1
 }
 
 func (c *Chunk) setRGBKeepHeight(i int, col RGB) {
@@ -80,7 +104,7 @@ func (c *Chunk) RecomputeShadows(w *World) {
 			wx := baseX + x
 			i := c.idx(x, y)
 
-			if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
+			if wx < 0 || wy < 0 || wx >= w.Width || wy >= w.Height {
 				c.setShade(i, ShadeDark)
 				continue
 			}
@@ -100,7 +124,7 @@ func (c *Chunk) RecomputeShadows(w *World) {
 				}
 				prevSX = sx
 				prevSY = sy
-				if sx < 0 || sy < 0 || sx >= WorldWidth || sy >= WorldHeight {
+				if sx < 0 || sy < 0 || sx >= w.Width || sy >= w.Height {
 					break
 				}
 
@@ -0,0 +1,309 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Per-pixel flags of a chunk's terrain before road repair. Neighbours read
+// them to repair the roads across their seams, so a chunk comes out the same
+// whatever order the chunks around it load in.
+const (
+	roadFlagRoad      uint8 = 1 << iota // road surface at ground level
+	roadFlagGround                      // height 0
+	roadFlagBreakable                   // not indestructible
+)
+
+// loadChunk finishes the chunk at cx, cy: it repairs its roads against its
+// neighbours and restores the edits it had when it was last unloaded.
+func (w *World) loadChunk(cx, cy int) *Chunk {
+	c := w.rawChunk(cx, cy)
+	w.repairRoads(c)
+	key := ChunkKey{X: cx, Y: cy}
+	if w.built[key] == nil {
+		w.built[key] = builtMask(c)
+	}
+	c.Decals = w.decals[key]
+	if d, ok := w.deltas[key]; ok {
+		d.apply(c)
+		c.edited = true
+		delete(w.deltas, key)
+	}
+	c.ready = true
+	return c
+}
+
+// rawChunk returns the chunk at cx, cy, generating it up to road repair if
+// it is not loaded. cx, cy must lie inside the world.
+func (w *World) rawChunk(cx, cy int) *Chunk {
+	key := ChunkKey{X: cx, Y: cy}
+	if c := w.chunks[key]; c != nil {
+		return c
+	}
+	c := w.generateRaw(cx, cy)
+	c.lastUsed = w.clock
+	w.chunks[key] = c
+	return c
+}
+
+// roadLayout returns the world's road network, solving it and the water
+// laid out with it on first use.
+func (w *World) roadLayout() *roadNetwork {
+	if w.roads == nil {
+		w.roads = newRoadNetwork(w.seed, w.Theme, w.Width, w.Height)
+		w.water = newWaterLayout(w.seed, w.Theme, w.Width, w.Height)
+	}
+	return w.roads
+}
+
+// generateRaw builds a new chunk's terrain up to road repair.
+func (w *World) generateRaw(cx, cy int) *Chunk {
+	if w.Map != nil {
+		c := NewChunk(cx, cy, w.Width, w.Height)
+		tp := buildThemePalette(w.Theme)
+		w.Map.fillChunk(c, w.seed, tp)
+		c.roadFlags = chunkRoadFlags(c, tp)
+		return c
+	}
+	c := NewChunk(cx, cy, w.Width, w.Height)
+	generateChunk(c, w.seed, w.Theme, w.roadLayout(), w.water)
+	if !w.Theme.NoRoads {
+		tp := buildThemePalette(w.Theme)
+		paintPerimeterRoads(c, tp)
+		c.roadFlags = chunkRoadFlags(c, tp)
+	}
+	return c
+}
+
+// pristineChunk regenerates the chunk at cx, cy as it was before any edits,
+// without touching the loaded one.
+func (w *World) pristineChunk(cx, cy int) *Chunk {
+	c := w.generateRaw(cx, cy)
+	w.repairRoads(c)
+	return c
+}
+
+// loadedChunk returns the chunk at cx, cy if it is loaded, without
+// generating it.
+func (w *World) loadedChunk(cx, cy int) *Chunk {
+	if cx < 0 || cy < 0 || cx > w.maxCx || cy > w.maxCy {
+		return nil
+	}
+	if c := w.chunks[ChunkKey{X: cx, Y: cy}]; c != nil && c.ready {
+		return c
+	}
+	return nil
+}
+
+// LoadedColorAt is ColorAt for loaded chunks only; ok is false where the
+// chunk is not loaded.
+func (w *World) LoadedColorAt(wx, wy int) (col RGB, ok bool) {
+	if !w.InBounds(wx, wy) {
+		return Palette.Border, true
+	}
+	cx, cy := wx/ChunkSize, wy/ChunkSize
+	c := w.loadedChunk(cx, cy)
+	if c == nil {
+		return RGB{}, false
+	}
+	return chunkColorAt(c, (wy-cy*ChunkSize)*ChunkSize+wx-cx*ChunkSize), true
+}
+
+// paintPerimeterRoads repaints the ring road and its dashed centre line
+// wherever block features left it at ground level.
+func paintPerimeterRoads(c *Chunk, tp themePalette) {
+	baseX, baseY := c.WorldOrigin()
+	for ly := 0; ly < ChunkSize; ly++ {
+		wy := baseY + ly
+		if wy < BorderThickness || wy >= c.worldH-BorderThickness {
+			continue
+		}
+		for lx := 0; lx < ChunkSize; lx++ {
+			wx := baseX + lx
+			if wx < BorderThickness || wx >= c.worldW-BorderThickness || !isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
+				continue
+			}
+			i := c.idx(lx, ly)
+			if c.Height[i] > 0 || c.Unbreakable[i] != 0 {
+				continue
+			}
+			c.setRGBKeepHeight(i, perimeterRoadColor(wx, wy, c.worldW, c.worldH, tp))
+		}
+	}
+}
+
+func chunkRoadFlags(c *Chunk, tp themePalette) []uint8 {
+	flags := make([]uint8, len(c.Height))
+	for i := range flags {
+		var f uint8
+		if c.Height[i] == 0 {
+			f |= roadFlagGround
+			if isRoadSurfaceColor(chunkColorAt(c, i), tp) {
+				f |= roadFlagRoad
+			}
+		}
+		if c.Unbreakable[i] == 0 {
+			f |= roadFlagBreakable
+		}
+		flags[i] = f
+	}
+	return flags
+}
+
+// repairRoads fills the one-pixel gaps block features leave in road
+// corridors, chunk seams included; on a hand-authored map, gaps anywhere.
+// It runs two passes, the second seeing the first's fills, so it reads the
+// flags of a two-pixel apron around the chunk from its neighbours,
+// generating them as far as needed.
+func (w *World) repairRoads(c *Chunk) {
+	if c.roadFlags == nil {
+		return
+	}
+	const apron = 2
+	const span = ChunkSize + 2*apron
+	baseX, baseY := c.WorldOrigin()
+	x0, y0 := baseX-apron, baseY-apron
+
+	region := make([]uint8, span*span)
+	for ry := 0; ry < span; ry++ {
+		wy := y0 + ry
+		for rx := 0; rx < span; rx++ {
+			wx := x0 + rx
+			if !w.InBounds(wx, wy) {
+				continue
+			}
+			cx, cy := wx/ChunkSize, wy/ChunkSize
+			src := c
+			if cx != c.CX || cy != c.CY {
+				src = w.rawChunk(cx, cy)
+			}
+			region[ry*span+rx] = src.roadFlags[(wy-cy*ChunkSize)*ChunkSize+wx-cx*ChunkSize]
+		}
+	}
+
+	// fill marks the gaps between roads in the r0..r1 square of the region.
+	fill := func(src []uint8, r0, r1 int) []uint8 {
+		dst := slices.Clone(src)
+		for ry := r0; ry < r1; ry++ {
+			wy := y0 + ry
+			if wy < BorderThickness+1 || wy >= w.Height-BorderThickness-1 {
+				continue
+			}
+			for rx := r0; rx < r1; rx++ {
+				wx := x0 + rx
+				if wx < BorderThickness+1 || wx >= w.Width-BorderThickness-1 {
+					continue
+				}
+				f := src[ry*span+rx]
+				if f&roadFlagRoad != 0 || f&roadFlagGround == 0 || f&roadFlagBreakable == 0 {
+					continue
+				}
+				if w.Map == nil && !isPerimeterRoad(wx, wy, w.Width, w.Height) && wx%Pattern >= RoadWidth && wy%Pattern >= RoadWidth {
+					continue
+				}
+				l := src[ry*span+rx-1]&roadFlagRoad != 0
+				r := src[ry*span+rx+1]&roadFlagRoad != 0
+				u := src[(ry-1)*span+rx]&roadFlagRoad != 0
+				d := src[(ry+1)*span+rx]&roadFlagRoad != 0
+				if (l && r) || (u && d) || ((l || r) && (u || d)) {
+					dst[ry*span+rx] |= roadFlagRoad
+				}
+			}
+		}
+		return dst
+	}
+	repaired := fill(fill(region, 1, span-1), apron, span-apron)
+
+	roadCol := buildThemePalette(w.Theme).Road
+	for ly := 0; ly < ChunkSize; ly++ {
+		for lx := 0; lx < ChunkSize; lx++ {
+			ri := (ly+apron)*span + lx + apron
+			if repaired[ri]&roadFlagRoad != 0 && region[ri]&roadFlagRoad == 0 {
+				c.setRGBKeepHeight(c.idx(lx, ly), roadCol)
+			}
+		}
+	}
+}
+
+// EvictIdleChunks unloads the chunks nothing has used for ChunkIdleTime
+// that lie more than a chunk outside keep, the area around the camera.
+// Edited chunks leave a delta behind, so they load back as they were. It
+// does its work at most every ChunkEvictInterval.
+func (w *World) EvictIdleChunks(keep RectF) {
+	if w.clock-w.lastEvict < ChunkEvictInterval {
+		return
+	}
+	w.lastEvict = w.clock
+	kx0 := floorDiv(int(keep.X0), ChunkSize) - 1
+	ky0 := floorDiv(int(keep.Y0), ChunkSize) - 1
+	kx1 := floorDiv(int(keep.X1), ChunkSize) + 1
+	ky1 := floorDiv(int(keep.Y1), ChunkSize) + 1
+	for key, c := range w.chunks {
+		if w.clock-c.lastUsed < ChunkIdleTime {
+			continue
+		}
+		if c.CX >= kx0 && c.CX <= kx1 && c.CY >= ky0 && c.CY <= ky1 {
+			continue
+		}
+		if c.ready && c.edited {
+			if d := diffChunk(c, w.pristineChunk(c.CX, c.CY)); len(d.Idx) > 0 {
+				w.deltas[key] = d
+			}
+		}
+		w.dropChunk(key)
+	}
+}
+
+// dropChunk forgets a chunk, handing its texture to the renderer to free.
+func (w *World) dropChunk(key ChunkKey) {
+	if c := w.chunks[key]; c != nil {
+		if c.Tex != 0 {
+			w.freedTex = append(w.freedTex, c.Tex)
+		}
+		if c.DecalTex != 0 {
+			w.freedTex = append(w.freedTex, c.DecalTex)
+		}
+	}
+	delete(w.chunks, key)
+}
+
+// diffChunk lists the pixels of c that differ from base.
+func diffChunk(c, base *Chunk) chunkDelta {
+	d := chunkDelta{CX: c.CX, CY: c.CY}
+	for i := range c.Height {
+		o := i * 4
+		if c.Height[i] == base.Height[i] &&
+			c.Pixels[o] == base.Pixels[o] &&
+			c.Pixels[o+1] == base.Pixels[o+1] &&
+			c.Pixels[o+2] == base.Pixels[o+2] {
+			continue
+		}
+		d.Idx = append(d.Idx, uint16(i))
+		d.RGB = append(d.RGB, c.Pixels[o], c.Pixels[o+1], c.Pixels[o+2])
+		d.Height = append(d.Height, c.Height[i])
+	}
+	return d
+}
+
+// check reports whether d fits a chunk.
+func (d *chunkDelta) check() error {
+	if len(d.RGB) != len(d.Idx)*3 || len(d.Height) != len(d.Idx) {
+		return fmt.Errorf("chunk %d,%d delta is malformed", d.CX, d.CY)
+	}
+	for _, i := range d.Idx {
+		if int(i) >= ChunkSize*ChunkSize {
+			return fmt.Errorf("chunk %d,%d pixel %d out of range", d.CX, d.CY, i)
+		}
+	}
+	return nil
+}
+
+// apply writes the delta's pixels into c. d must pass check.
+func (d *chunkDelta) apply(c *Chunk) {
+	for k, i := range d.Idx {
+		c.setRGBKeepHeight(int(i), RGB{R: d.RGB[k*3], G: d.RGB[k*3+1], B: d.RGB[k*3+2]})
+		c.Height[i] = d.Height[k]
+	}
+	c.NeedsUpload = true
+	c.NeedsShadow = true
+}
@@ -0,0 +1,375 @@
+package game
+
+import (
+	"math"
+	"slices"
+)
+
+// Structural collapse. Blasts and fires take buildings apart a pixel at a
+// time; damage queues a check, and World.Update flood-fills each building
+// next to it and compares what still stands with what generation built
+// there. A building that has lost too much of itself, or been broken into
+// stumps, comes down as a low pile of rubble. UpdateCollapses then throws
+// its debris and crushes whatever stood below.
+
+const (
+	rubbleHeight      = 3       // tallest rubble a collapse leaves
+	collapseIntegrity = 0.55    // share of a building that keeps it standing
+	collapseStump     = 24      // damaged pieces this small always fall
+	collapseMaxPixels = 1 << 15 // bigger buildings are never checked
+	collapseSpill     = 3       // how far the debris falls past the walls
+	collapseDebrisMax = 360     // debris particles per collapse
+)
+
+// structureCheck is damage at X, Y, reaching R pixels out.
+type structureCheck struct{ X, Y, R int }
+
+// collapse is a building that came down.
+type collapse struct {
+	x0, y0, x1, y1 int    // bounds of the fallen pixels, exclusive at x1, y1
+	fallen         []bool // by (y-y0)*(x1-x0) + x-x0
+	n              int    // fallen pixels
+	pieces         []collapsePiece
+}
+
+// collapsePiece is a pixel of a fallen building as it stood.
+type collapsePiece struct {
+	x, y int
+	h    uint8
+	col  RGB
+}
+
+// near reports whether a fallen pixel lies within r pixels of x, y.
+func (cl *collapse) near(x, y float64, r int) bool {
+	px, py := int(math.Round(x)), int(math.Round(y))
+	if px < cl.x0-r || py < cl.y0-r || px >= cl.x1+r || py >= cl.y1+r {
+		return false
+	}
+	w := cl.x1 - cl.x0
+	for yy := max(py-r, cl.y0); yy <= min(py+r, cl.y1-1); yy++ {
+		for xx := max(px-r, cl.x0); xx <= min(px+r, cl.x1-1); xx++ {
+			if cl.fallen[(yy-cl.y0)*w+xx-cl.x0] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// builtMask marks the pixels of a freshly generated chunk that belong to a
+// building: tall, breakable, and not foliage or water.
+func builtMask(c *Chunk) []bool {
+	mask := make([]bool, len(c.Height))
+	for i := range mask {
+		col := chunkColorAt(c, i)
+		mask[i] = c.Height[i] > rubbleHeight && c.Unbreakable[i] == 0 && c.Water[i] == 0 &&
+			!(col.G > col.R && col.G > col.B)
+	}
+	return mask
+}
+
+// builtAt reports whether generation put a building at wx, wy.
+func (w *World) builtAt(wx, wy int) bool {
+	if !w.InBounds(wx, wy) {
+		return false
+	}
+	key := ChunkKey{X: wx / ChunkSize, Y: wy / ChunkSize}
+	mask := w.built[key]
+	if mask == nil {
+		w.GetChunk(key.X, key.Y)
+		mask = w.built[key]
+	}
+	return mask[(wy-key.Y*ChunkSize)*ChunkSize+wx-key.X*ChunkSize]
+}
+
+// buildingAt names the building generation put at x, y, or failing that
+// the nearest one within r pixels, by the lowest pixel index it covers, so
+// every event about a building names it alike however little is left of
+// it. It is 0 with no building there or one too big to trace.
+func (w *World) buildingAt(x, y, r int) int {
+	bx, by, best := 0, 0, -1
+	for yy := y - r; yy <= y+r; yy++ {
+		for xx := x - r; xx <= x+r; xx++ {
+			d := (xx-x)*(xx-x) + (yy-y)*(yy-y)
+			if (best < 0 || d < best) && w.builtAt(xx, yy) {
+				bx, by, best = xx, yy, d
+			}
+		}
+	}
+	if best < 0 {
+		return 0
+	}
+	whole, ok := w.flood(bx, by, make(map[int]bool), w.builtAt)
+	if !ok {
+		return 0
+	}
+	return slices.Min(whole)
+}
+
+// standing reports whether the building generation put at wx, wy is still
+// there.
+func (w *World) standing(wx, wy int) bool {
+	return w.builtAt(wx, wy) && w.HeightAt(wx, wy) > rubbleHeight
+}
+
+// queueStructureCheck asks the next Update to test the buildings within r
+// pixels of x, y.
+func (w *World) queueStructureCheck(x, y, r int) {
+	for _, ch := range w.checks {
+		if math.Hypot(float64(x-ch.X), float64(y-ch.Y))+float64(r) <= float64(ch.R) {
+			return
+		}
+	}
+	w.checks = append(w.checks, structureCheck{X: x, Y: y, R: r})
+}
+
+// settleStructures runs the queued checks, bringing down every building
+// next to the damage that can no longer stand.
+func (w *World) settleStructures() {
+	if len(w.checks) == 0 {
+		return
+	}
+	checks := w.checks
+	w.checks = nil
+
+	seen := make(map[int]bool)      // standing pixels already looked at
+	builtSeen := make(map[int]bool) // pixels of the buildings as generated
+	origin := make(map[int]int)     // generated pixel -> index into sizes
+	var sizes []int                 // pixels of each generated building; -1 if too big
+	for _, ch := range checks {
+		r := ch.R + 1
+		for y := ch.Y - r; y <= ch.Y+r; y++ {
+			for x := ch.X - r; x <= ch.X+r; x++ {
+				if (x-ch.X)*(x-ch.X)+(y-ch.Y)*(y-ch.Y) > r*r || !w.InBounds(x, y) ||
+					seen[y*w.Width+x] || !w.standing(x, y) {
+					continue
+				}
+				piece, ok := w.flood(x, y, seen, w.standing)
+				if !ok {
+					continue
+				}
+				label, known := origin[y*w.Width+x]
+				if !known {
+					whole, ok := w.flood(x, y, builtSeen, w.builtAt)
+					label = len(sizes)
+					sizes = append(sizes, len(whole))
+					if !ok {
+						sizes[label] = -1
+					}
+					for _, k := range whole {
+						origin[k] = label
+					}
+				}
+				size := sizes[label]
+				if size < 0 || len(piece) >= size {
+					continue
+				}
+				if len(piece) < collapseStump || float64(len(piece)) < collapseIntegrity*float64(size) {
+					w.collapse(piece)
+				}
+			}
+		}
+	}
+}
+
+// flood collects the pixels 4-connected to x, y that pass in, marking them
+// in seen. ok is false if it gave up past collapseMaxPixels.
+func (w *World) flood(x, y int, seen map[int]bool, in func(x, y int) bool) (out []int, ok bool) {
+	start := y*w.Width + x
+	seen[start] = true
+	out = append(out, start)
+	for n := 0; n < len(out); n++ {
+		if len(out) > collapseMaxPixels {
+			return out, false
+		}
+		px, py := out[n]%w.Width, out[n]/w.Width
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := px+d[0], py+d[1]
+			k := ny*w.Width + nx
+			if !w.InBounds(nx, ny) || seen[k] || !in(nx, ny) {
+				continue
+			}
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	return out, true
+}
+
+// collapse brings down the building pixels of piece, leaving rubble, and
+// records them for UpdateCollapses.
+func (w *World) collapse(piece []int) {
+	cl := collapse{x0: w.Width, y0: w.Height, n: len(piece)}
+	for _, k := range piece {
+		x, y := k%w.Width, k/w.Width
+		cl.x0, cl.y0 = min(cl.x0, x), min(cl.y0, y)
+		cl.x1, cl.y1 = max(cl.x1, x+1), max(cl.y1, y+1)
+	}
+	cl.fallen = make([]bool, (cl.x1-cl.x0)*(cl.y1-cl.y0))
+	every := max(1, len(piece)/collapseDebrisMax)
+	for n, k := range piece {
+		x, y := k%w.Width, k/w.Width
+		c := w.GetChunk(x/ChunkSize, y/ChunkSize)
+		i := c.idx(x-c.CX*ChunkSize, y-c.CY*ChunkSize)
+		col := chunkColorAt(c, i)
+		if n%every == 0 {
+			cl.pieces = append(cl.pieces, collapsePiece{x: x, y: y, h: c.Height[i], col: col})
+		}
+		hv := hash2D(w.seed^0xC011A95E, x, y)
+		g := int(hv>>59) - 16
+		c.set(i, lerpRGB(col, Palette.Rubble, 0.6).Add(g, g, g), uint8(1+(hv&0xFF)%rubbleHeight), ShadeLit, 0)
+		c.NeedsUpload = true
+		c.edited = true
+		cl.fallen[(y-cl.y0)*(cl.x1-cl.x0)+x-cl.x0] = true
+	}
+	w.markShadows(cl.x0, cl.y0, cl.x1-1, cl.y1-1, MaxShadowDist)
+	w.roadsChanged(cl.x0, cl.y0, cl.x1-1, cl.y1-1)
+
+	// A fire in the building goes out as it falls; the collapse is what
+	// counts.
+	for _, key := range sortedBurnKeys(w.burningBuildings) {
+		bb := w.burningBuildings[key]
+		if cl.near(float64(bb.X0+bb.X1)/2, float64(bb.Y0+bb.Y1)/2, 2) {
+			delete(w.burningBuildings, key)
+		}
+	}
+	w.collapses = append(w.collapses, cl)
+	w.Events.Emit(Event{
+		Type: EventBuildingCollapsed, X: float64(cl.x0+cl.x1) / 2, Y: float64(cl.y0+cl.y1) / 2, Data: cl.n,
+		Building: w.buildingAt(piece[0]%w.Width, piece[0]/w.Width, 0),
+	})
+}
+
+// UpdateCollapses throws the debris of the buildings that came down in the
+// last World.Update and crushes everyone and everything next to them.
+func UpdateCollapses(w *World, ps *ParticleSystem, peds *PedestrianSystem, traffic *TrafficSystem, cam *Camera, cops *CopSystem, mil *MilitarySystem) {
+	for k := range w.collapses {
+		cl := &w.collapses[k]
+		size := math.Sqrt(float64(cl.n))
+		PlayExplosionSound(min(size*0.5, 30))
+		if cam != nil {
+			cam.AddShake(min(size*0.15, 6), 0.5)
+		}
+		if ps != nil {
+			spawnCollapseDebris(cl, ps)
+		}
+		crushUnder(cl, w, ps, peds, traffic, cops, mil)
+	}
+}
+
+// spawnCollapseDebris drops the pieces of a fallen building from where
+// they stood, in a cloud of dust.
+func spawnCollapseDebris(cl *collapse, ps *ParticleSystem) {
+	r := NewRand(hash2D(ps.seed^0xDEB815, cl.x0, cl.y0))
+	cx, cy := float64(cl.x0+cl.x1-1)/2, float64(cl.y0+cl.y1-1)/2
+	for _, pc := range cl.pieces {
+		dx, dy := float64(pc.x)-cx, float64(pc.y)-cy
+		d := math.Hypot(dx, dy) + 1
+		spd := r.RangeF(4, 24)
+		ps.Add(Particle{
+			X: float64(pc.x), Y: float64(pc.y),
+			VX: dx/d*spd + r.RangeF(-4, 4), VY: dy/d*spd + r.RangeF(-4, 4),
+			Z: float64(pc.h) * r.RangeF(0.5, 1), VZ: r.RangeF(-6, 14),
+			Size: 1, MaxLife: r.RangeF(1.2, 2.6),
+			Col: pc.col.Mul(uint8(r.Range(170, 240))), Kind: ParticleDebris,
+		})
+	}
+	dust := Palette.Rubble.Add(40, 36, 30)
+	for range min(len(cl.pieces)/2, 160) + 12 {
+		pc := cl.pieces[r.Intn(len(cl.pieces))]
+		g := r.Range(-12, 12)
+		ps.Add(Particle{
+			X: float64(pc.x) + r.RangeF(-1, 1), Y: float64(pc.y) + r.RangeF(-1, 1),
+			VX: r.RangeF(-10, 10), VY: r.RangeF(-10, 10),
+			Z: r.RangeF(0, float64(pc.h)), VZ: r.RangeF(6, 24),
+			Size: 1.2, MaxLife: r.RangeF(1.4, 3.2),
+			Col: dust.Add(g, g, g), Kind: ParticleSmoke,
+		})
+	}
+}
+
+// crushUnder kills the people within collapseSpill of a fallen building and
+// smashes the vehicles there.
+func crushUnder(cl *collapse, w *World, ps *ParticleSystem, peds *PedestrianSystem, traffic *TrafficSystem, cops *CopSystem, mil *MilitarySystem) {
+	stain := RGB{R: 130, G: 20, B: 20}
+	squash := func(x, y float64) {
+		w.AddDecal(int(math.Round(x)), int(math.Round(y)), DecalBlood, stain, 1)
+		if ps != nil {
+			ps.SpawnBlood(x, y, 0, 1, 14, 0.8)
+		}
+	}
+	const carReach = collapseSpill + 1
+	const carDamage = 12.0
+
+	if peds != nil {
+		for i := range peds.P {
+			p := &peds.P[i]
+			if p.Alive && cl.near(p.X, p.Y, collapseSpill) {
+				p.Alive = false
+				squash(p.X, p.Y)
+				paintFallenPed(w, int(math.Round(p.X)), int(math.Round(p.Y)), p.Skin, p.Col)
+			}
+		}
+	}
+	if traffic != nil {
+		for i := range traffic.Cars {
+			c := &traffic.Cars[i]
+			if !c.Alive || !cl.near(c.X, c.Y, carReach) {
+				continue
+			}
+			c.HP.Damage(carDamage)
+			c.Speed = 0
+			if c.HP.IsDead() {
+				c.Alive = false
+				if ps != nil {
+					cx, cy := int(math.Round(c.X)), int(math.Round(c.Y))
+					SpawnExplosionWithShockwave(cx, cy, w.ColorAt(cx, cy), 0.5, 0, w, ps)
+				}
+			}
+		}
+	}
+	if cops != nil {
+		for i := range cops.Peds {
+			p := &cops.Peds[i]
+			if p.Alive && cl.near(p.X, p.Y, collapseSpill) {
+				p.Alive = false
+				squash(p.X, p.Y)
+			}
+		}
+		for i := range cops.Cars {
+			c := &cops.Cars[i]
+			if !c.Alive || !cl.near(c.X, c.Y, carReach) {
+				continue
+			}
+			c.HP.Damage(carDamage)
+			if c.HP.IsDead() {
+				c.Alive = false
+				if ps != nil {
+					SpawnExplosionWithShockwave(int(c.X), int(c.Y), RGB{50, 100, 200}, 0.6, 0, w, ps)
+				}
+			}
+		}
+	}
+	if mil != nil {
+		for i := range mil.Troops {
+			t := &mil.Troops[i]
+			if t.Alive && cl.near(t.X, t.Y, collapseSpill) {
+				t.Alive = false
+				squash(t.X, t.Y)
+			}
+		}
+		for i := range mil.Tanks {
+			t := &mil.Tanks[i]
+			if !t.Alive || !cl.near(t.X, t.Y, carReach) {
+				continue
+			}
+			t.HP.Damage(carDamage)
+			if t.HP.IsDead() {
+				t.Alive = false
+				if ps != nil {
+					SpawnExplosionWithShockwave(int(t.X), int(t.Y), RGB{80, 100, 50}, 1.2, 0, w, ps)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"image/color"
+	"testing"
+)
+
+// wallWorld is a mapImage world with a 40 by 3 pixel wall standing at
+// 40,40, well clear of its other building.
+func wallWorld(t *testing.T) *World {
+	t.Helper()
+	img := mapImage(MinWorldSize, MinWorldSize)
+	for y := 40; y < 43; y++ {
+		for x := 40; x < 80; x++ {
+			img.Set(x, y, color.NRGBA{R: 192, A: 255})
+		}
+	}
+	m, err := DecodeWorldMap(img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWorld(1, themeNamed(t, "City"), m.Width, m.Height)
+	w.Map = m
+	w.Resize(m.Width, m.Height)
+	for x := 40; x < 80; x++ {
+		if !w.standing(x, 41) {
+			t.Fatalf("wall pixel %d,41 is not a standing building", x)
+		}
+	}
+	return w
+}
+
+// TestSettleStructures knocks pieces out of the wall and checks which of
+// what is left stands: a piece keeps standing while it holds
+// collapseIntegrity of the wall and is no stump.
+func TestSettleStructures(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		x0, x1   int // wall columns knocked out
+		fallen   [2]int
+		standing [2]int // columns left standing, [0,0] for none
+	}{
+		{"chipped end", 40, 41, [2]int{0, 0}, [2]int{41, 80}},
+		{"stump cut off", 45, 46, [2]int{40, 45}, [2]int{46, 80}},
+		{"still held", 52, 54, [2]int{40, 52}, [2]int{54, 80}},
+		{"cut in half", 59, 61, [2]int{40, 80}, [2]int{0, 0}},
+		{"most of it gone", 40, 66, [2]int{66, 80}, [2]int{0, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := wallWorld(t)
+			burnRect(w, tc.x0, 40, tc.x1, 43)
+			w.settleStructures()
+			if len(w.checks) != 0 {
+				t.Errorf("%d checks left queued", len(w.checks))
+			}
+			for x := 40; x < 80; x++ {
+				if x >= tc.x0 && x < tc.x1 {
+					continue
+				}
+				want := "standing"
+				if x >= tc.fallen[0] && x < tc.fallen[1] {
+					want = "fallen"
+				}
+				for y := 40; y < 43; y++ {
+					h := w.HeightAt(x, y)
+					got := "standing"
+					if h <= rubbleHeight {
+						got = "fallen"
+						if h == 0 {
+							t.Fatalf("%d,%d flattened, want rubble", x, y)
+						}
+					}
+					if got != want {
+						t.Fatalf("%d,%d %s at height %d, want %s", x, y, got, h, want)
+					}
+				}
+			}
+			if tc.fallen[1] > 0 && len(w.collapses) == 0 {
+				t.Error("no collapse recorded")
+			}
+			if tc.fallen[1] == 0 && len(w.collapses) != 0 {
+				t.Errorf("%d collapses recorded for a standing wall", len(w.collapses))
+			}
+		})
+	}
+}
+
+// TestCollapseDebris brings the wall down and checks its debris starts
+// where it stood and flies outward, and that it crushes those next to it
+// and no one further off.
+func TestCollapseDebris(t *testing.T) {
+	w := wallWorld(t)
+	peds := crowd(
+		Pedestrian{X: 65, Y: 42 + collapseSpill, Alive: true},
+		Pedestrian{X: 65, Y: 42 + collapseSpill + 1.6, Alive: true},
+		Pedestrian{X: 79 + collapseSpill, Y: 41, Alive: true},
+	)
+	burnRect(w, 59, 40, 61, 43)
+	w.settleStructures()
+	if len(w.collapses) == 0 {
+		t.Fatal("the wall stands")
+	}
+	ps := NewParticleSystem(4096, 1)
+	UpdateCollapses(w, ps, peds, nil, nil, nil, nil)
+
+	debris, outward := 0, 0.0
+	for _, p := range ps.P {
+		if p.Kind != ParticleDebris {
+			continue
+		}
+		debris++
+		var from *collapse
+		for k := range w.collapses {
+			if w.collapses[k].near(p.X, p.Y, 0) {
+				from = &w.collapses[k]
+			}
+		}
+		if from == nil {
+			t.Fatalf("debris from %v,%v, outside the fallen wall", p.X, p.Y)
+		}
+		if p.Z <= 0 || p.Z > mapBuildingHeight {
+			t.Errorf("debris dropped from height %v", p.Z)
+		}
+		outward += (p.X - float64(from.x0+from.x1-1)/2) * p.VX
+	}
+	if debris == 0 {
+		t.Fatal("no debris")
+	}
+	if outward <= 0 {
+		t.Error("the debris flies inward")
+	}
+	for i, want := range []bool{false, true, false} {
+		if peds.P[i].Alive != want {
+			t.Errorf("ped %d alive %v, want %v", i, peds.P[i].Alive, want)
+		}
+	}
+}
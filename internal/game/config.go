@@ -1,11 +1,19 @@
 package game
 
-// World dimensions (in world pixels).
-// Chosen as a 4:3 size aligned to the city pattern (33 px) so
-// auto-fit camera fills the window without partial edge blocks.
+// Default world dimensions (in world pixels), used by levels that don't set
+// their own. Chosen as a 4:3 size aligned to the city pattern (33 px); the
+// camera always shows this much of the city, so default worlds fill the
+// window without partial edge blocks and larger ones scroll.
 const (
-	WorldWidth  = 264
-	WorldHeight = 198
+	DefaultWorldWidth  = 264
+	DefaultWorldHeight = 198
+)
+
+// World size limits (in world pixels). Level sizes are rounded up to whole
+// city blocks.
+const (
+	MinWorldSize = 4 * Pattern
+	MaxWorldSize = 64 * Pattern
 )
 
 // Window defaults.
@@ -20,6 +28,14 @@ const (
 // Chunking.
 const ChunkSize = 128
 
+// Chunk streaming (seconds of play). Chunks generate on first use; one that
+// nothing has used for ChunkIdleTime and that lies away from the camera is
+// unloaded, keeping only the pixels it differs from generation by.
+const (
+	ChunkIdleTime      = 5.0
+	ChunkEvictInterval = 1.0
+)
+
 // Road/city-block layout (in world pixels).
 const (
 	RoadWidth     = 5
@@ -92,4 +108,5 @@ const (
 	SnakeEatRadius    = 2.5
 	SnakeCarEatRadius = 3.5
 	SnakeBonusRadius  = 3.0
+	SnakeSwimSpeed    = 0.6 // speed multiplier while the head is in water
 )
@@ -53,6 +53,8 @@ type CopCar struct {
 	ID         uint32 // unique ID so deployed peds can find their car
 	Kind       CopCarKind
 	SirenTimer float64 // timer for continuous siren sound
+
+	LastX, LastY float64 // position at the previous simulation tick
 }
 
 type CopPed struct {
@@ -65,6 +67,8 @@ type CopPed struct {
 	OwnerCarID   uint32 // 0 = standalone; >0 = deployed from this car
 	Returning    bool   // heading back to car before it drives off
 	Kind         CopPedKind
+
+	LastX, LastY float64 // position at the previous simulation tick
 }
 
 type Helicopter struct {
@@ -82,6 +86,8 @@ type Helicopter struct {
 	FireTimer    float64 // time until next individual shot within a burst
 	Burning      bool    // true at low HP — crashes after BurnTimer reaches 3s
 	BurnTimer    float64
+
+	LastX, LastY float64 // position at the previous simulation tick
 }
 
 // HeliShot is a single gatling round fired by a helicopter.
@@ -99,6 +105,9 @@ type CopSystem struct {
 	seed       uint64
 	SpawnTimer float64
 	nextCarID  uint32
+
+	Events      *EventBus // receives gameplay events; may be nil
+	wantedStars int       // last reported wanted level, see EventWantedChanged
 }
 
 func NewCopSystem(seed uint64) *CopSystem {
@@ -115,6 +124,7 @@ func (cs *CopSystem) Reset() {
 	cs.Shots = cs.Shots[:0]
 	cs.SpawnTimer = 3.0
 	cs.nextCarID = 1
+	cs.wantedStars = 0
 }
 
 // carPos returns the position of the car with the given ID, or false if not found.
@@ -183,6 +193,13 @@ func (cs *CopSystem) deployedAliveCount(carID uint32) int {
 
 // Update advances all cop AI, handles collisions, and spawns reinforcements.
 func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *ParticleSystem, cam *Camera, now float64) {
+	if snake != nil {
+		if stars := int(snake.WantedLevel); stars != cs.wantedStars {
+			cs.wantedStars = stars
+			hx, hy := snake.Head()
+			cs.Events.Emit(Event{Type: EventWantedChanged, X: hx, Y: hy, Data: stars})
+		}
+	}
 	if snake == nil || !snake.Alive || snake.WantedLevel < WantedHalf {
 		return
 	}
@@ -237,10 +254,17 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 			onHorizRoad := iy%Pattern < RoadWidth
 
 			if onVertRoad || onHorizRoad {
-				// On a road: follow the grid.
+				// On a road: follow the grid, skidding round sharp turns.
+				prevHeading := c.Heading
 				if onVertRoad && onHorizRoad {
-					// At intersection: pick best cardinal direction toward target.
-					c.Heading = bestCardinalToward(c.X, c.Y, targetX, targetY)
+					// At intersection: take the way the roads lead toward the
+					// target, round any that are blown up, or failing that the
+					// best cardinal direction.
+					h, ok := world.routeHeading(c.X, c.Y, targetX, targetY, uint64(c.ID))
+					if !ok {
+						h = bestCardinalToward(c.X, c.Y, targetX, targetY)
+					}
+					c.Heading = h
 				} else if onVertRoad {
 					// On a vertical road: heading must be N or S.
 					if math.Abs(math.Cos(c.Heading)) > math.Abs(math.Sin(c.Heading)) {
@@ -260,6 +284,11 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 						}
 					}
 				}
+				if c.Speed > 20 && math.Abs(angDiff(prevHeading, c.Heading)) > 1 {
+					for j := 0.0; j < 4; j++ {
+						world.tyreMarks(c.X-math.Cos(prevHeading)*j, c.Y-math.Sin(prevHeading)*j, prevHeading, c.Size, 0.6)
+					}
+				}
 
 				nx := c.X + math.Cos(c.Heading)*c.Speed*dt
 				ny := c.Y + math.Sin(c.Heading)*c.Speed*dt
@@ -273,8 +302,8 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 					centerY := float64((iy/Pattern)*Pattern) + float64(RoadWidth)/2.0
 					ny += (centerY - c.Y) * 5.0 * dt
 				}
-				c.X = clampF(nx, 0, float64(WorldWidth-1))
-				c.Y = clampF(ny, 0, float64(WorldHeight-1))
+				c.X = clampF(nx, 0, float64(world.Width-1))
+				c.Y = clampF(ny, 0, float64(world.Height-1))
 			} else {
 				// Off-road: steer directly toward target to reach a road.
 				dx := targetX - c.X
@@ -293,11 +322,11 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 				}
 				nx := c.X + math.Cos(c.Heading)*c.Speed*dt
 				ny := c.Y + math.Sin(c.Heading)*c.Speed*dt
-				if world.HeightAt(int(math.Round(nx)), int(math.Round(ny))) > 0 {
+				if !world.IsWalkable(int(math.Round(nx)), int(math.Round(ny))) {
 					nx, ny = c.X, c.Y
 				}
-				c.X = clampF(nx, 0, float64(WorldWidth-1))
-				c.Y = clampF(ny, 0, float64(WorldHeight-1))
+				c.X = clampF(nx, 0, float64(world.Width-1))
+				c.Y = clampF(ny, 0, float64(world.Height-1))
 			}
 
 			// Ram snake on contact.
@@ -339,6 +368,7 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 	}
 
 	// --- Cop peds ---
+	hideout := world.interiorAt(hx, hy) // the building the snake is in, if any
 	for i := range cs.Peds {
 		p := &cs.Peds[i]
 		if !p.Alive {
@@ -402,20 +432,28 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 			}
 			spd = 18.0
 		}
+		// The snake has holed up indoors: chasers break in through the
+		// nearest door, flankers through another.
+		if hideout != nil && p.Kind != PedSniper && !hideout.inside(p.X, p.Y) {
+			tx, ty := hideout.breachTarget(p.X, p.Y, hx, hy, p.Kind == PedFlanker)
+			if td := math.Hypot(tx-p.X, ty-p.Y); td > 0.1 {
+				moveX, moveY = (tx-p.X)/td, (ty-p.Y)/td
+			}
+		}
 
 		if moveX != 0 || moveY != 0 {
 			nx := p.X + moveX*spd*dt
 			ny := p.Y + moveY*spd*dt
-			// Building collision: only move if destination is clear.
-			if world.HeightAt(int(math.Round(nx)), int(math.Round(ny))) > 0 {
+			// Building and water collision: only move if destination is clear.
+			if !world.IsWalkable(int(math.Round(nx)), int(math.Round(ny))) {
 				// Blocked: pick a new position.
 				r := NewRand(cs.seed ^ uint64(i)*0xC0C0 ^ uint64(p.StuckTimer*100))
 				for range 12 {
 					tx := int(math.Round(p.X)) + r.Range(-8, 8)
 					ty := int(math.Round(p.Y)) + r.Range(-8, 8)
-					if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && world.HeightAt(tx, ty) == 0 {
-						p.X = float64(tx) + 0.5
-						p.Y = float64(ty) + 0.5
+					if world.IsWalkable(tx, ty) {
+						p.X = float64(tx)
+						p.Y = float64(ty)
 						break
 					}
 				}
@@ -430,7 +468,7 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 					for range 20 {
 						tx := int(math.Round(hx)) + r.Range(-20, 20)
 						ty := int(math.Round(hy)) + r.Range(-20, 20)
-						if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && world.HeightAt(tx, ty) == 0 {
+						if world.IsWalkable(tx, ty) {
 							p.X = float64(tx)
 							p.Y = float64(ty)
 							p.StuckTimer = 0
@@ -500,8 +538,8 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 		}
 
 		h.CircleAngle += 0.75 * dt
-		h.X = clampF(h.CenterX+math.Cos(h.CircleAngle)*h.CircleRadius, 2, float64(WorldWidth-2))
-		h.Y = clampF(h.CenterY+math.Sin(h.CircleAngle)*h.CircleRadius, 2, float64(WorldHeight-2))
+		h.X = clampF(h.CenterX+math.Cos(h.CircleAngle)*h.CircleRadius, 2, float64(world.Width-2))
+		h.Y = clampF(h.CenterY+math.Sin(h.CircleAngle)*h.CircleRadius, 2, float64(world.Height-2))
 		h.Heading = h.CircleAngle + math.Pi/2
 		h.RotorAngle += 14.0 * dt
 
@@ -584,7 +622,7 @@ func (cs *CopSystem) Update(dt float64, snake *Snake, world *World, ps *Particle
 		iy := int(math.Round(shot.Y))
 		hit := false
 
-		if ix < 0 || iy < 0 || ix >= WorldWidth || iy >= WorldHeight {
+		if ix < 0 || iy < 0 || ix >= world.Width || iy >= world.Height {
 			hit = true
 		} else if world.HeightAt(ix, iy) > 0 {
 			if ps != nil {
@@ -642,9 +680,9 @@ func (cs *CopSystem) spawnDeployedPeds(c *CopCar, world *World) {
 	}
 	for i := 0; i < numCops; i++ {
 		off := offsets[i]
-		px := clampF(c.X+off[0], 0, float64(WorldWidth-1))
-		py := clampF(c.Y+off[1], 0, float64(WorldHeight-1))
-		if world.HeightAt(int(math.Round(px)), int(math.Round(py))) > 0 {
+		px := clampF(c.X+off[0], 0, float64(world.Width-1))
+		py := clampF(c.Y+off[1], 0, float64(world.Height-1))
+		if !world.IsWalkable(int(math.Round(px)), int(math.Round(py))) {
 			px, py = c.X, c.Y // fallback to car center
 		}
 		cs.Peds = append(cs.Peds, CopPed{
@@ -690,7 +728,7 @@ func (cs *CopSystem) spawnForWanted(snake *Snake, world *World, r *Rand) {
 
 	if aliveCars < targetCars {
 		cs.nextCarID++
-		sx, sy := edgeSpawnPos(hx, hy, r)
+		sx, sy := edgeSpawnPos(world, hx, hy, r)
 		kind := CarKindChaser
 		if snake.WantedLevel >= 3.5 && r.Intn(2) == 0 {
 			kind = CarKindInterceptor
@@ -711,7 +749,7 @@ func (cs *CopSystem) spawnForWanted(snake *Snake, world *World, r *Rand) {
 	}
 
 	if alivePeds < targetPeds {
-		sx, sy := edgeSpawnPos(hx, hy, r)
+		sx, sy := edgeSpawnPos(world, hx, hy, r)
 		needed := targetPeds - alivePeds
 		kind := randomPedKind(snake.WantedLevel, r)
 		groupSize := 1
@@ -764,7 +802,7 @@ func (cs *CopSystem) spawnPedGroup(n int, cx, cy float64, kind CopPedKind, world
 		for range 20 {
 			tx := int(math.Round(cx)) + r.Range(-6, 6)
 			ty := int(math.Round(cy)) + r.Range(-6, 6)
-			if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && world.HeightAt(tx, ty) == 0 {
+			if world.IsWalkable(tx, ty) {
 				cs.Peds = append(cs.Peds, CopPed{
 					X: float64(tx), Y: float64(ty),
 					HP:         NewHealth(6.0),
@@ -813,22 +851,33 @@ func bestCardinalToward(cx, cy, tx, ty float64) float64 {
 	return best
 }
 
-func edgeSpawnPos(hx, hy float64, r *Rand) (float64, float64) {
-	switch r.Intn(4) {
-	case 0:
-		return r.RangeF(1, float64(WorldWidth-2)), 1
-	case 1:
-		return r.RangeF(1, float64(WorldWidth-2)), float64(WorldHeight - 2)
-	case 2:
-		return 1, r.RangeF(1, float64(WorldHeight-2))
-	default:
-		return float64(WorldWidth - 2), r.RangeF(1, float64(WorldHeight-2))
+// edgeSpawnPos picks a point on the edge of the view area around the head,
+// so reinforcements arrive from just off screen even in large worlds. It
+// tries a few for one on walkable ground, out of buildings and water.
+func edgeSpawnPos(w *World, hx, hy float64, r *Rand) (x, y float64) {
+	a := w.ViewArea(hx, hy)
+	for range 8 {
+		switch r.Intn(4) {
+		case 0:
+			x, y = r.RangeF(a.X0+1, a.X1-2), a.Y0+1
+		case 1:
+			x, y = r.RangeF(a.X0+1, a.X1-2), a.Y1-2
+		case 2:
+			x, y = a.X0+1, r.RangeF(a.Y0+1, a.Y1-2)
+		default:
+			x, y = a.X1-2, r.RangeF(a.Y0+1, a.Y1-2)
+		}
+		if w.IsWalkable(int(math.Round(x)), int(math.Round(y))) {
+			break
+		}
 	}
+	return x, y
 }
 
 func (cs *CopSystem) RemoveDead() {
 	for i := 0; i < len(cs.Cars); {
-		if !cs.Cars[i].Alive {
+		if c := &cs.Cars[i]; !c.Alive {
+			cs.Events.Emit(Event{Type: EventCopCarDestroyed, X: c.X, Y: c.Y})
 			cs.Cars[i] = cs.Cars[len(cs.Cars)-1]
 			cs.Cars = cs.Cars[:len(cs.Cars)-1]
 		} else {
@@ -836,7 +885,8 @@ func (cs *CopSystem) RemoveDead() {
 		}
 	}
 	for i := 0; i < len(cs.Peds); {
-		if !cs.Peds[i].Alive {
+		if p := &cs.Peds[i]; !p.Alive {
+			cs.Events.Emit(Event{Type: EventCopKilled, X: p.X, Y: p.Y})
 			cs.Peds[i] = cs.Peds[len(cs.Peds)-1]
 			cs.Peds = cs.Peds[:len(cs.Peds)-1]
 		} else {
@@ -844,7 +894,8 @@ func (cs *CopSystem) RemoveDead() {
 		}
 	}
 	for i := 0; i < len(cs.Helis); {
-		if !cs.Helis[i].Alive {
+		if h := &cs.Helis[i]; !h.Alive {
+			cs.Events.Emit(Event{Type: EventHeliKilled, X: h.X, Y: h.Y})
 			cs.Helis[i] = cs.Helis[len(cs.Helis)-1]
 			cs.Helis = cs.Helis[:len(cs.Helis)-1]
 		} else {
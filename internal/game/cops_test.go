@@ -0,0 +1,67 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// TestPursuitKeepsOutOfWater puts the snake out at sea and cops and troops
+// on the beach, and checks that none of them, nor the reinforcements sent
+// after it, ever wades in.
+func TestPursuitKeepsOutOfWater(t *testing.T) {
+	for _, seed := range []uint64{1, 5, 42} {
+		w := newTestWorld(seed, themeNamed(t, "Beach"), DefaultWorldWidth, DefaultWorldHeight)
+		// The snake floats a little way out from the shore, which the
+		// pursuit starts from.
+		sx, sy, bx, by := -1, -1, -1, -1
+		for y := BorderThickness; y < w.Height-BorderThickness && sx < 0; y++ {
+			for x := BorderThickness; x < w.Width-BorderThickness-12 && sx < 0; x++ {
+				if w.IsWalkable(x, y) && w.IsWater(x+1, y) && w.IsWater(x+12, y) {
+					sx, sy, bx, by = x+12, y, x, y
+				}
+			}
+		}
+		if sx < 0 {
+			t.Fatalf("seed %d: no shore", seed)
+		}
+		snake := NewSnake(float64(sx), float64(sy), SnakeBaseSpeed)
+		snake.WantedLevel = WantedMax
+		cs := NewCopSystem(seed)
+		ms := NewMilitarySystem(seed)
+		ms.Active = true
+		at := func(dx float64) (float64, float64) { return float64(bx) - dx, float64(by) }
+		x, y := at(0)
+		cs.Cars = append(cs.Cars, CopCar{X: x, Y: y, Speed: 40, HP: NewHealth(10), Alive: true, Size: CarSize, State: CarChasing, ID: 1})
+		x, y = at(1)
+		cs.Peds = append(cs.Peds, CopPed{X: x, Y: y, HP: NewHealth(6), Alive: true, Kind: PedChaser})
+		x, y = at(2)
+		ms.Tanks = append(ms.Tanks, Tank{X: x, Y: y, Speed: 15, HP: NewHealth(50), Alive: true, Size: CarSize * 1.5, FireTimer: 1e9})
+		x, y = at(1)
+		ms.Troops = append(ms.Troops, MilTroop{X: x, Y: y, HP: NewHealth(8), Alive: true})
+
+		cam := &Camera{Zoom: 1}
+		wet := func(what string, i int, x, y float64) {
+			if w.IsWater(int(math.Round(x)), int(math.Round(y))) {
+				t.Fatalf("seed %d: %s %d in the water at %.1f,%.1f", seed, what, i, x, y)
+			}
+		}
+		for tick := range 600 {
+			now := float64(tick) * SimTickDT
+			cs.Update(SimTickDT, snake, w, nil, cam, now)
+			ms.Update(SimTickDT, snake, w, nil, cam, now)
+			snake.HP = NewHealth(10)
+			for i, c := range cs.Cars {
+				wet("cop car", i, c.X, c.Y)
+			}
+			for i, p := range cs.Peds {
+				wet("cop", i, p.X, p.Y)
+			}
+			for i, k := range ms.Tanks {
+				wet("tank", i, k.X, k.Y)
+			}
+			for i, k := range ms.Troops {
+				wet("troop", i, k.X, k.Y)
+			}
+		}
+	}
+}
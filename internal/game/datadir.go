@@ -0,0 +1,21 @@
+//go:build !android
+
+package game
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DataDir returns the per-user directory for saves and profile data.
+// SNAKE_DATA_DIR overrides the platform config directory.
+func DataDir() (string, error) {
+	if dir := os.Getenv("SNAKE_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "qake-snake"), nil
+}
@@ -0,0 +1,20 @@
+//go:build android
+
+package game
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// DataDir returns the app's private files directory. gomobile only exports
+// the cache directory (as TMPDIR); files/ is its sibling and, unlike the
+// cache, is never cleared by the system.
+func DataDir() (string, error) {
+	tmp := os.Getenv("TMPDIR")
+	if tmp == "" {
+		return "", errors.New("app data directory unknown")
+	}
+	return filepath.Join(filepath.Dir(tmp), "files"), nil
+}
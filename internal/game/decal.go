@@ -0,0 +1,212 @@
+package game
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Decals. Blood, scorch marks, tyre marks, slime and bodies go into a layer
+// of their own per chunk instead of into the terrain's pixels. The renderer
+// lays the layer over the chunk, marks build up where they are laid again,
+// and most of them fade slowly, to a dried stain or away. The terrain only
+// changes where something is really destroyed, so saves diff just that.
+
+type DecalKind uint8
+
+const (
+	DecalNone DecalKind = iota
+	DecalBlood
+	DecalScorch
+	DecalTyre
+	DecalSlime
+	DecalBody
+)
+
+const decalFadeStep = 0.5 // seconds between fades
+
+// decalKinds is, by kind, the most cover a mark builds up to, the cover it
+// loses each fade step and the cover it fades down to.
+var decalKinds = [...]struct{ max, fade, floor uint8 }{
+	DecalNone:   {0, 0, 0},
+	DecalBlood:  {235, 1, 120},
+	DecalScorch: {215, 1, 150},
+	DecalTyre:   {150, 1, 0},
+	DecalSlime:  {170, 5, 0},
+	DecalBody:   {255, 0, 255},
+}
+
+// decalLayer is the decals of one chunk.
+type decalLayer struct {
+	Pix    []uint8     // RGBA; alpha is how much of the ground the mark covers
+	Kind   []DecalKind // by pixel
+	fading bool        // some mark is above its floor
+	dirty  bool        // changed since the renderer last uploaded it
+}
+
+func newDecalLayer() *decalLayer {
+	n := ChunkSize * ChunkSize
+	return &decalLayer{Pix: make([]uint8, n*4), Kind: make([]DecalKind, n)}
+}
+
+// decalLayerAt returns the decal layer of chunk cx, cy, starting one if it
+// has none.
+func (w *World) decalLayerAt(cx, cy int) *decalLayer {
+	key := ChunkKey{X: cx, Y: cy}
+	d := w.decals[key]
+	if d == nil {
+		d = newDecalLayer()
+		w.decals[key] = d
+		if c := w.loadedChunk(cx, cy); c != nil {
+			c.Decals = d
+		}
+	}
+	return d
+}
+
+// AddDecal lays a mark of the given kind and colour on the world pixel wx,
+// wy. strength is the share, 0 to 1, of the kind's full cover this one mark
+// adds; marks laid again build up. Water takes no marks.
+func (w *World) AddDecal(wx, wy int, kind DecalKind, col RGB, strength float64) {
+	if kind == DecalNone || !w.InBounds(wx, wy) || w.IsWater(wx, wy) {
+		return
+	}
+	d := w.decalLayerAt(wx/ChunkSize, wy/ChunkSize)
+	i := (wy%ChunkSize)*ChunkSize + wx%ChunkSize
+	o := i * 4
+	k := decalKinds[kind]
+	a0 := float64(d.Pix[o+3])
+	add := clampF(strength, 0, 1) * float64(k.max)
+	a := max(a0, min(a0+add*(1-a0/255), float64(k.max)))
+	if add <= 0 || a <= 0 {
+		return
+	}
+	old := RGB{R: d.Pix[o], G: d.Pix[o+1], B: d.Pix[o+2]}
+	mix := lerpRGB(old, col, add/(add+a0*(1-add/255)))
+	d.Pix[o], d.Pix[o+1], d.Pix[o+2], d.Pix[o+3] = mix.R, mix.G, mix.B, uint8(math.Round(a))
+	// The mark that holds longer keeps the pixel unless the new one covers
+	// more of it.
+	if old := d.Kind[i]; old == DecalNone || decalKinds[kind].floor >= decalKinds[old].floor || add >= a0 && old != DecalBody {
+		d.Kind[i] = kind
+	}
+	d.fading = true
+	d.dirty = true
+}
+
+// fadeDecals fades every decal layer, loaded or not, by one step.
+func (w *World) fadeDecals() {
+	for _, d := range w.decals {
+		if d.fading {
+			d.fade()
+		}
+	}
+}
+
+func (d *decalLayer) fade() {
+	d.fading = false
+	for i, kind := range d.Kind {
+		if kind == DecalNone {
+			continue
+		}
+		k := decalKinds[kind]
+		a := d.Pix[i*4+3]
+		if a <= k.floor {
+			continue
+		}
+		a -= min(a-k.floor, k.fade)
+		d.Pix[i*4+3] = a
+		d.dirty = true
+		if a == 0 {
+			d.Kind[i] = DecalNone
+		} else if a > k.floor {
+			d.fading = true
+		}
+	}
+}
+
+// scorchDecal chars the ground around a blast of the given radius, most
+// strongly at the crater's rim.
+func (w *World) scorchDecal(wx, wy, radius int) {
+	reach := radius*3/2 + 2
+	for y := wy - reach; y <= wy+reach; y++ {
+		for x := wx - reach; x <= wx+reach; x++ {
+			d := math.Hypot(float64(x-wx), float64(y-wy))
+			if d > float64(reach) {
+				continue
+			}
+			n := float64(hash2D(0x5C02C4ED, x, y)>>56) / 255
+			s := (1-d/float64(reach))*0.9 + (n-0.5)*0.4
+			if s > 0 {
+				w.AddDecal(x, y, DecalScorch, w.burnedGroundColorAt(x, y), s)
+			}
+		}
+	}
+}
+
+// tyreMarks lays the marks of a vehicle's rear wheels skidding at x, y.
+// size is the vehicle's length.
+func (w *World) tyreMarks(x, y, heading float64, size float32, strength float64) {
+	col := RGB{R: 28, G: 26, B: 26}
+	fx, fy := math.Cos(heading), math.Sin(heading)
+	back, side := float64(size)*0.35, float64(size)*CarVisualAspect*0.35
+	rx, ry := x-fx*back, y-fy*back
+	for _, s := range [2]float64{-side, side} {
+		w.AddDecal(int(math.Round(rx-fy*s)), int(math.Round(ry+fx*s)), DecalTyre, col, strength)
+	}
+}
+
+// decalSave is the marks of one chunk's decal layer.
+type decalSave struct {
+	CX, CY int
+	Idx    []uint16
+	RGBA   []uint8
+	Kind   []DecalKind
+}
+
+func (w *World) saveDecals() []decalSave {
+	keys := make([]ChunkKey, 0, len(w.decals))
+	for key := range w.decals {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b ChunkKey) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	var out []decalSave
+	for _, key := range keys {
+		d := w.decals[key]
+		s := decalSave{CX: key.X, CY: key.Y}
+		for i, kind := range d.Kind {
+			if kind != DecalNone {
+				s.Idx = append(s.Idx, uint16(i))
+				s.RGBA = append(s.RGBA, d.Pix[i*4:i*4+4]...)
+				s.Kind = append(s.Kind, kind)
+			}
+		}
+		if len(s.Idx) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (w *World) loadDecals(saved []decalSave) error {
+	for _, s := range saved {
+		if s.CX < 0 || s.CY < 0 || s.CX > w.maxCx || s.CY > w.maxCy {
+			return fmt.Errorf("save decals %d,%d do not match the world", s.CX, s.CY)
+		}
+		if len(s.RGBA) != len(s.Idx)*4 || len(s.Kind) != len(s.Idx) {
+			return fmt.Errorf("save decals %d,%d are malformed", s.CX, s.CY)
+		}
+		d := w.decalLayerAt(s.CX, s.CY)
+		for j, i := range s.Idx {
+			if int(i) >= len(d.Kind) || s.Kind[j] == DecalNone || int(s.Kind[j]) >= len(decalKinds) {
+				return fmt.Errorf("save decals %d,%d are malformed", s.CX, s.CY)
+			}
+			copy(d.Pix[int(i)*4:], s.RGBA[j*4:j*4+4])
+			d.Kind[i] = s.Kind[j]
+		}
+		d.fading, d.dirty = true, true
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package game
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestAddDecal(t *testing.T) {
+	red, tyre := RGB{R: 130, G: 20, B: 20}, RGB{R: 28, G: 26, B: 26}
+	type mark struct {
+		kind     DecalKind
+		strength float64
+	}
+	for _, tc := range []struct {
+		name   string
+		x, y   int
+		marks  []mark
+		kind   DecalKind
+		cover  uint8 // 0 to only check it is below the kind's max
+		growth bool  // each mark adds cover
+	}{
+		{"one full mark", 60, 40, []mark{{DecalBlood, 1}}, DecalBlood, decalKinds[DecalBlood].max, false},
+		{"marks build up", 60, 40, []mark{{DecalBlood, 0.3}, {DecalBlood, 0.3}, {DecalBlood, 0.3}}, DecalBlood, 0, true},
+		{"up to the kind's max", 60, 40, []mark{{DecalSlime, 1}, {DecalSlime, 1}, {DecalSlime, 1}}, DecalSlime, decalKinds[DecalSlime].max, false},
+		{"a faint skid leaves the blood", 60, 40, []mark{{DecalBlood, 1}, {DecalTyre, 0.2}}, DecalBlood, decalKinds[DecalBlood].max, false},
+		{"a heavy skid takes the slime", 60, 40, []mark{{DecalSlime, 0.2}, {DecalTyre, 1}}, DecalTyre, 0, true},
+		{"bodies stay bodies", 60, 40, []mark{{DecalBody, 1}, {DecalBlood, 1}}, DecalBody, 255, false},
+		{"water takes none", 21, 21, []mark{{DecalBlood, 1}}, DecalNone, 0, false},
+		{"nothing for nothing", 60, 40, []mark{{DecalBlood, 0}}, DecalNone, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := grassWorld(t)
+			last := uint8(0)
+			for i, m := range tc.marks {
+				col := red
+				if m.kind == DecalTyre {
+					col = tyre
+				}
+				w.AddDecal(tc.x, tc.y, m.kind, col, m.strength)
+				if _, cover := decalAt(w, tc.x, tc.y); tc.growth && cover <= last {
+					t.Errorf("mark %d left cover at %d from %d", i+1, cover, last)
+				}
+				_, last = decalAt(w, tc.x, tc.y)
+			}
+			kind, cover := decalAt(w, tc.x, tc.y)
+			if kind != tc.kind {
+				t.Errorf("kind %d, want %d", kind, tc.kind)
+			}
+			if tc.cover != 0 && cover != tc.cover || cover > decalKinds[kind].max {
+				t.Errorf("cover %d, want %d (max %d)", cover, tc.cover, decalKinds[kind].max)
+			}
+		})
+	}
+	w := grassWorld(t)
+	w.AddDecal(-1, 5, DecalBlood, red, 1)
+	w.AddDecal(5, w.Height, DecalBlood, red, 1)
+	if len(w.decals) != 0 {
+		t.Error("marks off the world were laid")
+	}
+}
+
+// TestDecalsFade runs every kind of mark through enough fade steps to
+// reach its floor: slime and skids go, blood and scorch dry to a stain and
+// bodies stay.
+func TestDecalsFade(t *testing.T) {
+	w := grassWorld(t)
+	kinds := []DecalKind{DecalBlood, DecalScorch, DecalTyre, DecalSlime, DecalBody}
+	for i, kind := range kinds {
+		w.AddDecal(40+i, 40, kind, RGB{R: 90, G: 40, B: 30}, 1)
+	}
+	for range 256 {
+		w.Update(decalFadeStep)
+	}
+	for i, kind := range kinds {
+		got, cover := decalAt(w, 40+i, 40)
+		if cover != decalKinds[kind].floor {
+			t.Errorf("kind %d faded to %d, want %d", kind, cover, decalKinds[kind].floor)
+		}
+		if want := kind; cover == 0 && got != DecalNone || cover > 0 && got != want {
+			t.Errorf("kind %d became %d at cover %d", kind, got, cover)
+		}
+	}
+	if w.decals[ChunkKey{}].fading {
+		t.Error("layer still fading with every mark at its floor")
+	}
+}
+
+// TestDecalsOutliveEviction unloads a marked chunk and checks its marks go
+// on fading while it is away and are there when it loads again.
+func TestDecalsOutliveEviction(t *testing.T) {
+	w := newTestWorld(1, themeNamed(t, "City"), 660, 660)
+	red := RGB{R: 130, G: 20, B: 20}
+	w.AddDecal(10, 10, DecalBlood, red, 1)
+	w.AddDecal(12, 10, DecalSlime, red, 1)
+	layer := w.decals[ChunkKey{}]
+	if c := w.loadedChunk(0, 0); c == nil || c.Decals != layer {
+		t.Fatal("the marked chunk does not hold its decals")
+	}
+
+	w.clock += 2 * ChunkIdleTime
+	w.EvictIdleChunks(RectF{X0: 600, Y0: 600, X1: 650, Y1: 650})
+	if w.loadedChunk(0, 0) != nil {
+		t.Fatal("the marked chunk stayed loaded")
+	}
+	for range 256 {
+		w.fadeDecals()
+	}
+	if kind, _ := decalAt(w, 12, 10); kind != DecalNone {
+		t.Errorf("slime still there after fading unloaded")
+	}
+	if c := w.GetChunk(0, 0); c.Decals != layer {
+		t.Fatal("the reloaded chunk lost its decals")
+	}
+	if kind, cover := decalAt(w, 10, 10); kind != DecalBlood || cover != decalKinds[DecalBlood].floor {
+		t.Errorf("blood came back as kind %d cover %d", kind, cover)
+	}
+}
+
+// TestDecalsSaveRoundTrip marks a level in play, saves and loads it, and
+// checks the loaded world has the same marks and fades them alike.
+func TestDecalsSaveRoundTrip(t *testing.T) {
+	sim := NewSimulation(3)
+	sim.StartLevel(1)
+	for n := range 120 {
+		sim.Step(SimTickDT, steerFor(n))
+	}
+	w := sim.World
+	w.AddDecal(30, 30, DecalBody, RGB{R: 120, G: 90, B: 70}, 1)
+	w.AddDecal(31, 30, DecalBlood, RGB{R: 130, G: 20, B: 20}, 0.6)
+	w.AddDecal(w.Width-20, w.Height-20, DecalScorch, RGB{R: 30, G: 25, B: 20}, 1)
+	saved := w.saveDecals()
+	if len(saved) < 2 {
+		t.Fatalf("marks in %d chunks", len(saved))
+	}
+
+	var buf bytes.Buffer
+	if err := sim.WriteSave(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadSave(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.World.saveDecals(); !reflect.DeepEqual(got, saved) {
+		t.Fatalf("loaded marks in %d chunks, saved %d", len(got), len(saved))
+	}
+	for n := 120; n < 300; n++ {
+		sim.Step(SimTickDT, steerFor(n))
+		loaded.Step(SimTickDT, steerFor(n))
+	}
+	if !reflect.DeepEqual(loaded.World.saveDecals(), sim.World.saveDecals()) {
+		t.Error("the loaded marks drift from the saved game's")
+	}
+	if kind, _ := decalAt(loaded.World, 30, 30); kind != DecalBody {
+		t.Errorf("the body became kind %d", kind)
+	}
+}
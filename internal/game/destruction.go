@@ -28,15 +28,21 @@ func ExplodeAt(wx, wy, radius int, w *World, ps *ParticleSystem, peds *Pedestria
 	if mil != nil {
 		ExplodeAffectMilitary(wx, wy, radius, w, ps, mil)
 	}
+	w.Events.Emit(Event{Type: EventExplosion, X: float64(wx), Y: float64(wy), Data: radius})
 	return pedKills
 }
 
-// SpawnExplosionWithShockwave spawns explosion particles and a matching shockwave.
+// SpawnExplosionWithShockwave spawns explosion particles and a matching
+// shockwave. A blast in water splashes instead of burning.
 func SpawnExplosionWithShockwave(wx, wy int, col RGB, intensity float64, shockRadius int, w *World, ps *ParticleSystem) {
 	if ps == nil || intensity <= 0 {
 		return
 	}
-	ps.SpawnExplosion(wx, wy, col, intensity)
+	if w != nil && w.IsWater(wx, wy) {
+		ps.SpawnSplash(wx, wy, w.ColorAt(wx, wy), intensity)
+	} else {
+		ps.SpawnExplosion(wx, wy, col, intensity)
+	}
 
 	// Ensure visible shockwaves even for tiny impacts.
 	r := shockRadius
@@ -162,8 +168,8 @@ func ExplodeAffectPeds(wx, wy, radius int, w *World, ps *ParticleSystem, peds *P
 
 			blood := RGB{R: 130, G: 20, B: 20}
 			w.PaintRGB(bx, by, blood)
-			w.PaintRGB(clamp(bx+1, 0, WorldWidth-1), by, blood)
-			w.PaintRGB(clamp(bx-1, 0, WorldWidth-1), by, blood)
+			w.PaintRGB(clamp(bx+1, 0, w.Width-1), by, blood)
+			w.PaintRGB(clamp(bx-1, 0, w.Width-1), by, blood)
 
 			paintFallenPed(w, bx, by, p.Skin, p.Col)
 
@@ -189,7 +195,7 @@ func ExplodeAffectPeds(wx, wy, radius int, w *World, ps *ParticleSystem, peds *P
 					tryY := ny + pushY*1.2
 					tx := int(math.Round(tryX))
 					ty := int(math.Round(tryY))
-					if tx < 0 || ty < 0 || tx >= WorldWidth || ty >= WorldHeight {
+					if tx < 0 || ty < 0 || tx >= w.Width || ty >= w.Height {
 						break
 					}
 					if pedWalkable(w, tx, ty) {
@@ -275,10 +281,10 @@ func SpawnShockwave(wx, wy, radius int, w *World, ps *ParticleSystem) {
 	maxDelay := float64(visRadius) * 0.018
 	rr := NewRand(uint64(wx*31+wy*17) ^ 0xDEC0DE)
 
-	minX := clamp(wx-visRadius, 0, WorldWidth-1)
-	maxX := clamp(wx+visRadius, 0, WorldWidth-1)
-	minY := clamp(wy-visRadius, 0, WorldHeight-1)
-	maxY := clamp(wy+visRadius, 0, WorldHeight-1)
+	minX := clamp(wx-visRadius, 0, w.Width-1)
+	maxX := clamp(wx+visRadius, 0, w.Width-1)
+	minY := clamp(wy-visRadius, 0, w.Height-1)
+	maxY := clamp(wy+visRadius, 0, w.Height-1)
 
 	spawned := 0
 	maxWave := 2000
@@ -337,7 +343,10 @@ func UpdateBurnVisuals(w *World, ps *ParticleSystem, dt float64) {
 		return
 	}
 
-	for _, tb := range w.burningTrees {
+	// Sorted like World.Update so particles spawn in the same order every
+	// run.
+	for _, key := range sortedBurnKeys(w.burningTrees) {
+		tb := w.burningTrees[key]
 		rr := NewRand(tb.rng ^ uint64(int(tb.timer*1000)))
 		if rr.RangeF(0, 1) < 4.0*dt {
 			fx := float64(tb.X) + rr.RangeF(-2, 2)
@@ -363,7 +372,8 @@ func UpdateBurnVisuals(w *World, ps *ParticleSystem, dt float64) {
 		}
 	}
 
-	for _, bb := range w.burningBuildings {
+	for _, key := range sortedBurnKeys(w.burningBuildings) {
+		bb := w.burningBuildings[key]
 		if !bb.smolder || len(bb.Pixels) == 0 {
 			continue
 		}
@@ -397,7 +407,7 @@ func UpdateBurnVisuals(w *World, ps *ParticleSystem, dt float64) {
 
 // paintFallenPed paints a small body decal on the ground.
 func paintFallenPed(w *World, bx, by int, skin, cloth RGB) {
-	if bx < 0 || by < 0 || bx >= WorldWidth || by >= WorldHeight {
+	if bx < 0 || by < 0 || bx >= w.Width || by >= w.Height {
 		return
 	}
 	cx, cy := bx, by
@@ -407,7 +417,7 @@ func paintFallenPed(w *World, bx, by int, skin, cloth RGB) {
 			for ox := -1; ox <= 1; ox++ {
 				nx := cx + ox
 				ny := cy + oy
-				if nx >= 0 && ny >= 0 && nx < WorldWidth && ny < WorldHeight && pedWalkable(w, nx, ny) {
+				if nx >= 0 && ny >= 0 && nx < w.Width && ny < w.Height && pedWalkable(w, nx, ny) {
 					cx, cy = nx, ny
 					found = true
 					break
@@ -418,9 +428,9 @@ func paintFallenPed(w *World, bx, by int, skin, cloth RGB) {
 			return
 		}
 	}
-	w.PaintRGB(cx, cy, skin)
-	w.PaintRGB(cx, cy+1, cloth)
-	w.PaintRGB(cx-1, cy+1, cloth)
-	w.PaintRGB(cx+1, cy+1, cloth)
-	w.PaintRGB(cx, cy+2, cloth)
+	w.AddDecal(cx, cy, DecalBody, skin, 1)
+	w.AddDecal(cx, cy+1, DecalBody, cloth, 1)
+	w.AddDecal(cx-1, cy+1, DecalBody, cloth, 1)
+	w.AddDecal(cx+1, cy+1, DecalBody, cloth, 1)
+	w.AddDecal(cx, cy+2, DecalBody, cloth, 1)
 }
@@ -3,16 +3,45 @@ package game
 type EventType int
 
 const (
-	EventEntityDied EventType = iota
-	EventExplosion
-	EventAlienSpawned
-	EventLevelComplete
+	EventExplosion     EventType = iota // Data: radius
+	EventLevelComplete                  // Data: level number
+	EventLevelFailed                    // Data: level number
+	EventLevelStarted                   // Data: level number
+
+	// Deaths are reported when the owning system removes the entity, so every
+	// cause (eaten, shot, burned, blown up) produces exactly one event.
+	EventPedKilled       // Variant, Infection, Armed
+	EventCarDestroyed    // civilian traffic
+	EventCopKilled       // cop on foot
+	EventCopCarDestroyed // police cruiser
+	EventHeliKilled      // Data: 1 for military helicopters, 0 for police
+	EventTankKilled
+	EventSoldierKilled
+
+	EventPedEaten          // snake swallowed a ped; Variant, Infection, Armed
+	EventBonusCollected    // Bonus
+	EventWantedChanged     // Data: wanted stars (0–5)
+	EventEvolved           // Data: new evolution level
+	EventBuildingBurned    // a burning building finished collapsing
+	EventBuildingCollapsed // a damaged building fell; Data: pixels
+	EventSnakeCollision    // versus: a snake bit a rival's body
 )
 
 type Event struct {
 	Type EventType
 	X, Y float64
 	Data int // Generic payload (e.g. radius for explosion).
+
+	// Ped details for EventPedKilled / EventPedEaten.
+	Variant   PedVariant
+	Infection InfectionState
+	Armed     bool
+
+	Bonus BonusKind // EventBonusCollected
+
+	// Building names the building for EventBuildingBurned and
+	// EventBuildingCollapsed (see World.buildingAt); 0 if it can't be told.
+	Building int
 }
 
 type EventHandler func(Event)
@@ -31,8 +60,22 @@ func (eb *EventBus) Subscribe(t EventType, fn EventHandler) {
 	eb.handlers[t] = append(eb.handlers[t], fn)
 }
 
+// Emit calls every handler subscribed to e.Type. A nil bus drops the event,
+// so systems used outside a Simulation don't need one.
 func (eb *EventBus) Emit(e Event) {
+	if eb == nil {
+		return
+	}
 	for _, fn := range eb.handlers[e.Type] {
 		fn(e)
 	}
 }
+
+// SubscribeAudio plays the one-shot sounds that follow gameplay events.
+func SubscribeAudio(eb *EventBus) {
+	eb.Subscribe(EventPedKilled, func(Event) { PlaySound(SoundSplatter) })
+	eb.Subscribe(EventSnakeCollision, func(Event) { PlaySound(SoundSplatter) })
+	eb.Subscribe(EventBonusCollected, func(Event) { PlaySound(SoundBonus) })
+	eb.Subscribe(EventLevelComplete, func(Event) { PlaySound(SoundLevelUp) })
+	eb.Subscribe(EventLevelFailed, func(Event) { PlaySound(SoundGameOver) })
+}
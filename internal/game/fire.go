@@ -0,0 +1,356 @@
+package game
+
+import "math"
+
+// Wildfire. Fire spreads over a grid of fireCellSize-pixel cells laid over
+// the world. What a cell burns depends on what lies in it: grass flashes
+// over, trees catch readily and burn for a while, buildings are slow to
+// catch but burn longest, and roads, bare ground, snow and water don't burn
+// at all. A burning cell sets its neighbours alight, more readily downwind,
+// and rain and snow damp it down. The trees and buildings of a burning cell
+// burn away through TreeBurn and BuildingBurn; grass is left scorched.
+
+const (
+	fireCellSize     = 4
+	fireSpreadRate   = 0.35 // catches per second a burning cell gives a neighbour of catch rate 1
+	fireWindScale    = 9.0  // wind that doubles the spread downwind and stops it upwind
+	fireEmberChance  = 0.2  // chance a burning piece of debris lights where it lands
+	fireFlameChance  = 0.08 // chance per tick a low flame lights the ground under it
+	firePedDamage    = 1.6  // HP per second to people in a burning cell
+	fireSnakeDamage  = 0.5  // HP per second to a snake with its head in one
+	fireVisualMargin = 16.0 // burning cells this far outside the view still flicker
+)
+
+type fuelKind uint8
+
+const (
+	fuelNone fuelKind = iota
+	fuelGrass
+	fuelTree
+	fuelBuilding
+)
+
+// fuels is how readily each fuel catches, relative to the others, and how
+// many seconds it burns.
+var fuels = [...]struct{ catch, burn float64 }{
+	fuelNone:     {0, 0},
+	fuelGrass:    {0.55, 1.6},
+	fuelTree:     {1.0, 4.5},
+	fuelBuilding: {0.22, 9.0},
+}
+
+// Cell states.
+const (
+	cellFresh uint8 = iota
+	cellBurning
+	cellBurnt
+)
+
+// fireField is the fire of one world.
+type fireField struct {
+	cols, rows int
+	state      []uint8    // by cell
+	burning    []fireBurn // in the order they caught
+	tick       uint64     // spread steps taken; seeds the rolls
+}
+
+// fireBurn is a burning cell.
+type fireBurn struct {
+	Cell int32
+	Fuel fuelKind
+	Left float32 // seconds until it burns out
+}
+
+func newFireField(width, height int) fireField {
+	cols := (width + fireCellSize - 1) / fireCellSize
+	rows := (height + fireCellSize - 1) / fireCellSize
+	return fireField{cols: cols, rows: rows, state: make([]uint8, cols*rows)}
+}
+
+// cellAt is the cell holding the world point x, y, or -1 outside the world.
+func (f *fireField) cellAt(x, y float64) int {
+	cx, cy := int(math.Floor(x))/fireCellSize, int(math.Floor(y))/fireCellSize
+	if x < 0 || y < 0 || cx >= f.cols || cy >= f.rows {
+		return -1
+	}
+	return cy*f.cols + cx
+}
+
+// burningAt reports whether the world point x, y is on fire.
+func (f *fireField) burningAt(x, y float64) bool {
+	k := f.cellAt(x, y)
+	return k >= 0 && f.state[k] == cellBurning
+}
+
+// burningNear reports whether the cell holding x, y or one beside it is on
+// fire.
+func (f *fireField) burningNear(x, y float64) bool {
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if f.burningAt(x+float64(dx*fireCellSize), y+float64(dy*fireCellSize)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fireFuel is what cell k has to burn.
+func (w *World) fireFuel(k int) fuelKind {
+	x0, y0 := k%w.fire.cols*fireCellSize, k/w.fire.cols*fireCellSize
+	var water, grass, trees, built int
+	for y := y0; y < min(y0+fireCellSize, w.Height); y++ {
+		for x := x0; x < min(x0+fireCellSize, w.Width); x++ {
+			if w.IsWater(x, y) {
+				water++
+				continue
+			}
+			col := w.ColorAt(x, y)
+			green := col.G > col.R && col.G > col.B
+			switch h := w.HeightAt(x, y); {
+			case h > rubbleHeight && green:
+				trees++
+			case h > rubbleHeight && w.builtAt(x, y):
+				built++
+			case h == 0 && green:
+				grass++
+			}
+		}
+	}
+	switch {
+	case water >= 8:
+		return fuelNone
+	case trees >= 4:
+		return fuelTree
+	case built >= 6:
+		return fuelBuilding
+	case grass >= 6:
+		return fuelGrass
+	}
+	return fuelNone
+}
+
+// igniteFire sets the cell at wx, wy alight if it has anything to burn.
+func (w *World) igniteFire(wx, wy int) {
+	k := w.fire.cellAt(float64(wx), float64(wy))
+	if k < 0 || w.fire.state[k] != cellFresh {
+		return
+	}
+	w.igniteCell(k, w.fireFuel(k))
+}
+
+func (w *World) igniteCell(k int, fuel fuelKind) {
+	if fuel == fuelNone {
+		return
+	}
+	f := &w.fire
+	f.state[k] = cellBurning
+	f.burning = append(f.burning, fireBurn{Cell: int32(k), Fuel: fuel, Left: float32(fuels[fuel].burn)})
+	cx := k%f.cols*fireCellSize + fireCellSize/2
+	cy := k/f.cols*fireCellSize + fireCellSize/2
+	switch fuel {
+	case fuelTree:
+		w.StartTreeBurn(cx, cy)
+	case fuelBuilding:
+		// One burn covers several cells of a building.
+		for _, bb := range w.burningBuildings {
+			if cx >= bb.X0 && cx <= bb.X1 && cy >= bb.Y0 && cy <= bb.Y1 {
+				return
+			}
+		}
+		w.StartBuildingBurn(cx, cy)
+	}
+}
+
+// scorch chars the grass of a burnt-out cell, some blades more than others.
+func (w *World) scorch(k int) {
+	x0, y0 := k%w.fire.cols*fireCellSize, k/w.fire.cols*fireCellSize
+	for y := y0; y < min(y0+fireCellSize, w.Height); y++ {
+		for x := x0; x < min(x0+fireCellSize, w.Width); x++ {
+			if col := w.ColorAt(x, y); w.HeightAt(x, y) == 0 && col.G > col.R && col.G > col.B {
+				t := 0.55 + float64(hash2D(0x5C0C4ED, x, y)>>61)*0.06
+				w.AddDecal(x, y, DecalScorch, w.burnedGroundColorAt(x, y), t)
+			}
+		}
+	}
+}
+
+// UpdateFire spreads the fire for dt under weather, flickers the burning
+// cells near view, burns everyone standing in them and sets cars there
+// alight.
+func UpdateFire(w *World, weather *WeatherSystem, ps *ParticleSystem, view RectF, snakes []*Snake, peds *PedestrianSystem, traffic *TrafficSystem, cops *CopSystem, mil *MilitarySystem, dt float64) {
+	f := &w.fire
+	if dt <= 0 {
+		return
+	}
+	f.tick++
+	r := NewRand(hash2D(w.seed^0xF1AE5EED, int(f.tick), len(f.burning)))
+	wind, wet := weather.Wind(), weather.Wetness()
+
+	// Burning cars spread fire like a burning cell of their own.
+	if traffic != nil {
+		for i := range traffic.Cars {
+			c := &traffic.Cars[i]
+			if c.Alive && c.OnFire {
+				if k := f.cellAt(c.X, c.Y); k >= 0 {
+					w.spreadFire(k, wind, wet, dt, r)
+				}
+			}
+		}
+	}
+
+	n := len(f.burning)
+	for i := 0; i < n; i++ {
+		w.spreadFire(int(f.burning[i].Cell), wind, wet, dt, r)
+	}
+	out := f.burning[:0]
+	for _, b := range f.burning {
+		b.Left -= float32(dt * (1 + 2*wet))
+		if b.Left > 0 {
+			out = append(out, b)
+			continue
+		}
+		f.state[b.Cell] = cellBurnt
+		if b.Fuel == fuelGrass {
+			w.scorch(int(b.Cell))
+		}
+	}
+	f.burning = out
+
+	if ps != nil {
+		x0, y0 := view.X0-fireVisualMargin, view.Y0-fireVisualMargin
+		x1, y1 := view.X1+fireVisualMargin, view.Y1+fireVisualMargin
+		for _, b := range f.burning {
+			x := float64(int(b.Cell)%f.cols*fireCellSize) + fireCellSize/2
+			y := float64(int(b.Cell)/f.cols*fireCellSize) + fireCellSize/2
+			if x < x0 || y < y0 || x > x1 || y > y1 {
+				continue
+			}
+			if r.Float64() < 6*dt {
+				ps.Add(Particle{
+					X: x + r.RangeF(-2, 2), Y: y + r.RangeF(-2, 2),
+					VX: wind*0.4 + r.RangeF(-5, 5), VY: r.RangeF(-5, 5),
+					Z: r.RangeF(3, 6), VZ: r.RangeF(20, 55),
+					Size: 0.4 + r.RangeF(0, 0.4), MaxLife: r.RangeF(0.2, 0.5),
+					Col: Palette.FireHot, Kind: ParticleFire,
+				})
+			}
+			if r.Float64() < 2.5*dt {
+				g := uint8(r.Range(50, 80))
+				ps.Add(Particle{
+					X: x + r.RangeF(-2, 2), Y: y + r.RangeF(-2, 2),
+					VX: wind*0.8 + r.RangeF(-3, 3), VY: r.RangeF(-10, -2),
+					Z: r.RangeF(6, 12), VZ: r.RangeF(15, 40),
+					Size: 1.0, MaxLife: r.RangeF(0.5, 1.1),
+					Col: RGB{R: g, G: g, B: g + 4}, Kind: ParticleSmoke,
+				})
+			}
+		}
+	}
+
+	burnFire(w, snakes, peds, traffic, cops, mil, dt)
+}
+
+// spreadFire gives the fresh neighbours of cell k their chance to catch.
+func (w *World) spreadFire(k int, wind, wet, dt float64, r *Rand) {
+	f := &w.fire
+	cx, cy := k%f.cols, k/f.cols
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			nx, ny := cx+dx, cy+dy
+			if (dx == 0 && dy == 0) || nx < 0 || ny < 0 || nx >= f.cols || ny >= f.rows {
+				continue
+			}
+			nk := ny*f.cols + nx
+			if f.state[nk] != cellFresh {
+				continue
+			}
+			p := fireSpreadRate * dt * (1 - 0.85*wet)
+			if dx != 0 {
+				p *= 1 + clampF(float64(dx)*wind/fireWindScale, -1, 1.5)
+				if dy != 0 {
+					p *= 0.7
+				}
+			}
+			// Roll before looking at the fuel: most rolls fail.
+			roll := r.Float64()
+			if roll >= p {
+				continue
+			}
+			if fuel := w.fireFuel(nk); roll < p*fuels[fuel].catch {
+				w.igniteCell(nk, fuel)
+			}
+		}
+	}
+}
+
+// burnFire hurts whoever stands in a burning cell and sets the cars there
+// alight.
+func burnFire(w *World, snakes []*Snake, peds *PedestrianSystem, traffic *TrafficSystem, cops *CopSystem, mil *MilitarySystem, dt float64) {
+	f := &w.fire
+	if len(f.burning) == 0 {
+		return
+	}
+	for _, s := range snakes {
+		if s == nil || !s.Alive || s.FlamethrowerTimer > 0 || len(s.Ghosts) > 0 {
+			continue
+		}
+		if hx, hy := s.Head(); f.burningAt(hx, hy) {
+			s.HP.Damage(fireSnakeDamage * dt)
+		}
+	}
+	if peds != nil {
+		for i := range peds.P {
+			p := &peds.P[i]
+			if !p.Alive || !f.burningAt(p.X, p.Y) {
+				continue
+			}
+			p.HP.Damage(firePedDamage * dt)
+			if p.HP.IsDead() {
+				p.Alive = false
+				paintFallenPed(w, int(math.Round(p.X)), int(math.Round(p.Y)), p.Skin.Mul(80), p.Col.Mul(80))
+				continue
+			}
+			// Run out of the fire the way it is least.
+			if !p.Fleeing {
+				k := f.cellAt(p.X, p.Y)
+				cx := float64(k%f.cols*fireCellSize) + fireCellSize/2
+				cy := float64(k/f.cols*fireCellSize) + fireCellSize/2
+				dx, dy := p.X-cx, p.Y-cy
+				d := math.Hypot(dx, dy) + 0.01
+				p.TargetX, p.TargetY = p.X+dx/d*10, p.Y+dy/d*10
+			}
+		}
+	}
+	if traffic != nil {
+		for i := range traffic.Cars {
+			c := &traffic.Cars[i]
+			// Parked cars catch from the grass around them too.
+			if c.Alive && (f.burningAt(c.X, c.Y) || c.Parked && f.burningNear(c.X, c.Y)) {
+				c.OnFire = true
+			}
+		}
+	}
+	if cops != nil {
+		for i := range cops.Peds {
+			p := &cops.Peds[i]
+			if p.Alive && f.burningAt(p.X, p.Y) {
+				p.HP.Damage(firePedDamage * dt)
+				if p.HP.IsDead() {
+					p.Alive = false
+				}
+			}
+		}
+	}
+	if mil != nil {
+		for i := range mil.Troops {
+			t := &mil.Troops[i]
+			if t.Alive && f.burningAt(t.X, t.Y) {
+				t.HP.Damage(firePedDamage * dt)
+				if t.HP.IsDead() {
+					t.Alive = false
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package game
+
+import "testing"
+
+// grassWorld is a mapImage world in the forest, whose grass burns (a city's
+// is too dry and brown to). The tests light it around 66,36, well clear of
+// the map's building, pond and road.
+func grassWorld(t *testing.T) *World {
+	t.Helper()
+	m, err := DecodeWorldMap(mapImage(MinWorldSize, MinWorldSize), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWorld(1, themeNamed(t, "Forest"), m.Width, m.Height)
+	w.Map = m
+	w.Resize(m.Width, m.Height)
+	return w
+}
+
+// catches lets the grass cell at 66,36 try to set its neighbours alight
+// trials times over, each from scratch, and counts how often each of them
+// caught, by dx+1, dy+1.
+func catches(w *World, wind, wet float64, trials int) (n [3][3]int) {
+	k := w.fire.cellAt(66, 36)
+	r := NewRand(7)
+	for range trials {
+		clear(w.fire.state)
+		w.fire.burning = w.fire.burning[:0]
+		w.fire.state[k] = cellBurning
+		w.spreadFire(k, wind, wet, 0.25, r)
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if w.fire.state[k+dy*w.fire.cols+dx] == cellBurning && (dx != 0 || dy != 0) {
+					n[dx+1][dy+1]++
+				}
+			}
+		}
+	}
+	return n
+}
+
+func TestFireSpreadsDownwind(t *testing.T) {
+	w := grassWorld(t)
+	const trials = 4000
+	// Grass catches at fireSpreadRate*catch a second from a neighbour.
+	want := fireSpreadRate * 0.25 * fuels[fuelGrass].catch * trials
+	for _, tc := range []struct {
+		name       string
+		wind       float64
+		east, west float64 // of want
+	}{
+		{"calm", 0, 1, 1},
+		{"breeze east", fireWindScale / 2, 1.5, 0.5},
+		{"gale east", 2 * fireWindScale, 2.5, 0},
+		{"breeze west", -fireWindScale / 2, 0.5, 1.5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n := catches(w, tc.wind, 0, trials)
+			for _, c := range []struct {
+				name string
+				got  int
+				want float64
+			}{
+				{"east", n[2][1], tc.east * want},
+				{"west", n[0][1], tc.west * want},
+				{"north", n[1][0], want},
+				{"south", n[1][2], want},
+			} {
+				if d := float64(c.got) - c.want; d > 0.15*want+5 || d < -0.15*want-5 {
+					t.Errorf("%s caught %d times in %d, want about %.0f", c.name, c.got, trials, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRainDampsFire(t *testing.T) {
+	w := grassWorld(t)
+	const trials = 4000
+	dry := catches(w, 0, 0, trials)
+	rain := NewWeatherSystem(1)
+	rain.mode = WeatherRain
+	wet := catches(w, 0, rain.Wetness(), trials)
+	if got, want := float64(wet[1][0]), float64(dry[1][0])*(1-0.85*rain.Wetness()); got > want*1.2+5 || got < want*0.8-5 {
+		t.Errorf("caught %d times in the rain, %d in the dry; want about %.0f", wet[1][0], dry[1][0], want)
+	}
+
+	// A burning cell burns out (1+2*wetness) times as fast in the rain.
+	for _, tc := range []struct {
+		name    string
+		weather *WeatherSystem
+		left    float64
+	}{
+		{"dry", NewWeatherSystem(1), fuels[fuelGrass].burn},
+		{"rain", rain, fuels[fuelGrass].burn / (1 + 2*rain.Wetness())},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := grassWorld(t)
+			w.igniteFire(66, 36)
+			k := w.fire.cellAt(66, 36)
+			ticks := 0
+			for ; w.fire.state[k] == cellBurning && ticks < 1000; ticks++ {
+				UpdateFire(w, tc.weather, nil, RectF{}, nil, nil, nil, nil, nil, SimTickDT)
+			}
+			if got := float64(ticks) * SimTickDT; got < tc.left-2*SimTickDT || got > tc.left+2*SimTickDT {
+				t.Errorf("burned %.2fs, want %.2fs", got, tc.left)
+			}
+		})
+	}
+}
+
+// TestFireScorches burns the grass out and checks every burnt-out grass
+// cell is scorched and the grass nothing burnt is not.
+func TestFireScorches(t *testing.T) {
+	w := grassWorld(t)
+	for x := 40; x < 100; x += 2 * fireCellSize {
+		w.igniteFire(x, 36)
+	}
+	for range int(60 / SimTickDT) {
+		if len(w.fire.burning) == 0 {
+			break
+		}
+		UpdateFire(w, nil, nil, RectF{}, nil, nil, nil, nil, nil, SimTickDT)
+	}
+	if len(w.fire.burning) != 0 {
+		t.Fatalf("%d cells still burn", len(w.fire.burning))
+	}
+	burnt := 0
+	for k, s := range w.fire.state {
+		x, y := k%w.fire.cols*fireCellSize+1, k/w.fire.cols*fireCellSize+1
+		if s == cellBurning || w.HeightAt(x, y) != 0 || w.IsWater(x, y) {
+			continue
+		}
+		col := w.ColorAt(x, y)
+		if col.G <= col.R || col.G <= col.B {
+			continue
+		}
+		kind, cover := decalAt(w, x, y)
+		if s == cellBurnt {
+			burnt++
+			if kind != DecalScorch || cover == 0 {
+				t.Fatalf("burnt grass at %d,%d has decal %d cover %d, want scorch", x, y, kind, cover)
+			}
+		} else if kind != DecalNone {
+			t.Fatalf("unburnt grass at %d,%d has decal %d", x, y, kind)
+		}
+	}
+	if burnt < 8 {
+		t.Errorf("%d grass cells burnt out", burnt)
+	}
+}
+
+// decalAt is the kind and cover of the decal on the world pixel x, y.
+func decalAt(w *World, x, y int) (DecalKind, uint8) {
+	d := w.decals[ChunkKey{X: x / ChunkSize, Y: y / ChunkSize}]
+	if d == nil {
+		return DecalNone, 0
+	}
+	i := (y%ChunkSize)*ChunkSize + x%ChunkSize
+	return d.Kind[i], d.Pix[i*4+3]
+}
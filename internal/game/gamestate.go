@@ -5,8 +5,8 @@ type GameState int
 const (
 	StateMenu          GameState = iota
 	StatePlaying                 // main gameplay
-	StateLevelComplete           // all peds eaten
-	StateLevelFailed             // snake died
+	StateLevelComplete           // objective met
+	StateLevelFailed             // snake died or objective failed
 )
 
 type GameSession struct {
@@ -17,78 +17,116 @@ type GameSession struct {
 	WeatherSeed  uint64
 	LevelTimer   float64
 	Score        int
+	RunScore     int     // scores of the levels completed since the run started
+	Elapsed      float64 // seconds played in the current level
+
+	// Mode is picked on the menu and kept for the whole run. DailySeed
+	// is set by the frontend and seeds the score attack map.
+	Mode      GameMode
+	DailySeed uint64
+
+	// Versus: per-player scores and the match winner (player number,
+	// 0 for a draw).
+	Scores [MaxPlayers]int
+	Winner int
+
+	// Endless mode respawn waves.
+	RespawnTimer float64
+	RespawnWave  uint64
+
+	Objective Objective // win condition of the current level
+	WantedCap float64   // highest wanted level the current level allows
+
+	// Menu start level: a new run begins at MenuLevel, which the player
+	// can pick from 1..MaxStartLevel (levels reached in earlier sessions).
+	MenuLevel     int
+	MaxStartLevel int
 
 	LastThemeIdx int
 	ThemeRoll    uint64
+
+	CanContinue bool // a saved level can be resumed from the menu
+
+	Events *EventBus // receives gameplay events; may be nil
 }
 
 func NewGameSession() *GameSession {
 	return &GameSession{
-		State:        StateMenu,
-		LastThemeIdx: -1,
+		State:         StateMenu,
+		LastThemeIdx:  -1,
+		MenuLevel:     1,
+		MaxStartLevel: 1,
 	}
 }
 
+// CycleMenuLevel steps the menu start level by dir, wrapping within the
+// unlocked range.
+func (s *GameSession) CycleMenuLevel(dir int) {
+	n := max(s.MaxStartLevel, 1)
+	s.MenuLevel = ((s.MenuLevel-1+dir)%n+n)%n + 1
+}
+
 // StartLevel resets entities and begins a new level.
 func (s *GameSession) StartLevel(level int, world *World, peds *PedestrianSystem, traffic *TrafficSystem, bonuses *BonusSystem, cops *CopSystem, mil *MilitarySystem, snake **Snake, particles *ParticleSystem, seed uint64) {
 	s.CurrentLevel = level
 	s.Score = 0
+	s.Scores, s.Winner = [MaxPlayers]int{}, 0
+	s.Elapsed = 0
+	s.RespawnTimer, s.RespawnWave = endlessRespawnInterval, 0
 	s.State = StatePlaying
 
-	cfg := GetLevelConfig(level)
-	if len(Themes) > 0 {
-		theme, themeIdx := PickLevelTheme(seed, level, s.ThemeRoll, s.LastThemeIdx)
-		s.ThemeRoll++
-		cfg.Theme = theme
-		s.LastThemeIdx = themeIdx
+	// Score attack plays the same map for everyone on a given day.
+	if s.Mode == ModeScoreAttack {
+		seed = s.DailySeed
+		s.ThemeRoll, s.LastThemeIdx = 0, -1
 	}
-	// Per-level mixed seed: keeps level flow varied across theme rolls/retries.
-	levelSeed := hash2D(seed^uint64(level)*0xA11CE5ED^s.ThemeRoll*0x9E3779B185EBCA87, level, s.LastThemeIdx+1)
+
+	cfg := modeLevelConfig(s.Mode, level)
+	levelSeed := s.rollLevel(&cfg, level, seed)
 	// No-road themes disable traffic; compensate with extra peds.
 	if cfg.Theme.NoRoads {
 		cfg.Cars = 0
 		cfg.Peds = cfg.Peds * 3 / 2
 	}
 	s.ThemeName = cfg.Theme.Name
-	// Random starting time of day.
+	// Random starting time of day unless the level pins it.
 	r := NewRand(levelSeed ^ 0xBAD5EED)
 	s.LevelTimer = r.RangeF(0, DayCyclePeriod)
+	if cfg.FixedTime {
+		s.LevelTimer = cfg.TimeOfDay * DayCyclePeriod
+	}
 	s.WeatherSeed = levelSeed ^ 0x57A7E12D4F3CB71D
 	s.Weather = PickLevelWeather(cfg.Theme, NewRand(s.WeatherSeed^uint64(level)*0x9E3779B185EBCA87), level)
+	if cfg.FixedWeather {
+		s.Weather = cfg.Weather
+	}
+	s.WantedCap = cfg.WantedMax
 
-	// Regenerate world with level-varied seed and theme.
+	// Reset the world to the level's seed, theme, size and map; chunks
+	// generate as they are used.
 	world.seed = levelSeed
 	world.Theme = cfg.Theme
+	world.Map = cfg.Map
 	world.burningTrees = make(map[int64]*TreeBurn)
 	world.burningBuildings = make(map[int64]*BuildingBurn)
-	world.temp = world.temp[:0]
-	world.scheduled = world.scheduled[:0]
-	for i := range world.chunks {
-		world.chunks[i] = nil
-	}
-	world.GenerateAll()
+	world.Resize(cfg.worldSize())
 	world.BuildSpatialIndex()
-	for cy := 0; cy <= world.maxCy; cy++ {
-		for cx := 0; cx <= world.maxCx; cx++ {
-			c := world.GetChunk(cx, cy)
-			if c != nil {
-				c.RecomputeShadows(world)
-			}
-		}
-	}
 
 	// Reset pedestrians.
 	peds.seed = levelSeed ^ 0xFED5EED
 	peds.SetEnvironment(cfg.Theme.FamilyName())
 	peds.P = peds.P[:0]
+	peds.resizeGrid(world.Width, world.Height)
 	peds.SpawnRandom(world, cfg.Peds)
 	peds.SpawnArmed(world, cfg.ArmedPeds)
 	peds.SpawnInfected(world, cfg.InfectedPeds)
+	peds.HideIndoors(world)
 
 	// Reset traffic.
 	traffic.seed = levelSeed ^ 0xCAFE5EED
 	traffic.SetEnvironment(cfg.Theme.FamilyName())
 	traffic.Cars = traffic.Cars[:0]
+	traffic.resizeGrid(world.Width, world.Height)
 	traffic.SpawnRandom(world, cfg.Cars)
 	traffic.RebuildGrid()
 
@@ -98,7 +136,9 @@ func (s *GameSession) StartLevel(level int, world *World, peds *PedestrianSystem
 	bonuses.lastKind = -1
 	bonuses.SpawnTimer = 3.0 + NewRand(levelSeed^0xB0B5EED^0x51A3E).RangeF(0, 4.0)
 	bonuses.Boxes = bonuses.Boxes[:0]
-	bonuses.SpawnRandom(cfg.BonusBoxes)
+	bonuses.Weights = cfg.BonusWeights
+	bonuses.SpawnRandom(world, cfg.BonusBoxes)
+	bonuses.SpawnIndoors(world, (cfg.BonusBoxes+1)/2)
 
 	// Reset cops and military.
 	cops.Reset()
@@ -107,16 +147,66 @@ func (s *GameSession) StartLevel(level int, world *World, peds *PedestrianSystem
 	// Reset particles.
 	particles.Clear()
 
-	// Spawn snake at world center on a road.
-	sx := float64(WorldWidth/2/Pattern*Pattern + RoadWidth/2)
-	sy := float64(WorldHeight/2/Pattern*Pattern + RoadWidth/2)
+	s.Objective = newObjective(cfg.Win, cfg.WinTarget, world, levelSeed)
+
+	sx, sy := snakeSpawn(world)
 	*snake = NewSnake(sx, sy, LevelSpeed(level))
+	(*snake).Events = s.Events
+
+	s.Events.Emit(Event{Type: EventLevelStarted, Data: level})
 }
 
-// Update advances the level timer.
+// snakeSpawn is where the snake starts: the road crossing nearest the
+// middle of the world that no parcel has built over (any open crossing in
+// themes without roads), or where the map puts it.
+func snakeSpawn(w *World) (float64, float64) {
+	if w.Map != nil {
+		return w.Map.spawnPoint()
+	}
+	tp := buildThemePalette(w.Theme)
+	cbx, cby := w.Width/2/Pattern, w.Height/2/Pattern
+	for r := 0; r <= max(w.Width, w.Height)/Pattern; r++ {
+		for by := cby - r; by <= cby+r; by++ {
+			for bx := cbx - r; bx <= cbx+r; bx++ {
+				if max(abs(bx-cbx), abs(by-cby)) != r {
+					continue
+				}
+				x, y := bx*Pattern+RoadWidth/2, by*Pattern+RoadWidth/2
+				if w.HeightAt(x, y) == 0 && !w.IsWater(x, y) && (w.Theme.NoRoads || isRoadPixel(w, tp, x, y)) {
+					return float64(x), float64(y)
+				}
+			}
+		}
+	}
+	return float64(cbx*Pattern + RoadWidth/2), float64(cby*Pattern + RoadWidth/2)
+}
+
+// rollLevel picks the theme variant of this attempt at level into cfg and
+// returns the level seed the world and spawns derive from.
+func (s *GameSession) rollLevel(cfg *LevelConfig, level int, seed uint64) uint64 {
+	if cfg.FixedTheme {
+		theme, themeIdx := PickFixedLevelTheme(cfg.Theme, seed, level, s.ThemeRoll)
+		s.ThemeRoll++
+		cfg.Theme = theme
+		s.LastThemeIdx = themeIdx
+	} else if len(Themes) > 0 {
+		theme, themeIdx := PickLevelTheme(seed, level, s.ThemeRoll, s.LastThemeIdx)
+		s.ThemeRoll++
+		cfg.Theme = theme
+		s.LastThemeIdx = themeIdx
+	}
+	// Per-level mixed seed: keeps level flow varied across theme rolls/retries.
+	return hash2D(seed^uint64(level)*0xA11CE5ED^s.ThemeRoll*0x9E3779B185EBCA87, level, s.LastThemeIdx+1)
+}
+
+// Update advances the level timer and timed objectives.
 func (s *GameSession) Update(dt float64) {
 	if s.State == StatePlaying {
 		s.LevelTimer += dt
+		s.Elapsed += dt
+		if s.Objective.Kind == ObjectiveSurvive {
+			s.Objective.Progress += dt
+		}
 	}
 }
 
@@ -126,19 +216,34 @@ func (s *GameSession) CheckLevelEnd(peds *PedestrianSystem, snake *Snake) {
 		return
 	}
 	if snake != nil {
-		s.Score = snake.Score
+		s.Score = s.modeScore(snake)
 	}
 
-	// Lose: snake is dead.
-	if snake == nil || !snake.Alive {
+	// Lose: snake is dead or the objective can no longer be met.
+	if snake == nil || !snake.Alive || s.Objective.Failed {
 		s.State = StateLevelFailed
-		PlaySound(SoundGameOver)
+		s.Events.Emit(Event{Type: EventLevelFailed, Data: s.CurrentLevel})
+		return
+	}
+
+	switch s.Mode {
+	case ModeEndless:
+		// Only death ends an endless run.
+		return
+	case ModeScoreAttack:
+		// Time up: HP left over is worth points.
+		if s.Elapsed >= ScoreAttackDuration {
+			s.State = StateLevelComplete
+			s.Score += int(snake.HP.Fraction() * scoreAttackHPBonus)
+			s.Events.Emit(Event{Type: EventLevelComplete, Data: s.CurrentLevel})
+		}
 		return
 	}
 
-	// Win: all peds eaten.
-	if peds.AliveCount() == 0 {
+	// Win: the level objective is met.
+	if s.Objective.complete(s, peds, snake) {
 		s.State = StateLevelComplete
-		PlaySound(SoundLevelUp)
+		s.RunScore += s.Score
+		s.Events.Emit(Event{Type: EventLevelComplete, Data: s.CurrentLevel})
 	}
 }
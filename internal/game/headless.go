@@ -0,0 +1,11 @@
+//go:build headless
+
+package game
+
+// The headless build is the simulation and world generation without the
+// window, renderer or audio, for tools such as snake-mapgen:
+//
+//	go build -tags headless ./cmd/snake-mapgen
+
+// localVersus is off: there is no keyboard for a second player.
+const localVersus = false
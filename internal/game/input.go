@@ -1,4 +1,4 @@
-//go:build !android
+//go:build !android && !headless
 
 package game
 
@@ -8,6 +8,9 @@ import (
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
+// localVersus: the keyboard steers player two while the mouse steers player one.
+const localVersus = true
+
 type Input struct {
 	prevMouse   map[glfw.MouseButton]bool
 	prevKeys    map[glfw.Key]bool
@@ -54,24 +57,27 @@ func CursorWorldPos(window *glfw.Window, cam Camera, fbW, fbH int) (float64, flo
 
 // SnakeSteerTarget returns the desired heading angle and whether the snake is idle.
 // Idle = no WASD input, cursor hasn't moved this frame, and cursor is within the deadzone.
-// WASD gives cardinal directions and always overrides idle.
-func SnakeSteerTarget(window *glfw.Window, in *Input, snake *Snake, cam Camera, fbW, fbH int) (float64, bool) {
+// WASD gives cardinal directions and always overrides idle; wasd=false leaves
+// the keys to player two in versus.
+func SnakeSteerTarget(window *glfw.Window, in *Input, snake *Snake, cam Camera, fbW, fbH int, wasd bool) (float64, bool) {
 	if snake == nil {
 		return 0, false
 	}
 
 	// WASD: cardinal directions take priority — never idle while steering by key.
-	if window.GetKey(glfw.KeyW) == glfw.Press {
-		return -math.Pi / 2, false
-	}
-	if window.GetKey(glfw.KeyS) == glfw.Press {
-		return math.Pi / 2, false
-	}
-	if window.GetKey(glfw.KeyA) == glfw.Press {
-		return math.Pi, false
-	}
-	if window.GetKey(glfw.KeyD) == glfw.Press {
-		return 0, false
+	if wasd {
+		if window.GetKey(glfw.KeyW) == glfw.Press {
+			return -math.Pi / 2, false
+		}
+		if window.GetKey(glfw.KeyS) == glfw.Press {
+			return math.Pi / 2, false
+		}
+		if window.GetKey(glfw.KeyA) == glfw.Press {
+			return math.Pi, false
+		}
+		if window.GetKey(glfw.KeyD) == glfw.Press {
+			return 0, false
+		}
 	}
 
 	// Track cursor movement (window pixel space).
@@ -100,8 +106,33 @@ func SnakeSteerTarget(window *glfw.Window, in *Input, snake *Snake, cam Camera,
 	return snake.Heading, false
 }
 
+// KeyboardSteerTarget steers versus player two with WASD, diagonals
+// included. With no key held the snake keeps its heading.
+func KeyboardSteerTarget(window *glfw.Window, snake *Snake) (float64, bool) {
+	if snake == nil {
+		return 0, false
+	}
+	dx, dy := 0.0, 0.0
+	if window.GetKey(glfw.KeyW) == glfw.Press {
+		dy--
+	}
+	if window.GetKey(glfw.KeyS) == glfw.Press {
+		dy++
+	}
+	if window.GetKey(glfw.KeyA) == glfw.Press {
+		dx--
+	}
+	if window.GetKey(glfw.KeyD) == glfw.Press {
+		dx++
+	}
+	if dx == 0 && dy == 0 {
+		return snake.Heading, false
+	}
+	return math.Atan2(dy, dx), false
+}
+
 // UpdateCameraZoom handles E/R zoom only (no panning — camera follows snake).
-func UpdateCameraZoom(cam *Camera, window *glfw.Window, dt float64, fbW, fbH int) {
+func UpdateCameraZoom(cam *Camera, world *World, window *glfw.Window, dt float64, fbW, fbH int) {
 	zoomRate := 1.4
 	if window.GetKey(glfw.KeyE) == glfw.Press {
 		cam.Zoom *= math.Exp(zoomRate * dt)
@@ -109,5 +140,5 @@ func UpdateCameraZoom(cam *Camera, window *glfw.Window, dt float64, fbW, fbH int
 	if window.GetKey(glfw.KeyR) == glfw.Press {
 		cam.Zoom *= math.Exp(-zoomRate * dt)
 	}
-	cam.Clamp(fbW, fbH)
+	cam.Clamp(world, fbW, fbH)
 }
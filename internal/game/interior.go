@@ -0,0 +1,581 @@
+package game
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// Building interiors. Some plain rectangular buildings are hollow: the outer
+// wall stands, the inside is floor split into rooms by a partition wall, and
+// doors lead out on the sides facing the street. The roof is not terrain but
+// an overlay (see Roofs) that is cut away while a snake is inside, so what
+// hides in there stays hidden until then. Interiors are planned from the
+// block's seed with the rest of its buildings, and the world looks them up a
+// block at a time.
+
+// Interior sizes in world pixels.
+const (
+	interiorMinSize   = 12 // shortest side of a building with an inside
+	interiorDoorWidth = 3
+	interiorRoomMin   = 5 // narrowest room a partition may leave
+	interiorMaxBlock  = 16
+)
+
+// interiorSpec is the inside of a buildingSpec, in block pixels.
+type interiorSpec struct {
+	Doors []rectI // gaps through the outer wall
+	Walls []rectI // partition walls, split around their doorways
+}
+
+// planInteriors hollows out theme.Interiors percent of the rectangular
+// buildings big enough to walk around in. It draws from its own stream so
+// the rest of the block comes out as before.
+func planInteriors(buildings []buildingSpec, seed uint64, theme ThemeConfig) {
+	if theme.Interiors <= 0 {
+		return
+	}
+	r := NewRand(seed ^ 0x1D0042B5C0FFEE11)
+	for k := range buildings {
+		b := &buildings[k]
+		if len(b.Parts) != 1 || r.Intn(100) >= theme.Interiors {
+			continue
+		}
+		fp := b.Parts[0]
+		if fp.X1-fp.X0 < interiorMinSize || fp.Y1-fp.Y0 < interiorMinSize {
+			continue
+		}
+		b.Interior = planInterior(r, fp)
+	}
+}
+
+// planInterior lays out the doors and rooms of a building with footprint fp.
+// Doors go through the walls nearest the block's edges, which face the
+// street: two of them, sometimes three.
+func planInterior(r *Rand, fp rectI) *interiorSpec {
+	w, h := fp.X1-fp.X0, fp.Y1-fp.Y0
+	gaps := [4]int{fp.Y0, BlockInner - fp.X1, BlockInner - fp.Y1, fp.X0} // top, right, bottom, left
+	sides := []int{0, 1, 2, 3}
+	slices.SortStableFunc(sides, func(a, b int) int { return cmp.Compare(gaps[a], gaps[b]) })
+	n := 2
+	if r.Intn(100) < 35 {
+		n = 3
+	}
+
+	in := &interiorSpec{}
+	for _, side := range sides[:n] {
+		along := w
+		if side == 1 || side == 3 {
+			along = h
+		}
+		s := r.Range(2, along-2-interiorDoorWidth)
+		var d rectI
+		switch side {
+		case 0:
+			d = rectI{X0: fp.X0 + s, Y0: fp.Y0, X1: fp.X0 + s + interiorDoorWidth, Y1: fp.Y0 + 1}
+		case 1:
+			d = rectI{X0: fp.X1 - 1, Y0: fp.Y0 + s, X1: fp.X1, Y1: fp.Y0 + s + interiorDoorWidth}
+		case 2:
+			d = rectI{X0: fp.X0 + s, Y0: fp.Y1 - 1, X1: fp.X0 + s + interiorDoorWidth, Y1: fp.Y1}
+		default:
+			d = rectI{X0: fp.X0, Y0: fp.Y0 + s, X1: fp.X0 + 1, Y1: fp.Y0 + s + interiorDoorWidth}
+		}
+		in.Doors = append(in.Doors, d)
+	}
+
+	// One partition across the longer side, if both rooms are wide enough
+	// and it does not run into a door.
+	vertical := w >= h
+	inner := max(w, h) - 2
+	if inner < 2*interiorRoomMin+1 {
+		return in
+	}
+	for range 4 {
+		at := r.Range(interiorRoomMin, inner-interiorRoomMin-1) + 1 // from the outer wall
+		gap := r.Range(1, min(w, h)-1-interiorDoorWidth)
+		var wall, way rectI
+		if vertical {
+			x := fp.X0 + at
+			wall = rectI{X0: x, Y0: fp.Y0 + 1, X1: x + 1, Y1: fp.Y1 - 1}
+			way = rectI{X0: x, Y0: fp.Y0 + gap, X1: x + 1, Y1: fp.Y0 + gap + interiorDoorWidth}
+		} else {
+			y := fp.Y0 + at
+			wall = rectI{X0: fp.X0 + 1, Y0: y, X1: fp.X1 - 1, Y1: y + 1}
+			way = rectI{X0: fp.X0 + gap, Y0: y, X1: fp.X0 + gap + interiorDoorWidth, Y1: y + 1}
+		}
+		blocked := false
+		for _, d := range in.Doors {
+			// Keep a pixel clear either side of a door in line with the wall.
+			if d.X0-1 < wall.X1 && wall.X0 < d.X1+1 && d.Y0-1 < wall.Y1 && wall.Y0 < d.Y1+1 {
+				blocked = true
+				break
+			}
+		}
+		if blocked {
+			continue
+		}
+		if vertical {
+			in.Walls = appendRect(in.Walls, rectI{X0: wall.X0, Y0: wall.Y0, X1: wall.X1, Y1: way.Y0})
+			in.Walls = appendRect(in.Walls, rectI{X0: wall.X0, Y0: way.Y1, X1: wall.X1, Y1: wall.Y1})
+		} else {
+			in.Walls = appendRect(in.Walls, rectI{X0: wall.X0, Y0: wall.Y0, X1: way.X0, Y1: wall.Y1})
+			in.Walls = appendRect(in.Walls, rectI{X0: way.X1, Y0: wall.Y0, X1: wall.X1, Y1: wall.Y1})
+		}
+		break
+	}
+	return in
+}
+
+// appendRect appends r to rs unless it is empty.
+func appendRect(rs []rectI, r rectI) []rectI {
+	if r.X0 >= r.X1 || r.Y0 >= r.Y1 {
+		return rs
+	}
+	return append(rs, r)
+}
+
+func (r rectI) contains(x, y int) bool {
+	return x >= r.X0 && x < r.X1 && y >= r.Y0 && y < r.Y1
+}
+
+func rectsContain(rs []rectI, x, y int) bool {
+	for _, r := range rs {
+		if r.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// interiorPlaced reports whether b's interior is built in a block with
+// its top-left inner corner at blockX0, blockY0: the building is drawn at
+// all, clear of the ring road, and no water or shore reaches it.
+func interiorPlaced(b buildingSpec, blockX0, blockY0, worldW, worldH int, water *waterLayout) bool {
+	if b.Interior == nil {
+		return false
+	}
+	fp := b.Parts[0]
+	r := rectI{X0: blockX0 + fp.X0, Y0: blockY0 + fp.Y0, X1: blockX0 + fp.X1, Y1: blockY0 + fp.Y1}
+	if r.X0 < BorderThickness+RoadWidth || r.Y0 < BorderThickness+RoadWidth ||
+		r.X1 > worldW-BorderThickness-RoadWidth || r.Y1 > worldH-BorderThickness-RoadWidth {
+		return false
+	}
+	return water.dry(r)
+}
+
+// interiorFloorColor is the floor of b: its walls' colour worked into the
+// lot's.
+func interiorFloorColor(b buildingSpec, tp themePalette) RGB {
+	return lerpRGB(tp.Lot, b.Col, 0.35).Mul(210)
+}
+
+// interiorDoorstep is how far a doorstep reaches out from a door.
+const interiorDoorstep = 2
+
+// drawInteriors draws the buildings of block bx, by that have an inside.
+// They go over everything the blocks drew, merged parcels included, so a
+// block that is part of a merged parcel still gets its buildings with an
+// inside.
+func drawInteriors(c *Chunk, bx, by int, feat blockFeatures, tp themePalette, theme ThemeConfig, water *waterLayout) {
+	blockX0 := bx*Pattern + RoadWidth + SidewalkWidth
+	blockY0 := by*Pattern + RoadWidth + SidewalkWidth
+	baseX, baseY := c.WorldOrigin()
+	if blockX0 >= baseX+ChunkSize || blockY0 >= baseY+ChunkSize ||
+		blockX0+BlockInner <= baseX || blockY0+BlockInner <= baseY {
+		return
+	}
+	for _, b := range feat.Buildings {
+		if interiorPlaced(b, blockX0, blockY0, c.worldW, c.worldH, water) {
+			drawInterior(c, b, feat.Buildings, blockX0, blockY0, feat.Seed, tp, isWinterTheme(theme))
+		}
+	}
+}
+
+// drawInterior draws b hollow: the outer wall as applyBlockFeatures draws
+// it, doors through it, partition walls and a floor, then a doorstep
+// outside each door clear of the block's other buildings. Trees and
+// anything else in the way give way to it.
+func drawInterior(c *Chunk, b buildingSpec, buildings []buildingSpec, blockX0, blockY0 int, seed uint64, tp themePalette, winter bool) {
+	fp := b.Parts[0]
+	floor := interiorFloorColor(b, tp)
+	sill := tp.Sidewalk.Add(-14, -14, -12)
+	baseX, baseY := c.WorldOrigin()
+	at := func(lx, ly int) (int, bool) {
+		wx, wy := blockX0+lx, blockY0+ly
+		if wx < baseX || wy < baseY || wx >= baseX+ChunkSize || wy >= baseY+ChunkSize {
+			return 0, false
+		}
+		i := c.idx(wx-baseX, wy-baseY)
+		return i, c.Unbreakable[i] == 0
+	}
+
+	for ly := fp.Y0; ly < fp.Y1; ly++ {
+		for lx := fp.X0; lx < fp.X1; lx++ {
+			i, ok := at(lx, ly)
+			if !ok {
+				continue
+			}
+			wx, wy := blockX0+lx, blockY0+ly
+			switch {
+			case rectsContain(b.Interior.Doors, lx, ly):
+				c.set(i, sill, 0, ShadeLit, 0)
+			case lx == fp.X0 || lx == fp.X1-1 || ly == fp.Y0 || ly == fp.Y1-1:
+				col, hgt := b.Outline, b.H
+				if b.RoofRim {
+					hgt += b.RimAddH
+				}
+				if winter && uint8(hash2D(seed^0xA11CE5ED, wx, wy)>>56) < 104 {
+					col = col.Add(38, 42, 46)
+					hgt++
+				}
+				c.set(i, col, hgt, ShadeLit, 0)
+			case rectsContain(b.Interior.Walls, lx, ly):
+				c.set(i, b.Outline, b.H, ShadeLit, 0)
+			default:
+				// Two-pixel tiles with a little wear.
+				col := floor
+				if ((lx>>1)+(ly>>1))&1 == 0 {
+					col = col.Add(7, 6, 4)
+				}
+				n := int(hash2D(seed^0xF1005EED, wx, wy)>>62) - 1
+				c.set(i, col.Add(n, n, n), 0, ShadeLit, 0)
+			}
+		}
+	}
+
+	for _, d := range b.Interior.Doors {
+		step := d
+		switch {
+		case d.Y0 == fp.Y0:
+			step.Y0, step.Y1 = d.Y0-interiorDoorstep, d.Y0
+		case d.Y1 == fp.Y1:
+			step.Y0, step.Y1 = d.Y1, d.Y1+interiorDoorstep
+		case d.X0 == fp.X0:
+			step.X0, step.X1 = d.X0-interiorDoorstep, d.X0
+		default:
+			step.X0, step.X1 = d.X1, d.X1+interiorDoorstep
+		}
+		for ly := max(step.Y0, 0); ly < min(step.Y1, BlockInner); ly++ {
+			for lx := max(step.X0, 0); lx < min(step.X1, BlockInner); lx++ {
+				if i, ok := at(lx, ly); ok && !buildingsContain(buildings, lx, ly) {
+					c.set(i, sill, 0, ShadeLit, 0)
+				}
+			}
+		}
+	}
+}
+
+func buildingsContain(buildings []buildingSpec, x, y int) bool {
+	for _, b := range buildings {
+		if rectsContain(b.Parts, x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// interior is a building interior placed in the world, in world pixels.
+type interior struct {
+	rect   rectI // the building, outer wall included
+	doors  []door
+	roof   RGB
+	unit   RGB     // roof unit colour
+	units  []rectI // roof units
+	winter bool    // snow on the roof
+	seed   uint64
+}
+
+// door is a gap through an interior's outer wall.
+type door struct {
+	x, y   float64 // middle of the gap
+	nx, ny float64 // outward normal
+}
+
+func newInterior(b buildingSpec, blockX0, blockY0 int, seed uint64, theme ThemeConfig, tp themePalette) interior {
+	fp := b.Parts[0]
+	in := interior{
+		rect:   rectI{X0: blockX0 + fp.X0, Y0: blockY0 + fp.Y0, X1: blockX0 + fp.X1, Y1: blockY0 + fp.Y1},
+		roof:   b.Col,
+		unit:   tp.BuildingDark,
+		winter: isWinterTheme(theme),
+		seed:   seed,
+	}
+	for _, u := range b.RoofUnits {
+		in.units = append(in.units, rectI{X0: blockX0 + u.X0, Y0: blockY0 + u.Y0, X1: blockX0 + u.X1, Y1: blockY0 + u.Y1})
+	}
+	for _, d := range b.Interior.Doors {
+		dr := door{x: float64(blockX0) + float64(d.X0+d.X1)/2, y: float64(blockY0) + float64(d.Y0+d.Y1)/2}
+		switch {
+		case d.Y0 == fp.Y0:
+			dr.ny = -1
+		case d.Y1 == fp.Y1:
+			dr.ny = 1
+		case d.X0 == fp.X0:
+			dr.nx = -1
+		default:
+			dr.nx = 1
+		}
+		in.doors = append(in.doors, dr)
+	}
+	return in
+}
+
+// inside reports whether a world point is on the floor, inside the walls.
+func (in *interior) inside(x, y float64) bool {
+	return x >= float64(in.rect.X0+1) && x < float64(in.rect.X1-1) &&
+		y >= float64(in.rect.Y0+1) && y < float64(in.rect.Y1-1)
+}
+
+// blockInteriors lists the interiors of city block bx, by.
+func (w *World) blockInteriors(bx, by int) []interior {
+	cols := floorDiv(w.Width-1, Pattern) + 1
+	if w.Map != nil || bx < 0 || by < 0 || bx >= cols || by > floorDiv(w.Height-1, Pattern) {
+		return nil
+	}
+	key := by*cols + bx
+	if list, ok := w.interiors[key]; ok {
+		return list
+	}
+	var list []interior
+	x0 := bx*Pattern + RoadWidth + SidewalkWidth
+	y0 := by*Pattern + RoadWidth + SidewalkWidth
+	if x0 >= BorderThickness && y0 >= BorderThickness &&
+		x0+BlockInner <= w.Width-BorderThickness && y0+BlockInner <= w.Height-BorderThickness {
+		if w.water == nil {
+			w.water = newWaterLayout(w.seed, w.Theme, w.Width, w.Height)
+		}
+		tp := buildThemePalette(w.Theme)
+		feat := genBlockFeatures(w.seed, bx, by, w.Theme, tp)
+		for _, b := range feat.Buildings {
+			if interiorPlaced(b, x0, y0, w.Width, w.Height, w.water) && len(list) < interiorMaxBlock {
+				list = append(list, newInterior(b, x0, y0, feat.Seed, w.Theme, tp))
+			}
+		}
+	}
+	w.interiors[key] = list
+	return list
+}
+
+// interiorAt is the interior whose floor holds the world point x, y, or nil.
+func (w *World) interiorAt(x, y float64) *interior {
+	list := w.blockInteriors(floorDiv(int(math.Floor(x)), Pattern), floorDiv(int(math.Floor(y)), Pattern))
+	for k := range list {
+		if list[k].inside(x, y) {
+			return &list[k]
+		}
+	}
+	return nil
+}
+
+// floorSpot picks an open floor pixel of in, or false if r finds none.
+func (in *interior) floorSpot(w *World, r *Rand) (float64, float64, bool) {
+	for range 16 {
+		x := r.Range(in.rect.X0+1, in.rect.X1-2)
+		y := r.Range(in.rect.Y0+1, in.rect.Y1-2)
+		if w.HeightAt(x, y) == 0 {
+			return float64(x) + 0.5, float64(y) + 0.5, true
+		}
+	}
+	return 0, 0, false
+}
+
+// breachTarget is where a unit at x, y makes for to get at a snake at
+// sx, sy inside in: round the building to the outside of a door, then
+// through it. Breachers take
+// the door nearest them; flankers leave the door nearest the snake to
+// them and come in through another.
+func (in *interior) breachTarget(x, y, sx, sy float64, flank bool) (float64, float64) {
+	skip := -1
+	if flank && len(in.doors) > 1 {
+		best := math.MaxFloat64
+		for k, d := range in.doors {
+			if dd := math.Hypot(d.x-sx, d.y-sy); dd < best {
+				best, skip = dd, k
+			}
+		}
+	}
+	pick, best := 0, math.MaxFloat64
+	for k, d := range in.doors {
+		if dd := math.Hypot(d.x-x, d.y-y); k != skip && dd < best {
+			best, pick = dd, k
+		}
+	}
+	d := in.doors[pick]
+	// Lined up in front of the door: go in. Otherwise get in front of it.
+	out := (x-d.x)*d.nx + (y-d.y)*d.ny
+	side := math.Abs((x-d.x)*d.ny - (y-d.y)*d.nx)
+	if out < 5 && side < interiorDoorWidth/2 && out > -1 {
+		return d.x - d.nx*3, d.y - d.ny*3
+	}
+	if out >= 1.5 {
+		return d.x + d.nx*4, d.y + d.ny*4
+	}
+	if out >= 0 {
+		// Hugging the wall: step off it first.
+		return x + d.nx*3, y + d.ny*3
+	}
+
+	// Behind the door's wall: walk round the building a corner at a time,
+	// three pixels out from it.
+	x0, y0 := float64(in.rect.X0)-3, float64(in.rect.Y0)-3
+	x1, y1 := float64(in.rect.X1)+3, float64(in.rect.Y1)+3
+	if d.ny != 0 {
+		cx := x0
+		if x > d.x {
+			cx = x1
+		}
+		if x >= float64(in.rect.X0) && x < float64(in.rect.X1) {
+			// Right behind it: first to a back corner.
+			if d.ny < 0 {
+				return cx, y1
+			}
+			return cx, y0
+		}
+		if d.ny < 0 {
+			return cx, y0
+		}
+		return cx, y1
+	}
+	cy := y0
+	if y > d.y {
+		cy = y1
+	}
+	if y >= float64(in.rect.Y0) && y < float64(in.rect.Y1) {
+		if d.nx < 0 {
+			return x1, cy
+		}
+		return x0, cy
+	}
+	if d.nx < 0 {
+		return x0, cy
+	}
+	return x1, cy
+}
+
+// exitTarget is where someone at x, y inside in runs to get away from a
+// snake at sx, sy: just outside the door that leads furthest from it.
+func (in *interior) exitTarget(x, y, sx, sy float64) (float64, float64) {
+	pick, best := 0, math.Inf(-1)
+	for k, d := range in.doors {
+		if score := math.Hypot(d.x-sx, d.y-sy) - math.Hypot(d.x-x, d.y-y); score > best {
+			best, pick = score, k
+		}
+	}
+	d := in.doors[pick]
+	return d.x + d.nx*4, d.y + d.ny*4
+}
+
+// roofDown reports whether most of in's outer wall has been knocked down,
+// taking the roof with it.
+func (in *interior) roofDown(w *World) bool {
+	standing, total := 0, 0
+	count := func(x, y int) {
+		total++
+		if w.HeightAt(x, y) > rubbleHeight {
+			standing++
+		}
+	}
+	for x := in.rect.X0; x < in.rect.X1; x++ {
+		count(x, in.rect.Y0)
+		count(x, in.rect.Y1-1)
+	}
+	for y := in.rect.Y0 + 1; y < in.rect.Y1-1; y++ {
+		count(in.rect.X0, y)
+		count(in.rect.X1-1, y)
+	}
+	return standing*2 < total
+}
+
+// roofFadeSpeed is how fast a roof fades in and out, in alpha per second.
+const roofFadeSpeed = 5.0
+
+// Roofs draws the roofs over building interiors. A roof fades out while a
+// snake is inside and stays off once most of the building's outer wall is
+// gone.
+type Roofs struct {
+	// World the fades belong to.
+	seed          uint64
+	width, height int
+
+	alpha map[int]float32 // by block*interiorMaxBlock+k; missing is closed
+	shown []shownRoof
+}
+
+type shownRoof struct {
+	in    *interior
+	alpha float32
+}
+
+// Update fades the roofs of the interiors in view.
+func (rf *Roofs) Update(w *World, view RectF, snakes []*Snake, dt float64) {
+	if rf.alpha == nil || rf.seed != w.seed || rf.width != w.Width || rf.height != w.Height {
+		rf.seed, rf.width, rf.height = w.seed, w.Width, w.Height
+		rf.alpha = make(map[int]float32)
+	}
+	rf.shown = rf.shown[:0]
+	cols := floorDiv(w.Width-1, Pattern) + 1
+	bx0, by0 := floorDiv(int(view.X0), Pattern), floorDiv(int(view.Y0), Pattern)
+	bx1, by1 := floorDiv(int(view.X1), Pattern), floorDiv(int(view.Y1), Pattern)
+	for by := by0; by <= by1; by++ {
+		for bx := bx0; bx <= bx1; bx++ {
+			list := w.blockInteriors(bx, by)
+			for k := range list {
+				in := &list[k]
+				open := in.roofDown(w)
+				for _, s := range snakes {
+					if s != nil && s.Alive && in.inside(s.Head()) {
+						open = true
+					}
+				}
+				key := (by*cols+bx)*interiorMaxBlock + k
+				a, ok := rf.alpha[key]
+				if !ok {
+					a = 1
+				}
+				step := float32(roofFadeSpeed * dt)
+				if open {
+					a = max(a-step, 0)
+				} else {
+					a = min(a+step, 1)
+				}
+				if a == 1 {
+					delete(rf.alpha, key)
+				} else {
+					rf.alpha[key] = a
+				}
+				if a > 0 {
+					rf.shown = append(rf.shown, shownRoof{in: in, alpha: a})
+				}
+			}
+		}
+	}
+}
+
+// Sprites appends a sprite for every roof pixel shown by the last Update:
+// the building's colour over its floor, with its roof units.
+func (rf *Roofs) Sprites(buf []float32) []float32 {
+	for _, s := range rf.shown {
+		in := s.in
+		for y := in.rect.Y0 + 1; y < in.rect.Y1-1; y++ {
+			for x := in.rect.X0 + 1; x < in.rect.X1-1; x++ {
+				col := in.roof
+				for _, u := range in.units {
+					if u.contains(x, y) {
+						col = in.unit
+						if x == u.X0 || x == u.X1-1 || y == u.Y0 || y == u.Y1-1 {
+							col = col.Mul(180)
+						}
+						break
+					}
+				}
+				if in.winter && uint8(hash2D(in.seed^0xA11CE5ED, x, y)>>56) < 228 {
+					col = col.Add(72, 78, 84)
+				}
+				// Slightly oversized so neighbours overlap at any zoom.
+				buf = append(buf, float32(x)+0.5, float32(y)+0.5, 1.15,
+					float32(col.R)/255, float32(col.G)/255, float32(col.B)/255, s.alpha, 0)
+			}
+		}
+	}
+	return buf
+}
@@ -0,0 +1,139 @@
+package game
+
+import "math"
+
+// SimTickDT is the fixed simulation step. 60 Hz matches the rate the snake
+// path buffer and NPC tuning were balanced at.
+const SimTickDT = 1.0 / 60.0
+
+const (
+	// maxFrameDT caps how much wall time one frame may feed the accumulator
+	// so a long stall (window drag, breakpoint) can't trigger a tick storm.
+	// Frontends hand Advance the raw frame time and leave the capping here.
+	maxFrameDT = 0.25
+
+	// interpMaxJump: moves longer than this in one tick are teleports or
+	// fresh spawns and are drawn at their new position without blending.
+	interpMaxJump = 6.0
+)
+
+// Advance feeds frame time into the fixed-step accumulator and runs whole
+// ticks. next is asked for the input of each tick; returning false stops
+// stepping for this frame (e.g. a replay ran out). Afterwards Alpha holds how
+// far the frame sits between the last two ticks.
+func (sim *Simulation) Advance(frameDT float64, next func() (InputFrame, bool)) {
+	if frameDT > maxFrameDT {
+		frameDT = maxFrameDT
+	}
+	if frameDT > 0 {
+		sim.stepAcc += frameDT
+	}
+	for sim.stepAcc >= SimTickDT {
+		in, ok := next()
+		if !ok {
+			sim.stepAcc = 0
+			break
+		}
+		sim.stepAcc -= SimTickDT
+		sim.captureTick()
+		sim.Step(SimTickDT, in)
+	}
+	sim.Alpha = sim.stepAcc / SimTickDT
+}
+
+// captureTick remembers positions before a tick for render interpolation.
+func (sim *Simulation) captureTick() {
+	sim.eachMover(func(x, y, lastX, lastY *float64) {
+		*lastX, *lastY = *x, *y
+	})
+	sim.lastHeadOK = [MaxPlayers]bool{}
+	for i, s := range sim.Snakes {
+		if s != nil && len(s.Path) > 0 {
+			sim.lastHead[i] = s.Path[0]
+			sim.lastHeadOK[i] = true
+		}
+	}
+}
+
+// eachMover calls fn with the position and previous-tick position of
+// everything drawn between ticks: peds, cars, cops, their helicopters and
+// the military. The order is the same on every call.
+func (sim *Simulation) eachMover(fn func(x, y, lastX, lastY *float64)) {
+	for i := range sim.Peds.P {
+		p := &sim.Peds.P[i]
+		fn(&p.X, &p.Y, &p.LastX, &p.LastY)
+	}
+	for i := range sim.Traffic.Cars {
+		c := &sim.Traffic.Cars[i]
+		fn(&c.X, &c.Y, &c.LastX, &c.LastY)
+	}
+	for i := range sim.Cops.Cars {
+		c := &sim.Cops.Cars[i]
+		fn(&c.X, &c.Y, &c.LastX, &c.LastY)
+	}
+	for i := range sim.Cops.Peds {
+		c := &sim.Cops.Peds[i]
+		fn(&c.X, &c.Y, &c.LastX, &c.LastY)
+	}
+	for i := range sim.Cops.Helis {
+		h := &sim.Cops.Helis[i]
+		fn(&h.X, &h.Y, &h.LastX, &h.LastY)
+	}
+	for i := range sim.Mil.Tanks {
+		t := &sim.Mil.Tanks[i]
+		fn(&t.X, &t.Y, &t.LastX, &t.LastY)
+	}
+	for i := range sim.Mil.Troops {
+		t := &sim.Mil.Troops[i]
+		fn(&t.X, &t.Y, &t.LastX, &t.LastY)
+	}
+	for i := range sim.Mil.Helis {
+		h := &sim.Mil.Helis[i]
+		fn(&h.X, &h.Y, &h.LastX, &h.LastY)
+	}
+}
+
+// interpPos blends from the previous tick towards the current position.
+func interpPos(lastX, lastY, x, y, alpha float64) (float64, float64) {
+	if math.Abs(x-lastX) > interpMaxJump || math.Abs(y-lastY) > interpMaxJump {
+		return x, y
+	}
+	return lastX + (x-lastX)*alpha, lastY + (y-lastY)*alpha
+}
+
+// BeginInterpolatedRender moves everything that moves, and the snake
+// heads, to their in-between positions for drawing. Every call must be
+// paired with EndInterpolatedRender before the next tick.
+func (sim *Simulation) BeginInterpolatedRender() {
+	a := clampF(sim.Alpha, 0, 1)
+	saved := sim.interpSaved[:0]
+	sim.eachMover(func(x, y, lastX, lastY *float64) {
+		saved = append(saved, PathPoint{X: *x, Y: *y})
+		*x, *y = interpPos(*lastX, *lastY, *x, *y, a)
+	})
+	for i, s := range sim.Snakes {
+		if s != nil && sim.lastHeadOK[i] && len(s.Path) > 0 {
+			head := s.Path[0]
+			saved = append(saved, head)
+			s.Path[0].X, s.Path[0].Y = interpPos(sim.lastHead[i].X, sim.lastHead[i].Y, head.X, head.Y, a)
+		}
+	}
+	sim.interpSaved = saved
+}
+
+// EndInterpolatedRender restores the simulation positions.
+func (sim *Simulation) EndInterpolatedRender() {
+	saved := sim.interpSaved
+	n := 0
+	sim.eachMover(func(x, y, _, _ *float64) {
+		*x, *y = saved[n].X, saved[n].Y
+		n++
+	})
+	for i, s := range sim.Snakes {
+		if s != nil && sim.lastHeadOK[i] && n < len(saved) && len(s.Path) > 0 {
+			s.Path[0] = saved[n]
+			n++
+		}
+	}
+	sim.interpSaved = saved[:0]
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// TestAdvance feeds uneven frame times into the accumulator and checks each
+// frame runs the whole ticks it owes and leaves Alpha in [0,1).
+func TestAdvance(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		frames []float64
+		ticks  []int // ticks run by each frame
+	}{
+		{"uneven frames", []float64{0.004, 0.021, 0.05, 0, 0.033, 0.017}, []int{0, 1, 3, 0, 2, 1}},
+		{"fast display", []float64{0.007, 0.007, 0.007, 0.007, 0.007}, []int{0, 0, 1, 0, 1}},
+		{"negative frame time", []float64{0.02, -1, 0.02}, []int{1, 0, 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := NewSimulation(1)
+			sim.StartLevel(1)
+			n := 0
+			for i, dt := range tc.frames {
+				before := n
+				sim.Advance(dt, func() (InputFrame, bool) {
+					n++
+					return steerFor(n), true
+				})
+				if got := n - before; got != tc.ticks[i] {
+					t.Errorf("frame %d (%.3fs) ran %d ticks, want %d", i, dt, got, tc.ticks[i])
+				}
+				if sim.Alpha < 0 || sim.Alpha >= 1 {
+					t.Errorf("frame %d: alpha %v", i, sim.Alpha)
+				}
+			}
+		})
+	}
+}
+
+func TestAdvanceCapsStalls(t *testing.T) {
+	sim := NewSimulation(1)
+	sim.StartLevel(1)
+	n := 0
+	next := func() (InputFrame, bool) {
+		n++
+		return InputFrame{}, true
+	}
+	sim.Advance(5, next)
+	if most := int(math.Ceil(maxFrameDT / SimTickDT)); n > most || n < most-1 {
+		t.Errorf("a 5s stall ran %d ticks, want at most %d", n, most)
+	}
+	if sim.Alpha < 0 || sim.Alpha >= 1 {
+		t.Errorf("alpha %v after a stall", sim.Alpha)
+	}
+
+	// Input running out drops the time still owed.
+	n = 0
+	sim.Advance(0.1, func() (InputFrame, bool) {
+		n++
+		return InputFrame{}, n < 3
+	})
+	if n != 3 || sim.Alpha != 0 {
+		t.Errorf("ran %d ticks with alpha %v, want 2 ticks and alpha 0", n-1, sim.Alpha)
+	}
+	n = 0
+	sim.Advance(0.001, next)
+	if n != 0 {
+		t.Errorf("owed time carried past the end of input: %d ticks", n)
+	}
+}
+
+func TestInterpPos(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		lx, ly, x, y float64
+		alpha        float64
+		wx, wy       float64
+	}{
+		{"start", 10, 10, 12, 14, 0, 10, 10},
+		{"halfway", 10, 10, 12, 14, 0.5, 11, 12},
+		{"end", 10, 10, 12, 14, 1, 12, 14},
+		{"teleport", 10, 10, 10 + interpMaxJump + 1, 10, 0.5, 10 + interpMaxJump + 1, 10},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if x, y := interpPos(tc.lx, tc.ly, tc.x, tc.y, tc.alpha); x != tc.wx || y != tc.wy {
+				t.Errorf("drawn at %v,%v, want %v,%v", x, y, tc.wx, tc.wy)
+			}
+		})
+	}
+}
+
+// TestInterpolatedRender checks drawing between ticks puts everything
+// between its last two positions, and puts it all back afterwards.
+func TestInterpolatedRender(t *testing.T) {
+	sim := NewSimulation(3)
+	sim.StartLevel(1)
+	n := 0
+	for range 20 {
+		sim.Advance(0.023, func() (InputFrame, bool) {
+			n++
+			return steerFor(n), true
+		})
+	}
+	type pos struct{ x, y, lx, ly float64 }
+	var before []pos
+	sim.eachMover(func(x, y, lx, ly *float64) {
+		before = append(before, pos{*x, *y, *lx, *ly})
+	})
+	head := sim.Snake.Path[0]
+
+	sim.BeginInterpolatedRender()
+	k := 0
+	sim.eachMover(func(x, y, _, _ *float64) {
+		p := before[k]
+		k++
+		if math.Abs(p.x-p.lx) > interpMaxJump || math.Abs(p.y-p.ly) > interpMaxJump {
+			return
+		}
+		if *x < min(p.x, p.lx) || *x > max(p.x, p.lx) || *y < min(p.y, p.ly) || *y > max(p.y, p.ly) {
+			t.Fatalf("mover %d drawn at %v,%v, outside %v,%v..%v,%v", k-1, *x, *y, p.lx, p.ly, p.x, p.y)
+		}
+	})
+	sim.EndInterpolatedRender()
+
+	k = 0
+	sim.eachMover(func(x, y, _, _ *float64) {
+		if p := before[k]; *x != p.x || *y != p.y {
+			t.Fatalf("mover %d left at %v,%v, was %v,%v", k, *x, *y, p.x, p.y)
+		}
+		k++
+	})
+	if sim.Snake.Path[0] != head {
+		t.Errorf("snake head left at %+v, was %+v", sim.Snake.Path[0], head)
+	}
+}
@@ -1,5 +1,15 @@
 package game
 
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
 type LevelConfig struct {
 	Peds         int
 	Cars         int
@@ -7,78 +17,397 @@ type LevelConfig struct {
 	InfectedPeds int
 	BonusBoxes   int
 	Theme        ThemeConfig
+
+	// Optional overrides; the zero values keep the randomized defaults.
+	FixedTheme   bool // use Theme instead of rolling one per attempt
+	FixedWeather bool // use Weather instead of rolling by theme
+	Weather      WeatherType
+	FixedTime    bool    // start at TimeOfDay instead of a random time
+	TimeOfDay    float64 // 0..1 fraction of DayCyclePeriod
+	BonusWeights []int   // relative weight per BonusKind; nil = uniform
+	Win          ObjectiveKind
+	WinTarget    float64   // seconds, points, kills or outbreak share for Win
+	WantedMax    float64   // wanted level cap (0–WantedMax)
+	WorldWidth   int       // world pixels, whole city blocks; 0 = DefaultWorldWidth
+	WorldHeight  int       // world pixels, whole city blocks; 0 = DefaultWorldHeight
+	Map          *WorldMap // hand-authored terrain; nil = generated
+}
+
+// worldSize is the level's world size with the defaults filled in.
+func (c *LevelConfig) worldSize() (int, int) {
+	if c.Map != nil {
+		return c.Map.Width, c.Map.Height
+	}
+	w, h := c.WorldWidth, c.WorldHeight
+	if w == 0 {
+		w = DefaultWorldWidth
+	}
+	if h == 0 {
+		h = DefaultWorldHeight
+	}
+	return w, h
+}
+
+// LevelDef is one level in a level pack file. Pointer fields are optional.
+type LevelDef struct {
+	Note         string         `json:"note,omitempty"`
+	Peds         int            `json:"peds"`
+	Cars         int            `json:"cars"`
+	ArmedPeds    int            `json:"armed_peds"`
+	InfectedPeds int            `json:"infected_peds"`
+	BonusBoxes   int            `json:"bonus_boxes"`
+	Theme        string         `json:"theme,omitempty"`       // theme family name, e.g. "Forest"
+	Weather      string         `json:"weather,omitempty"`     // "none", "rain" or "snow"
+	TimeOfDay    *float64       `json:"time_of_day,omitempty"` // 0..1 through the day cycle (0.25 = noon)
+	BonusWeights map[string]int `json:"bonus_weights,omitempty"`
+	Win          string         `json:"win,omitempty"` // objective key, see objectiveKeys
+	WinTarget    *float64       `json:"win_target,omitempty"`
+	WantedMax    *float64       `json:"wanted_max,omitempty"`
+	WorldWidth   int            `json:"world_width,omitempty"`  // world pixels, rounded up to whole city blocks
+	WorldHeight  int            `json:"world_height,omitempty"` // world pixels, rounded up to whole city blocks
+	Map          string         `json:"map,omitempty"`          // PNG in the legend colours, relative to the pack file
+	HeightMap    string         `json:"height_map,omitempty"`   // greyscale PNG of building heights
+
+	worldMap *WorldMap // Map and HeightMap, loaded with the pack
+}
+
+// LevelScaling extends a pack past its last level: level n gets
+// Base + PerLevel*(n - len(Levels) - 1), counts truncated. Objectives, when
+// set, are cycled through in order for those levels.
+type LevelScaling struct {
+	Note       string           `json:"note,omitempty"`
+	Base       LevelDef         `json:"base"`
+	Objectives []LevelObjective `json:"objectives,omitempty"`
+	PerLevel   struct {
+		Peds         float64 `json:"peds"`
+		Cars         float64 `json:"cars"`
+		ArmedPeds    float64 `json:"armed_peds"`
+		InfectedPeds float64 `json:"infected_peds"`
+		BonusBoxes   float64 `json:"bonus_boxes"`
+	} `json:"per_level"`
 }
 
-// GetLevelConfig returns settings for a given level.
-// The base population/bonus targets are defined per level.
-// Theme selection is randomized at StartLevel.
-// Levels 1–14 are hand-crafted; beyond that scales up procedurally.
-func GetLevelConfig(level int) LevelConfig {
-	var cfg LevelConfig
-
-	switch level {
-	case 1:
-		// Easy intro — familiar suburban streets, light traffic.
-		cfg = LevelConfig{Peds: 25, Cars: 6, ArmedPeds: 1, InfectedPeds: 1, BonusBoxes: 3, Theme: ThemeSuburban}
-	case 2:
-		// Open countryside — few buildings, room to maneuver.
-		cfg = LevelConfig{Peds: 35, Cars: 8, ArmedPeds: 3, InfectedPeds: 2, BonusBoxes: 3, Theme: ThemeRural}
-	case 3:
-		// City streets — denser buildings, more traffic.
-		cfg = LevelConfig{Peds: 50, Cars: 14, ArmedPeds: 5, InfectedPeds: 4, BonusBoxes: 4, Theme: ThemeCity}
-	case 4:
-		// Dense woodland — no roads, peds boosted, no cars.
-		cfg = LevelConfig{Peds: 60, Cars: 0, ArmedPeds: 8, InfectedPeds: 5, BonusBoxes: 4, Theme: ThemeForest}
-	case 5:
-		// Quiet village — tiny buildings, tight alleys between cottages.
-		cfg = LevelConfig{Peds: 65, Cars: 14, ArmedPeds: 10, InfectedPeds: 7, BonusBoxes: 3, Theme: ThemeVillage}
-	case 6:
-		// Green city — wide parks to chase through, light traffic.
-		cfg = LevelConfig{Peds: 75, Cars: 16, ArmedPeds: 13, InfectedPeds: 9, BonusBoxes: 3, Theme: ThemeParkCity}
-	case 7:
-		// Industrial zone — blocky warehouses, lots of cars, grim.
-		cfg = LevelConfig{Peds: 85, Cars: 22, ArmedPeds: 16, InfectedPeds: 11, BonusBoxes: 3, Theme: ThemeIndustrial}
-	case 8:
-		// Deep forest — brutal wilderness, dense trees, armed hunters.
-		cfg = LevelConfig{Peds: 90, Cars: 0, ArmedPeds: 22, InfectedPeds: 15, BonusBoxes: 3, Theme: ThemeForest}
-	case 9:
-		// Megacity — towering blocks, urban canyons, heavy traffic.
-		cfg = LevelConfig{Peds: 100, Cars: 28, ArmedPeds: 22, InfectedPeds: 18, BonusBoxes: 3, Theme: ThemeMegacity}
-	case 10:
-		// Park City under siege — open parks, maximum peds, chaos.
-		cfg = LevelConfig{Peds: 120, Cars: 26, ArmedPeds: 28, InfectedPeds: 22, BonusBoxes: 2, Theme: ThemeParkCity}
-	case 11:
-		// Arctic raid — cold open lanes with bundled survivors and patrol vehicles.
-		cfg = LevelConfig{Peds: 130, Cars: 18, ArmedPeds: 30, InfectedPeds: 20, BonusBoxes: 3, Theme: ThemeArctic}
-	case 12:
-		// Desert hunt — faster raiders on wide dusty blocks.
-		cfg = LevelConfig{Peds: 145, Cars: 22, ArmedPeds: 34, InfectedPeds: 20, BonusBoxes: 3, Theme: ThemeDesert}
-	case 13:
-		// Space outpost — no roads, tanky astronauts and hostile crews.
-		cfg = LevelConfig{Peds: 120, Cars: 0, ArmedPeds: 34, InfectedPeds: 24, BonusBoxes: 4, Theme: ThemeSpace}
-	case 14:
-		// Underwater zone — slow heavy divers, dense no-road pursuit.
-		cfg = LevelConfig{Peds: 115, Cars: 0, ArmedPeds: 28, InfectedPeds: 32, BonusBoxes: 4, Theme: ThemeUnderwater}
+// LevelObjective is one entry of a scaling objective cycle.
+type LevelObjective struct {
+	Win       string   `json:"win"`
+	WinTarget *float64 `json:"win_target,omitempty"`
+}
+
+// LevelPack is a campaign: hand-made levels plus optional scaling beyond.
+type LevelPack struct {
+	Name    string        `json:"name"`
+	Levels  []LevelDef    `json:"levels"`
+	Scaling *LevelScaling `json:"scaling,omitempty"`
+}
+
+//go:embed levels/default.json
+var defaultLevelPackJSON []byte
+
+// Levels is the active level pack. It starts as the embedded default pack.
+var Levels = mustParseLevelPack(defaultLevelPackJSON)
+
+// bonusKindKeys names every BonusKind for level pack files.
+var bonusKindKeys = [BonusKindCount]string{
+	BonusSpeed:             "speed",
+	BonusFire:              "fire",
+	BonusBash:              "bash",
+	BonusSurge:             "surge",
+	BonusTeleport:          "teleport",
+	BonusSwarm:             "swarm",
+	BonusAI:                "ai",
+	BonusNuke:              "nuke",
+	BonusSpread:            "spread",
+	BonusClone:             "clone",
+	BonusVacuum:            "vacuum",
+	BonusHealth:            "health",
+	BonusBerserk:           "berserk",
+	BonusFlamethrower:      "flamethrower",
+	BonusMissile:           "missile",
+	BonusGatling:           "gatling",
+	BonusBombSwarm:         "bomb_swarm",
+	BonusTargetNuke:        "target_nuke",
+	BonusTargetWorms:       "target_worms",
+	BonusTargetGunship:     "target_gunship",
+	BonusTargetHeliMissile: "target_heli_missile",
+	BonusTargetBombBelt:    "target_bomb_belt",
+	BonusTargetAirSupport:  "target_air_support",
+	BonusTargetPigs:        "target_pigs",
+	BonusTargetCars:        "target_cars",
+	BonusTargetSnakes:      "target_snakes",
+}
+
+func mustParseLevelPack(data []byte) *LevelPack {
+	pack, err := ParseLevelPack(data, "")
+	if err != nil {
+		panic(fmt.Errorf("embedded level pack: %w", err))
+	}
+	return pack
+}
+
+// ParseLevelPack decodes and validates a JSON level pack, loading its maps
+// from dir.
+func ParseLevelPack(data []byte, dir string) (*LevelPack, error) {
+	var pack LevelPack
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&pack); err != nil {
+		return nil, err
+	}
+	if len(pack.Levels) == 0 {
+		return nil, fmt.Errorf("level pack %q has no levels", pack.Name)
+	}
+	maps := make(map[[2]string]*WorldMap)
+	for i := range pack.Levels {
+		if err := pack.Levels[i].loadMap(dir, maps); err != nil {
+			return nil, fmt.Errorf("level %d: %w", i+1, err)
+		}
+		if _, err := pack.Levels[i].config(); err != nil {
+			return nil, fmt.Errorf("level %d: %w", i+1, err)
+		}
+	}
+	if pack.Scaling != nil {
+		if err := pack.Scaling.Base.loadMap(dir, maps); err != nil {
+			return nil, fmt.Errorf("scaling: %w", err)
+		}
+		if _, err := pack.Scaling.Base.config(); err != nil {
+			return nil, fmt.Errorf("scaling: %w", err)
+		}
+		if per := pack.Scaling.PerLevel; per.Peds < 0 || per.Cars < 0 || per.ArmedPeds < 0 || per.InfectedPeds < 0 || per.BonusBoxes < 0 {
+			return nil, fmt.Errorf("scaling: negative per_level count")
+		}
+		for i, obj := range pack.Scaling.Objectives {
+			def := pack.Scaling.Base
+			def.Win, def.WinTarget = obj.Win, obj.WinTarget
+			if _, err := def.config(); err != nil {
+				return nil, fmt.Errorf("scaling objective %d: %w", i+1, err)
+			}
+		}
+	}
+	return &pack, nil
+}
+
+// LoadLevelPack reads a level pack file and makes it the active pack.
+func LoadLevelPack(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	pack, err := ParseLevelPack(data, filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	Levels = pack
+	return nil
+}
+
+// loadMap reads the level's map images, sharing them through loaded between
+// levels that use the same ones.
+func (d *LevelDef) loadMap(dir string, loaded map[[2]string]*WorldMap) error {
+	if d.Map == "" {
+		return nil
+	}
+	key := [2]string{d.Map, d.HeightMap}
+	if m, ok := loaded[key]; ok {
+		d.worldMap = m
+		return nil
+	}
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	m, err := LoadWorldMap(resolve(d.Map), resolve(d.HeightMap))
+	if err != nil {
+		return err
+	}
+	loaded[key] = m
+	d.worldMap = m
+	return nil
+}
+
+// config resolves a definition into a LevelConfig.
+func (d *LevelDef) config() (LevelConfig, error) {
+	cfg := LevelConfig{
+		Peds:         d.Peds,
+		Cars:         d.Cars,
+		ArmedPeds:    d.ArmedPeds,
+		InfectedPeds: d.InfectedPeds,
+		BonusBoxes:   d.BonusBoxes,
+		Theme:        ThemeCity,
+		WantedMax:    WantedMax,
+	}
+	if d.Peds < 0 || d.Cars < 0 || d.ArmedPeds < 0 || d.InfectedPeds < 0 || d.BonusBoxes < 0 {
+		return cfg, fmt.Errorf("negative count")
+	}
+
+	if d.Theme != "" {
+		theme, _, ok := themeByName(d.Theme)
+		if !ok {
+			return cfg, fmt.Errorf("unknown theme %q", d.Theme)
+		}
+		cfg.Theme = theme
+		cfg.FixedTheme = true
+	}
+
+	switch strings.ToLower(d.Weather) {
+	case "":
+	case "none", "clear":
+		cfg.FixedWeather, cfg.Weather = true, WeatherNone
+	case "rain":
+		cfg.FixedWeather, cfg.Weather = true, WeatherRain
+	case "snow":
+		cfg.FixedWeather, cfg.Weather = true, WeatherSnow
 	default:
-		// Levels 15+ scale enemy/population pressure aggressively.
-		// Theme is randomized in StartLevel; this is only a fallback.
-		extra := level - 15
-		fallbackTheme := ThemeCity
-		if len(Themes) > 0 {
-			fallbackTheme = Themes[(level-1)%len(Themes)]
+		return cfg, fmt.Errorf("unknown weather %q", d.Weather)
+	}
+
+	if d.TimeOfDay != nil {
+		if *d.TimeOfDay < 0 || *d.TimeOfDay > 1 {
+			return cfg, fmt.Errorf("time_of_day %v outside 0..1", *d.TimeOfDay)
+		}
+		cfg.FixedTime, cfg.TimeOfDay = true, *d.TimeOfDay
+	}
+
+	if len(d.BonusWeights) > 0 {
+		cfg.BonusWeights = make([]int, BonusKindCount)
+		for i := range cfg.BonusWeights {
+			cfg.BonusWeights[i] = 1
+		}
+		total := 0
+		for name, w := range d.BonusWeights {
+			k := bonusKindByKey(name)
+			if k < 0 {
+				return cfg, fmt.Errorf("unknown bonus %q", name)
+			}
+			if w < 0 {
+				return cfg, fmt.Errorf("negative weight for bonus %q", name)
+			}
+			cfg.BonusWeights[k] = w
+		}
+		for _, w := range cfg.BonusWeights {
+			total += w
 		}
-		cfg = LevelConfig{
-			Peds:         160 + extra*20,
-			Cars:         34 + extra*4,
-			ArmedPeds:    36 + extra*5,
-			InfectedPeds: 30 + extra*4,
-			BonusBoxes:   2 + extra/2,
-			Theme:        fallbackTheme,
+		if total == 0 {
+			return cfg, fmt.Errorf("bonus_weights disable every bonus")
+		}
+	}
+
+	if d.WantedMax != nil {
+		cfg.WantedMax = clampF(*d.WantedMax, 0, WantedMax)
+	}
+
+	var err error
+	if cfg.WorldWidth, err = worldSizeField("world_width", d.WorldWidth); err != nil {
+		return cfg, err
+	}
+	if cfg.WorldHeight, err = worldSizeField("world_height", d.WorldHeight); err != nil {
+		return cfg, err
+	}
+	if d.Map != "" {
+		if d.WorldWidth != 0 || d.WorldHeight != 0 {
+			return cfg, fmt.Errorf("a level with a map takes its size from it, not world_width or world_height")
+		}
+		cfg.Map = d.worldMap
+	} else if d.HeightMap != "" {
+		return cfg, fmt.Errorf("height_map without map")
+	}
+
+	if d.Win != "" {
+		cfg.Win = objectiveKindByKey(d.Win)
+		if cfg.Win < 0 {
+			return cfg, fmt.Errorf("unknown win condition %q", d.Win)
+		}
+	}
+	cfg.WinTarget = objectiveDefaultTarget[cfg.Win]
+	if d.WinTarget != nil {
+		if *d.WinTarget <= 0 {
+			return cfg, fmt.Errorf("win_target must be positive")
 		}
+		cfg.WinTarget = *d.WinTarget
+	}
+	if cfg.Win == ObjectiveContain && cfg.WinTarget > 1 {
+		return cfg, fmt.Errorf("win_target for %q is a share and must be at most 1", d.Win)
+	}
+	// Tanks, military helicopters and escapes all need the top wanted level.
+	switch cfg.Win {
+	case ObjectiveKillTank, ObjectiveKillHeli, ObjectiveEscape:
+		if cfg.WantedMax < WantedMax {
+			return cfg, fmt.Errorf("win condition %q needs wanted_max %v", d.Win, WantedMax)
+		}
+	}
+	return cfg, nil
+}
+
+// worldSizeField validates a world_width or world_height and rounds it up
+// to whole city blocks, which the road grid and perimeter ring need. 0 keeps
+// the default.
+func worldSizeField(name string, v int) (int, error) {
+	if v == 0 {
+		return 0, nil
+	}
+	if v < MinWorldSize || v > MaxWorldSize {
+		return 0, fmt.Errorf("%s %d outside %d..%d", name, v, MinWorldSize, MaxWorldSize)
+	}
+	return (v + Pattern - 1) / Pattern * Pattern, nil
+}
+
+func bonusKindByKey(key string) BonusKind {
+	for k, name := range bonusKindKeys {
+		if name == key {
+			return BonusKind(k)
+		}
+	}
+	return -1
+}
+
+// themeByName finds a theme by family name (case-insensitive).
+func themeByName(name string) (ThemeConfig, int, bool) {
+	for i, t := range Themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, i, true
+		}
+	}
+	return ThemeConfig{}, -1, false
+}
+
+// GetLevelConfig returns settings for a given level from the active pack.
+// Levels past the end of the pack use its scaling rule, or repeat the last
+// level when the pack has none.
+func GetLevelConfig(level int) (LevelConfig, error) {
+	return Levels.Config(level)
+}
+
+// Config returns settings for a given level of this pack.
+func (p *LevelPack) Config(level int) (LevelConfig, error) {
+	level = max(level, 1)
+	var def LevelDef
+	switch {
+	case level <= len(p.Levels):
+		def = p.Levels[level-1]
+	case p.Scaling != nil:
+		extra := float64(level - len(p.Levels) - 1)
+		def = p.Scaling.Base
+		per := &p.Scaling.PerLevel
+		def.Peds += int(per.Peds * extra)
+		def.Cars += int(per.Cars * extra)
+		def.ArmedPeds += int(per.ArmedPeds * extra)
+		def.InfectedPeds += int(per.InfectedPeds * extra)
+		def.BonusBoxes += int(per.BonusBoxes * extra)
+		if objs := p.Scaling.Objectives; len(objs) > 0 {
+			obj := objs[int(extra)%len(objs)]
+			def.Win, def.WinTarget = obj.Win, obj.WinTarget
+		}
+	default:
+		def = p.Levels[len(p.Levels)-1]
+	}
+	cfg, err := def.config()
+	if err != nil {
+		return cfg, fmt.Errorf("level %d: %w", level, err)
 	}
 
 	// Slightly denser population across all levels.
 	cfg.Peds += max(3, cfg.Peds/8)
 
-	return cfg
+	return cfg, nil
 }
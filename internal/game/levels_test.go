@@ -0,0 +1,151 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLevelPack(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		json  string
+		want  string // error text; empty for a pack that parses
+		check func(t *testing.T, p *LevelPack)
+	}{
+		{"defaults", `{"name":"P","levels":[{"peds":10,"cars":2}]}`, "", func(t *testing.T, p *LevelPack) {
+			cfg := levelConfig(t, p, 1)
+			if cfg.Theme.Name != ThemeCity.Name || cfg.FixedTheme || cfg.FixedWeather || cfg.FixedTime {
+				t.Errorf("unset fields were fixed: %+v", cfg)
+			}
+			if cfg.Win != ObjectiveEatAll || cfg.WantedMax != WantedMax || cfg.BonusWeights != nil {
+				t.Errorf("defaults %v, %v, %v", cfg.Win, cfg.WantedMax, cfg.BonusWeights)
+			}
+			if w, h := cfg.worldSize(); w != DefaultWorldWidth || h != DefaultWorldHeight {
+				t.Errorf("world %dx%d, want the default", w, h)
+			}
+		}},
+		{"overrides", `{"levels":[{"theme":"forest","weather":"Snow","time_of_day":0.75,"wanted_max":9,
+			"bonus_weights":{"nuke":0,"fire":5},"win":"survive","win_target":30,"world_width":300,"world_height":400}]}`, "", func(t *testing.T, p *LevelPack) {
+			cfg := levelConfig(t, p, 1)
+			if cfg.Theme.Name != "Forest" || !cfg.FixedTheme {
+				t.Errorf("theme %q fixed %v", cfg.Theme.Name, cfg.FixedTheme)
+			}
+			if !cfg.FixedWeather || cfg.Weather != WeatherSnow || !cfg.FixedTime || cfg.TimeOfDay != 0.75 {
+				t.Errorf("weather %v/%v time %v/%v", cfg.FixedWeather, cfg.Weather, cfg.FixedTime, cfg.TimeOfDay)
+			}
+			if cfg.WantedMax != WantedMax {
+				t.Errorf("wanted_max %v not clamped to %v", cfg.WantedMax, WantedMax)
+			}
+			if cfg.BonusWeights[BonusNuke] != 0 || cfg.BonusWeights[BonusFire] != 5 || cfg.BonusWeights[BonusSpeed] != 1 {
+				t.Errorf("bonus weights %v", cfg.BonusWeights)
+			}
+			if cfg.Win != ObjectiveSurvive || cfg.WinTarget != 30 {
+				t.Errorf("win %v target %v", cfg.Win, cfg.WinTarget)
+			}
+			if cfg.WorldWidth%Pattern != 0 || cfg.WorldWidth < 300 || cfg.WorldHeight%Pattern != 0 || cfg.WorldHeight < 400 {
+				t.Errorf("world %dx%d not rounded up to whole blocks", cfg.WorldWidth, cfg.WorldHeight)
+			}
+		}},
+		{"default target", `{"levels":[{"win":"contain_outbreak"}]}`, "", func(t *testing.T, p *LevelPack) {
+			if cfg := levelConfig(t, p, 1); cfg.WinTarget != objectiveDefaultTarget[ObjectiveContain] {
+				t.Errorf("target %v", cfg.WinTarget)
+			}
+		}},
+		{"past the end", `{"levels":[{"peds":10},{"peds":20,"cars":3}]}`, "", func(t *testing.T, p *LevelPack) {
+			if a, b := levelConfig(t, p, 2), levelConfig(t, p, 7); a.Peds != b.Peds || a.Cars != b.Cars {
+				t.Errorf("level 7 %d peds %d cars, want level 2's %d and %d", b.Peds, b.Cars, a.Peds, a.Cars)
+			}
+			if a, b := levelConfig(t, p, -3), levelConfig(t, p, 1); a.Peds != b.Peds {
+				t.Errorf("level -3 is not level 1")
+			}
+		}},
+		{"scaling", `{"levels":[{"peds":10}],"scaling":{"base":{"peds":40,"cars":4},"per_level":{"peds":5,"cars":0.5},
+			"objectives":[{"win":"score","win_target":900},{"win":"survive"}]}}`, "", func(t *testing.T, p *LevelPack) {
+			l2, l4, l5 := levelConfig(t, p, 2), levelConfig(t, p, 4), levelConfig(t, p, 5)
+			if l4.Peds-l2.Peds < 10 || l4.Cars != 5 {
+				t.Errorf("level 4 %d peds %d cars from level 2's %d", l4.Peds, l4.Cars, l2.Peds)
+			}
+			if l2.Win != ObjectiveScore || l2.WinTarget != 900 || l5.Win != ObjectiveSurvive || levelConfig(t, p, 6).Win != ObjectiveScore {
+				t.Errorf("objectives cycle %v, %v, %v", l2.Win, l5.Win, levelConfig(t, p, 6).Win)
+			}
+		}},
+
+		{"not json", `{"levels":[`, "unexpected EOF", nil},
+		{"unknown field", `{"levels":[{"pedz":1}]}`, "unknown field", nil},
+		{"no levels", `{"name":"Empty","levels":[]}`, `"Empty" has no levels`, nil},
+		{"negative count", `{"levels":[{"peds":5},{"cars":-1}]}`, "level 2: negative count", nil},
+		{"unknown theme", `{"levels":[{"theme":"Mars"}]}`, `unknown theme "Mars"`, nil},
+		{"unknown weather", `{"levels":[{"weather":"hail"}]}`, `unknown weather "hail"`, nil},
+		{"time of day", `{"levels":[{"time_of_day":1.5}]}`, "time_of_day 1.5 outside", nil},
+		{"unknown bonus", `{"levels":[{"bonus_weights":{"laser":2}}]}`, `unknown bonus "laser"`, nil},
+		{"negative weight", `{"levels":[{"bonus_weights":{"nuke":-1}}]}`, `negative weight for bonus "nuke"`, nil},
+		{"no bonuses", `{"levels":[{"bonus_weights":{` + allBonusesOff() + `}}]}`, "disable every bonus", nil},
+		{"world too small", `{"levels":[{"world_width":10}]}`, "world_width 10 outside", nil},
+		{"world too big", `{"levels":[{"world_height":100000}]}`, "world_height 100000 outside", nil},
+		{"height map alone", `{"levels":[{"height_map":"h.png"}]}`, "height_map without map", nil},
+		{"unknown win", `{"levels":[{"win":"eat_everything"}]}`, `unknown win condition "eat_everything"`, nil},
+		{"zero target", `{"levels":[{"win":"score","win_target":0}]}`, "win_target must be positive", nil},
+		{"share over one", `{"levels":[{"win":"contain_outbreak","win_target":2}]}`, "must be at most 1", nil},
+		{"tank without wanted", `{"levels":[{"win":"kill_tank","wanted_max":3}]}`, "needs wanted_max", nil},
+		{"bad scaling", `{"levels":[{}],"scaling":{"base":{"theme":"Mars"}}}`, "scaling: unknown theme", nil},
+		{"negative scaling", `{"levels":[{}],"scaling":{"base":{"peds":40},"per_level":{"peds":5,"cars":-0.5}}}`, "scaling: negative per_level count", nil},
+		{"bad scaling objective", `{"levels":[{}],"scaling":{"base":{},"objectives":[{"win":"survive"},{"win":"fly"}]}}`, "scaling objective 2", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseLevelPack([]byte(tc.json), t.TempDir())
+			if tc.want != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.want) {
+					t.Fatalf("error %v, want one mentioning %q", err, tc.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, p)
+		})
+	}
+}
+
+// levelConfig is p.Config(level), which must resolve.
+func levelConfig(t *testing.T, p *LevelPack, level int) LevelConfig {
+	t.Helper()
+	cfg, err := p.Config(level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// TestLevelPackConfigErrors checks Config reports a definition that does not
+// resolve rather than handing back a half-made level. ParseLevelPack rejects
+// such packs, so these are built directly.
+func TestLevelPackConfigErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		pack  LevelPack
+		level int
+		want  string
+	}{
+		{"bad level", LevelPack{Levels: []LevelDef{{}, {Theme: "Mars"}}}, 2, `level 2: unknown theme "Mars"`},
+		{"scaled below zero", LevelPack{Levels: []LevelDef{{}}, Scaling: &LevelScaling{Base: LevelDef{Cars: 2}}}, 5, "level 5: negative count"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if sc := tc.pack.Scaling; sc != nil {
+				sc.PerLevel.Cars = -1
+			}
+			if _, err := tc.pack.Config(tc.level); err == nil || err.Error() != tc.want {
+				t.Errorf("error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+// allBonusesOff is a bonus_weights body setting every bonus to 0.
+func allBonusesOff() string {
+	keys := make([]string, 0, len(bonusKindKeys))
+	for _, k := range bonusKindKeys {
+		keys = append(keys, `"`+k+`":0`)
+	}
+	return strings.Join(keys, ",")
+}
@@ -1,4 +1,4 @@
-//go:build !android
+//go:build !android && !headless
 
 package game
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"time"
@@ -59,20 +60,79 @@ func RunDesktop() {
 		1.0,
 	)
 
-	// World generation — random themed variant from the start.
-	world := NewWorld(seed)
-	startTheme, _ := PickLevelTheme(seed, 1, 0, -1)
-	world.Theme = startTheme
-	world.GenerateAll()
-	world.BuildSpatialIndex()
-	for cy := 0; cy <= world.maxCy; cy++ {
-		for cx := 0; cx <= world.maxCx; cx++ {
-			c := world.GetChunk(cx, cy)
-			if c != nil {
-				c.RecomputeShadows(world)
+	// SNAKE_LEVELS=<file> replaces the built-in campaign with a level pack.
+	if path := os.Getenv("SNAKE_LEVELS"); path != "" {
+		if err := LoadLevelPack(path); err != nil {
+			panic(fmt.Errorf("levels: %w", err))
+		}
+	}
+
+	// Simulation: world generation and all gameplay systems.
+	// SNAKE_REPLAY=<file> plays back a recorded level attempt instead.
+	sim := NewSimulation(seed)
+	var player *ReplayPlayer
+	if path := os.Getenv("SNAKE_REPLAY"); path != "" {
+		rep, err := LoadReplayFile(path)
+		if err != nil {
+			panic(fmt.Errorf("replay: %w", err))
+		}
+		sim = NewReplaySimulation(rep)
+		player = &ReplayPlayer{Replay: rep}
+	}
+	// SNAKE_RECORD=<dir> writes every level attempt as a replay file.
+	recordDir := ""
+	if player == nil {
+		recordDir = os.Getenv("SNAKE_RECORD")
+	}
+	recorded := 0
+	savePath := SavePath()
+
+	// Campaign profile: unlocked start levels, bests and kill totals.
+	// Replay playback never touches it.
+	profilePath := ""
+	if player == nil {
+		profilePath = ProfilePath()
+	}
+	profile, err := LoadProfile(profilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "profile load failed (starting fresh): %v\n", err)
+	}
+	saveProfile := func() {
+		if err := profile.Save(profilePath); err != nil {
+			fmt.Fprintf(os.Stderr, "profile save failed: %v\n", err)
+		}
+	}
+	attach := func(sim *Simulation) {
+		if recordDir != "" {
+			sim.RecordReplay = true
+			sim.ReplayDone = func(rep *Replay) {
+				recorded++
+				name := fmt.Sprintf("snake-%d-level%d-%03d.snkr", rep.Header.Seed, rep.Header.Level, recorded)
+				if err := SaveReplayFile(filepath.Join(recordDir, name), rep); err != nil {
+					fmt.Fprintf(os.Stderr, "replay save failed: %v\n", err)
+				}
 			}
 		}
+		if player != nil {
+			return
+		}
+		profile.Subscribe(sim.Events, sim.Session)
+		// A finished level can't be continued; drop its save.
+		levelEnded := func(Event) {
+			if savePath != "" {
+				os.Remove(savePath)
+			}
+			saveProfile()
+		}
+		sim.Events.Subscribe(EventLevelComplete, levelEnded)
+		sim.Events.Subscribe(EventLevelFailed, levelEnded)
+		sim.Session.CanContinue = HasSave(savePath)
+		sim.Session.MaxStartLevel = profile.HighestLevel
+		sim.Session.DailySeed = DailySeed(time.Now())
 	}
+	attach(sim)
+	// Input edges (SPACE, clicks) are held until a tick consumes them.
+	var pending InputFrame
 
 	// Renderer.
 	rend, err := NewRenderer()
@@ -85,41 +145,22 @@ func RunDesktop() {
 		panic(fmt.Errorf("font: %w", err))
 	}
 
-	// Systems.
-	peds := NewPedestrianSystem(400, seed^0xFED)
-	traffic := NewTrafficSystem(seed ^ 0xCAFE)
-	particles := NewParticleSystem(MaxParticles, seed^0xBEAD)
-	weather := NewWeatherSystem(seed ^ 0x57A7)
-	bonuses := NewBonusSystem(seed^0xB0B, 5)
-	cops := NewCopSystem(seed ^ 0xC095)
-	mil := NewMilitarySystem(seed ^ 0xA7A1)
-
-	// Game session.
-	session := NewGameSession()
-	_ = NewEventBus()
-
-	// Snake (nil until game starts).
-	var snake *Snake
-
-	cam := Camera{
-		X:    float64(WorldWidth) / 2,
-		Y:    float64(WorldHeight) / 2,
-		Zoom: DefaultZoom,
-	}
 	input := NewInput()
 
 	// Reusable render buffers.
 	var glowBuf, normBuf []float32
 	var carShadowBuf, carHeadBuf []float32
+	var minimap Minimap
+	var minimapBuf []float32
+	var roofs Roofs
+	var roofBuf []float32
+	var trafficLightBuf []float32
 
 	last := glfw.GetTime()
 	for !window.ShouldClose() {
 		now := glfw.GetTime()
 		dt := now - last
 		last = now
-		if dt > 0.1 {
-			dt = 0.1
-		}
 
 		glfw.PollEvents()
 		if window.GetKey(glfw.KeyEscape) == glfw.Press {
@@ -132,140 +173,65 @@ func RunDesktop() {
 			continue
 		}
 
-		// State transitions.
-		switch session.State {
-		case StateMenu:
-			if input.JustPressed(window, glfw.KeySpace) {
+		// C on the menu resumes the level saved when the game was last closed.
+		if sim.Session.State == StateMenu && sim.Session.CanContinue && input.JustPressed(window, glfw.KeyC) {
+			if loaded, err := LoadGameFile(savePath); err != nil {
+				fmt.Fprintf(os.Stderr, "continue failed: %v\n", err)
+				sim.Session.CanContinue = false
+			} else {
 				PlaySound(SoundMenuSelect)
-				StartLevelMusic(1)
-				session.StartLevel(1, world, peds, traffic, bonuses, cops, mil, &snake, particles, seed)
-				weather.Configure(session.Weather, session.WeatherSeed)
+				sim = loaded
+				attach(sim)
+				pending = InputFrame{}
 			}
+		}
 
-		case StatePlaying:
-			targetSelecting := false
-			if snake != nil && snake.Alive && snake.TargetNukeTimer > 0 {
-				targetSelecting = true
-				snake.TargetNukeTimer -= dt
-				rem := snake.TargetNukeTimer
-				if rem < 0 {
-					rem = 0
-				}
-				snake.PowerupTimer = 1.1
-				snake.PowerupMsg, snake.PowerupCol = snake.TargetingPrompt(rem)
-
-				if input.JustClicked(window, glfw.MouseButtonLeft) {
-					targetCam := cam
-					tx, ty := cam.EffectivePos()
-					targetCam.X = tx
-					targetCam.Y = ty
-					mx, my := CursorWorldPos(window, targetCam, fbW, fbH)
-					wx := clamp(int(math.Round(mx)), 0, WorldWidth-1)
-					wy := clamp(int(math.Round(my)), 0, WorldHeight-1)
-					snake.ActivateTargetAbilityAt(wx, wy, world, peds, traffic, particles, &cam, cops, mil)
-				} else if snake.TargetNukeTimer <= 0 {
-					snake.TargetNukeTimer = 0
-					snake.PowerupMsg, snake.PowerupCol = snake.TargetingLockLostPrompt()
-					snake.PowerupTimer = 1.6
-				}
+		// UP/DOWN on the menu pick the game mode, LEFT/RIGHT the level a
+		// new campaign run starts at.
+		if sim.Session.State == StateMenu {
+			if input.JustPressed(window, glfw.KeyUp) {
+				sim.Session.CycleMode(-1)
 			}
-
-			if !targetSelecting {
-				// Steer snake (player input only when AI mode is not active).
-				if snake != nil && snake.Alive {
-					if snake.AITimer <= 0 {
-						steer, idle := SnakeSteerTarget(window, input, snake, cam, fbW, fbH)
-						hx, hy := snake.Head()
-						// Auto-steer toward nearby bonus boxes.
-						if bonuses != nil {
-							bestDist := 5.0 // attraction range
-							for i := range bonuses.Boxes {
-								b := &bonuses.Boxes[i]
-								if !b.Alive {
-									continue
-								}
-								d := math.Hypot(b.X-hx, b.Y-hy)
-								if d < bestDist {
-									bestDist = d
-									steer = math.Atan2(b.Y-hy, b.X-hx)
-									idle = false
-								}
-							}
-						}
-						// Bounce override: while escaping a wall, hold the escape
-						// heading so Steer() can't immediately re-enter the obstacle.
-						if snake.BounceTimer > 0 {
-							steer = snake.BounceDir
-							idle = false
-						} else if !idle && world != nil {
-							// Proactive wall avoidance: look ahead and steer around
-							// buildings before the snake reaches them.
-							steer = WallAvoidAngle(hx, hy, steer, world)
-						}
-						snake.Idle = idle
-						if !idle {
-							snake.Steer(steer, dt)
-						}
-					} else {
-						snake.Idle = false
-					}
-					// Flamethrower is automatic, no input needed.
-					snake.Update(dt, world, peds, traffic, bonuses, particles, &cam, cops, mil)
-				}
-
-				// Update systems.
-				session.Update(dt)
-				cam.UpdateShake(dt, seed^uint64(now*1000))
-				world.Update(dt)
-				UpdateBurnVisuals(world, particles, dt)
-				peds.Update(dt, world, snake, particles)
-				sunAmbNow, _, _, _ := SunCycleLight(session.LevelTimer)
-				traffic.NightFactor = NightIntensityFromAmbient(sunAmbNow)
-				traffic.Update(dt, world, particles, peds, &cam)
-				weather.UpdateAndSpawn(particles, dt)
-				particles.UpdateWithShockwaveDamage(dt, world, peds, cops, mil)
-				snakeHP := 1.0
-				if snake != nil {
-					snakeHP = snake.HP.Fraction()
-				}
-				bonuses.Update(dt, peds.AliveCount(), snakeHP)
-				bonuses.SpawnSparks(particles, dt)
-
-				cops.Update(dt, snake, world, particles, &cam, now)
-				mil.Update(dt, snake, world, particles, &cam, now)
-
-				// Cleanup dead entities.
-				peds.RemoveDead()
-				traffic.RemoveDead()
-				cops.RemoveDead()
-				mil.RemoveDead()
-
-				session.CheckLevelEnd(peds, snake)
+			if input.JustPressed(window, glfw.KeyDown) {
+				sim.Session.CycleMode(1)
 			}
-
-		case StateLevelComplete:
-			if input.JustPressed(window, glfw.KeySpace) {
-				nextLevel := session.CurrentLevel + 1
-				StartLevelMusic(nextLevel)
-				session.StartLevel(nextLevel, world, peds, traffic, bonuses, cops, mil, &snake, particles, seed)
-				weather.Configure(session.Weather, session.WeatherSeed)
+			if input.JustPressed(window, glfw.KeyLeft) {
+				sim.Session.CycleMenuLevel(-1)
 			}
-			particles.Update(dt, world)
-
-		case StateLevelFailed:
-			if input.JustPressed(window, glfw.KeySpace) {
-				StartLevelMusic(session.CurrentLevel)
-				session.StartLevel(session.CurrentLevel, world, peds, traffic, bonuses, cops, mil, &snake, particles, seed)
-				weather.Configure(session.Weather, session.WeatherSeed)
+			if input.JustPressed(window, glfw.KeyRight) {
+				sim.Session.CycleMenuLevel(1)
 			}
-			particles.Update(dt, world)
 		}
 
-		// Always fit the full world on screen.
-		UpdateAutoCamera(&cam, snake, dt, fbW, fbH)
+		in := desktopInputFrame(window, input, sim, fbW, fbH)
+		in.Advance = in.Advance || pending.Advance
+		if pending.Click && !in.Click {
+			in.Click, in.ClickX, in.ClickY = true, pending.ClickX, pending.ClickY
+		}
+		pending = in
+		sim.Advance(dt, func() (InputFrame, bool) {
+			if player != nil {
+				// A finished replay returns false and holds the final frame.
+				return player.Next()
+			}
+			tick := pending
+			pending.Advance, pending.Click = false, false
+			return tick, true
+		})
+		session := sim.Session
+		world := sim.World
+		snake := sim.Snake
+		peds, traffic, cops, mil, bonuses, particles := sim.Peds, sim.Traffic, sim.Cops, sim.Mil, sim.Bonuses, sim.Particles
+		cam := &sim.Cam
+
+		// Draw moving entities between the last two ticks.
+		sim.BeginInterpolatedRender()
+
+		// Follow the (interpolated) heads; default worlds fit the screen.
+		UpdateAutoCamera(cam, world, sim.Snakes, dt, fbW, fbH)
 
 		// Render with shake applied.
-		renderCam := cam
+		renderCam := *cam
 		sx, sy := cam.EffectivePos()
 		renderCam.X = sx
 		renderCam.Y = sy
@@ -304,23 +270,26 @@ func RunDesktop() {
 			rend.DrawGlowSprites(milGlow, renderCam, fbW, fbH)
 		}
 
-		// Draw snake body as point sprites, plus bomb/vacuum glow effects.
-		if snake != nil && snake.Alive {
-			snakeBuf := snake.SnakeRenderData()
+		// Draw snake bodies as point sprites, plus bomb/vacuum glow effects.
+		for _, s := range sim.Snakes {
+			if !s.Alive {
+				continue
+			}
+			snakeBuf := s.SnakeRenderData()
 			if len(snakeBuf) > 0 {
 				rend.SetSpriteAmbient(1.0, 1.0, 1.0, 1.0)
 				rend.DrawSprites(snakeBuf, renderCam, fbW, fbH, false)
 				rend.SetSpriteAmbient(sunAmb, sunTR, sunTG, sunTB)
 			}
-			if glowBuf := snake.GlowData(); len(glowBuf) > 0 {
+			if glowBuf := s.GlowData(); len(glowBuf) > 0 {
 				rend.DrawGlowSprites(glowBuf, renderCam, fbW, fbH)
 			}
 		}
 		// Tactical nuke targeting marker under cursor while time remains.
 		if snake != nil && snake.Alive && snake.TargetNukeTimer > 0 {
 			mx, my := CursorWorldPos(window, renderCam, fbW, fbH)
-			mx = clampF(mx, 0, float64(WorldWidth-1))
-			my = clampF(my, 0, float64(WorldHeight-1))
+			mx = clampF(mx, 0, float64(world.Width-1))
+			my = clampF(my, 0, float64(world.Height-1))
 			pulse := float32(1.0 + 0.15*math.Sin(now*9.0))
 			outerR, outerG, outerB := float32(0.95), float32(0.22), float32(0.08)
 			innerR, innerG, innerB := float32(1.0), float32(0.92), float32(0.25)
@@ -365,11 +334,22 @@ func RunDesktop() {
 			rend.DrawGlowSprites(bonuses.GlowData(), renderCam, fbW, fbH)
 		}
 
+		// Roofs over building interiors, cut away while a snake is inside.
+		roofs.Update(world, renderCam.View(fbW, fbH), sim.Snakes, dt)
+		roofBuf = roofs.Sprites(roofBuf[:0])
+		rend.DrawSprites(roofBuf, renderCam, fbW, fbH, false)
+
+		// Traffic lights at the signalled junctions.
+		trafficLightBuf = TrafficLightSprites(world, renderCam.View(fbW, fbH), trafficLightBuf[:0])
+		if len(trafficLightBuf) > 0 {
+			rend.DrawGlowSprites(trafficLightBuf, renderCam, fbW, fbH)
+		}
+
 		// Streetlights + car headlights: additive radial glow during dusk/night.
 		lightBrightness := NightIntensityFromAmbient(sunAmb)
 		if lightBrightness > 0.01 {
 			if !world.Theme.NoRoads {
-				rend.DrawGlowSprites(streetlightSprites(lightBrightness), renderCam, fbW, fbH)
+				rend.DrawGlowSprites(streetlightSprites(world, lightBrightness), renderCam, fbW, fbH)
 			}
 			carHeadBuf = CarHeadlightSprites(traffic, lightBrightness, carHeadBuf)
 			if len(carHeadBuf) > 0 {
@@ -389,31 +369,80 @@ func RunDesktop() {
 		}
 
 		// HUD uses stable camera (no shake).
-		RenderHUD(rend, session, peds, snake, fbW, fbH)
+		if session.State == StatePlaying && !cam.ShowsWorld(world, fbW, fbH) {
+			minimap.Update(world, dt)
+			minimapBuf = RenderMinimap(rend, &minimap, *cam, &session.Objective, sim.Snakes, minimapBuf, fbW, fbH)
+		}
+		RenderHUD(rend, session, profile, peds, sim.Snakes, fbW, fbH)
+		sim.EndInterpolatedRender()
 
 		rend.RestoreChunkProgram()
 		window.SwapBuffers()
 	}
+
+	// Keep the attempt in progress when quitting mid-level while recording.
+	if sim.Replay != nil && sim.ReplayDone != nil {
+		sim.ReplayDone(sim.Replay)
+	}
+	// Quitting mid-level saves it for Continue on the next launch.
+	if player == nil && savePath != "" && sim.Session.State == StatePlaying {
+		if err := SaveGameFile(savePath, sim); err != nil {
+			fmt.Fprintf(os.Stderr, "save failed: %v\n", err)
+		}
+	}
+	if player == nil {
+		saveProfile()
+	}
+}
+
+// desktopInputFrame samples keyboard and mouse into a simulation input frame.
+func desktopInputFrame(window *glfw.Window, input *Input, sim *Simulation, fbW, fbH int) InputFrame {
+	var in InputFrame
+	in.Advance = input.JustPressed(window, glfw.KeySpace)
+
+	if sim.TargetSelecting() {
+		if input.JustClicked(window, glfw.MouseButtonLeft) {
+			targetCam := sim.Cam
+			targetCam.X, targetCam.Y = sim.Cam.EffectivePos()
+			mx, my := CursorWorldPos(window, targetCam, fbW, fbH)
+			in.Click = true
+			in.ClickX = int(math.Round(mx))
+			in.ClickY = int(math.Round(my))
+		}
+		return in
+	}
+
+	versus := len(sim.Snakes) > 1
+	if sim.Snake != nil && sim.Snake.Alive && sim.Snake.AITimer <= 0 {
+		in.Steer, in.Idle = SnakeSteerTarget(window, input, sim.Snake, sim.Cam, fbW, fbH, !versus)
+	}
+	if versus {
+		rival := sim.Snakes[1]
+		in.Rivals[0].Steer, in.Rivals[0].Idle = KeyboardSteerTarget(window, rival)
+	}
+	return in
 }
 
 // streetlightCache avoids rebuilding the streetlight sprite buffer every frame.
 // Brightness changes gradually; we quantize to 1/200 steps (~0.5% granularity).
 var streetlightCache struct {
-	brightness float32
-	buf        []float32
+	brightness    float32
+	width, height int
+	buf           []float32
 }
 
 // streetlightSprites returns radial glow sprites for road intersection lights.
 // RGB is pre-multiplied by brightness for additive blending via DrawGlowSprites.
-func streetlightSprites(brightness float32) []float32 {
+func streetlightSprites(w *World, brightness float32) []float32 {
 	// Quantize to avoid rebuilding on every tiny floating-point change.
 	q := float32(int(brightness*200)) / 200.0
-	if streetlightCache.buf != nil && streetlightCache.brightness == q {
+	if streetlightCache.buf != nil && streetlightCache.brightness == q &&
+		streetlightCache.width == w.Width && streetlightCache.height == w.Height {
 		return streetlightCache.buf
 	}
 	buf := make([]float32, 0, 128)
-	for y := 0; y+RoadWidth < WorldHeight; y += Pattern {
-		for x := 0; x+RoadWidth < WorldWidth; x += Pattern {
+	for y := 0; y+RoadWidth < w.Height; y += Pattern {
+		for x := 0; x+RoadWidth < w.Width; x += Pattern {
 			fx := float32(x + RoadWidth)
 			fy := float32(y + RoadWidth)
 			// Outer warm halo: large radius, soft orange-yellow, pre-multiplied.
@@ -423,6 +452,7 @@ func streetlightSprites(brightness float32) []float32 {
 		}
 	}
 	streetlightCache.brightness = q
+	streetlightCache.width, streetlightCache.height = w.Width, w.Height
 	streetlightCache.buf = buf
 	return buf
 }
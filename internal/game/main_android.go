@@ -8,7 +8,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
-	"strings"
+	"os"
+	"path/filepath"
 	"time"
 
 	"golang.org/x/mobile/app"
@@ -22,33 +23,34 @@ import (
 //go:embed font_alt.png
 var fontPNGMobile []byte
 
+// localVersus is off: touch steering has no second player.
+const localVersus = false
+
 type mobileGame struct {
-	seed uint64
-
-	world     *World
-	peds      *PedestrianSystem
-	traffic   *TrafficSystem
-	particles *ParticleSystem
-	weather   *WeatherSystem
-	bonuses   *BonusSystem
-	cops      *CopSystem
-	mil       *MilitarySystem
-	session   *GameSession
-	snake     *Snake
-	cam       Camera
-
-	now       float64
+	seed     uint64
+	sim      *Simulation
+	savePath string // in-progress level save; "" when storage is unavailable
+	profile  *Profile
+	profPath string
+
 	stateTime float64
 
 	// touch state
-	activeTouch touch.Sequence
-	touchDown   bool
-	moveTargets []moveTarget
-	lastTouchX  float32
-	lastTouchY  float32
-
-	// software frame buffer (world pixel space)
-	frame []byte
+	activeTouch   touch.Sequence
+	touchDown     bool
+	moveTargets   []moveTarget
+	lastTouchX    float32
+	lastTouchY    float32
+	pendingStart  bool // tap on a menu/end screen waiting for the next step
+	pendingClick  bool // targeting tap waiting for the next step
+	pendingResume bool // "continue" tap on the menu waiting for the next step
+	clickX        int
+	clickY        int
+
+	// software frame buffer: the world pixels under the view, with its
+	// top-left corner at world pixel frameX, frameY
+	frame                          []byte
+	frameX, frameY, frameW, frameH int
 
 	// reusable sprite buffers
 	pedBuf        []float32
@@ -58,6 +60,13 @@ type mobileGame struct {
 	carHeadBuf    []float32
 	targetGlowBuf []float32
 	snakeLitBuf   []float32
+	minimapBuf    []float32
+
+	minimap Minimap
+	roofs   Roofs
+	roofBuf []float32
+
+	trafficLightBuf []float32
 
 	// GL blit resources
 	prog     gl.Program
@@ -66,6 +75,8 @@ type mobileGame struct {
 	aPos     gl.Attrib
 	aUV      gl.Attrib
 	uTex     gl.Uniform
+	texW     int // size the blit texture was allocated at
+	texH     int
 	glReady  bool
 	fbWidth  int
 	fbHeight int
@@ -176,12 +187,12 @@ const (
 	moveWaypointMaxTTL  = 36.0
 )
 
-func gridIdx(x, y int) int {
-	return y*WorldWidth + x
+func gridIdx(w *World, x, y int) int {
+	return y*w.Width + x
 }
 
-func idxToGrid(idx int) (int, int) {
-	return idx % WorldWidth, idx / WorldWidth
+func idxToGrid(w *World, idx int) (int, int) {
+	return idx % w.Width, idx / w.Width
 }
 
 func absInt(v int) int {
@@ -207,7 +218,7 @@ func walkableForSnakePath(w *World, x, y int) bool {
 	const clearance = 1
 	for yy := y - clearance; yy <= y+clearance; yy++ {
 		for xx := x - clearance; xx <= x+clearance; xx++ {
-			if xx < 0 || yy < 0 || xx >= WorldWidth || yy >= WorldHeight {
+			if xx < 0 || yy < 0 || xx >= w.Width || yy >= w.Height {
 				return false
 			}
 			if w.HeightAt(xx, yy) > 0 {
@@ -222,16 +233,16 @@ func nearestWalkableForSnakePath(w *World, x, y, maxRadius int) (int, int, bool)
 	if w == nil {
 		return 0, 0, false
 	}
-	x = clamp(x, 0, WorldWidth-1)
-	y = clamp(y, 0, WorldHeight-1)
+	x = clamp(x, 0, w.Width-1)
+	y = clamp(y, 0, w.Height-1)
 	if walkableForSnakePath(w, x, y) {
 		return x, y, true
 	}
 	for r := 1; r <= maxRadius; r++ {
-		minX := clamp(x-r, 0, WorldWidth-1)
-		maxX := clamp(x+r, 0, WorldWidth-1)
-		minY := clamp(y-r, 0, WorldHeight-1)
-		maxY := clamp(y+r, 0, WorldHeight-1)
+		minX := clamp(x-r, 0, w.Width-1)
+		maxX := clamp(x+r, 0, w.Width-1)
+		minY := clamp(y-r, 0, w.Height-1)
+		maxY := clamp(y+r, 0, w.Height-1)
 		bestD2 := int(^uint(0) >> 1)
 		bestX, bestY := 0, 0
 		found := false
@@ -345,7 +356,7 @@ func findPathPoints(w *World, sx, sy, gx, gy int) []pathPoint {
 		}
 	}
 
-	const nodeCount = WorldWidth * WorldHeight
+	nodeCount := w.Width * w.Height
 	const inf = int(^uint(0) >> 2)
 	gScore := make([]int, nodeCount)
 	parent := make([]int, nodeCount)
@@ -355,8 +366,8 @@ func findPathPoints(w *World, sx, sy, gx, gy int) []pathPoint {
 		parent[i] = -1
 	}
 
-	startIdx := gridIdx(startX, startY)
-	goalIdx := gridIdx(goalX, goalY)
+	startIdx := gridIdx(w, startX, startY)
+	goalIdx := gridIdx(w, goalX, goalY)
 	gScore[startIdx] = 0
 	open := &pathMinHeap{}
 	heap.Init(open)
@@ -396,11 +407,11 @@ func findPathPoints(w *World, sx, sy, gx, gy int) []pathPoint {
 		}
 		closed[cur.Idx] = true
 
-		cx, cy := idxToGrid(cur.Idx)
+		cx, cy := idxToGrid(w, cur.Idx)
 		for _, n := range neighbors {
 			nx := cx + n.DX
 			ny := cy + n.DY
-			if nx < 0 || ny < 0 || nx >= WorldWidth || ny >= WorldHeight {
+			if nx < 0 || ny < 0 || nx >= w.Width || ny >= w.Height {
 				continue
 			}
 			if !walkableForSnakePath(w, nx, ny) {
@@ -412,7 +423,7 @@ func findPathPoints(w *World, sx, sy, gx, gy int) []pathPoint {
 					continue
 				}
 			}
-			nidx := gridIdx(nx, ny)
+			nidx := gridIdx(w, nx, ny)
 			if closed[nidx] {
 				continue
 			}
@@ -435,7 +446,7 @@ func findPathPoints(w *World, sx, sy, gx, gy int) []pathPoint {
 
 	path := make([]pathPoint, 0, 128)
 	for cur := goalIdx; cur >= 0; cur = parent[cur] {
-		px, py := idxToGrid(cur)
+		px, py := idxToGrid(w, cur)
 		path = append(path, pathPoint{X: px, Y: py})
 		if cur == startIdx {
 			break
@@ -549,60 +560,91 @@ void main() {
 
 func newMobileGame(seed uint64) *mobileGame {
 	g := &mobileGame{
-		seed:  seed,
-		frame: make([]byte, WorldWidth*WorldHeight*4),
-		cam: Camera{
-			X:    float64(WorldWidth) / 2,
-			Y:    float64(WorldHeight) / 2,
-			Zoom: DefaultZoom,
-		},
+		seed:     seed,
+		savePath: SavePath(),
+		profPath: ProfilePath(),
 	}
+	prof, err := LoadProfile(g.profPath)
+	if err != nil {
+		fmt.Printf("profile load failed (starting fresh): %v\n", err)
+	}
+	g.profile = prof
 	g.resetSystems()
 	return g
 }
 
 func (g *mobileGame) resetSystems() {
-	seed := g.seed
-	world := NewWorld(seed)
-	startTheme, _ := PickLevelTheme(seed, 1, 0, -1)
-	world.Theme = startTheme
-	world.GenerateAll()
-	world.BuildSpatialIndex()
-	for cy := 0; cy <= world.maxCy; cy++ {
-		for cx := 0; cx <= world.maxCx; cx++ {
-			c := world.GetChunk(cx, cy)
-			if c != nil {
-				c.RecomputeShadows(world)
-			}
+	g.attachSimulation(NewSimulation(g.seed))
+}
+
+// attachSimulation switches to sim and wires the frontend hooks into it.
+func (g *mobileGame) attachSimulation(sim *Simulation) {
+	g.sim = sim
+	g.sim.ClickVerb = "TAP"
+	g.profile.Subscribe(g.sim.Events, g.sim.Session)
+	// A finished level can't be continued; drop its save.
+	levelEnded := func(Event) {
+		if g.savePath != "" {
+			os.Remove(g.savePath)
 		}
+		g.saveProfile()
 	}
+	g.sim.Events.Subscribe(EventLevelComplete, levelEnded)
+	g.sim.Events.Subscribe(EventLevelFailed, levelEnded)
+	g.sim.Session.CanContinue = HasSave(g.savePath)
+	g.sim.Session.MaxStartLevel = g.profile.HighestLevel
+	g.sim.Session.DailySeed = DailySeed(time.Now())
 
-	g.world = world
-	g.peds = NewPedestrianSystem(400, seed^0xFED)
-	g.traffic = NewTrafficSystem(seed ^ 0xCAFE)
-	g.particles = NewParticleSystem(MaxParticles, seed^0xBEAD)
-	g.weather = NewWeatherSystem(seed ^ 0x57A7)
-	g.bonuses = NewBonusSystem(seed^0xB0B, 5)
-	g.cops = NewCopSystem(seed ^ 0xC095)
-	g.mil = NewMilitarySystem(seed ^ 0xA7A1)
-	g.session = NewGameSession()
-	g.snake = nil
-
-	_ = NewEventBus()
 	g.stateTime = 0
 	g.clearMoveTarget()
 }
 
+// saveInProgress stores the running level so it survives the app being
+// backgrounded and killed.
+func (g *mobileGame) saveInProgress() {
+	if g.savePath == "" || g.sim.Session.State != StatePlaying {
+		return
+	}
+	if err := SaveGameFile(g.savePath, g.sim); err != nil {
+		fmt.Printf("save failed: %v\n", err)
+	}
+}
+
+func (g *mobileGame) saveProfile() {
+	if err := g.profile.Save(g.profPath); err != nil {
+		fmt.Printf("profile save failed: %v\n", err)
+	}
+}
+
+// resumeSaved replaces the menu simulation with the saved level.
+func (g *mobileGame) resumeSaved() {
+	sim, err := LoadGameFile(g.savePath)
+	if err != nil {
+		fmt.Printf("continue failed: %v\n", err)
+		g.sim.Session.CanContinue = false
+		return
+	}
+	PlaySound(SoundMenuSelect)
+	g.attachSimulation(sim)
+}
+
 func (g *mobileGame) handleTouch(e touch.Event) {
 	switch e.Type {
 	case touch.TypeBegin:
-		if g.session != nil && g.session.State == StateMenu {
-			PlaySound(SoundMenuSelect)
-			StartLevelMusic(1)
-			g.session.StartLevel(1, g.world, g.peds, g.traffic, g.bonuses, g.cops, g.mil, &g.snake, g.particles, g.seed)
-			g.weather.Configure(g.session.Weather, g.session.WeatherSeed)
-			g.stateTime = 0
-			g.clearMoveTarget()
+		if g.sim.Session.State == StateMenu {
+			// The top eighth cycles the game mode, the next one the
+			// campaign start level and the bottom quarter is the
+			// CONTINUE button.
+			session := g.sim.Session
+			if float64(e.Y) < float64(g.fbHeight)*0.125 {
+				session.CycleMode(1)
+			} else if session.Mode == ModeCampaign && session.MaxStartLevel > 1 && float64(e.Y) < float64(g.fbHeight)*0.25 {
+				session.CycleMenuLevel(1)
+			} else if g.sim.Session.CanContinue && float64(e.Y) > float64(g.fbHeight)*0.75 {
+				g.pendingResume = true
+			} else {
+				g.pendingStart = true
+			}
 			g.touchDown = false
 			return
 		}
@@ -613,7 +655,7 @@ func (g *mobileGame) handleTouch(e touch.Event) {
 		if e.Sequence == g.activeTouch {
 			g.lastTouchX = e.X
 			g.lastTouchY = e.Y
-			if g.activateTargetAbilityAtScreen(e.X, e.Y) {
+			if g.queueTargetTapAtScreen(e.X, e.Y) {
 				// This tap was consumed by tactical targeting; don't also queue movement.
 				g.touchDown = false
 				return
@@ -633,42 +675,15 @@ func (g *mobileGame) handleTouch(e touch.Event) {
 	}
 }
 
-func (g *mobileGame) targetSelectingActive() bool {
-	return g.session != nil &&
-		g.session.State == StatePlaying &&
-		g.snake != nil &&
-		g.snake.Alive &&
-		g.snake.TargetNukeTimer > 0
-}
-
-func (g *mobileGame) setTargetingPrompt(rem float64) {
-	if g.snake == nil {
-		return
-	}
-	g.snake.PowerupTimer = 1.1
-	msg, col := g.snake.TargetingPrompt(rem)
-	g.snake.PowerupMsg = strings.ReplaceAll(msg, "CLICK", "TAP")
-	g.snake.PowerupCol = col
-}
-
-func (g *mobileGame) setTargetingLockLostPrompt() {
-	if g.snake == nil {
-		return
-	}
-	msg, col := g.snake.TargetingLockLostPrompt()
-	g.snake.PowerupMsg = msg
-	g.snake.PowerupCol = col
-	g.snake.PowerupTimer = 1.6
-}
-
-func (g *mobileGame) activateTargetAbilityAtScreen(sx, sy float32) bool {
-	if !g.targetSelectingActive() {
+// queueTargetTapAtScreen records a targeting tap for the next simulation step.
+func (g *mobileGame) queueTargetTapAtScreen(sx, sy float32) bool {
+	if !g.sim.TargetSelecting() {
 		return false
 	}
-	wxF, wyF := g.screenToWorld(float64(sx), float64(sy))
-	wx := clamp(int(math.Round(wxF)), 0, WorldWidth-1)
-	wy := clamp(int(math.Round(wyF)), 0, WorldHeight-1)
-	g.snake.ActivateTargetAbilityAt(wx, wy, g.world, g.peds, g.traffic, g.particles, &g.cam, g.cops, g.mil)
+	wx, wy := g.screenToWorld(float64(sx), float64(sy))
+	g.pendingClick = true
+	g.clickX = clamp(int(math.Round(wx)), 0, g.sim.World.Width-1)
+	g.clickY = clamp(int(math.Round(wy)), 0, g.sim.World.Height-1)
 	g.clearMoveTarget()
 	return true
 }
@@ -680,27 +695,28 @@ func (g *mobileGame) clearMoveTarget() {
 func (g *mobileGame) setMoveRouteToWorld(goalX, goalY float64) {
 	const maxTTLStep = 2.0
 	g.moveTargets = g.moveTargets[:0]
-	if g.world == nil {
+	if g.sim.World == nil {
 		return
 	}
 
 	// Build an obstacle-aware route from current snake head to destination.
 	startX, startY := goalX, goalY
-	if g.snake != nil {
-		startX, startY = g.snake.Head()
+	if g.sim.Snake != nil {
+		startX, startY = g.sim.Snake.Head()
 	}
+	w := g.sim.World
 	path := findPathPoints(
-		g.world,
-		clamp(int(math.Round(startX)), 0, WorldWidth-1),
-		clamp(int(math.Round(startY)), 0, WorldHeight-1),
-		clamp(int(math.Round(goalX)), 0, WorldWidth-1),
-		clamp(int(math.Round(goalY)), 0, WorldHeight-1),
+		w,
+		clamp(int(math.Round(startX)), 0, w.Width-1),
+		clamp(int(math.Round(startY)), 0, w.Height-1),
+		clamp(int(math.Round(goalX)), 0, w.Width-1),
+		clamp(int(math.Round(goalY)), 0, w.Height-1),
 	)
 	if len(path) == 0 {
 		// Fallback: direct target, still clamped to world bounds.
 		g.moveTargets = append(g.moveTargets, moveTarget{
-			X:   clampF(goalX, 0, float64(WorldWidth-1)),
-			Y:   clampF(goalY, 0, float64(WorldHeight-1)),
+			X:   clampF(goalX, 0, float64(w.Width-1)),
+			Y:   clampF(goalY, 0, float64(w.Height-1)),
 			TTL: moveWaypointBaseTTL,
 		})
 		return
@@ -756,112 +772,39 @@ func (g *mobileGame) decayMoveTargets(dt float64) {
 }
 
 func (g *mobileGame) step(dt float64) {
-	if dt <= 0 || dt > 0.1 {
-		dt = 0.016
-	}
-	g.now += dt
 	g.decayMoveTargets(dt)
 
+	if g.pendingResume {
+		g.pendingResume = false
+		g.resumeSaved()
+	}
+
 	// State transitions are touch-driven/automatic on mobile.
-	switch g.session.State {
-	case StateMenu:
-		// Wait for tap-to-start from handleTouch.
-	case StateLevelComplete:
-		g.stateTime += dt
-		if g.stateTime > 0.8 {
-			nextLevel := g.session.CurrentLevel + 1
-			StartLevelMusic(nextLevel)
-			g.session.StartLevel(nextLevel, g.world, g.peds, g.traffic, g.bonuses, g.cops, g.mil, &g.snake, g.particles, g.seed)
-			g.weather.Configure(g.session.Weather, g.session.WeatherSeed)
-			g.stateTime = 0
-			g.clearMoveTarget()
-		}
-	case StateLevelFailed:
+	if st := g.sim.Session.State; st == StateLevelComplete || st == StateLevelFailed {
 		g.stateTime += dt
-		if g.stateTime > 0.8 {
-			StartLevelMusic(g.session.CurrentLevel)
-			g.session.StartLevel(g.session.CurrentLevel, g.world, g.peds, g.traffic, g.bonuses, g.cops, g.mil, &g.snake, g.particles, g.seed)
-			g.weather.Configure(g.session.Weather, g.session.WeatherSeed)
-			g.stateTime = 0
-			g.clearMoveTarget()
-		}
 	}
 
-	if g.session.State == StatePlaying {
-		targetSelecting := false
-		if g.snake != nil && g.snake.Alive && g.snake.TargetNukeTimer > 0 {
-			targetSelecting = true
-			g.snake.TargetNukeTimer -= dt
-			rem := g.snake.TargetNukeTimer
-			if rem < 0 {
-				rem = 0
-			}
-			g.setTargetingPrompt(rem)
-			if g.snake.TargetNukeTimer <= 0 {
-				g.snake.TargetNukeTimer = 0
-				g.setTargetingLockLostPrompt()
+	prevState := g.sim.Session.State
+	g.sim.Advance(dt, func() (InputFrame, bool) {
+		// Taps are edges: the first tick that runs consumes them.
+		in := InputFrame{Advance: g.pendingStart}
+		g.pendingStart = false
+		switch g.sim.Session.State {
+		case StateLevelComplete, StateLevelFailed:
+			in.Advance = g.stateTime > 0.8
+		case StatePlaying:
+			if g.pendingClick {
+				in.Click, in.ClickX, in.ClickY = true, g.clickX, g.clickY
+			} else if s := g.sim.Snake; s != nil && s.Alive && s.AITimer <= 0 {
+				in.Steer, in.Idle = g.touchSteerTarget()
 			}
+			g.pendingClick = false
 		}
-
-		if !targetSelecting && g.snake != nil && g.snake.Alive {
-			if g.snake.AITimer <= 0 {
-				steer, idle := g.touchSteerTarget()
-				hx, hy := g.snake.Head()
-				if g.bonuses != nil {
-					bestDist := 5.0
-					for i := range g.bonuses.Boxes {
-						b := &g.bonuses.Boxes[i]
-						if !b.Alive {
-							continue
-						}
-						d := math.Hypot(b.X-hx, b.Y-hy)
-						if d < bestDist {
-							bestDist = d
-							steer = math.Atan2(b.Y-hy, b.X-hx)
-							idle = false
-						}
-					}
-				}
-				if g.snake.BounceTimer > 0 {
-					steer = g.snake.BounceDir
-					idle = false
-				} else if !idle && g.world != nil {
-					steer = WallAvoidAngle(hx, hy, steer, g.world)
-				}
-				g.snake.Idle = idle
-				if !idle {
-					g.snake.Steer(steer, dt)
-				}
-			} else {
-				g.snake.Idle = false
-			}
-			g.snake.Update(dt, g.world, g.peds, g.traffic, g.bonuses, g.particles, &g.cam, g.cops, g.mil)
-		}
-
-		g.session.Update(dt)
-		g.cam.UpdateShake(dt, g.seed^uint64(g.now*1000))
-		g.world.Update(dt)
-		UpdateBurnVisuals(g.world, g.particles, dt)
-		g.peds.Update(dt, g.world, g.snake, g.particles)
-		sunAmbNow, _, _, _ := SunCycleLight(g.session.LevelTimer)
-		g.traffic.NightFactor = NightIntensityFromAmbient(sunAmbNow)
-		g.traffic.Update(dt, g.world, g.particles, g.peds, &g.cam)
-		g.weather.UpdateAndSpawn(g.particles, dt)
-		g.particles.UpdateWithShockwaveDamage(dt, g.world, g.peds, g.cops, g.mil)
-		snakeHP := 1.0
-		if g.snake != nil {
-			snakeHP = g.snake.HP.Fraction()
-		}
-		g.bonuses.Update(dt, g.peds.AliveCount(), snakeHP)
-		g.bonuses.SpawnSparks(g.particles, dt)
-		g.cops.Update(dt, g.snake, g.world, g.particles, &g.cam, g.now)
-		g.mil.Update(dt, g.snake, g.world, g.particles, &g.cam, g.now)
-
-		g.peds.RemoveDead()
-		g.traffic.RemoveDead()
-		g.cops.RemoveDead()
-		g.mil.RemoveDead()
-		g.session.CheckLevelEnd(g.peds, g.snake)
+		return in, true
+	})
+	if prevState != StatePlaying && g.sim.Session.State == StatePlaying {
+		g.stateTime = 0
+		g.clearMoveTarget()
 	}
 
 	_, _, _, _, zoomX, zoomY := g.renderViewport()
@@ -869,33 +812,38 @@ func (g *mobileGame) step(dt float64) {
 	if zoom <= 0 {
 		zoom = 1
 	}
-	g.cam.Zoom = zoom
-	g.cam.X = float64(WorldWidth) * 0.5
-	g.cam.Y = float64(WorldHeight) * 0.5
+	g.sim.Cam.Zoom = zoom
+	view := g.sim.World.ViewArea(g.sim.focus())
+	g.sim.Cam.X = (view.X0 + view.X1) * 0.5
+	g.sim.Cam.Y = (view.Y0 + view.Y1) * 0.5
+	if g.scrolls() {
+		g.minimap.Update(g.sim.World, dt)
+	}
+	g.roofs.Update(g.sim.World, view, g.sim.Snakes, dt)
 }
 
 func (g *mobileGame) touchSteerTarget() (float64, bool) {
-	if g.snake == nil {
+	if g.sim.Snake == nil {
 		return 0, true
 	}
-	hx, hy := g.snake.Head()
+	hx, hy := g.sim.Snake.Head()
 	for len(g.moveTargets) > 0 {
 		target := g.moveTargets[0]
-		txi := clamp(int(math.Round(target.X)), 0, WorldWidth-1)
-		tyi := clamp(int(math.Round(target.Y)), 0, WorldHeight-1)
-		if g.world != nil && !walkableForSnakePath(g.world, txi, tyi) {
+		txi := clamp(int(math.Round(target.X)), 0, g.sim.World.Width-1)
+		tyi := clamp(int(math.Round(target.Y)), 0, g.sim.World.Height-1)
+		if g.sim.World != nil && !walkableForSnakePath(g.sim.World, txi, tyi) {
 			g.popMoveTarget()
 			continue
 		}
 
 		// If we already have clear line to a farther waypoint, skip intermediate one.
-		if len(g.moveTargets) > 1 && g.world != nil {
+		if len(g.moveTargets) > 1 && g.sim.World != nil {
 			next := g.moveTargets[1]
-			nxi := clamp(int(math.Round(next.X)), 0, WorldWidth-1)
-			nyi := clamp(int(math.Round(next.Y)), 0, WorldHeight-1)
-			hxi := clamp(int(math.Round(hx)), 0, WorldWidth-1)
-			hyi := clamp(int(math.Round(hy)), 0, WorldHeight-1)
-			if hasLineClearance(g.world, hxi, hyi, nxi, nyi) {
+			nxi := clamp(int(math.Round(next.X)), 0, g.sim.World.Width-1)
+			nyi := clamp(int(math.Round(next.Y)), 0, g.sim.World.Height-1)
+			hxi := clamp(int(math.Round(hx)), 0, g.sim.World.Width-1)
+			hyi := clamp(int(math.Round(hy)), 0, g.sim.World.Height-1)
+			if hasLineClearance(g.sim.World, hxi, hyi, nxi, nyi) {
 				g.popMoveTarget()
 				continue
 			}
@@ -910,21 +858,21 @@ func (g *mobileGame) touchSteerTarget() (float64, bool) {
 		}
 		return math.Atan2(dy, dx), false
 	}
-	return g.snake.Heading, true
+	return g.sim.Snake.Heading, true
 }
 
-func clampViewCenter(camX, camY, viewW, viewH float64) (float64, float64) {
-	if viewW >= float64(WorldWidth) {
-		camX = float64(WorldWidth) * 0.5
+func clampViewCenter(w *World, camX, camY, viewW, viewH float64) (float64, float64) {
+	if viewW >= float64(w.Width) {
+		camX = float64(w.Width) * 0.5
 	} else {
 		halfW := viewW * 0.5
-		camX = clampF(camX, halfW, float64(WorldWidth)-halfW)
+		camX = clampF(camX, halfW, float64(w.Width)-halfW)
 	}
-	if viewH >= float64(WorldHeight) {
-		camY = float64(WorldHeight) * 0.5
+	if viewH >= float64(w.Height) {
+		camY = float64(w.Height) * 0.5
 	} else {
 		halfH := viewH * 0.5
-		camY = clampF(camY, halfH, float64(WorldHeight)-halfH)
+		camY = clampF(camY, halfH, float64(w.Height)-halfH)
 	}
 	return camX, camY
 }
@@ -933,12 +881,14 @@ func (g *mobileGame) renderViewport() (x, y, w, h int, zoomX, zoomY float64) {
 	if g.fbWidth <= 0 || g.fbHeight <= 0 {
 		return 0, 0, 0, 0, 1, 1
 	}
-	// Fill mode with full map visibility: stretch to full window so gameplay area
-	// always occupies the whole screen (no crop, no letterbox).
+	// Fill mode: stretch a default-sized stretch of city to the full window so
+	// the gameplay area always occupies the whole screen (no crop, no
+	// letterbox). Default worlds show whole; larger ones scroll.
 	x, y = 0, 0
 	w, h = g.fbWidth, g.fbHeight
-	zoomX = float64(w) / float64(WorldWidth)
-	zoomY = float64(h) / float64(WorldHeight)
+	viewW, viewH := g.sim.World.ViewSize()
+	zoomX = float64(w) / viewW
+	zoomY = float64(h) / viewH
 	if zoomX <= 0 {
 		zoomX = 1
 	}
@@ -951,18 +901,18 @@ func (g *mobileGame) renderViewport() (x, y, w, h int, zoomX, zoomY float64) {
 func (g *mobileGame) screenToWorld(sx, sy float64) (float64, float64) {
 	vx, vy, vw, vh, zoomX, zoomY := g.renderViewport()
 	if vw <= 0 || vh <= 0 || zoomX <= 0 || zoomY <= 0 {
-		return float64(WorldWidth) * 0.5, float64(WorldHeight) * 0.5
+		return float64(g.sim.World.Width) * 0.5, float64(g.sim.World.Height) * 0.5
 	}
 	lx := clampF(sx-float64(vx), 0, float64(vw))
 	ly := clampF(sy-float64(vy), 0, float64(vh))
 	// Use non-shaken camera for stable tap placement.
-	camX, camY := g.cam.X, g.cam.Y
+	camX, camY := g.sim.Cam.X, g.sim.Cam.Y
 	viewW := float64(vw) / zoomX
 	viewH := float64(vh) / zoomY
-	camX, camY = clampViewCenter(camX, camY, viewW, viewH)
+	camX, camY = clampViewCenter(g.sim.World, camX, camY, viewW, viewH)
 	wx := camX + (lx-float64(vw)*0.5)/zoomX
 	wy := camY + (ly-float64(vh)*0.5)/zoomY
-	return clampF(wx, 0, float64(WorldWidth-1)), clampF(wy, 0, float64(WorldHeight-1))
+	return clampF(wx, 0, float64(g.sim.World.Width-1)), clampF(wy, 0, float64(g.sim.World.Height-1))
 }
 
 func clampByte(v float64) uint8 {
@@ -975,36 +925,70 @@ func clampByte(v float64) uint8 {
 	return uint8(v + 0.5)
 }
 
+// updateFrameArea moves the software frame over the world pixels the
+// camera shows (plus one for the fractional edge) and reallocates it when
+// the view size changes.
+func (g *mobileGame) updateFrameArea() {
+	w := g.sim.World
+	_, _, vw, vh, zoomX, zoomY := g.renderViewport()
+	viewW, viewH := float64(vw)/zoomX, float64(vh)/zoomY
+	camX, camY := g.sim.Cam.EffectivePos()
+	fw := min(int(math.Ceil(viewW))+1, w.Width)
+	fh := min(int(math.Ceil(viewH))+1, w.Height)
+	g.frameX = clamp(int(math.Floor(camX-viewW*0.5)), 0, w.Width-fw)
+	g.frameY = clamp(int(math.Floor(camY-viewH*0.5)), 0, w.Height-fh)
+	if fw != g.frameW || fh != g.frameH || g.frame == nil {
+		g.frameW, g.frameH = fw, fh
+		g.frame = make([]byte, fw*fh*4)
+	}
+}
+
 func (g *mobileGame) renderSoftware() {
-	sunAmb, sunTR, sunTG, sunTB := SunCycleLight(g.session.LevelTimer)
-	sunAngle, sunSlope := SunCycleShadow(g.session.LevelTimer)
-	g.world.UpdateSun(sunAngle, sunSlope)
-	for cy := 0; cy <= g.world.maxCy; cy++ {
-		for cx := 0; cx <= g.world.maxCx; cx++ {
-			c := g.world.GetChunk(cx, cy)
+	sunAmb, sunTR, sunTG, sunTB := SunCycleLight(g.sim.Session.LevelTimer)
+	sunAngle, sunSlope := SunCycleShadow(g.sim.Session.LevelTimer)
+	g.sim.World.UpdateSun(sunAngle, sunSlope)
+	g.updateFrameArea()
+	x0, y0 := g.frameX, g.frameY
+	x1, y1 := x0+g.frameW, y0+g.frameH
+	for cy := y0 / ChunkSize; cy <= min((y1-1)/ChunkSize, g.sim.World.maxCy); cy++ {
+		for cx := x0 / ChunkSize; cx <= min((x1-1)/ChunkSize, g.sim.World.maxCx); cx++ {
+			c := g.sim.World.GetChunk(cx, cy)
 			if c == nil {
 				continue
 			}
 			if c.NeedsShadow {
-				c.RecomputeShadows(g.world)
+				c.RecomputeShadows(g.sim.World)
 			}
 			baseX, baseY := c.WorldOrigin()
+			var decals []uint8
+			if c.Decals != nil {
+				decals = c.Decals.Pix
+			}
 			for ly := 0; ly < ChunkSize; ly++ {
 				wy := baseY + ly
-				if wy < 0 || wy >= WorldHeight {
+				if wy < y0 || wy >= y1 {
 					continue
 				}
 				for lx := 0; lx < ChunkSize; lx++ {
 					wx := baseX + lx
-					if wx < 0 || wx >= WorldWidth {
+					if wx < x0 || wx >= x1 {
 						continue
 					}
 					src := (ly*ChunkSize + lx) * 4
-					dst := (wy*WorldWidth + wx) * 4
+					dst := ((wy-y0)*g.frameW + wx - x0) * 4
 					shade := float32(c.Pixels[src+3]) / 255.0
-					g.frame[dst+0] = clampByte(float64(c.Pixels[src+0]) * float64(shade) * float64(sunAmb) * float64(sunTR))
-					g.frame[dst+1] = clampByte(float64(c.Pixels[src+1]) * float64(shade) * float64(sunAmb) * float64(sunTG))
-					g.frame[dst+2] = clampByte(float64(c.Pixels[src+2]) * float64(shade) * float64(sunAmb) * float64(sunTB))
+					r, gg, b := float64(c.Pixels[src+0]), float64(c.Pixels[src+1]), float64(c.Pixels[src+2])
+					// Decals blend over the ground before shading, as in the
+					// desktop chunk shader.
+					if decals != nil && decals[src+3] > 0 {
+						a := float64(decals[src+3]) / 255.0
+						r += (float64(decals[src+0]) - r) * a
+						gg += (float64(decals[src+1]) - gg) * a
+						b += (float64(decals[src+2]) - b) * a
+					}
+					g.frame[dst+0] = clampByte(r * float64(shade) * float64(sunAmb) * float64(sunTR))
+					g.frame[dst+1] = clampByte(gg * float64(shade) * float64(sunAmb) * float64(sunTG))
+					g.frame[dst+2] = clampByte(b * float64(shade) * float64(sunAmb) * float64(sunTB))
 					g.frame[dst+3] = 255
 				}
 			}
@@ -1033,6 +1017,8 @@ func (g *mobileGame) drawSprites(buf []float32, additive bool) {
 }
 
 func (g *mobileGame) drawSquare(cx, cy, size float32, r, gg, b, a float32, additive bool) {
+	// The frame only covers the view; work in frame pixels.
+	cx, cy = cx-float32(g.frameX), cy-float32(g.frameY)
 	if size < 0.5 {
 		size = 0.5
 	}
@@ -1041,7 +1027,7 @@ func (g *mobileGame) drawSquare(cx, cy, size float32, r, gg, b, a float32, addit
 	maxX := int(math.Ceil(float64(cx + half)))
 	minY := int(math.Floor(float64(cy - half)))
 	maxY := int(math.Ceil(float64(cy + half)))
-	if maxX < 0 || maxY < 0 || minX >= WorldWidth || minY >= WorldHeight {
+	if maxX < 0 || maxY < 0 || minX >= g.frameW || minY >= g.frameH {
 		return
 	}
 	if minX < 0 {
@@ -1050,11 +1036,11 @@ func (g *mobileGame) drawSquare(cx, cy, size float32, r, gg, b, a float32, addit
 	if minY < 0 {
 		minY = 0
 	}
-	if maxX >= WorldWidth {
-		maxX = WorldWidth - 1
+	if maxX >= g.frameW {
+		maxX = g.frameW - 1
 	}
-	if maxY >= WorldHeight {
-		maxY = WorldHeight - 1
+	if maxY >= g.frameH {
+		maxY = g.frameH - 1
 	}
 
 	srcR := float64(r * 255)
@@ -1063,7 +1049,7 @@ func (g *mobileGame) drawSquare(cx, cy, size float32, r, gg, b, a float32, addit
 	srcA := float64(a)
 	for py := minY; py <= maxY; py++ {
 		for px := minX; px <= maxX; px++ {
-			o := (py*WorldWidth + px) * 4
+			o := (py*g.frameW + px) * 4
 			if additive {
 				g.frame[o+0] = clampByte(float64(g.frame[o+0]) + srcR*srcA)
 				g.frame[o+1] = clampByte(float64(g.frame[o+1]) + srcG*srcA)
@@ -1081,6 +1067,7 @@ func (g *mobileGame) drawSquare(cx, cy, size float32, r, gg, b, a float32, addit
 }
 
 func (g *mobileGame) drawOrientedRect(cx, cy, heading, halfW, halfL float32, r, gg, b, a float32, additive bool) {
+	cx, cy = cx-float32(g.frameX), cy-float32(g.frameY)
 	if halfW <= 0 || halfL <= 0 {
 		return
 	}
@@ -1095,7 +1082,7 @@ func (g *mobileGame) drawOrientedRect(cx, cy, heading, halfW, halfL float32, r,
 	maxX := int(math.Ceil(float64(cx + extX)))
 	minY := int(math.Floor(float64(cy - extY)))
 	maxY := int(math.Ceil(float64(cy + extY)))
-	if maxX < 0 || maxY < 0 || minX >= WorldWidth || minY >= WorldHeight {
+	if maxX < 0 || maxY < 0 || minX >= g.frameW || minY >= g.frameH {
 		return
 	}
 	if minX < 0 {
@@ -1104,11 +1091,11 @@ func (g *mobileGame) drawOrientedRect(cx, cy, heading, halfW, halfL float32, r,
 	if minY < 0 {
 		minY = 0
 	}
-	if maxX >= WorldWidth {
-		maxX = WorldWidth - 1
+	if maxX >= g.frameW {
+		maxX = g.frameW - 1
 	}
-	if maxY >= WorldHeight {
-		maxY = WorldHeight - 1
+	if maxY >= g.frameH {
+		maxY = g.frameH - 1
 	}
 
 	srcR := float64(r * 255)
@@ -1133,7 +1120,7 @@ func (g *mobileGame) drawOrientedRect(cx, cy, heading, halfW, halfL float32, r,
 			if math.Abs(lf) > halfLF || math.Abs(lp) > halfWF {
 				continue
 			}
-			o := (py*WorldWidth + px) * 4
+			o := (py*g.frameW + px) * 4
 			if additive {
 				g.frame[o+0] = clampByte(float64(g.frame[o+0]) + srcR*srcA)
 				g.frame[o+1] = clampByte(float64(g.frame[o+1]) + srcG*srcA)
@@ -1161,14 +1148,14 @@ func (g *mobileGame) drawCarShadows() {
 			g.drawCircle(ox+fwdX*t, oy+fwdY*t, sz*0.5, 0, 0, 0, 0.22, false)
 		}
 	}
-	for i := range g.traffic.Cars {
-		c := &g.traffic.Cars[i]
+	for i := range g.sim.Traffic.Cars {
+		c := &g.sim.Traffic.Cars[i]
 		if c.Alive {
 			addShadow(c.X, c.Y, c.Heading)
 		}
 	}
-	for i := range g.cops.Cars {
-		c := &g.cops.Cars[i]
+	for i := range g.sim.Cops.Cars {
+		c := &g.sim.Cops.Cars[i]
 		if c.Alive {
 			addShadow(c.X, c.Y, c.Heading)
 		}
@@ -1207,6 +1194,7 @@ func (g *mobileGame) drawCopCar(c *CopCar) {
 }
 
 func (g *mobileGame) drawCircle(cx, cy, rad float32, r, gg, b, a float32, additive bool) {
+	cx, cy = cx-float32(g.frameX), cy-float32(g.frameY)
 	if rad < 0.5 {
 		rad = 0.5
 	}
@@ -1214,7 +1202,7 @@ func (g *mobileGame) drawCircle(cx, cy, rad float32, r, gg, b, a float32, additi
 	maxX := int(math.Ceil(float64(cx + rad)))
 	minY := int(math.Floor(float64(cy - rad)))
 	maxY := int(math.Ceil(float64(cy + rad)))
-	if maxX < 0 || maxY < 0 || minX >= WorldWidth || minY >= WorldHeight {
+	if maxX < 0 || maxY < 0 || minX >= g.frameW || minY >= g.frameH {
 		return
 	}
 	if minX < 0 {
@@ -1223,11 +1211,11 @@ func (g *mobileGame) drawCircle(cx, cy, rad float32, r, gg, b, a float32, additi
 	if minY < 0 {
 		minY = 0
 	}
-	if maxX >= WorldWidth {
-		maxX = WorldWidth - 1
+	if maxX >= g.frameW {
+		maxX = g.frameW - 1
 	}
-	if maxY >= WorldHeight {
-		maxY = WorldHeight - 1
+	if maxY >= g.frameH {
+		maxY = g.frameH - 1
 	}
 
 	r2 := rad * rad
@@ -1242,7 +1230,7 @@ func (g *mobileGame) drawCircle(cx, cy, rad float32, r, gg, b, a float32, additi
 			if dx*dx+dy*dy > r2 {
 				continue
 			}
-			o := (py*WorldWidth + px) * 4
+			o := (py*g.frameW + px) * 4
 			if additive {
 				g.frame[o+0] = clampByte(float64(g.frame[o+0]) + srcR*srcA)
 				g.frame[o+1] = clampByte(float64(g.frame[o+1]) + srcG*srcA)
@@ -1260,6 +1248,7 @@ func (g *mobileGame) drawCircle(cx, cy, rad float32, r, gg, b, a float32, additi
 }
 
 func (g *mobileGame) drawRing(cx, cy, innerR, outerR float32, r, gg, b, a float32, additive bool) {
+	cx, cy = cx-float32(g.frameX), cy-float32(g.frameY)
 	if outerR <= innerR || outerR < 0.75 {
 		return
 	}
@@ -1267,7 +1256,7 @@ func (g *mobileGame) drawRing(cx, cy, innerR, outerR float32, r, gg, b, a float3
 	maxX := int(math.Ceil(float64(cx + outerR)))
 	minY := int(math.Floor(float64(cy - outerR)))
 	maxY := int(math.Ceil(float64(cy + outerR)))
-	if maxX < 0 || maxY < 0 || minX >= WorldWidth || minY >= WorldHeight {
+	if maxX < 0 || maxY < 0 || minX >= g.frameW || minY >= g.frameH {
 		return
 	}
 	if minX < 0 {
@@ -1276,11 +1265,11 @@ func (g *mobileGame) drawRing(cx, cy, innerR, outerR float32, r, gg, b, a float3
 	if minY < 0 {
 		minY = 0
 	}
-	if maxX >= WorldWidth {
-		maxX = WorldWidth - 1
+	if maxX >= g.frameW {
+		maxX = g.frameW - 1
 	}
-	if maxY >= WorldHeight {
-		maxY = WorldHeight - 1
+	if maxY >= g.frameH {
+		maxY = g.frameH - 1
 	}
 
 	outer2 := outerR * outerR
@@ -1310,7 +1299,7 @@ func (g *mobileGame) drawRing(cx, cy, innerR, outerR float32, r, gg, b, a float3
 				continue
 			}
 			srcA := baseA * falloff
-			o := (py*WorldWidth + px) * 4
+			o := (py*g.frameW + px) * 4
 			if additive {
 				g.frame[o+0] = clampByte(float64(g.frame[o+0]) + srcR*srcA)
 				g.frame[o+1] = clampByte(float64(g.frame[o+1]) + srcG*srcA)
@@ -1478,7 +1467,7 @@ void main() {
 	glctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 	glctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 	glctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	glctx.TexImage2D(gl.TEXTURE_2D, 0, int(gl.RGBA), WorldWidth, WorldHeight, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	g.texW, g.texH = 0, 0 // sized to the frame on first draw
 
 	g.prog = prog
 	g.tex = tex
@@ -1728,8 +1717,8 @@ func carHeadlightSpritesMobile(ts *TrafficSystem, brightness float32, buf []floa
 
 func (g *mobileGame) buildCarSpriteBuffers() {
 	g.trafficCarBuf = g.trafficCarBuf[:0]
-	for i := range g.traffic.Cars {
-		c := &g.traffic.Cars[i]
+	for i := range g.sim.Traffic.Cars {
+		c := &g.sim.Traffic.Cars[i]
 		if !c.Alive {
 			continue
 		}
@@ -1738,8 +1727,8 @@ func (g *mobileGame) buildCarSpriteBuffers() {
 			1, 1, 1, 1, float32(c.Heading+math.Pi*0.5))
 	}
 	g.copCarBuf = g.copCarBuf[:0]
-	for i := range g.cops.Cars {
-		c := &g.cops.Cars[i]
+	for i := range g.sim.Cops.Cars {
+		c := &g.sim.Cops.Cars[i]
 		if !c.Alive {
 			continue
 		}
@@ -1790,13 +1779,14 @@ func (g *mobileGame) drawGL(glctx gl.Context) {
 	}
 	glctx.Viewport(vx, vy, vw, vh)
 
-	camX, camY := g.cam.EffectivePos()
+	camX, camY := g.sim.Cam.EffectivePos()
 	viewW := float64(vw) / zoomX64
 	viewH := float64(vh) / zoomY64
-	u0 := (camX - viewW*0.5) / float64(WorldWidth)
-	v0 := (camY - viewH*0.5) / float64(WorldHeight)
-	u1 := (camX + viewW*0.5) / float64(WorldWidth)
-	v1 := (camY + viewH*0.5) / float64(WorldHeight)
+	fx, fy := float64(g.frameX), float64(g.frameY)
+	u0 := (camX - viewW*0.5 - fx) / float64(g.frameW)
+	v0 := (camY - viewH*0.5 - fy) / float64(g.frameH)
+	u1 := (camX + viewW*0.5 - fx) / float64(g.frameW)
+	v1 := (camY + viewH*0.5 - fy) / float64(g.frameH)
 	verts := []float32{
 		-1, -1, float32(u0), float32(v1),
 		1, -1, float32(u1), float32(v1),
@@ -1806,7 +1796,11 @@ func (g *mobileGame) drawGL(glctx gl.Context) {
 
 	glctx.ActiveTexture(gl.TEXTURE0)
 	glctx.BindTexture(gl.TEXTURE_2D, g.tex)
-	glctx.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, WorldWidth, WorldHeight, gl.RGBA, gl.UNSIGNED_BYTE, g.frame)
+	if g.texW != g.frameW || g.texH != g.frameH {
+		glctx.TexImage2D(gl.TEXTURE_2D, 0, int(gl.RGBA), g.frameW, g.frameH, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		g.texW, g.texH = g.frameW, g.frameH
+	}
+	glctx.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, g.frameW, g.frameH, gl.RGBA, gl.UNSIGNED_BYTE, g.frame)
 
 	glctx.UseProgram(g.prog)
 	glctx.BindBuffer(gl.ARRAY_BUFFER, g.vbo)
@@ -1818,35 +1812,35 @@ func (g *mobileGame) drawGL(glctx gl.Context) {
 	glctx.Uniform1i(g.uTex, 0)
 	glctx.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
 
-	sunAmb, sunTR, sunTG, sunTB := SunCycleLight(g.session.LevelTimer)
+	sunAmb, sunTR, sunTG, sunTB := SunCycleLight(g.sim.Session.LevelTimer)
 	zoomX := float32(zoomX64)
 	zoomY := float32(zoomY64)
 
-	g.carShadowBuf = carShadowSpritesMobile(g.traffic, g.cops, g.carShadowBuf)
+	g.carShadowBuf = carShadowSpritesMobile(g.sim.Traffic, g.sim.Cops, g.carShadowBuf)
 	g.drawLitSpritesGL(glctx, g.carShadowBuf, false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
 
 	g.buildCarSpriteBuffers()
 	g.drawNPCSpritesGL(glctx, g.trafficCarBuf, g.carTexBase, CarVisualAspect, float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 	g.drawNPCSpritesGL(glctx, g.copCarBuf, g.copCarTex, CarVisualAspect, float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 
-	g.pedBuf = g.peds.PedRenderData(g.pedBuf, g.now)
+	g.pedBuf = g.sim.Peds.PedRenderData(g.pedBuf, g.sim.Now)
 	g.drawLitSpritesGL(glctx, g.pedBuf, false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
 
-	g.drawLitSpritesGL(glctx, g.cops.CopRenderData(g.now), false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
-	g.drawGlowSpritesGL(glctx, g.cops.CopGlowData(g.now), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
-	g.drawLitSpritesGL(glctx, g.mil.RenderData(g.now), false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
-	g.drawGlowSpritesGL(glctx, g.mil.GlowData(g.now), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
+	g.drawLitSpritesGL(glctx, g.sim.Cops.CopRenderData(g.sim.Now), false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
+	g.drawGlowSpritesGL(glctx, g.sim.Cops.CopGlowData(g.sim.Now), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
+	g.drawLitSpritesGL(glctx, g.sim.Mil.RenderData(g.sim.Now), false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
+	g.drawGlowSpritesGL(glctx, g.sim.Mil.GlowData(g.sim.Now), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 
-	if g.snake != nil && g.snake.Alive {
-		g.snakeLitBuf = brightenSpriteColors(g.snake.SnakeRenderData(), g.snakeLitBuf, 1.22)
+	if g.sim.Snake != nil && g.sim.Snake.Alive {
+		g.snakeLitBuf = brightenSpriteColors(g.sim.Snake.SnakeRenderData(), g.snakeLitBuf, 1.22)
 		g.drawLitSpritesGL(glctx, g.snakeLitBuf, false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
-		g.drawGlowSpritesGL(glctx, g.snake.GlowData(), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
+		g.drawGlowSpritesGL(glctx, g.sim.Snake.GlowData(), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 	}
 
-	if len(g.moveTargets) > 0 && g.snake != nil && g.snake.Alive && g.snake.TargetNukeTimer <= 0 {
+	if len(g.moveTargets) > 0 && g.sim.Snake != nil && g.sim.Snake.Alive && g.sim.Snake.TargetNukeTimer <= 0 {
 		g.targetGlowBuf = g.targetGlowBuf[:0]
 		t := g.moveTargets[len(g.moveTargets)-1]
-		pulse := float32(1.0 + 0.14*math.Sin(g.now*2.5))
+		pulse := float32(1.0 + 0.14*math.Sin(g.sim.Now*2.5))
 		baseOuter := float32(11.0)
 		baseInner := baseOuter * 0.45
 		cx := float32(t.X)
@@ -1858,27 +1852,66 @@ func (g *mobileGame) drawGL(glctx gl.Context) {
 		g.drawGlowSpritesGL(glctx, g.targetGlowBuf, float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 	}
 
-	g.drawBonusSpritesGL(glctx, g.bonuses.RenderData(), float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
-	g.drawGlowSpritesGL(glctx, g.bonuses.GlowData(), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
+	g.drawBonusSpritesGL(glctx, g.sim.Bonuses.RenderData(), float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
+	g.drawGlowSpritesGL(glctx, g.sim.Bonuses.GlowData(), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
+
+	// Roofs over building interiors, cut away while a snake is inside.
+	g.roofBuf = g.roofs.Sprites(g.roofBuf[:0])
+	g.drawLitSpritesGL(glctx, g.roofBuf, false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
+
+	view := RectF{X0: camX - viewW*0.5, Y0: camY - viewH*0.5, X1: camX + viewW*0.5, Y1: camY + viewH*0.5}
+	g.trafficLightBuf = TrafficLightSprites(g.sim.World, view, g.trafficLightBuf[:0])
+	g.drawGlowSpritesGL(glctx, g.trafficLightBuf, float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 
 	lightBrightness := NightIntensityFromAmbient(sunAmb)
 	if lightBrightness > 0.01 {
-		if !g.world.Theme.NoRoads {
-			g.drawGlowSpritesGL(glctx, streetlightSprites(lightBrightness), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
+		if !g.sim.World.Theme.NoRoads {
+			g.drawGlowSpritesGL(glctx, streetlightSprites(g.sim.World, lightBrightness), float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 		}
-		g.carHeadBuf = carHeadlightSpritesMobile(g.traffic, lightBrightness, g.carHeadBuf)
+		g.carHeadBuf = carHeadlightSpritesMobile(g.sim.Traffic, lightBrightness, g.carHeadBuf)
 		g.drawGlowSpritesGL(glctx, g.carHeadBuf, float32(camX), float32(camY), zoomX, zoomY, vw, vh)
 	}
 
-	g.glowBuf, g.normBuf = g.particles.ParticleRenderData(g.glowBuf, g.normBuf)
+	g.glowBuf, g.normBuf = g.sim.Particles.ParticleRenderData(g.glowBuf, g.normBuf)
 	g.drawLitSpritesGL(glctx, g.normBuf, false, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
 	g.drawLitSpritesGL(glctx, g.glowBuf, true, float32(camX), float32(camY), zoomX, zoomY, vw, vh, sunAmb, sunTR, sunTG, sunTB)
 
+	g.renderMinimapMobile(glctx, camX, camY, viewW, viewH, vw, vh)
 	g.renderHUDMobile(glctx, g.fbWidth, g.fbHeight)
 }
 
+// renderMinimapMobile draws the minimap under the objective line while a
+// world larger than the view is being played.
+func (g *mobileGame) renderMinimapMobile(glctx gl.Context, camX, camY, viewW, viewH float64, vw, vh int) {
+	if g.sim.Session.State != StatePlaying || !g.scrolls() {
+		return
+	}
+	px := float32(max(2, vh/220))
+	mw, _ := g.minimap.Size(px)
+	view := RectF{X0: camX - viewW*0.5, Y0: camY - viewH*0.5, X1: camX + viewW*0.5, Y1: camY + viewH*0.5}
+	g.minimapBuf = g.minimap.Sprites(g.minimapBuf[:0], float32(vw)-mw-float32(mobileUISp(8)), float32(mobileUISp(40)), px, view, &g.sim.Session.Objective, g.sim.Snakes)
+	// Screen pixels in, screen pixels out: centre the camera on the screen at zoom 1.
+	g.drawLitSpritesGL(glctx, g.minimapBuf, false, float32(vw)/2, float32(vh)/2, 1, 1, vw, vh, 1, 1, 1, 1)
+}
+
+// scrolls reports whether the world is larger than the view.
+func (g *mobileGame) scrolls() bool {
+	viewW, viewH := g.sim.World.ViewSize()
+	return viewW < float64(g.sim.World.Width) || viewH < float64(g.sim.World.Height)
+}
+
 func RunAndroid() {
 	seed := uint64(time.Now().UnixNano())
+	// A levels.json dropped into the app files directory replaces the
+	// built-in campaign.
+	if dir, err := DataDir(); err == nil {
+		path := filepath.Join(dir, "levels.json")
+		if _, err := os.Stat(path); err == nil {
+			if err := LoadLevelPack(path); err != nil {
+				fmt.Printf("level pack ignored: %v\n", err)
+			}
+		}
+	}
 	game := newMobileGame(seed)
 	if err := InitAudio(); err != nil {
 		fmt.Printf("audio init failed (continuing without sound): %v\n", err)
@@ -1909,6 +1942,9 @@ func RunAndroid() {
 					last = time.Now()
 					a.Send(paint.Event{})
 				case lifecycle.CrossOff:
+					// Backgrounded apps may be killed without further notice.
+					game.saveInProgress()
+					game.saveProfile()
 					if glctx != nil {
 						game.destroyGL(glctx)
 						glctx = nil
@@ -1933,8 +1969,10 @@ func RunAndroid() {
 				dt := now.Sub(last).Seconds()
 				last = now
 				game.step(dt)
+				game.sim.BeginInterpolatedRender()
 				game.renderSoftware()
 				game.drawGL(glctx)
+				game.sim.EndInterpolatedRender()
 				a.Publish()
 				a.Send(paint.Event{})
 			}
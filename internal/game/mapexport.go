@@ -0,0 +1,256 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// GenerateLevelWorld builds, with every chunk loaded, the world a run that
+// starts at level gets on its first attempt with the given seed. A theme
+// name pins the theme family the way a level's "theme" does.
+func GenerateLevelWorld(seed uint64, level int, theme string) (*World, error) {
+	cfg, err := GetLevelConfig(level)
+	if err != nil {
+		return nil, err
+	}
+	if theme != "" {
+		t, _, ok := themeByName(theme)
+		if !ok {
+			return nil, fmt.Errorf("unknown theme %q", theme)
+		}
+		cfg.Theme, cfg.FixedTheme = t, true
+	}
+	levelSeed := NewGameSession().rollLevel(&cfg, level, seed)
+	w := NewWorld(levelSeed, DefaultWorldWidth, DefaultWorldHeight)
+	w.Theme, w.Map = cfg.Theme, cfg.Map
+	w.Resize(cfg.worldSize())
+	w.GenerateAll()
+	return w, nil
+}
+
+// WorldImageLayer selects what World.Image draws.
+type WorldImageLayer int
+
+const (
+	ImageColor  WorldImageLayer = iota // terrain colours
+	ImageShaded                        // terrain colours in the sun's shadows
+	ImageHeight                        // height as a grey level, as in a map's height_map
+)
+
+// Image draws the whole world, loading every chunk.
+func (w *World) Image(layer WorldImageLayer) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w.Width, w.Height))
+	for cy := 0; cy <= w.maxCy; cy++ {
+		for cx := 0; cx <= w.maxCx; cx++ {
+			c := w.GetChunk(cx, cy)
+			if layer == ImageShaded && c.NeedsShadow {
+				c.RecomputeShadows(w)
+			}
+			baseX, baseY := c.WorldOrigin()
+			for ly := 0; ly < ChunkSize && baseY+ly < w.Height; ly++ {
+				for lx := 0; lx < ChunkSize && baseX+lx < w.Width; lx++ {
+					i := c.idx(lx, ly)
+					o := c.pixOff(i)
+					col := color.RGBA{R: c.Pixels[o], G: c.Pixels[o+1], B: c.Pixels[o+2], A: 255}
+					switch layer {
+					case ImageShaded:
+						s := uint16(c.Pixels[o+3])
+						col.R = uint8(uint16(col.R) * s / 255)
+						col.G = uint8(uint16(col.G) * s / 255)
+						col.B = uint8(uint16(col.B) * s / 255)
+					case ImageHeight:
+						h := c.Height[i]
+						col = color.RGBA{R: h, G: h, B: h, A: 255}
+					}
+					img.SetRGBA(baseX+lx, baseY+ly, col)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// CityLayout is the plan a world was generated from: its street grid and
+// what each city block holds. Rectangles are in world pixels with X1, Y1
+// exclusive; park areas, buildings and trees are placed relative to their
+// block's Rect.
+type CityLayout struct {
+	Seed   uint64        `json:"seed"`
+	Theme  string        `json:"theme"`
+	Width  int           `json:"width"`
+	Height int           `json:"height"`
+	Roads  []LayoutRect  `json:"roads"`
+	Blocks []LayoutBlock `json:"blocks"`
+	Map    bool          `json:"map,omitempty"` // hand-drawn; roads and blocks are empty
+}
+
+type LayoutRect struct {
+	X0 int `json:"x0"`
+	Y0 int `json:"y0"`
+	X1 int `json:"x1"`
+	Y1 int `json:"y1"`
+}
+
+// LayoutBlock is one city block. A block inside a parcel spanning several
+// blocks is drawn as part of it, so it lists no buildings, trees or park
+// areas of its own.
+type LayoutBlock struct {
+	BX        int              `json:"bx"`
+	BY        int              `json:"by"`
+	Rect      LayoutRect       `json:"rect"`
+	Parcel    *LayoutParcel    `json:"parcel,omitempty"`
+	Park      bool             `json:"park,omitempty"`
+	Parking   bool             `json:"parking,omitempty"`
+	ParkRects []LayoutRect     `json:"park_rects,omitempty"`
+	Buildings []LayoutBuilding `json:"buildings,omitempty"`
+	Trees     []LayoutTree     `json:"trees,omitempty"`
+}
+
+// LayoutParcel is the parcel a block belongs to, in blocks.
+type LayoutParcel struct {
+	Kind string `json:"kind"` // "park", "building" or "parking"
+	BX   int    `json:"bx"`
+	BY   int    `json:"by"`
+	W    int    `json:"w"`
+	H    int    `json:"h"`
+}
+
+type LayoutBuilding struct {
+	Color     string       `json:"color"`
+	Outline   string       `json:"outline"`
+	Height    int          `json:"height"`
+	Parts     []LayoutRect `json:"parts"`
+	RoofRim   bool         `json:"roof_rim,omitempty"`
+	RoofUnits []LayoutRect `json:"roof_units,omitempty"`
+	Doors     []LayoutRect `json:"doors,omitempty"` // set if the building has an inside
+}
+
+type LayoutTree struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Radius int `json:"radius"`
+}
+
+var parcelKindNames = [...]string{
+	parcelPark:     "park",
+	parcelBuilding: "building",
+	parcelParking:  "parking",
+}
+
+// Layout describes the plan w was generated from.
+func (w *World) Layout() *CityLayout {
+	l := &CityLayout{Seed: w.seed, Theme: w.Theme.Name, Width: w.Width, Height: w.Height, Map: w.Map != nil}
+	if w.Map != nil {
+		return l
+	}
+
+	// The ring road, then the open segments of the grid inside it.
+	in0, in1 := BorderThickness, BorderThickness+RoadWidth
+	l.Roads = append(l.Roads,
+		LayoutRect{X0: in0, Y0: in0, X1: w.Width - in0, Y1: in1},
+		LayoutRect{X0: in0, Y0: w.Height - in1, X1: w.Width - in0, Y1: w.Height - in0},
+		LayoutRect{X0: in0, Y0: in1, X1: in1, Y1: w.Height - in1},
+		LayoutRect{X0: w.Width - in1, Y0: in1, X1: w.Width - in0, Y1: w.Height - in1},
+	)
+	if !w.Theme.NoRoads {
+		if w.roads == nil {
+			w.roads = newRoadNetwork(w.seed, w.Theme, w.Width, w.Height)
+		}
+		clip := func(r LayoutRect) LayoutRect {
+			r.X0, r.X1 = max(r.X0, in1), min(r.X1, w.Width-in1)
+			r.Y0, r.Y1 = max(r.Y0, in1), min(r.Y1, w.Height-in1)
+			return r
+		}
+		rn := w.roads
+		for k := 1; k < rn.gridW; k++ {
+			for y := 0; y < rn.gridH; y++ {
+				r := clip(LayoutRect{X0: k * Pattern, Y0: y * Pattern, X1: k*Pattern + RoadWidth, Y1: (y + 1) * Pattern})
+				if rn.vertOpen[k][y] && r.X0 < r.X1 && r.Y0 < r.Y1 {
+					l.Roads = append(l.Roads, r)
+				}
+			}
+		}
+		for x := 0; x < rn.gridW; x++ {
+			for k := 1; k < rn.gridH; k++ {
+				r := clip(LayoutRect{X0: x * Pattern, Y0: k * Pattern, X1: (x + 1) * Pattern, Y1: k*Pattern + RoadWidth})
+				if rn.horzOpen[x][k] && r.X0 < r.X1 && r.Y0 < r.Y1 {
+					l.Roads = append(l.Roads, r)
+				}
+			}
+		}
+	}
+
+	tp := buildThemePalette(w.Theme)
+	if w.water == nil {
+		w.water = newWaterLayout(w.seed, w.Theme, w.Width, w.Height)
+	}
+	for by := 0; by <= floorDiv(w.Height-1, Pattern); by++ {
+		for bx := 0; bx <= floorDiv(w.Width-1, Pattern); bx++ {
+			x0 := bx*Pattern + RoadWidth + SidewalkWidth
+			y0 := by*Pattern + RoadWidth + SidewalkWidth
+			// Edge blocks cut by the border are left as plain lot.
+			if x0 < BorderThickness || y0 < BorderThickness ||
+				x0+BlockInner > w.Width-BorderThickness || y0+BlockInner > w.Height-BorderThickness {
+				continue
+			}
+			feat := genBlockFeatures(w.seed, bx, by, w.Theme, tp)
+			b := LayoutBlock{
+				BX: bx, BY: by,
+				Rect:    LayoutRect{X0: x0, Y0: y0, X1: x0 + BlockInner, Y1: y0 + BlockInner},
+				Park:    feat.IsPark,
+				Parking: feat.IsParking,
+			}
+			if p := feat.Parcel; p.Kind != parcelNone {
+				b.Parcel = &LayoutParcel{Kind: parcelKindNames[p.Kind], BX: p.AX, BY: p.AY, W: p.W, H: p.H}
+			}
+			single := b.Parcel == nil || b.Parcel.W*b.Parcel.H == 1
+			if single {
+				for _, r := range feat.ParkRects {
+					b.ParkRects = append(b.ParkRects, layoutRect(r))
+				}
+			}
+			for _, bs := range feat.Buildings {
+				// Merged parcels draw buildings of their own; of the
+				// block's, only those with an inside go over them.
+				placed := interiorPlaced(bs, x0, y0, w.Width, w.Height, w.water)
+				if !single && !placed {
+					continue
+				}
+				lb := LayoutBuilding{
+					Color:   hexColor(bs.Col),
+					Outline: hexColor(bs.Outline),
+					Height:  int(bs.H),
+					RoofRim: bs.RoofRim,
+				}
+				for _, r := range bs.Parts {
+					lb.Parts = append(lb.Parts, layoutRect(r))
+				}
+				for _, r := range bs.RoofUnits {
+					lb.RoofUnits = append(lb.RoofUnits, layoutRect(r))
+				}
+				if placed {
+					for _, r := range bs.Interior.Doors {
+						lb.Doors = append(lb.Doors, layoutRect(r))
+					}
+				}
+				b.Buildings = append(b.Buildings, lb)
+			}
+			if single {
+				for _, t := range feat.Trees {
+					b.Trees = append(b.Trees, LayoutTree{X: t.X, Y: t.Y, Radius: t.Radius})
+				}
+			}
+			l.Blocks = append(l.Blocks, b)
+		}
+	}
+	return l
+}
+
+func layoutRect(r rectI) LayoutRect {
+	return LayoutRect{X0: r.X0, Y0: r.Y0, X1: r.X1, Y1: r.Y1}
+}
+
+func hexColor(c RGB) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,240 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// Hand-authored maps. A map image paints every world pixel in one of the
+// mapLegend colours; an optional greyscale heightmap of the same size sets
+// the height of building and wall pixels, black keeping the default. The
+// outermost BorderThickness pixels are always the world border.
+
+type mapMaterial uint8
+
+const (
+	mapGrass mapMaterial = iota
+	mapRoad
+	mapRoadLine // road with the centre-line colour
+	mapSidewalk
+	mapLot
+	mapBuilding
+	mapTree
+	mapWater // open water (see Chunk.setWater)
+	mapWall  // indestructible
+)
+
+// mapLegend is the colour of every material in a map image.
+var mapLegend = map[RGB]mapMaterial{
+	{R: 0, G: 192, B: 0}:     mapGrass,
+	{R: 64, G: 64, B: 64}:    mapRoad,
+	{R: 255, G: 255, B: 255}: mapRoadLine,
+	{R: 160, G: 160, B: 160}: mapSidewalk,
+	{R: 192, G: 160, B: 96}:  mapLot,
+	{R: 192, G: 0, B: 0}:     mapBuilding,
+	{R: 0, G: 96, B: 0}:      mapTree,
+	{R: 0, G: 0, B: 255}:     mapWater,
+	{R: 0, G: 0, B: 0}:       mapWall,
+}
+
+// mapSpawnColor marks the snake's starting pixel, which is road.
+var mapSpawnColor = RGB{R: 255, G: 0, B: 255}
+
+// Heights of map pixels the heightmap leaves at zero. Tree canopies step up
+// by two towards their middle.
+const (
+	mapBuildingHeight = 24
+	mapTreeHeight     = 15
+)
+
+// WorldMap is a hand-authored world read from images (see LoadWorldMap).
+type WorldMap struct {
+	Width, Height int
+
+	mat            []mapMaterial // Width*Height, row-major
+	height         []uint8       // Width*Height; 0 = the material's default
+	spawnX, spawnY int
+}
+
+// LoadWorldMap reads a map image and, unless heightPath is empty, its
+// heightmap.
+func LoadWorldMap(path, heightPath string) (*WorldMap, error) {
+	img, err := readPNG(path)
+	if err != nil {
+		return nil, err
+	}
+	var heights image.Image
+	if heightPath != "" {
+		if heights, err = readPNG(heightPath); err != nil {
+			return nil, err
+		}
+	}
+	m, err := DecodeWorldMap(img, heights)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+// DecodeWorldMap builds a map from a legend-coloured image and an optional
+// heightmap of the same size.
+func DecodeWorldMap(img, heights image.Image) (*WorldMap, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < MinWorldSize || h < MinWorldSize || w > MaxWorldSize || h > MaxWorldSize {
+		return nil, fmt.Errorf("map is %dx%d, sides must be %d..%d", w, h, MinWorldSize, MaxWorldSize)
+	}
+	if heights != nil && (heights.Bounds().Dx() != w || heights.Bounds().Dy() != h) {
+		return nil, fmt.Errorf("heightmap is %dx%d, map is %dx%d",
+			heights.Bounds().Dx(), heights.Bounds().Dy(), w, h)
+	}
+
+	m := &WorldMap{
+		Width: w, Height: h,
+		mat:    make([]mapMaterial, w*h),
+		height: make([]uint8, w*h),
+		spawnX: -1, spawnY: -1,
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			col := RGB{R: c.R, G: c.G, B: c.B}
+			mat, ok := mapLegend[col]
+			if col == mapSpawnColor {
+				if m.spawnX >= 0 {
+					return nil, fmt.Errorf("second spawn marker at %d,%d", x, y)
+				}
+				m.spawnX, m.spawnY = x, y
+				mat, ok = mapRoad, true
+			}
+			if !ok {
+				return nil, fmt.Errorf("pixel %d,%d is #%02x%02x%02x, which is not in the legend", x, y, col.R, col.G, col.B)
+			}
+			m.mat[y*w+x] = mat
+			if heights != nil {
+				hb := heights.Bounds()
+				m.height[y*w+x] = color.GrayModel.Convert(heights.At(hb.Min.X+x, hb.Min.Y+y)).(color.Gray).Y
+			}
+		}
+	}
+	if m.spawnX < 0 {
+		m.spawnX, m.spawnY = m.nearestRoad(w/2, h/2)
+	}
+	return m, nil
+}
+
+// nearestRoad is the road pixel closest to x, y, or x, y on a map without
+// roads.
+func (m *WorldMap) nearestRoad(x, y int) (int, int) {
+	bx, by, best := x, y, -1
+	for py := 0; py < m.Height; py++ {
+		for px := 0; px < m.Width; px++ {
+			if mat := m.mat[py*m.Width+px]; mat != mapRoad && mat != mapRoadLine {
+				continue
+			}
+			if d := (px-x)*(px-x) + (py-y)*(py-y); best < 0 || d < best {
+				bx, by, best = px, py, d
+			}
+		}
+	}
+	return bx, by
+}
+
+// spawnPoint is where the snake starts: the spawn marker, or else the road
+// nearest the middle of the map.
+func (m *WorldMap) spawnPoint() (float64, float64) {
+	return float64(m.spawnX) + 0.5, float64(m.spawnY) + 0.5
+}
+
+// at is the material at x, y; outside the map it is wall.
+func (m *WorldMap) at(x, y int) mapMaterial {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return mapWall
+	}
+	return m.mat[y*m.Width+x]
+}
+
+// depth is how many pixels x, y lies inside its patch of material, up to
+// limit: 0 on the patch's edge.
+func (m *WorldMap) depth(x, y, limit int) int {
+	mat := m.at(x, y)
+	for d := 1; d <= limit; d++ {
+		for dy := -d; dy <= d; dy++ {
+			for dx := -d; dx <= d; dx++ {
+				if m.at(x+dx, y+dy) != mat {
+					return d - 1
+				}
+			}
+		}
+	}
+	return limit
+}
+
+// fillChunk paints the map's terrain into c.
+func (m *WorldMap) fillChunk(c *Chunk, worldSeed uint64, tp themePalette) {
+	baseX, baseY := c.WorldOrigin()
+	buildingCols := [3]RGB{tp.BuildingA, tp.BuildingB, tp.BuildingC}
+	treeCols := [3]RGB{tp.TreeBase, tp.TreeMid, tp.TreeTop}
+	for y := 0; y < ChunkSize; y++ {
+		wy := baseY + y
+		for x := 0; x < ChunkSize; x++ {
+			wx := baseX + x
+			i := c.idx(x, y)
+			if wx < BorderThickness || wy < BorderThickness || wx >= m.Width-BorderThickness || wy >= m.Height-BorderThickness {
+				c.set(i, Palette.Border, BorderHeight, ShadeLit, 1)
+				continue
+			}
+			j := wy*m.Width + wx
+			switch m.mat[j] {
+			case mapGrass:
+				c.set(i, grassColor(worldSeed, wx, wy, tp), 0, ShadeLit, 0)
+			case mapRoad:
+				c.set(i, tp.Road, 0, ShadeLit, 0)
+			case mapRoadLine:
+				c.set(i, roadStripeColor(tp), 0, ShadeLit, 0)
+			case mapSidewalk:
+				c.set(i, tp.Sidewalk, 0, ShadeLit, 0)
+			case mapLot:
+				c.set(i, tp.Lot, 0, ShadeLit, 0)
+			case mapBuilding:
+				h := m.heightOr(j, mapBuildingHeight)
+				col := buildingCols[int(h)%len(buildingCols)]
+				if m.depth(wx, wy, 1) == 0 {
+					col = col.Mul(200)
+				}
+				c.set(i, col, h, ShadeLit, 0)
+			case mapTree:
+				d := m.depth(wx, wy, 2)
+				c.set(i, treeCols[d], mapTreeHeight+uint8(2*d), ShadeLit, 0)
+			case mapWater:
+				c.setWater(i, waterColor(worldSeed, wx, wy, float64(m.depth(wx, wy, 1))+0.5, tp))
+			case mapWall:
+				c.set(i, tp.BuildingDark, m.heightOr(j, BorderHeight), ShadeLit, 1)
+			}
+		}
+	}
+}
+
+// heightOr is the heightmap value at j, or def where it is zero.
+func (m *WorldMap) heightOr(j int, def uint8) uint8 {
+	if m.height[j] == 0 {
+		return def
+	}
+	return m.height[j]
+}
@@ -0,0 +1,167 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mapImage is a w by h map image of grass with a road across the middle
+// row, a building at 10,10 and a pond at 20,20, each 4 pixels square.
+func mapImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	paint := func(x0, y0, x1, y1 int, c RGB) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				img.Set(x, y, color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255})
+			}
+		}
+	}
+	paint(0, 0, w, h, RGB{R: 0, G: 192})
+	paint(0, h/2, w, h/2+1, RGB{R: 64, G: 64, B: 64})
+	paint(10, 10, 14, 14, RGB{R: 192})
+	paint(20, 20, 24, 24, RGB{B: 255})
+	return img
+}
+
+func TestDecodeWorldMap(t *testing.T) {
+	size := MinWorldSize
+	for _, tc := range []struct {
+		name    string
+		img     func() image.Image
+		heights func() image.Image
+		want    string // error text; empty for a map that decodes
+		check   func(t *testing.T, m *WorldMap)
+	}{
+		{"road spawn", func() image.Image { return mapImage(size, size) }, nil, "", func(t *testing.T, m *WorldMap) {
+			if x, y := m.spawnPoint(); int(x) != size/2 || int(y) != size/2 {
+				t.Errorf("spawn %v,%v, want the road in the middle", x, y)
+			}
+			if m.at(11, 11) != mapBuilding || m.at(21, 21) != mapWater || m.at(-1, 5) != mapWall {
+				t.Errorf("materials %v %v %v", m.at(11, 11), m.at(21, 21), m.at(-1, 5))
+			}
+		}},
+		{"marked spawn", func() image.Image {
+			img := mapImage(size, size)
+			img.Set(30, 40, color.NRGBA{R: 255, B: 255, A: 255})
+			return img
+		}, nil, "", func(t *testing.T, m *WorldMap) {
+			if x, y := m.spawnPoint(); x != 30.5 || y != 40.5 || m.at(30, 40) != mapRoad {
+				t.Errorf("spawn %v,%v on %v, want the marker on road", x, y, m.at(30, 40))
+			}
+		}},
+		{"heights", func() image.Image { return mapImage(size, size) }, func() image.Image {
+			g := image.NewGray(image.Rect(0, 0, size, size))
+			g.SetGray(11, 11, color.Gray{Y: 40})
+			return g
+		}, "", func(t *testing.T, m *WorldMap) {
+			if h := m.heightOr(11*size+11, mapBuildingHeight); h != 40 {
+				t.Errorf("height %d, want 40", h)
+			}
+			if h := m.heightOr(12*size+12, mapBuildingHeight); h != mapBuildingHeight {
+				t.Errorf("black kept height %d, want %d", h, mapBuildingHeight)
+			}
+		}},
+		{"too small", func() image.Image { return mapImage(size-1, size) }, nil, "sides must be", nil},
+		{"too big", func() image.Image { return mapImage(size, MaxWorldSize+1) }, nil, "sides must be", nil},
+		{"heightmap size", func() image.Image { return mapImage(size, size) }, func() image.Image {
+			return image.NewGray(image.Rect(0, 0, size, size+1))
+		}, "heightmap is", nil},
+		{"two spawns", func() image.Image {
+			img := mapImage(size, size)
+			img.Set(3, 3, color.NRGBA{R: 255, B: 255, A: 255})
+			img.Set(4, 3, color.NRGBA{R: 255, B: 255, A: 255})
+			return img
+		}, nil, "second spawn marker at 4,3", nil},
+		{"off the legend", func() image.Image {
+			img := mapImage(size, size)
+			img.Set(7, 8, color.NRGBA{R: 1, G: 2, B: 3, A: 255})
+			return img
+		}, nil, "pixel 7,8 is #010203", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var heights image.Image
+			if tc.heights != nil {
+				heights = tc.heights()
+			}
+			m, err := DecodeWorldMap(tc.img(), heights)
+			if tc.want != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.want) {
+					t.Fatalf("error %v, want one mentioning %q", err, tc.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, m)
+		})
+	}
+}
+
+// TestWorldMapTerrain loads a map through a level pack and checks the
+// world it builds.
+func TestWorldMapTerrain(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "town.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, mapImage(MinWorldSize, MinWorldSize+Pattern)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	pack, err := ParseLevelPack([]byte(`{"levels":[{"map":"town.png","theme":"City"}]}`), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := levelConfig(t, pack, 1)
+	if w, h := cfg.worldSize(); w != MinWorldSize || h != MinWorldSize+Pattern {
+		t.Fatalf("world %dx%d, want the map's size", w, h)
+	}
+	w := newTestWorld(1, cfg.Theme, cfg.Map.Width, cfg.Map.Height)
+	w.Map = cfg.Map
+	w.Resize(cfg.Map.Width, cfg.Map.Height)
+	tp := buildThemePalette(w.Theme)
+
+	for _, tc := range []struct {
+		name     string
+		x, y     int
+		height   uint8
+		water    bool
+		colour   RGB // zero for any
+		walkable bool
+	}{
+		{"border", 0, 5, BorderHeight, false, Palette.Border, false},
+		{"grass", 5, 5, 0, false, RGB{}, true},
+		{"road", 40, w.Height / 2, 0, false, tp.Road, true},
+		{"building", 11, 11, mapBuildingHeight, false, RGB{}, false},
+		{"pond", 21, 21, 0, true, RGB{}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if h := w.HeightAt(tc.x, tc.y); h != tc.height {
+				t.Errorf("height %d, want %d", h, tc.height)
+			}
+			if w.IsWater(tc.x, tc.y) != tc.water {
+				t.Errorf("water %v, want %v", !tc.water, tc.water)
+			}
+			if tc.colour != (RGB{}) && w.ColorAt(tc.x, tc.y) != tc.colour {
+				t.Errorf("colour %v, want %v", w.ColorAt(tc.x, tc.y), tc.colour)
+			}
+			if w.IsWalkable(tc.x, tc.y) != tc.walkable {
+				t.Errorf("walkable %v, want %v", !tc.walkable, tc.walkable)
+			}
+		})
+	}
+
+	if _, err := ParseLevelPack([]byte(`{"levels":[{"map":"town.png","world_width":300}]}`), dir); err == nil {
+		t.Error("a map level took a world_width")
+	}
+	if _, err := ParseLevelPack([]byte(`{"levels":[{"map":"missing.png"}]}`), dir); err == nil {
+		t.Error("a missing map loaded")
+	}
+}
@@ -147,3 +147,15 @@ func (r *Rand) RangeF(min, max float64) float64 {
 }
 
 func rgbEq(a, b RGB) bool { return a.R == b.R && a.G == b.G && a.B == b.B }
+
+// repeatChar returns a string of n copies of ch.
+func repeatChar(ch byte, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = ch
+	}
+	return string(b)
+}
@@ -24,6 +24,8 @@ type Tank struct {
 	Alive     bool
 	FireTimer float64
 	Size      float32
+
+	LastX, LastY float64 // position at the previous simulation tick
 }
 
 type MilHeli struct {
@@ -41,6 +43,8 @@ type MilHeli struct {
 	FireTimer    float64
 	Burning      bool // true at low HP — crashes after BurnTimer reaches 3s
 	BurnTimer    float64
+
+	LastX, LastY float64 // position at the previous simulation tick
 }
 
 type Missile struct {
@@ -69,6 +73,8 @@ type MilTroop struct {
 	WaypointTimer float64
 	Kind          TroopKind
 	GroupID       uint32
+
+	LastX, LastY float64 // position at the previous simulation tick
 }
 
 type MilitarySystem struct {
@@ -84,6 +90,8 @@ type MilitarySystem struct {
 	MineTimer   float64
 	seed        uint64
 	nextGroupID uint32
+
+	Events *EventBus // receives gameplay events; may be nil
 }
 
 func NewMilitarySystem(seed uint64) *MilitarySystem {
@@ -148,14 +156,17 @@ func (ms *MilitarySystem) Update(dt float64, snake *Snake, world *World, ps *Par
 
 		nx := t.X + math.Cos(t.Heading)*t.Speed*dt
 		ny := t.Y + math.Sin(t.Heading)*t.Speed*dt
-		// Tanks plow through thin obstacles.
-		if world.HeightAt(int(math.Round(nx)), int(math.Round(ny))) > 0 {
+		world.tyreMarks(t.X, t.Y, t.Heading, t.Size, 0.08)
+		// Tanks plow through thin obstacles, but stop at the water's edge.
+		if ix, iy := int(math.Round(nx)), int(math.Round(ny)); world.IsWater(ix, iy) {
+			nx, ny = t.X, t.Y
+		} else if !world.IsWalkable(ix, iy) {
 			if ps != nil {
-				SpawnExplosionWithShockwave(int(math.Round(nx)), int(math.Round(ny)), RGB{100, 90, 70}, 0.15, 0, world, ps)
+				SpawnExplosionWithShockwave(ix, iy, RGB{100, 90, 70}, 0.15, 0, world, ps)
 			}
 		}
-		t.X = clampF(nx, 0, float64(WorldWidth-1))
-		t.Y = clampF(ny, 0, float64(WorldHeight-1))
+		t.X = clampF(nx, 0, float64(world.Width-1))
+		t.Y = clampF(ny, 0, float64(world.Height-1))
 
 		// Turret tracks snake head directly.
 		t.TurretAng = math.Atan2(hy-t.Y, hx-t.X)
@@ -214,8 +225,8 @@ func (ms *MilitarySystem) Update(dt float64, snake *Snake, world *World, ps *Par
 		}
 
 		h.CircleAngle += 0.55 * dt
-		h.X = clampF(h.CenterX+math.Cos(h.CircleAngle)*h.CircleRadius, 2, float64(WorldWidth-2))
-		h.Y = clampF(h.CenterY+math.Sin(h.CircleAngle)*h.CircleRadius, 2, float64(WorldHeight-2))
+		h.X = clampF(h.CenterX+math.Cos(h.CircleAngle)*h.CircleRadius, 2, float64(world.Width-2))
+		h.Y = clampF(h.CenterY+math.Sin(h.CircleAngle)*h.CircleRadius, 2, float64(world.Height-2))
 		h.Heading = h.CircleAngle + math.Pi/2
 		h.RotorAngle += 14.0 * dt
 
@@ -325,7 +336,7 @@ func (ms *MilitarySystem) Update(dt float64, snake *Snake, world *World, ps *Par
 		}
 
 		hit := false
-		if ix < 0 || iy < 0 || ix >= WorldWidth || iy >= WorldHeight {
+		if ix < 0 || iy < 0 || ix >= world.Width || iy >= world.Height {
 			hit = true
 		} else if world.HeightAt(ix, iy) > 0 {
 			radius := 4
@@ -389,6 +400,7 @@ func (ms *MilitarySystem) Update(dt float64, snake *Snake, world *World, ps *Par
 	}
 
 	// --- Troops ---
+	hideout := world.interiorAt(hx, hy) // the building the snake is in, if any
 	for i := range ms.Troops {
 		t := &ms.Troops[i]
 		if !t.Alive {
@@ -444,20 +456,28 @@ func (ms *MilitarySystem) Update(dt float64, snake *Snake, world *World, ps *Par
 			}
 			spd = 16.0
 		}
+		// The snake has holed up indoors: half the squad breaks in through
+		// the nearest door while the other half flanks through another.
+		if hideout != nil && t.Kind != TroopSniper && !hideout.inside(t.X, t.Y) {
+			tx, ty := hideout.breachTarget(t.X, t.Y, hx, hy, i%2 == 1)
+			if td := math.Hypot(tx-t.X, ty-t.Y); td > 0.1 {
+				moveX, moveY = (tx-t.X)/td, (ty-t.Y)/td
+			}
+		}
 
 		if moveX != 0 || moveY != 0 {
 			nx := t.X + moveX*spd*dt
 			ny := t.Y + moveY*spd*dt
-			// Building collision: only move if destination is clear.
-			if world.HeightAt(int(math.Round(nx)), int(math.Round(ny))) > 0 {
+			// Building and water collision: only move if destination is clear.
+			if !world.IsWalkable(int(math.Round(nx)), int(math.Round(ny))) {
 				// Blocked: pick a new walkable position.
 				r := NewRand(ms.seed ^ uint64(i)*0xC0DE ^ uint64(t.StuckTimer*100))
 				for range 12 {
 					tx := int(math.Round(t.X)) + r.Range(-8, 8)
 					ty := int(math.Round(t.Y)) + r.Range(-8, 8)
-					if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && world.HeightAt(tx, ty) == 0 {
-						t.X = float64(tx) + 0.5
-						t.Y = float64(ty) + 0.5
+					if world.IsWalkable(tx, ty) {
+						t.X = float64(tx)
+						t.Y = float64(ty)
 						break
 					}
 				}
@@ -472,7 +492,7 @@ func (ms *MilitarySystem) Update(dt float64, snake *Snake, world *World, ps *Par
 					for range 20 {
 						tx := int(math.Round(hx)) + r.Range(-20, 20)
 						ty := int(math.Round(hy)) + r.Range(-20, 20)
-						if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && world.HeightAt(tx, ty) == 0 {
+						if world.IsWalkable(tx, ty) {
 							t.X = float64(tx)
 							t.Y = float64(ty)
 							t.StuckTimer = 0
@@ -547,7 +567,7 @@ func (ms *MilitarySystem) Update(dt float64, snake *Snake, world *World, ps *Par
 			my := hy + math.Sin(ang)*d
 			ix := int(math.Round(mx))
 			iy := int(math.Round(my))
-			if ix >= 0 && iy >= 0 && ix < WorldWidth && iy < WorldHeight && world.HeightAt(ix, iy) == 0 {
+			if world.IsWalkable(ix, iy) {
 				ms.Mines = append(ms.Mines, Mine{X: mx, Y: my, Alive: true})
 				break
 			}
@@ -573,7 +593,7 @@ func (ms *MilitarySystem) spawnReinforcements(snake *Snake, world *World, r *Ran
 		}
 	}
 	if aliveTanks < maxTanks {
-		sx, sy := edgeSpawnPos(hx, hy, r)
+		sx, sy := edgeSpawnPos(world, hx, hy, r)
 		ms.Tanks = append(ms.Tanks, Tank{
 			X: sx, Y: sy,
 			Heading:   math.Atan2(hy-sy, hx-sx),
@@ -626,7 +646,7 @@ func (ms *MilitarySystem) spawnReinforcements(snake *Snake, world *World, r *Ran
 		}
 	}
 	if aliveTroops < maxTroops {
-		sx, sy := edgeSpawnPos(hx, hy, r)
+		sx, sy := edgeSpawnPos(world, hx, hy, r)
 		ms.nextGroupID++
 
 		kind := TroopRegular
@@ -642,7 +662,7 @@ func (ms *MilitarySystem) spawnReinforcements(snake *Snake, world *World, r *Ran
 			for range 20 {
 				tx := int(math.Round(sx)) + r.Range(-5, 5)
 				ty := int(math.Round(sy)) + r.Range(-5, 5)
-				if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && world.HeightAt(tx, ty) == 0 {
+				if world.IsWalkable(tx, ty) {
 					hp := 8.0
 					if kind == TroopMinigun {
 						hp = 12.0
@@ -667,7 +687,8 @@ func (ms *MilitarySystem) spawnReinforcements(snake *Snake, world *World, r *Ran
 
 func (ms *MilitarySystem) RemoveDead() {
 	for i := 0; i < len(ms.Tanks); {
-		if !ms.Tanks[i].Alive {
+		if t := &ms.Tanks[i]; !t.Alive {
+			ms.Events.Emit(Event{Type: EventTankKilled, X: t.X, Y: t.Y})
 			ms.Tanks[i] = ms.Tanks[len(ms.Tanks)-1]
 			ms.Tanks = ms.Tanks[:len(ms.Tanks)-1]
 		} else {
@@ -675,7 +696,8 @@ func (ms *MilitarySystem) RemoveDead() {
 		}
 	}
 	for i := 0; i < len(ms.Helis); {
-		if !ms.Helis[i].Alive {
+		if h := &ms.Helis[i]; !h.Alive {
+			ms.Events.Emit(Event{Type: EventHeliKilled, X: h.X, Y: h.Y, Data: 1})
 			ms.Helis[i] = ms.Helis[len(ms.Helis)-1]
 			ms.Helis = ms.Helis[:len(ms.Helis)-1]
 		} else {
@@ -691,7 +713,8 @@ func (ms *MilitarySystem) RemoveDead() {
 		}
 	}
 	for i := 0; i < len(ms.Troops); {
-		if !ms.Troops[i].Alive {
+		if t := &ms.Troops[i]; !t.Alive {
+			ms.Events.Emit(Event{Type: EventSoldierKilled, X: t.X, Y: t.Y})
 			ms.Troops[i] = ms.Troops[len(ms.Troops)-1]
 			ms.Troops = ms.Troops[:len(ms.Troops)-1]
 		} else {
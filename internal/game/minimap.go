@@ -0,0 +1,115 @@
+package game
+
+import "slices"
+
+const (
+	minimapCells   = 64  // cells along the longer world side
+	minimapRefresh = 1.0 // seconds between resamples, so fires and craters show up
+)
+
+// minimapFog colours the cells of chunks that have never been loaded.
+var minimapFog = RGB{R: 24, G: 24, B: 28}
+
+// Minimap is a coarse overview of a world too large to fit on screen.
+type Minimap struct {
+	Cols, Rows int
+	Cell       int   // world pixels per cell
+	Colors     []RGB // Cols*Rows, row-major
+
+	// World the colours were sampled from.
+	seed          uint64
+	width, height int
+	timer         float64
+}
+
+// Update resamples the world every minimapRefresh seconds, and at once when
+// a new world has been generated. Only loaded chunks are sampled; cells over
+// unloaded ones keep what they last showed, or fog before they were seen.
+func (m *Minimap) Update(w *World, dt float64) {
+	m.timer -= dt
+	if m.timer > 0 && m.seed == w.seed && m.width == w.Width && m.height == w.Height {
+		return
+	}
+	m.timer = minimapRefresh
+	if m.seed != w.seed || m.width != w.Width || m.height != w.Height {
+		m.seed, m.width, m.height = w.seed, w.Width, w.Height
+		m.Cell = max((max(w.Width, w.Height)+minimapCells-1)/minimapCells, 1)
+		m.Cols = (w.Width + m.Cell - 1) / m.Cell
+		m.Rows = (w.Height + m.Cell - 1) / m.Cell
+		m.Colors = slices.Grow(m.Colors[:0], m.Cols*m.Rows)[:m.Cols*m.Rows]
+		for i := range m.Colors {
+			m.Colors[i] = minimapFog
+		}
+	}
+
+	// Average four samples per cell so thin roads don't flicker in and out.
+	q := max(m.Cell/4, 0)
+	for cy := 0; cy < m.Rows; cy++ {
+		for cx := 0; cx < m.Cols; cx++ {
+			x0, y0 := cx*m.Cell, cy*m.Cell
+			var r, g, b, n int
+			for _, o := range [4][2]int{{q, q}, {m.Cell - 1 - q, q}, {q, m.Cell - 1 - q}, {m.Cell - 1 - q, m.Cell - 1 - q}} {
+				col, ok := w.LoadedColorAt(min(x0+o[0], w.Width-1), min(y0+o[1], w.Height-1))
+				if ok {
+					r, g, b, n = r+int(col.R), g+int(col.G), b+int(col.B), n+1
+				}
+			}
+			if n > 0 {
+				m.Colors[cy*m.Cols+cx] = RGB{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n)}
+			}
+		}
+	}
+}
+
+// Sprites appends the minimap to buf as screen-space sprites (see
+// ScreenCamera) with its top-left corner at x, y and px screen pixels per
+// cell: the terrain, the outline of view, the objective zone and a dot for
+// every living snake.
+func (m *Minimap) Sprites(buf []float32, x, y, px float32, view RectF, obj *Objective, snakes []*Snake) []float32 {
+	if m.Cols == 0 {
+		return buf
+	}
+	scale := px / float32(m.Cell)
+	toScreen := func(wx, wy float64) (float32, float32) {
+		return x + float32(wx)*scale, y + float32(wy)*scale
+	}
+
+	for cy := 0; cy < m.Rows; cy++ {
+		for cx := 0; cx < m.Cols; cx++ {
+			col := m.Colors[cy*m.Cols+cx]
+			buf = append(buf,
+				x+(float32(cx)+0.5)*px, y+(float32(cy)+0.5)*px, px,
+				float32(col.R)/255, float32(col.G)/255, float32(col.B)/255, 0.85, 0)
+		}
+	}
+
+	// View outline, one screen pixel wide.
+	vx0, vy0 := toScreen(clampF(view.X0, 0, float64(m.width)), clampF(view.Y0, 0, float64(m.height)))
+	vx1, vy1 := toScreen(clampF(view.X1, 0, float64(m.width)), clampF(view.Y1, 0, float64(m.height)))
+	for sx := vx0; sx <= vx1; sx++ {
+		buf = append(buf, sx, vy0, 1, 1, 1, 1, 0.9, 0, sx, vy1, 1, 1, 1, 1, 0.9, 0)
+	}
+	for sy := vy0; sy <= vy1; sy++ {
+		buf = append(buf, vx0, sy, 1, 1, 1, 1, 0.9, 0, vx1, sy, 1, 1, 1, 1, 0.9, 0)
+	}
+
+	if obj != nil && obj.HasZone() {
+		zx, zy := toScreen(obj.ZoneX, obj.ZoneY)
+		buf = append(buf, zx, zy, max(4, float32(obj.ZoneR*2)*scale), 0.3, 1, 0.45, 1, 0)
+	}
+	for _, s := range snakes {
+		if s == nil || !s.Alive {
+			continue
+		}
+		hx, hy := s.Head()
+		sx, sy := toScreen(hx, hy)
+		col := s.evoHeadColor()
+		buf = append(buf, sx, sy, 4, float32(col.R)/255, float32(col.G)/255, float32(col.B)/255, 1, 0)
+	}
+	return buf
+}
+
+// Size is the minimap's size in screen pixels at px pixels per cell.
+func (m *Minimap) Size(px float32) (float32, float32) {
+	return float32(m.Cols) * px, float32(m.Rows) * px
+}
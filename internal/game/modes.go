@@ -0,0 +1,169 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// GameMode selects the rules a run is played by.
+type GameMode int
+
+const (
+	ModeCampaign    GameMode = iota // level after level from the level pack
+	ModeEndless                     // respawning city, wanted ramps until death
+	ModeScoreAttack                 // fixed clock on the map of the day
+	ModeVersus                      // local players on one screen, last snake standing
+	GameModeCount
+)
+
+var gameModeNames = [GameModeCount]string{
+	ModeCampaign:    "Campaign",
+	ModeEndless:     "Endless",
+	ModeScoreAttack: "Score Attack",
+	ModeVersus:      "Versus",
+}
+
+// gameModeKeys name the modes in the profile file.
+var gameModeKeys = [GameModeCount]string{
+	ModeCampaign:    "campaign",
+	ModeEndless:     "endless",
+	ModeScoreAttack: "score_attack",
+	ModeVersus:      "versus",
+}
+
+const (
+	// Endless: survival points per second alive, one wanted star per
+	// endlessWantedRamp seconds and a top-up of the city every
+	// endlessRespawnInterval seconds that grows by one ped per endlessPedRamp.
+	endlessLevel           = 3
+	endlessSurvivalBonus   = 10
+	endlessWantedRamp      = 45.0
+	endlessRespawnInterval = 2.0
+	endlessPedRamp         = 6.0
+	endlessMaxPeds         = 300
+	endlessPedWave         = 12
+	endlessCarWave         = 3
+
+	// Score attack: the clock and the bonus for HP left at the buzzer.
+	ScoreAttackDuration = 180.0
+	scoreAttackLevel    = 6
+	scoreAttackHPBonus  = 2000
+)
+
+func (m GameMode) String() string {
+	if m < 0 || m >= GameModeCount {
+		return fmt.Sprintf("GameMode(%d)", int(m))
+	}
+	return gameModeNames[m]
+}
+
+// DailySeed is the score attack seed for the UTC day containing t, so every
+// player gets the same map on the same day.
+func DailySeed(t time.Time) uint64 {
+	y, m, d := t.UTC().Date()
+	return splitmix64(uint64(y*10000+int(m)*100+d) ^ 0x5C0BEA77AC4D)
+}
+
+// CycleMode steps the menu game mode by dir, wrapping. Versus is skipped
+// on frontends without a second input scheme.
+func (s *GameSession) CycleMode(dir int) {
+	n := int(GameModeCount)
+	s.Mode = GameMode(((int(s.Mode)+dir)%n + n) % n)
+	if s.Mode == ModeVersus && !localVersus {
+		s.CycleMode(dir)
+	}
+}
+
+// menuStartLevel is the level a new run of the selected mode begins at.
+func (s *GameSession) menuStartLevel() int {
+	switch s.Mode {
+	case ModeEndless:
+		return endlessLevel
+	case ModeScoreAttack:
+		return scoreAttackLevel
+	case ModeVersus:
+		return versusLevel
+	}
+	return clamp(s.MenuLevel, 1, max(s.MaxStartLevel, 1))
+}
+
+// modeLevelConfig returns the level settings for mode. Only the campaign
+// reads the level pack; the other modes have fixed rules.
+func modeLevelConfig(mode GameMode, level int) LevelConfig {
+	switch mode {
+	case ModeEndless:
+		return LevelConfig{Peds: 60, Cars: 12, ArmedPeds: 4, InfectedPeds: 4, BonusBoxes: 4, Theme: ThemeCity, WantedMax: WantedMax}
+	case ModeScoreAttack:
+		return LevelConfig{Peds: 140, Cars: 20, ArmedPeds: 12, InfectedPeds: 12, BonusBoxes: 5, Theme: ThemeCity, WantedMax: WantedMax}
+	case ModeVersus:
+		return versusLevelConfig()
+	}
+	cfg, err := GetLevelConfig(level)
+	if err != nil {
+		// ParseLevelPack resolves every level, scaled ones included, so
+		// only a pack built around it can get here.
+		panic(err)
+	}
+	return cfg
+}
+
+// modeScore applies the mode's scoring rules on top of the snake's score.
+func (s *GameSession) modeScore(snake *Snake) int {
+	if s.Mode == ModeEndless {
+		return snake.Score + int(s.Elapsed)*endlessSurvivalBonus
+	}
+	return snake.Score
+}
+
+// ObjectiveLabel is the HUD line for the current level: the objective's
+// progress in the campaign, the clock in the other modes.
+func (s *GameSession) ObjectiveLabel(peds *PedestrianSystem, snake *Snake) string {
+	switch s.Mode {
+	case ModeEndless:
+		return fmt.Sprintf("Survived: %.0fs", math.Floor(s.Elapsed))
+	case ModeScoreAttack:
+		return fmt.Sprintf("Time left: %.0fs", math.Max(0, math.Ceil(ScoreAttackDuration-s.Elapsed)))
+	case ModeVersus:
+		return fmt.Sprintf("Time left: %.0fs", math.Max(0, math.Ceil(VersusDuration-s.Elapsed)))
+	}
+	return s.Objective.Label(peds, snake)
+}
+
+// updateEndless keeps the endless city populated and ramps up the wanted
+// level with time survived.
+func (sim *Simulation) updateEndless(dt float64) {
+	s := sim.Session
+	if snake := sim.Snake; snake != nil && snake.Alive {
+		snake.WantedLevel = math.Max(snake.WantedLevel, math.Min(WantedMax, s.Elapsed/endlessWantedRamp))
+	}
+
+	s.RespawnTimer -= dt
+	if s.RespawnTimer > 0 {
+		return
+	}
+	s.RespawnTimer = endlessRespawnInterval
+	s.RespawnWave++
+	cfg := modeLevelConfig(ModeEndless, s.CurrentLevel)
+	mix := s.RespawnWave * 0x9E3779B185EBCA87
+
+	// SpawnRandom fills up to a total count from its seed; vary the seed
+	// so every wave lands somewhere new.
+	peds := sim.Peds
+	wantPeds := min(cfg.Peds+int(s.Elapsed/endlessPedRamp), endlessMaxPeds)
+	if len(peds.P) < wantPeds {
+		seed := peds.seed
+		peds.seed = seed ^ mix
+		peds.SpawnRandom(sim.World, min(wantPeds, len(peds.P)+endlessPedWave))
+		peds.seed = seed
+	}
+
+	traffic := sim.Traffic
+	if !sim.World.Theme.NoRoads && len(traffic.Cars) < cfg.Cars {
+		seed := traffic.seed
+		traffic.seed = seed ^ mix
+		traffic.SpawnRandom(sim.World, min(cfg.Cars-len(traffic.Cars), endlessCarWave))
+		traffic.seed = seed
+		traffic.RebuildGrid()
+	}
+}
@@ -0,0 +1,29 @@
+package game
+
+import "testing"
+
+func TestObjectiveLabel(t *testing.T) {
+	peds := crowd(Pedestrian{Alive: true}, Pedestrian{Alive: true}, Pedestrian{})
+	for _, tc := range []struct {
+		name    string
+		mode    GameMode
+		obj     Objective
+		elapsed float64
+		want    string
+	}{
+		{"campaign humans", ModeCampaign, Objective{}, 12, "Humans: 2"},
+		{"campaign survive", ModeCampaign, Objective{Kind: ObjectiveSurvive, Target: 60, Progress: 12.5}, 12.5, "Survive: 48s"},
+		{"endless", ModeEndless, Objective{}, 95.7, "Survived: 95s"},
+		{"score attack", ModeScoreAttack, Objective{}, 30.2, "Time left: 150s"},
+		{"score attack over", ModeScoreAttack, Objective{}, ScoreAttackDuration + 3, "Time left: 0s"},
+		{"versus", ModeVersus, Objective{}, 100.5, "Time left: 80s"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewGameSession()
+			s.Mode, s.Objective, s.Elapsed = tc.mode, tc.obj, tc.elapsed
+			if got := s.ObjectiveLabel(peds, nil); got != tc.want {
+				t.Errorf("label %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ObjectiveKind selects how a level is won.
+type ObjectiveKind int
+
+const (
+	ObjectiveEatAll    ObjectiveKind = iota // eat every human
+	ObjectiveSurvive                        // stay alive for Target seconds
+	ObjectiveScore                          // reach Target points
+	ObjectiveBuildings                      // burn or bring down Target buildings
+	ObjectiveKillTank                       // destroy Target tanks
+	ObjectiveKillHeli                       // shoot down Target helicopters
+	ObjectiveEatClean                       // eat every healthy human; eating an infected one fails
+	ObjectiveEscape                         // reach the zone while the wanted level is maxed
+	ObjectiveZone                           // reach the marked zone
+	ObjectiveContain                        // eat every infected human before Target of them are
+	ObjectiveKindCount
+)
+
+// objectiveKeys names every ObjectiveKind for level pack files ("win").
+var objectiveKeys = [ObjectiveKindCount]string{
+	ObjectiveEatAll:    "eat_all",
+	ObjectiveSurvive:   "survive",
+	ObjectiveScore:     "score",
+	ObjectiveBuildings: "destroy_buildings",
+	ObjectiveKillTank:  "kill_tank",
+	ObjectiveKillHeli:  "kill_heli",
+	ObjectiveEatClean:  "eat_clean",
+	ObjectiveEscape:    "escape",
+	ObjectiveZone:      "reach_zone",
+	ObjectiveContain:   "contain_outbreak",
+}
+
+// objectiveDefaultTarget is used when a level sets no "win_target".
+var objectiveDefaultTarget = [ObjectiveKindCount]float64{
+	ObjectiveSurvive:   90,
+	ObjectiveScore:     5000,
+	ObjectiveBuildings: 3,
+	ObjectiveKillTank:  1,
+	ObjectiveKillHeli:  1,
+	ObjectiveContain:   0.5,
+}
+
+const (
+	objectiveZoneRadius  = 7.0
+	objectiveZoneMinDist = 70.0 // from the snake spawn
+)
+
+// Objective is the win condition of the current level and its progress.
+type Objective struct {
+	Kind     ObjectiveKind
+	Target   float64 // seconds, points, kills or outbreak share depending on Kind
+	Progress float64
+
+	// Marked zone for ObjectiveZone and ObjectiveEscape, in world pixels.
+	ZoneX, ZoneY, ZoneR float64
+
+	Failed bool // ObjectiveEatClean: a sick human was eaten; ObjectiveContain: the outbreak got out of hand
+
+	// ObjectiveBuildings: the buildings counted so far, so one that burns
+	// and then falls, or burns in several places, counts once.
+	Buildings []int
+}
+
+func objectiveKindByKey(key string) ObjectiveKind {
+	for k, name := range objectiveKeys {
+		if name == key {
+			return ObjectiveKind(k)
+		}
+	}
+	return -1
+}
+
+// HasZone reports whether the objective marks a zone in the world.
+func (o *Objective) HasZone() bool {
+	return o.Kind == ObjectiveZone || o.Kind == ObjectiveEscape
+}
+
+// newObjective sets up the objective for a freshly generated level.
+func newObjective(kind ObjectiveKind, target float64, world *World, seed uint64) Objective {
+	o := Objective{Kind: kind, Target: target}
+	if o.HasZone() {
+		o.ZoneX, o.ZoneY = pickObjectiveZone(world, seed)
+		o.ZoneR = objectiveZoneRadius
+	}
+	return o
+}
+
+// pickObjectiveZone finds an open spot well away from the snake spawn.
+func pickObjectiveZone(world *World, seed uint64) (float64, float64) {
+	r := NewRand(seed ^ 0x20E5EED)
+	cx, cy := float64(world.Width)/2, float64(world.Height)/2
+	if world.Map != nil {
+		cx, cy = world.Map.spawnPoint()
+	}
+	margin := objectiveZoneRadius + 4
+	bx, by := margin, margin
+	for range 400 {
+		x := r.RangeF(margin, float64(world.Width)-margin)
+		y := r.RangeF(margin, float64(world.Height)-margin)
+		if math.Hypot(x-cx, y-cy) < objectiveZoneMinDist {
+			continue
+		}
+		bx, by = x, y
+		if !world.IsBlocked(int(x), int(y)) &&
+			!world.IsBlocked(int(x-3), int(y)) && !world.IsBlocked(int(x+3), int(y)) &&
+			!world.IsBlocked(int(x), int(y-3)) && !world.IsBlocked(int(x), int(y+3)) {
+			break
+		}
+	}
+	return bx, by
+}
+
+// subscribeObjective counts the events objectives track.
+func (s *GameSession) subscribeObjective(eb *EventBus) {
+	count := func(kind ObjectiveKind) EventHandler {
+		return func(Event) {
+			if s.State == StatePlaying && s.Objective.Kind == kind {
+				s.Objective.Progress++
+			}
+		}
+	}
+	eb.Subscribe(EventBuildingBurned, s.countBuilding)
+	eb.Subscribe(EventBuildingCollapsed, s.countBuilding)
+	eb.Subscribe(EventTankKilled, count(ObjectiveKillTank))
+	eb.Subscribe(EventHeliKilled, count(ObjectiveKillHeli))
+	eb.Subscribe(EventPedEaten, func(e Event) {
+		if s.State == StatePlaying && s.Objective.Kind == ObjectiveEatClean && e.Infection.Sick() {
+			s.Objective.Failed = true
+		}
+	})
+}
+
+// countBuilding counts a building burned or brought down toward
+// ObjectiveBuildings, unless it has been counted already.
+func (s *GameSession) countBuilding(e Event) {
+	o := &s.Objective
+	if s.State != StatePlaying || o.Kind != ObjectiveBuildings {
+		return
+	}
+	if e.Building != 0 {
+		if slices.Contains(o.Buildings, e.Building) {
+			return
+		}
+		o.Buildings = append(o.Buildings, e.Building)
+	}
+	o.Progress++
+}
+
+// complete reports whether the objective has been met.
+func (o *Objective) complete(session *GameSession, peds *PedestrianSystem, snake *Snake) bool {
+	switch o.Kind {
+	case ObjectiveEatAll:
+		return peds.AliveCount() == 0
+	case ObjectiveScore:
+		o.Progress = float64(session.Score)
+		return o.Progress >= o.Target
+	case ObjectiveSurvive, ObjectiveBuildings, ObjectiveKillTank, ObjectiveKillHeli:
+		return o.Progress >= o.Target
+	case ObjectiveEatClean:
+		return healthyAliveCount(peds) == 0
+	case ObjectiveContain:
+		o.Progress = peds.Outbreak()
+		if o.Progress >= o.Target {
+			o.Failed = true
+			return false
+		}
+		return infectedAliveCount(peds) == 0
+	case ObjectiveEscape:
+		if snake.WantedLevel < WantedMax {
+			return false
+		}
+		fallthrough
+	case ObjectiveZone:
+		hx, hy := snake.Head()
+		return math.Hypot(hx-o.ZoneX, hy-o.ZoneY) <= o.ZoneR
+	}
+	return false
+}
+
+func healthyAliveCount(peds *PedestrianSystem) int {
+	n := 0
+	for i := range peds.P {
+		if peds.P[i].Alive && !peds.P[i].Infection.Sick() {
+			n++
+		}
+	}
+	return n
+}
+
+// Label is the HUD line describing objective progress.
+func (o *Objective) Label(peds *PedestrianSystem, snake *Snake) string {
+	switch o.Kind {
+	case ObjectiveSurvive:
+		return fmt.Sprintf("Survive: %.0fs", math.Max(0, math.Ceil(o.Target-o.Progress)))
+	case ObjectiveScore:
+		return fmt.Sprintf("Score: %.0f/%.0f", o.Progress, o.Target)
+	case ObjectiveBuildings:
+		return fmt.Sprintf("Buildings: %.0f/%.0f", o.Progress, o.Target)
+	case ObjectiveKillTank:
+		return fmt.Sprintf("Tanks: %.0f/%.0f", o.Progress, o.Target)
+	case ObjectiveKillHeli:
+		return fmt.Sprintf("Helis: %.0f/%.0f", o.Progress, o.Target)
+	case ObjectiveEatClean:
+		if peds == nil {
+			return "Healthy humans"
+		}
+		return fmt.Sprintf("Healthy: %d", healthyAliveCount(peds))
+	case ObjectiveContain:
+		if peds == nil {
+			return "Contain the outbreak"
+		}
+		return fmt.Sprintf("Infected: %d", infectedAliveCount(peds))
+	case ObjectiveEscape:
+		if snake != nil && snake.WantedLevel < WantedMax {
+			return "Max WANTED, then escape"
+		}
+		return "Escape " + o.zoneDirection(snake)
+	case ObjectiveZone:
+		return "Zone " + o.zoneDirection(snake)
+	}
+	if peds == nil {
+		return "Humans"
+	}
+	return fmt.Sprintf("Humans: %d", peds.AliveCount())
+}
+
+// zoneDirection gives compass direction and distance from the snake head.
+func (o *Objective) zoneDirection(snake *Snake) string {
+	if snake == nil {
+		return ""
+	}
+	hx, hy := snake.Head()
+	dx, dy := o.ZoneX-hx, o.ZoneY-hy
+	dirs := [8]string{"E", "SE", "S", "SW", "W", "NW", "N", "NE"}
+	oct := int(math.Round(math.Atan2(dy, dx)/(math.Pi/4))+8) % 8
+	return fmt.Sprintf("%s %.0fm", dirs[oct], math.Hypot(dx, dy))
+}
+
+// SpawnMarker sends glowing sparks up around the zone so it can be found
+// in the world.
+func (o *Objective) SpawnMarker(ps *ParticleSystem, dt, now float64, snake *Snake) {
+	if ps == nil || !o.HasZone() {
+		return
+	}
+	col := RGB{R: 80, G: 255, B: 120}
+	if o.Kind == ObjectiveEscape && (snake == nil || snake.WantedLevel < WantedMax) {
+		col = RGB{R: 120, G: 120, B: 140}
+	}
+	rr := NewRand(uint64(o.ZoneX*53+o.ZoneY*37) ^ uint64(now*40))
+	for range 6 {
+		if rr.RangeF(0, 1) > 5.0*dt {
+			continue
+		}
+		ang := rr.RangeF(0, 2*math.Pi)
+		ps.Add(Particle{
+			X: o.ZoneX + math.Cos(ang)*o.ZoneR,
+			Y: o.ZoneY + math.Sin(ang)*o.ZoneR,
+			Z: 0, VZ: rr.RangeF(8, 16),
+			Size:    rr.RangeF(0.15, 0.3),
+			MaxLife: rr.RangeF(0.5, 0.9),
+			Col:     col,
+			Kind:    ParticleGlow,
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package game
+
+import "testing"
+
+func TestObjectiveBuildingsCountOnce(t *testing.T) {
+	burned := func(b int) Event { return Event{Type: EventBuildingBurned, Building: b} }
+	fell := func(b int) Event { return Event{Type: EventBuildingCollapsed, Building: b} }
+	for _, tc := range []struct {
+		name   string
+		kind   ObjectiveKind
+		events []Event
+		want   float64
+	}{
+		{"one each", ObjectiveBuildings, []Event{burned(100), fell(200), burned(300)}, 3},
+		{"burns then falls", ObjectiveBuildings, []Event{burned(100), fell(100)}, 1},
+		{"burns in two places", ObjectiveBuildings, []Event{burned(100), burned(100), burned(200)}, 2},
+		{"falls in pieces", ObjectiveBuildings, []Event{fell(100), fell(100)}, 1},
+		{"untraceable ones all count", ObjectiveBuildings, []Event{burned(0), fell(0)}, 2},
+		{"other objective", ObjectiveKillTank, []Event{burned(100), fell(200)}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewGameSession()
+			eb := NewEventBus()
+			s.subscribeObjective(eb)
+			s.State = StatePlaying
+			s.Objective = Objective{Kind: tc.kind, Target: 10}
+			for _, e := range tc.events {
+				eb.Emit(e)
+			}
+			if s.Objective.Progress != tc.want {
+				t.Errorf("progress %v, want %v", s.Objective.Progress, tc.want)
+			}
+		})
+	}
+}
+
+// TestBuildingAt checks every pixel of a generated building names it alike
+// and open ground names none.
+func TestBuildingAt(t *testing.T) {
+	w := newTestWorld(7, themeNamed(t, "City"), DefaultWorldWidth, DefaultWorldHeight)
+	var bx, by int
+	found := false
+	for y := 0; y < w.Height && !found; y++ {
+		for x := 0; x < w.Width && !found; x++ {
+			bx, by, found = x, y, w.builtAt(x, y)
+		}
+	}
+	if !found {
+		t.Fatal("no building in the city")
+	}
+	id := w.buildingAt(bx, by, 0)
+	if id == 0 {
+		t.Fatal("first building has no name")
+	}
+	whole, _ := w.flood(bx, by, make(map[int]bool), w.builtAt)
+	for _, k := range whole {
+		if got := w.buildingAt(k%w.Width, k/w.Width, 0); got != id {
+			t.Fatalf("pixel %d,%d of building %d names %d", k%w.Width, k/w.Width, id, got)
+		}
+	}
+	for _, k := range whole {
+		w.BurnPixel(k%w.Width, k/w.Width)
+	}
+	if got := w.buildingAt(bx, by, 0); got != id {
+		t.Errorf("burnt to the ground it names %d, want %d", got, id)
+	}
+	if got := w.buildingAt(2, 2, 0); got != 0 {
+		t.Errorf("the ring road names building %d", got)
+	}
+}
@@ -51,7 +51,10 @@ var Palette = struct {
 	TreeTop      RGB
 	Rubble       RGB
 	Border       RGB
+	Water        RGB
+	Shore        RGB
 	Smoke        RGB
+	Slime        RGB
 	Glow         RGB
 	FireHot      RGB
 	FireMid      RGB
@@ -72,7 +75,10 @@ var Palette = struct {
 	TreeTop:      RGB{R: 120, G: 150, B: 85},
 	Rubble:       RGB{R: 104, G: 108, B: 112},
 	Border:       RGB{R: 0, G: 0, B: 0},
+	Water:        RGB{R: 52, G: 96, B: 150},
+	Shore:        RGB{R: 198, G: 182, B: 136},
 	Smoke:        RGB{R: 120, G: 120, B: 125},
+	Slime:        RGB{R: 200, G: 216, B: 176},
 	Glow:         RGB{R: 255, G: 200, B: 90},
 	FireHot:      RGB{R: 255, G: 210, B: 110},
 	FireMid:      RGB{R: 255, G: 150, B: 70},
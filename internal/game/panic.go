@@ -0,0 +1,166 @@
+package game
+
+import "math"
+
+// Panic. Fear spreads through a crowd. A snake close by, an explosion or a
+// killing in plain sight frightens the pedestrians near it, and the
+// frightened scream, frightening those around them in turn, a little less
+// each time, so a big blast ripples out through the streets. Panicked
+// pedestrians stampede: out of a building the trouble is in, into a nearby
+// one whose door leads away from it, or just away, over the roads too,
+// where cars stop for them. They trample anyone in their way who isn't
+// running too.
+
+const (
+	panicFlee        = 0.35 // fear at which a ped stampedes
+	panicScream      = 0.5  // and screams
+	panicPass        = 0.8  // share of a screamer's fear those who hear it catch
+	panicCalm        = 0.1  // fear lost per second
+	panicReact       = 0.3  // seconds from catching fright to the first scream
+	panicScreamEvery = 0.8
+	panicScreamReach = 10.0
+	panicBlastReach  = 4.0  // times an explosion's radius that is frightened by it
+	panicCorpseReach = 18.0 // how far off a killing is seen
+	panicDoorReach   = 24.0 // how far a stampeding ped runs for a door
+	panicRun         = 1.4  // times the walking speed
+	trampleReach     = 1.6
+	trampleDamage    = 1.5 // HP per second under a stampede
+)
+
+// scare gives p fear of something at x, y, if that is more than it has.
+func (p *Pedestrian) scare(fear, x, y float64) {
+	if fear <= p.Panic || p.Infection == StateZombie {
+		return
+	}
+	if p.Panic < panicScream {
+		p.ScreamTimer = max(p.ScreamTimer, panicReact)
+	}
+	p.Panic = min(fear, 1)
+	p.PanicX, p.PanicY = x, y
+}
+
+// frighten scares the pedestrians within reach of x, y, the nearer the
+// more; with sight set, only those who can see it.
+func (ps *PedestrianSystem) frighten(w *World, x, y, reach float64, sight bool) {
+	for i := range ps.P {
+		p := &ps.P[i]
+		if !p.Alive {
+			continue
+		}
+		d := math.Hypot(p.X-x, p.Y-y)
+		if d >= reach || sight && !HasLineOfSight(p.X, p.Y, x, y, w) {
+			continue
+		}
+		p.scare(min(1.25*(1-d/reach), 1), x, y)
+	}
+}
+
+// subscribePanic frightens pedestrians at explosions and at killings they
+// see.
+func (ps *PedestrianSystem) subscribePanic(eb *EventBus, w *World) {
+	eb.Subscribe(EventExplosion, func(e Event) {
+		ps.frighten(w, e.X, e.Y, float64(e.Data)*panicBlastReach, false)
+	})
+	killed := func(e Event) { ps.frighten(w, e.X, e.Y, panicCorpseReach, true) }
+	eb.Subscribe(EventPedKilled, killed)
+	eb.Subscribe(EventCopKilled, killed)
+	eb.Subscribe(EventSoldierKilled, killed)
+}
+
+// spreadPanic calms every pedestrian a little and lets the frightened
+// scream, passing some of their fear to those near them. The spatial
+// buckets must be current.
+func (ps *PedestrianSystem) spreadPanic(dt float64) {
+	cols, rows := ps.bucketCols, ps.bucketRows
+	for i := range ps.P {
+		p := &ps.P[i]
+		if !p.Alive || p.Panic <= 0 {
+			continue
+		}
+		p.Panic = max(0, p.Panic-panicCalm*dt)
+		p.ScreamTimer -= dt
+		if p.Panic < panicScream || p.ScreamTimer > 0 {
+			continue
+		}
+		p.ScreamTimer = panicScreamEvery
+		fear := p.Panic * panicPass
+		bx := clamp(int(p.X)/ps.bucketSize, 0, cols-1)
+		by := clamp(int(p.Y)/ps.bucketSize, 0, rows-1)
+		for yy := max(by-1, 0); yy <= min(by+1, rows-1); yy++ {
+			for xx := max(bx-1, 0); xx <= min(bx+1, cols-1); xx++ {
+				for _, j := range ps.buckets[yy*cols+xx] {
+					if o := &ps.P[j]; j != i && o.Alive && math.Hypot(o.X-p.X, o.Y-p.Y) < panicScreamReach {
+						o.scare(fear, p.PanicX, p.PanicY)
+					}
+				}
+			}
+		}
+	}
+}
+
+// stampede points a panicked pedestrian away from what frightened it: out
+// of the building the trouble is in, into one nearby whose door leads away
+// from it, or straight away from it. Indoors with the trouble outside, it
+// hides.
+func (p *Pedestrian) stampede(w *World) {
+	p.Waiting = false
+	if in := w.interiorAt(p.X, p.Y); in != nil {
+		if !in.inside(p.PanicX, p.PanicY) {
+			p.Hiding, p.Crossing = true, false
+			return
+		}
+		p.TargetX, p.TargetY = in.exitTarget(p.X, p.Y, p.PanicX, p.PanicY)
+		return
+	}
+	if in := refuge(w, p.X, p.Y, p.PanicX, p.PanicY); in != nil {
+		p.TargetX, p.TargetY = in.breachTarget(p.X, p.Y, 0, 0, false)
+		return
+	}
+	dx, dy := p.X-p.PanicX, p.Y-p.PanicY
+	d := math.Hypot(dx, dy)
+	if d < 0.1 {
+		dx, dy, d = p.FacingX, p.FacingY, 1 // right on top of it: keep going
+	}
+	p.TargetX = p.X + dx/d*20
+	p.TargetY = p.Y + dy/d*20
+}
+
+// refuge is the building within a door's run of x, y whose door nearest
+// x, y leads away from trouble at tx, ty, or nil.
+func refuge(w *World, x, y, tx, ty float64) *interior {
+	var best *interior
+	bestD := panicDoorReach
+	away := math.Hypot(x-tx, y-ty)
+	bx, by := floorDiv(int(math.Floor(x)), Pattern), floorDiv(int(math.Floor(y)), Pattern)
+	for yy := by - 1; yy <= by+1; yy++ {
+		for xx := bx - 1; xx <= bx+1; xx++ {
+			list := w.blockInteriors(xx, yy)
+			for k := range list {
+				in := &list[k]
+				if in.inside(tx, ty) {
+					continue
+				}
+				near, nearD := door{}, math.Inf(1)
+				for _, d := range in.doors {
+					if dd := math.Hypot(d.x-x, d.y-y); dd < nearD {
+						near, nearD = d, dd
+					}
+				}
+				if nearD < bestD && math.Hypot(near.x-tx, near.y-ty) > away {
+					best, bestD = in, nearD
+				}
+			}
+		}
+	}
+	return best
+}
+
+// trample hurts p, knocked down under a stampede, and kills it when it
+// has had enough.
+func (p *Pedestrian) trample(w *World, dt float64) {
+	p.HP.Damage(trampleDamage * dt)
+	if p.HP.IsDead() {
+		p.Alive = false
+		paintFallenPed(w, int(math.Round(p.X)), int(math.Round(p.Y)), p.Skin, p.Col)
+	}
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScare(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		ped        Pedestrian
+		fear       float64
+		wantPanic  float64
+		wantScream float64
+	}{
+		{"calm ped", Pedestrian{}, 0.6, 0.6, panicReact},
+		{"capped", Pedestrian{}, 1.25, 1, panicReact},
+		{"already more afraid", Pedestrian{Panic: 0.7, ScreamTimer: 0.2}, 0.5, 0.7, 0.2},
+		{"already screaming", Pedestrian{Panic: 0.6, ScreamTimer: 0.1}, 0.9, 0.9, 0.1},
+		{"zombies fear nothing", Pedestrian{Infection: StateZombie}, 1, 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := tc.ped
+			p.scare(tc.fear, 3, 4)
+			if p.Panic != tc.wantPanic || p.ScreamTimer != tc.wantScream {
+				t.Errorf("panic %v scream in %v, want %v and %v", p.Panic, p.ScreamTimer, tc.wantPanic, tc.wantScream)
+			}
+			if p.Panic > tc.ped.Panic && (p.PanicX != 3 || p.PanicY != 4) {
+				t.Errorf("fears %v,%v, want 3,4", p.PanicX, p.PanicY)
+			}
+		})
+	}
+}
+
+// TestPanicRipples frightens the first of a line of peds, each within a
+// scream of the next, and checks the fear runs down the line, weaker at
+// each step, and dies out.
+func TestPanicRipples(t *testing.T) {
+	const spacing = panicScreamReach - 2
+	var line []Pedestrian
+	for k := range 10 {
+		line = append(line, Pedestrian{X: 20 + float64(k)*spacing, Y: 50, Alive: true})
+	}
+	ps := crowd(line...)
+	ps.P[0].scare(1, 10, 50)
+	for range int(4 / SimTickDT) {
+		ps.spreadPanic(SimTickDT)
+	}
+
+	reached := 0
+	for k := 1; k < len(ps.P); k++ {
+		p, prev := ps.P[k], ps.P[k-1]
+		if p.Panic == 0 {
+			break
+		}
+		reached = k
+		if p.Panic >= prev.Panic {
+			t.Errorf("ped %d is as afraid as ped %d: %.3f, %.3f", k, k-1, p.Panic, prev.Panic)
+		}
+		if p.PanicX != 10 || p.PanicY != 50 {
+			t.Errorf("ped %d fears %v,%v, not what frightened the first", k, p.PanicX, p.PanicY)
+		}
+	}
+	// Fear passes on at panicPass a scream until it is too weak to scream.
+	want := int(math.Ceil(math.Log(panicScream) / math.Log(panicPass)))
+	if reached < want-1 || reached > want {
+		t.Errorf("fear reached %d peds down the line, want about %d", reached, want)
+	}
+}
+
+func TestFrighten(t *testing.T) {
+	// A building stands from 10,10 to 14,14 (see mapImage).
+	m, err := DecodeWorldMap(mapImage(MinWorldSize, MinWorldSize), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWorld(1, themeNamed(t, "City"), m.Width, m.Height)
+	w.Map = m
+	w.Resize(m.Width, m.Height)
+
+	for _, tc := range []struct {
+		name  string
+		event Event
+		peds  [][2]float64
+		want  []bool // frightened, by ped
+	}{
+		{"blast", Event{Type: EventExplosion, X: 60, Y: 60, Data: 5}, [][2]float64{{61, 60}, {70, 60}, {60, 60 + 4*5 + 1}}, []bool{true, true, false}},
+		{"blast through walls", Event{Type: EventExplosion, X: 8, Y: 12, Data: 3}, [][2]float64{{16, 12}}, []bool{true}},
+		{"killing in sight", Event{Type: EventPedKilled, X: 8, Y: 12}, [][2]float64{{8, 20}, {8 + panicCorpseReach + 1, 12}}, []bool{true, false}},
+		{"killing behind a building", Event{Type: EventCopKilled, X: 8, Y: 12}, [][2]float64{{16, 12}}, []bool{false}},
+		{"soldier killed", Event{Type: EventSoldierKilled, X: 30, Y: 30}, [][2]float64{{35, 30}}, []bool{true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var peds []Pedestrian
+			for _, p := range tc.peds {
+				peds = append(peds, Pedestrian{X: p[0], Y: p[1], Alive: true})
+			}
+			ps := crowd(peds...)
+			eb := NewEventBus()
+			ps.subscribePanic(eb, w)
+			eb.Emit(tc.event)
+			for i, want := range tc.want {
+				if got := ps.P[i].Panic > 0; got != want {
+					t.Errorf("ped %d frightened %v, want %v", i, got, want)
+				}
+			}
+			if len(tc.peds) > 1 && tc.want[0] && tc.want[1] && ps.P[0].Panic <= ps.P[1].Panic {
+				t.Errorf("the nearer ped is no more afraid: %.2f, %.2f", ps.P[0].Panic, ps.P[1].Panic)
+			}
+		})
+	}
+}
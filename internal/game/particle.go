@@ -13,6 +13,7 @@ const (
 	ParticleWave
 	ParticleRain
 	ParticleSnow
+	ParticleSplash // water spray; gone when it falls back
 )
 
 type Particle struct {
@@ -121,6 +122,8 @@ func (ps *ParticleSystem) ParticleRenderData(glowBuf, normBuf []float32) ([]floa
 			a = (1.0 - t) * 0.75
 		case ParticleSnow:
 			a = (1.0 - t) * 0.95
+		case ParticleSplash:
+			a = (1.0 - t*0.5) * 0.9
 		}
 		if a <= 0 {
 			continue
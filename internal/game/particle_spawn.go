@@ -79,6 +79,56 @@ func (ps *ParticleSystem) SpawnExplosion(wx, wy int, baseCol RGB, intensity floa
 	}
 }
 
+// SpawnSplash throws up water where a blast hits it: a column of spray,
+// droplets flung out low and a drifting mist. col is the water's colour.
+func (ps *ParticleSystem) SpawnSplash(wx, wy int, col RGB, intensity float64) {
+	if intensity <= 0 {
+		return
+	}
+
+	r := NewRand(hash2D(ps.seed^0x5B1A54, wx, wy))
+	fx := float64(wx)
+	fy := float64(wy)
+	foam := col.Add(90, 96, 90)
+
+	// Spray column.
+	for range int(60*intensity) + 4 {
+		ang := r.RangeF(0, math.Pi*2)
+		spd := r.RangeF(4, 30) * intensity
+		ps.Add(Particle{
+			X: fx + r.RangeF(-1.5, 1.5), Y: fy + r.RangeF(-1.5, 1.5),
+			VX: math.Cos(ang) * spd, VY: math.Sin(ang) * spd,
+			Z: r.RangeF(0, 2), VZ: r.RangeF(50, 140) * intensity,
+			Size: 1.0, MaxLife: r.RangeF(0.6, 1.2),
+			Col: lerpRGB(col, foam, r.RangeF(0.4, 1)), Kind: ParticleSplash,
+		})
+	}
+
+	// Droplets.
+	for range int(40*intensity) + 2 {
+		ang := r.RangeF(0, math.Pi*2)
+		spd := r.RangeF(25, 90) * intensity
+		ps.Add(Particle{
+			X: fx, Y: fy,
+			VX: math.Cos(ang) * spd, VY: math.Sin(ang) * spd,
+			Z: r.RangeF(0, 2), VZ: r.RangeF(15, 45),
+			Size: 0.6, MaxLife: r.RangeF(0.4, 0.9),
+			Col: lerpRGB(col, foam, r.RangeF(0.2, 0.7)), Kind: ParticleSplash,
+		})
+	}
+
+	// Mist.
+	for range int(14 * intensity) {
+		ps.Add(Particle{
+			X: fx + r.RangeF(-3, 3), Y: fy + r.RangeF(-3, 3),
+			VX: r.RangeF(-8, 8), VY: r.RangeF(-8, 8),
+			Z: r.RangeF(2, 10), VZ: r.RangeF(8, 20),
+			Size: 1.0, MaxLife: r.RangeF(0.4, 0.9),
+			Col: foam, Kind: ParticleSmoke,
+		})
+	}
+}
+
 // ApplySuction pulls all live particles toward (cx, cy) within radius.
 func (ps *ParticleSystem) ApplySuction(cx, cy, radius, strength, dt float64) {
 	r2 := radius * radius
@@ -88,9 +88,11 @@ func (ps *ParticleSystem) UpdateWithShockwaveDamage(dt float64, w *World, peds *
 				continue // removed
 			}
 		case ParticleRain:
-			ps.updateRain(p, dt, d.rainXY)
+			ps.updateRain(p, dt, d.rainXY, w)
 		case ParticleSnow:
-			ps.updateSnow(p, dt, d.snowXY)
+			ps.updateSnow(p, dt, d.snowXY, w)
+		case ParticleSplash:
+			ps.updateSplash(p, dt, d.debrisXY)
 		default: // Debris, Glow
 			if ps.updateBloodOrDebris(p, i, dt, d.debrisXY, d.debrisBurnXY, w) {
 				continue // removed
@@ -157,11 +159,11 @@ func (ps *ParticleSystem) applyWaveImpactDamage(p *Particle, w *World, peds *Ped
 				continue
 			}
 			ped.Alive = false
-			bx := clamp(int(math.Round(ped.X)), 0, WorldWidth-1)
-			by := clamp(int(math.Round(ped.Y)), 0, WorldHeight-1)
+			bx := clamp(int(math.Round(ped.X)), 0, w.Width-1)
+			by := clamp(int(math.Round(ped.Y)), 0, w.Height-1)
 			stain := RGB{R: 130, G: 20, B: 20}
-			_ = w.PaintRGB(bx, by, stain)
-			_ = w.PaintRGB(clamp(bx+1, 0, WorldWidth-1), by, stain)
+			w.AddDecal(bx, by, DecalBlood, stain, 1)
+			w.AddDecal(bx+1, by, DecalBlood, stain, 1)
 			paintFallenPed(w, bx, by, ped.Skin, ped.Col)
 			makeBlood(ped.X, ped.Y, 18, 0.95)
 		}
@@ -179,8 +181,8 @@ func (ps *ParticleSystem) applyWaveImpactDamage(p *Particle, w *World, peds *Ped
 				continue
 			}
 			cop.Alive = false
-			bx := clamp(int(math.Round(cop.X)), 0, WorldWidth-1)
-			by := clamp(int(math.Round(cop.Y)), 0, WorldHeight-1)
+			bx := clamp(int(math.Round(cop.X)), 0, w.Width-1)
+			by := clamp(int(math.Round(cop.Y)), 0, w.Height-1)
 			_ = w.PaintRGB(bx, by, RGB{R: 120, G: 18, B: 18})
 			makeBlood(cop.X, cop.Y, 16, 0.9)
 		}
@@ -198,8 +200,8 @@ func (ps *ParticleSystem) applyWaveImpactDamage(p *Particle, w *World, peds *Ped
 				continue
 			}
 			troop.Alive = false
-			bx := clamp(int(math.Round(troop.X)), 0, WorldWidth-1)
-			by := clamp(int(math.Round(troop.Y)), 0, WorldHeight-1)
+			bx := clamp(int(math.Round(troop.X)), 0, w.Width-1)
+			by := clamp(int(math.Round(troop.Y)), 0, w.Height-1)
 			_ = w.PaintRGB(bx, by, RGB{R: 122, G: 18, B: 18})
 			makeBlood(troop.X, troop.Y, 17, 0.92)
 		}
@@ -215,6 +217,18 @@ func (ps *ParticleSystem) updateSmoke(p *Particle, dt, decayXY, decayZ float64)
 	p.Z += p.VZ * dt
 }
 
+func (ps *ParticleSystem) updateSplash(p *Particle, dt, decayXY float64) {
+	p.VZ -= particleGravity * dt
+	p.VX *= decayXY
+	p.VY *= decayXY
+	p.X += p.VX * dt
+	p.Y += p.VY * dt
+	p.Z += p.VZ * dt
+	if p.Z <= 0 && p.VZ < 0 {
+		p.Life = p.MaxLife
+	}
+}
+
 func (ps *ParticleSystem) updateFire(p *Particle, dt, decayXY, decayZ float64, w *World) {
 	p.VX *= decayXY
 	p.VY *= decayXY
@@ -236,6 +250,10 @@ func (ps *ParticleSystem) updateFire(p *Particle, dt, decayXY, decayZ float64, w
 	if w == nil {
 		return
 	}
+	// Flames licking the ground light it.
+	if p.Burning && p.Z < 3 && NewRand(ps.seed^uint64(int(p.X)*13+int(p.Y))^uint64(p.Life*1000)).Float64() < fireFlameChance {
+		w.igniteFire(int(p.X), int(p.Y))
+	}
 	dx := p.X - prevX
 	dy := p.Y - prevY
 	steps := max(1, int(math.Ceil(math.Hypot(dx, dy))))
@@ -279,25 +297,25 @@ func (ps *ParticleSystem) updateFire(p *Particle, dt, decayXY, decayZ float64, w
 	}
 }
 
-func (ps *ParticleSystem) updateRain(p *Particle, dt, decayXY float64) {
+func (ps *ParticleSystem) updateRain(p *Particle, dt, decayXY float64, w *World) {
 	p.VX *= decayXY
 	p.X += p.VX * dt
 	p.Y += p.VY * dt
 
 	// Cull quickly once drops move outside an expanded world rectangle.
-	if p.X < -20 || p.X > float64(WorldWidth)+20 || p.Y < -20 || p.Y > float64(WorldHeight)+20 {
+	if p.X < -20 || p.X > float64(w.Width)+20 || p.Y < -20 || p.Y > float64(w.Height)+20 {
 		p.Life = p.MaxLife
 	}
 }
 
-func (ps *ParticleSystem) updateSnow(p *Particle, dt, decayXY float64) {
+func (ps *ParticleSystem) updateSnow(p *Particle, dt, decayXY float64, w *World) {
 	wobble := math.Sin((p.X*0.06)+(p.Y*0.04)+(p.Life*3.0)) * 8.0
 	p.VX = p.VX*decayXY + wobble*dt
 	p.X += p.VX * dt
 	p.Y += p.VY * dt
 
 	// Cull quickly once flakes move outside an expanded world rectangle.
-	if p.X < -24 || p.X > float64(WorldWidth)+24 || p.Y < -24 || p.Y > float64(WorldHeight)+24 {
+	if p.X < -24 || p.X > float64(w.Width)+24 || p.Y < -24 || p.Y > float64(w.Height)+24 {
 		p.Life = p.MaxLife
 	}
 }
@@ -347,6 +365,13 @@ func (ps *ParticleSystem) updateBloodOrDebris(p *Particle, idx int, dt, decayXY,
 	wy := int(math.Round(p.Y))
 	th := float64(w.HeightAt(wx, wy))
 
+	// Whatever lands in water sinks without a trace.
+	if p.Z <= 1.0 && w.IsWater(wx, wy) {
+		ps.P[idx] = ps.P[len(ps.P)-1]
+		ps.P = ps.P[:len(ps.P)-1]
+		return true
+	}
+
 	if p.Z <= th+1.0 {
 		p.Z = th + 1.0
 		p.VZ = -p.VZ * particleBounce
@@ -370,7 +395,7 @@ func (ps *ParticleSystem) updateBloodOrDebris(p *Particle, idx int, dt, decayXY,
 							for ox := -r; ox <= r; ox++ {
 								tx := wx + ox
 								ty := wy + oy
-								if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && w.HeightAt(tx, ty) == 0 {
+								if tx >= 0 && ty >= 0 && tx < w.Width && ty < w.Height && w.HeightAt(tx, ty) == 0 {
 									painted = w.PaintRGB(tx, ty, p.Col)
 									if painted {
 										break
@@ -383,6 +408,9 @@ func (ps *ParticleSystem) updateBloodOrDebris(p *Particle, idx int, dt, decayXY,
 				// Burning debris spawn ground fires.
 				if p.Burning {
 					rr := NewRand(ps.seed ^ uint64(idx+1))
+					if rr.Float64() < fireEmberChance {
+						w.igniteFire(wx, wy)
+					}
 					for fi := 0; fi < 2+rr.Range(0, 3); fi++ {
 						fp := Particle{
 							X: float64(wx) + rr.RangeF(-0.9, 0.9), Y: float64(wy) + rr.RangeF(-0.9, 0.9),
@@ -411,12 +439,7 @@ func (ps *ParticleSystem) updateBloodOrDebris(p *Particle, idx int, dt, decayXY,
 						if math.Hypot(float64(ox), float64(oy)) > float64(brush) {
 							continue
 						}
-						sx := wx + ox
-						sy := wy + oy
-						if sx >= 0 && sy >= 0 && sx < WorldWidth && sy < WorldHeight {
-							dst := w.ColorAt(sx, sy)
-							w.PaintRGB(sx, sy, lerpRGB(dst, stain, 0.55))
-						}
+						w.AddDecal(wx+ox, wy+oy, DecalBlood, stain, 0.55)
 					}
 				}
 				ps.P[idx] = ps.P[len(ps.P)-1]
@@ -41,6 +41,7 @@ type Pedestrian struct {
 	Phase            float64
 	Size             float32
 	Crossing         bool
+	Waiting          bool // at the lights for the walk signal, to cross to Target
 	Alive            bool
 	HP               Health
 
@@ -48,6 +49,9 @@ type Pedestrian struct {
 	PrevX, PrevY float64
 	StuckTimer   float64
 
+	// Position at the previous simulation tick (render interpolation).
+	LastX, LastY float64
+
 	// Grouping.
 	GroupID  uint64
 	IsLeader bool
@@ -56,9 +60,25 @@ type Pedestrian struct {
 
 	// Snake awareness.
 	Fleeing bool
-
-	// Infection visual (10% spawn symptomatic — bad to eat, won't spread).
-	Infection InfectionState
+	Hiding  bool // holed up inside a building until a snake comes in
+
+	// Fear (see panic.go): how frightened, 0..1, of what where, and the
+	// seconds to the next scream.
+	Panic          float64
+	PanicX, PanicY float64
+	ScreamTimer    float64
+
+	// Daily routine (see schedule.go): the part of the day it has planned
+	// for, where it is making for if it has a goal, and whether it has gone
+	// in for the night.
+	Plan         DayPart
+	Goal         bool
+	GoalX, GoalY float64
+	InForNight   bool
+
+	// Infection and the seconds left in its current stage (see virus.go).
+	Infection      InfectionState
+	InfectionTimer float64
 
 	// Armed pedestrian: shoots at snake.
 	Armed         bool
@@ -73,6 +93,8 @@ type PedestrianSystem struct {
 	seed uint64
 	Env  string
 
+	DayPhase float64 // 0..1 through the day cycle; set each frame from the level clock
+
 	groupLeader map[uint64]int
 	nextGroupID uint64
 
@@ -81,6 +103,8 @@ type PedestrianSystem struct {
 	bucketCols int
 	bucketRows int
 	buckets    [][]int
+
+	Events *EventBus // receives gameplay events; may be nil
 }
 
 func NewPedestrianSystem(maxPeds int, seed uint64) *PedestrianSystem {
@@ -98,12 +122,17 @@ func NewPedestrianSystem(maxPeds int, seed uint64) *PedestrianSystem {
 		nextGroupID: seed + 1,
 		bucketSize:  16,
 	}
-	ps.bucketCols = (WorldWidth + ps.bucketSize - 1) / ps.bucketSize
-	ps.bucketRows = (WorldHeight + ps.bucketSize - 1) / ps.bucketSize
-	ps.buckets = make([][]int, ps.bucketCols*ps.bucketRows)
+	ps.resizeGrid(DefaultWorldWidth, DefaultWorldHeight)
 	return ps
 }
 
+// resizeGrid sizes the spatial buckets for a world of the given size.
+func (ps *PedestrianSystem) resizeGrid(width, height int) {
+	ps.bucketCols = (width + ps.bucketSize - 1) / ps.bucketSize
+	ps.bucketRows = (height + ps.bucketSize - 1) / ps.bucketSize
+	ps.buckets = make([][]int, ps.bucketCols*ps.bucketRows)
+}
+
 func (ps *PedestrianSystem) SetEnvironment(name string) {
 	if name == "" {
 		name = ThemeCity.Name
@@ -191,16 +220,17 @@ func applyVariantTuning(p *Pedestrian) {
 	}
 }
 
-// pedWalkable returns true if a pedestrian can walk on this tile.
+// pedWalkable returns true if a pedestrian can walk on this tile: open
+// ground that is neither road nor water.
 func pedWalkable(w *World, wx, wy int) bool {
-	if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
+	if wx < 0 || wy < 0 || wx >= w.Width || wy >= w.Height {
 		return false
 	}
 	col := w.ColorAt(wx, wy)
 	if rgbEq(col, Palette.Road) || rgbEq(col, Palette.Border) {
 		return false
 	}
-	return w.HeightAt(wx, wy) == 0
+	return w.HeightAt(wx, wy) == 0 && !w.IsWater(wx, wy)
 }
 
 func (ps *PedestrianSystem) SpawnRandom(w *World, n int) {
@@ -211,8 +241,8 @@ func (ps *PedestrianSystem) SpawnRandom(w *World, n int) {
 	attempts := 0
 	for len(ps.P) < n && attempts < n*12 {
 		attempts++
-		x := r.Range(0, WorldWidth-1)
-		y := r.Range(0, WorldHeight-1)
+		x := r.Range(0, w.Width-1)
+		y := r.Range(0, w.Height-1)
 		if !pedWalkable(w, x, y) {
 			continue
 		}
@@ -308,8 +338,8 @@ func (ps *PedestrianSystem) SpawnArmed(w *World, n int) {
 	spawned := 0
 	for spawned < n && attempts < n*12 {
 		attempts++
-		x := r.Range(0, WorldWidth-1)
-		y := r.Range(0, WorldHeight-1)
+		x := r.Range(0, w.Width-1)
+		y := r.Range(0, w.Height-1)
 		if !pedWalkable(w, x, y) {
 			continue
 		}
@@ -344,7 +374,8 @@ func (ps *PedestrianSystem) SpawnArmed(w *World, n int) {
 	}
 }
 
-// SpawnInfected spawns n peds pre-marked as symptomatic (green, bad to eat).
+// SpawnInfected spawns n symptomatic peds (green, bad to eat), the start
+// of the level's outbreak.
 func (ps *PedestrianSystem) SpawnInfected(w *World, n int) {
 	if w == nil || n <= 0 {
 		return
@@ -354,8 +385,8 @@ func (ps *PedestrianSystem) SpawnInfected(w *World, n int) {
 	spawned := 0
 	for spawned < n && attempts < n*12 {
 		attempts++
-		x := r.Range(0, WorldWidth-1)
-		y := r.Range(0, WorldHeight-1)
+		x := r.Range(0, w.Width-1)
+		y := r.Range(0, w.Height-1)
 		if !pedWalkable(w, x, y) {
 			continue
 		}
@@ -369,17 +400,18 @@ func (ps *PedestrianSystem) SpawnInfected(w *World, n int) {
 			TargetX: float64(x) + 0.5, TargetY: float64(y) + 0.5,
 			Speed: spd, BaseSpeed: spd,
 			Col: col, OrigCol: origCol,
-			Skin:      skinPalette[r.Intn(len(skinPalette))],
-			Phase:     r.RangeF(0, 1),
-			Size:      pedUniformSize,
-			Alive:     true,
-			HP:        NewHealth(hp),
-			Infection: StateSymptomatic,
-			Variant:   variant,
-			FacingY:   1,
-			WalkCycle: r.RangeF(0, 2),
-			PrevX:     float64(x) + 0.5,
-			PrevY:     float64(y) + 0.5,
+			Skin:           skinPalette[r.Intn(len(skinPalette))],
+			Phase:          r.RangeF(0, 1),
+			Size:           pedUniformSize,
+			Alive:          true,
+			HP:             NewHealth(hp),
+			Infection:      StateSymptomatic,
+			Variant:        variant,
+			InfectionTimer: sickness(r),
+			FacingY:        1,
+			WalkCycle:      r.RangeF(0, 2),
+			PrevX:          float64(x) + 0.5,
+			PrevY:          float64(y) + 0.5,
 		}
 		applyVariantTuning(&p)
 		ps.P = append(ps.P, p)
@@ -387,8 +419,55 @@ func (ps *PedestrianSystem) SpawnInfected(w *World, n int) {
 	}
 }
 
+// pedsPerHideout caps how many peds HideIndoors puts in one building.
+const pedsPerHideout = 3
+
+// HideIndoors moves about a third of the unarmed, healthy peds walking
+// alone into a building near them, where they keep still until a snake
+// finds them.
+func (ps *PedestrianSystem) HideIndoors(w *World) {
+	if w == nil {
+		return
+	}
+	r := NewRand(ps.seed ^ 0x41D1E5)
+	hiders := make(map[*interior]int)
+	var near []*interior
+	for i := range ps.P {
+		p := &ps.P[i]
+		if !p.Alive || p.Armed || p.Infection != StateHealthy || p.GroupID != 0 || r.Intn(3) != 0 {
+			continue
+		}
+		bx, by := floorDiv(int(p.X), Pattern), floorDiv(int(p.Y), Pattern)
+		near = near[:0]
+		for ny := by - 1; ny <= by+1; ny++ {
+			for nx := bx - 1; nx <= bx+1; nx++ {
+				list := w.blockInteriors(nx, ny)
+				for k := range list {
+					if hiders[&list[k]] < pedsPerHideout {
+						near = append(near, &list[k])
+					}
+				}
+			}
+		}
+		if len(near) == 0 {
+			continue
+		}
+		in := near[r.Intn(len(near))]
+		x, y, ok := in.floorSpot(w, r)
+		if !ok {
+			continue
+		}
+		hiders[in]++
+		p.X, p.Y = x, y
+		p.TargetX, p.TargetY = x, y
+		p.PrevX, p.PrevY = x, y
+		p.LastX, p.LastY = x, y
+		p.Hiding = true
+	}
+}
+
 // Update advances all pedestrian AI.
-func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles *ParticleSystem) {
+func (ps *PedestrianSystem) Update(dt float64, w *World, snakes []*Snake, particles *ParticleSystem) {
 	if dt <= 0 || w == nil {
 		return
 	}
@@ -409,6 +488,9 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 		buckets[by*cols+bx] = append(buckets[by*cols+bx], j)
 	}
 
+	ps.spreadInfection(dt)
+	ps.spreadPanic(dt)
+
 	// Refresh group leaders.
 	for gid := range ps.groupLeader {
 		delete(ps.groupLeader, gid)
@@ -419,11 +501,6 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 		}
 	}
 
-	var snakeHX, snakeHY float64
-	if snake != nil {
-		snakeHX, snakeHY = snake.Head()
-	}
-
 	for i := range ps.P {
 		p := &ps.P[i]
 		if !p.Alive {
@@ -431,6 +508,13 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 		}
 		startX, startY := p.X, p.Y
 
+		// Shoot at and flee from whichever snake is closest.
+		snake, snakeHX, snakeHY := nearestSnake(snakes, p.X, p.Y)
+
+		// Zombies hunt rather than flee, hide or follow.
+		zombie := p.Infection == StateZombie
+		hunting := zombie && p.hunt(dt, snake, snakeHX, snakeHY)
+
 		// Armed ped: shoot at snake if close and has LOS.
 		if p.Armed && snake != nil && snake.Alive {
 			p.ShootCooldown -= dt
@@ -455,21 +539,51 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 			}
 		}
 
-		// Flee from snake head if close.
+		ps.keepRoutine(i, w)
+
+		// Hiding indoors: keep still until a snake gets in or close.
+		if p.Hiding {
+			in := w.interiorAt(p.X, p.Y)
+			if in != nil && (snake == nil || (!in.inside(snakeHX, snakeHY) && math.Hypot(snakeHX-p.X, snakeHY-p.Y) > 8)) {
+				p.VX, p.VY = 0, 0
+				p.PrevX, p.PrevY = p.X, p.Y
+				continue
+			}
+			p.Hiding = false
+		}
+
+		// Flee from snake head if close; indoors, make for a door.
 		p.Fleeing = false
-		if snake != nil && snake.Alive {
+		if snake != nil && snake.Alive && !zombie {
 			dist := math.Hypot(snakeHX-p.X, snakeHY-p.Y)
 			if dist < 15.0 && dist > 0.1 {
 				p.Fleeing = true
-				fdx := (p.X - snakeHX) / dist
-				fdy := (p.Y - snakeHY) / dist
-				p.TargetX = p.X + fdx*20.0
-				p.TargetY = p.Y + fdy*20.0
+				p.Waiting = false
+				p.scare(1, snakeHX, snakeHY)
+				if in := w.interiorAt(p.X, p.Y); in != nil {
+					p.TargetX, p.TargetY = in.exitTarget(p.X, p.Y, snakeHX, snakeHY)
+				} else {
+					fdx := (p.X - snakeHX) / dist
+					fdy := (p.Y - snakeHY) / dist
+					p.TargetX = p.X + fdx*20.0
+					p.TargetY = p.Y + fdy*20.0
+				}
+			}
+		}
+
+		// Panicked: stampede, each for themselves.
+		panicking := !zombie && p.Panic >= panicFlee
+		if panicking && !p.Fleeing {
+			p.stampede(w)
+			if p.Hiding {
+				p.VX, p.VY = 0, 0
+				p.PrevX, p.PrevY = p.X, p.Y
+				continue
 			}
 		}
 
 		// Followers steer towards leader.
-		if !p.IsLeader && p.GroupID != 0 {
+		if !p.IsLeader && p.GroupID != 0 && !zombie && !panicking {
 			if lid, ok := ps.groupLeader[p.GroupID]; ok && lid < len(ps.P) {
 				leader := &ps.P[lid]
 				p.TargetX = leader.X + p.OffsetX
@@ -482,7 +596,23 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 		dy := p.TargetY - p.Y
 		dist := math.Hypot(dx, dy)
 
-		if dist < 0.8 || dist > 200 {
+		// At the lights: wait at the kerb for the walk signal.
+		if p.Waiting {
+			iwx, iwy := int(math.Round(p.X)), int(math.Round(p.Y))
+			sx, sy := 0, 0
+			if math.Abs(dx) > math.Abs(dy) {
+				sx = int(math.Copysign(1, dx))
+			} else {
+				sy = int(math.Copysign(1, dy))
+			}
+			if _, walk := w.walkSignal(iwx, iwy, sx, sy); walk {
+				p.Waiting = false
+				p.Crossing = true
+			}
+		}
+
+		if !hunting && !p.Waiting && (dist < 0.8 || dist > 200) {
+			p.Crossing = false // across, or given up
 			r := NewRand(ps.seed ^ uint64(i)*0xC0FFEE)
 			px0 := int(math.Round(p.X))
 			py0 := int(math.Round(p.Y))
@@ -502,6 +632,7 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 				if rgbEq(tcol, Palette.Grass) || rgbEq(tcol, Palette.GrassPatch) {
 					score += 0.8
 				}
+				score += p.goalScore(float64(tx)+0.5, float64(ty)+0.5)
 				if dist > 0.1 {
 					vx := float64(tx - px0)
 					vy := float64(ty - py0)
@@ -523,14 +654,14 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 			dist = math.Hypot(dx, dy)
 		}
 
-		if dist > 0.001 {
+		if dist > 0.001 && !p.Waiting {
 			nx := dx / dist
 			ny := dy / dist
 			spd := p.Speed
-			if p.Fleeing {
-				spd *= 1.4
+			if p.Fleeing || panicking {
+				spd *= panicRun
 			}
-			if dist < 3.0 && !p.Fleeing {
+			if dist < 3.0 && !p.Fleeing && !panicking {
 				spd *= 0.35
 			}
 			newX := p.X + nx*spd*dt
@@ -538,7 +669,16 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 
 			wx := int(math.Round(newX))
 			wy := int(math.Round(newY))
-			if !pedWalkable(w, wx, wy) && !p.Crossing {
+			if hunting || panicking {
+				// Zombies and stampedes slide along whatever is in the way.
+				if zombieWalkable(w, wx, wy) {
+					p.X, p.Y = newX, newY
+				} else if zombieWalkable(w, wx, int(math.Round(p.Y))) {
+					p.X = newX
+				} else if zombieWalkable(w, int(math.Round(p.X)), wy) {
+					p.Y = newY
+				}
+			} else if !pedWalkable(w, wx, wy) && !p.Crossing {
 				r := NewRand(ps.seed ^ uint64(i)*0xBEEF)
 				for tries := 0; tries < 12; tries++ {
 					tx := int(math.Round(p.X)) + r.Range(-8, 8)
@@ -555,29 +695,54 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 			}
 		}
 
-		// Occasional road crossing.
+		// Occasional road crossing. At the lights people cross more often,
+		// kerb by kerb so crowds gather, but wait for the walk signal.
 		iwx := int(math.Round(p.X))
 		iwy := int(math.Round(p.Y))
 		dirs := [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
 		for _, d := range dirs {
+			if p.Crossing || p.Waiting || panicking {
+				break
+			}
 			nwx := iwx + d[0]
 			nwy := iwy + d[1]
-			if nwx < 0 || nwy < 0 || nwx >= WorldWidth || nwy >= WorldHeight {
+			if nwx < 0 || nwy < 0 || nwx >= w.Width || nwy >= w.Height {
 				continue
 			}
 			if rgbEq(w.ColorAt(nwx, nwy), Palette.Road) {
 				r := NewRand(ps.seed ^ uint64(i)*0xDEADBEEF)
-				if r.Intn(1000) < 8 {
+				chance := 8
+				signalled, walk := w.walkSignal(iwx, iwy, d[0], d[1])
+				if signalled && !zombie {
+					if !walk && !p.IsLeader && p.GroupID != 0 {
+						continue // followers keep with their group
+					}
+					r = NewRand(ps.seed ^ uint64(i)*0xDEADBEEF ^ uint64(iwy*w.Width+iwx)*0x9E3779B9)
+					chance = 120
+				}
+				if p.Goal {
+					// Over the road towards where it is going, rarely away.
+					if (p.GoalX-p.X)*float64(d[0])+(p.GoalY-p.Y)*float64(d[1]) > p.gather() {
+						chance *= goalCrossing
+					} else {
+						chance /= goalCrossing
+					}
+				}
+				if r.Intn(1000) < chance {
 					sx := nwx + d[0]
 					sy := nwy + d[1]
 					for steps := 0; steps < 12; steps++ {
-						if sx < 0 || sy < 0 || sx >= WorldWidth || sy >= WorldHeight {
+						if sx < 0 || sy < 0 || sx >= w.Width || sy >= w.Height {
 							break
 						}
 						if pedWalkable(w, sx, sy) {
 							p.TargetX = float64(sx) + 0.5
 							p.TargetY = float64(sy) + 0.5
-							p.Crossing = true
+							if signalled && !walk && !zombie {
+								p.Waiting = true
+							} else {
+								p.Crossing = true
+							}
 							break
 						}
 						sx += d[0]
@@ -622,6 +787,9 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 						p.Y += uy * half
 						other.X -= ux * half
 						other.Y -= uy * half
+						if panicking && od < trampleReach && other.Alive && other.Panic < panicFlee {
+							other.trample(w, dt)
+						}
 					}
 				}
 			}
@@ -631,13 +799,13 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 		moved := math.Hypot(p.X-p.PrevX, p.Y-p.PrevY)
 		p.PrevX = p.X
 		p.PrevY = p.Y
-		if moved < 0.1*dt {
+		if moved < 0.1*dt && !p.Waiting {
 			p.StuckTimer += dt
 			if p.StuckTimer > 4.0 {
 				r := NewRand(ps.seed ^ uint64(i)*0xDEAD ^ uint64(p.StuckTimer*100))
 				for tries := 0; tries < 30; tries++ {
-					tx := r.Range(0, WorldWidth-1)
-					ty := r.Range(0, WorldHeight-1)
+					tx := r.Range(0, w.Width-1)
+					ty := r.Range(0, w.Height-1)
 					if pedWalkable(w, tx, ty) {
 						p.X = float64(tx) + 0.5
 						p.Y = float64(ty) + 0.5
@@ -684,8 +852,8 @@ func (ps *PedestrianSystem) Update(dt float64, w *World, snake *Snake, particles
 // RemoveDead removes dead pedestrians using swap-remove.
 func (ps *PedestrianSystem) RemoveDead() {
 	for i := 0; i < len(ps.P); {
-		if !ps.P[i].Alive {
-			PlaySound(SoundSplatter)
+		if p := &ps.P[i]; !p.Alive {
+			ps.Events.Emit(Event{Type: EventPedKilled, X: p.X, Y: p.Y, Variant: p.Variant, Infection: p.Infection, Armed: p.Armed})
 			ps.P[i] = ps.P[len(ps.P)-1]
 			ps.P = ps.P[:len(ps.P)-1]
 		} else {
@@ -705,6 +873,24 @@ func (ps *PedestrianSystem) AliveCount() int {
 	return n
 }
 
+// nearestSnake returns the living snake whose head is closest to (x, y),
+// or nil when every snake is dead.
+func nearestSnake(snakes []*Snake, x, y float64) (*Snake, float64, float64) {
+	var best *Snake
+	var bx, by float64
+	bestD := math.MaxFloat64
+	for _, s := range snakes {
+		if s == nil || !s.Alive {
+			continue
+		}
+		hx, hy := s.Head()
+		if d := (hx-x)*(hx-x) + (hy-y)*(hy-y); d < bestD {
+			best, bx, by, bestD = s, hx, hy, d
+		}
+	}
+	return best, bx, by
+}
+
 func pedForwardDir(p *Pedestrian) (fx, fy, speed float32) {
 	speed = float32(math.Hypot(p.VX, p.VY))
 	if speed >= 0.25 {
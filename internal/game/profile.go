@@ -0,0 +1,240 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ProfileFileName is the campaign profile inside DataDir.
+const ProfileFileName = "profile.json"
+
+// ProfileVersion is bumped when Profile changes incompatibly.
+const ProfileVersion = 1
+
+// LevelRecord holds the personal bests for one campaign level.
+type LevelRecord struct {
+	BestScore   int     `json:"best_score"`
+	BestTime    float64 `json:"best_time"` // fastest completion in seconds; 0 = never completed
+	Completions int     `json:"completions"`
+}
+
+// ModeRecord holds the bests for one of the non-campaign game modes.
+type ModeRecord struct {
+	BestScore int     `json:"best_score"`
+	BestTime  float64 `json:"best_time"` // longest run in seconds
+	Runs      int     `json:"runs"`
+
+	// Best score on the score attack map of the day DailySeed.
+	DailySeed uint64 `json:"daily_seed,omitempty"`
+	DailyBest int    `json:"daily_best,omitempty"`
+}
+
+// KillStats counts every kill across all runs.
+type KillStats struct {
+	Peds     int `json:"peds"`
+	Eaten    int `json:"eaten"`
+	Cars     int `json:"cars"`
+	Cops     int `json:"cops"`
+	CopCars  int `json:"cop_cars"`
+	Helis    int `json:"helis"`
+	Tanks    int `json:"tanks"`
+	Soldiers int `json:"soldiers"`
+}
+
+// Profile is campaign progress persisted between sessions.
+type Profile struct {
+	Version      int                    `json:"version"`
+	HighestLevel int                    `json:"highest_level"` // highest level ever started
+	BestRunScore int                    `json:"best_run_score"`
+	Levels       map[int]*LevelRecord   `json:"levels"`
+	Modes        map[string]*ModeRecord `json:"modes"`
+	Kills        KillStats              `json:"kills"`
+}
+
+// NewProfile returns an empty profile with only level 1 unlocked.
+func NewProfile() *Profile {
+	return &Profile{
+		Version:      ProfileVersion,
+		HighestLevel: 1,
+		Levels:       make(map[int]*LevelRecord),
+		Modes:        make(map[string]*ModeRecord),
+	}
+}
+
+// Level returns the record for level, creating it on first use.
+func (p *Profile) Level(level int) *LevelRecord {
+	rec := p.Levels[level]
+	if rec == nil {
+		rec = &LevelRecord{}
+		p.Levels[level] = rec
+	}
+	return rec
+}
+
+// Mode returns the record for a non-campaign mode, creating it on first use.
+func (p *Profile) Mode(mode GameMode) *ModeRecord {
+	key := gameModeKeys[mode]
+	rec := p.Modes[key]
+	if rec == nil {
+		rec = &ModeRecord{}
+		p.Modes[key] = rec
+	}
+	return rec
+}
+
+// MenuSummary describes the bests for the mode and start level selected on
+// the menu, or "" when there is nothing recorded yet.
+func (p *Profile) MenuSummary(session *GameSession) string {
+	if session.Mode != ModeCampaign {
+		rec := p.Modes[gameModeKeys[session.Mode]]
+		if rec == nil || rec.Runs == 0 {
+			return ""
+		}
+		line := fmt.Sprintf("%s best: %d", session.Mode, rec.BestScore)
+		if session.Mode == ModeEndless {
+			line += fmt.Sprintf("  %.0fs", rec.BestTime)
+		}
+		if session.Mode == ModeScoreAttack && rec.DailySeed == session.DailySeed {
+			line += fmt.Sprintf("   Today: %d", rec.DailyBest)
+		}
+		return line
+	}
+
+	level := session.MenuLevel
+	rec := p.Levels[level]
+	if rec == nil || rec.BestScore == 0 && rec.BestTime == 0 {
+		if p.BestRunScore > 0 {
+			return fmt.Sprintf("Best run: %d", p.BestRunScore)
+		}
+		return ""
+	}
+	line := fmt.Sprintf("Level %d best: %d", level, rec.BestScore)
+	if rec.BestTime > 0 {
+		line += fmt.Sprintf("  %.1fs", rec.BestTime)
+	}
+	if p.BestRunScore > 0 {
+		line += fmt.Sprintf("   Best run: %d", p.BestRunScore)
+	}
+	return line
+}
+
+// recordModeRun files a finished endless or score attack run. Versus has
+// no single score to keep a best of, so its matches are not filed.
+func (p *Profile) recordModeRun(session *GameSession) {
+	if session.Mode == ModeVersus {
+		return
+	}
+	rec := p.Mode(session.Mode)
+	rec.Runs++
+	rec.BestScore = max(rec.BestScore, session.Score)
+	rec.BestTime = max(rec.BestTime, session.Elapsed)
+	if session.Mode == ModeScoreAttack {
+		if rec.DailySeed != session.DailySeed {
+			rec.DailySeed, rec.DailyBest = session.DailySeed, 0
+		}
+		rec.DailyBest = max(rec.DailyBest, session.Score)
+	}
+}
+
+// Subscribe keeps the profile up to date from a simulation's events.
+// Campaign levels feed the level records; the other modes their own.
+func (p *Profile) Subscribe(eb *EventBus, session *GameSession) {
+	eb.Subscribe(EventLevelStarted, func(e Event) {
+		if session.Mode == ModeCampaign {
+			p.HighestLevel = max(p.HighestLevel, e.Data)
+		}
+	})
+	eb.Subscribe(EventLevelComplete, func(e Event) {
+		if session.Mode != ModeCampaign {
+			p.recordModeRun(session)
+			return
+		}
+		rec := p.Level(e.Data)
+		rec.Completions++
+		rec.BestScore = max(rec.BestScore, session.Score)
+		if rec.BestTime == 0 || session.Elapsed < rec.BestTime {
+			rec.BestTime = session.Elapsed
+		}
+		p.HighestLevel = max(p.HighestLevel, e.Data+1)
+		p.BestRunScore = max(p.BestRunScore, session.RunScore)
+	})
+	eb.Subscribe(EventLevelFailed, func(e Event) {
+		if session.Mode != ModeCampaign {
+			p.recordModeRun(session)
+			return
+		}
+		rec := p.Level(e.Data)
+		rec.BestScore = max(rec.BestScore, session.Score)
+		p.BestRunScore = max(p.BestRunScore, session.RunScore+session.Score)
+	})
+
+	k := &p.Kills
+	eb.Subscribe(EventPedKilled, func(Event) { k.Peds++ })
+	eb.Subscribe(EventPedEaten, func(Event) { k.Eaten++ })
+	eb.Subscribe(EventCarDestroyed, func(Event) { k.Cars++ })
+	eb.Subscribe(EventCopKilled, func(Event) { k.Cops++ })
+	eb.Subscribe(EventCopCarDestroyed, func(Event) { k.CopCars++ })
+	eb.Subscribe(EventHeliKilled, func(Event) { k.Helis++ })
+	eb.Subscribe(EventTankKilled, func(Event) { k.Tanks++ })
+	eb.Subscribe(EventSoldierKilled, func(Event) { k.Soldiers++ })
+}
+
+// ProfilePath returns where the profile is kept, or "" when there is no
+// writable data directory.
+func ProfilePath() string {
+	dir, err := DataDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, ProfileFileName)
+}
+
+// LoadProfile reads the profile at path. A missing file is a fresh profile.
+func LoadProfile(path string) (*Profile, error) {
+	p := NewProfile()
+	if path == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return NewProfile(), err
+	}
+	if p.Levels == nil {
+		p.Levels = make(map[int]*LevelRecord)
+	}
+	if p.Modes == nil {
+		p.Modes = make(map[string]*ModeRecord)
+	}
+	p.HighestLevel = max(p.HighestLevel, 1)
+	p.Version = ProfileVersion
+	return p, nil
+}
+
+// Save writes the profile to path, replacing the previous file atomically.
+func (p *Profile) Save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileRecordsRuns(t *testing.T) {
+	type end struct {
+		failed bool
+		score  int
+		time   float64 // seconds played
+	}
+	for _, tc := range []struct {
+		name  string
+		mode  GameMode
+		level int
+		ends  []end
+		check func(t *testing.T, p *Profile)
+	}{
+		{"campaign bests", ModeCampaign, 3, []end{{false, 500, 40}, {true, 900, 10}, {false, 300, 30}}, func(t *testing.T, p *Profile) {
+			want := LevelRecord{BestScore: 900, BestTime: 30, Completions: 2}
+			if got := *p.Levels[3]; got != want {
+				t.Errorf("level record %+v, want %+v", got, want)
+			}
+			if p.HighestLevel != 4 {
+				t.Errorf("highest level %d, want 4", p.HighestLevel)
+			}
+		}},
+		{"endless runs", ModeEndless, 1, []end{{true, 700, 0}, {true, 400, 0}}, func(t *testing.T, p *Profile) {
+			if rec := p.Modes["endless"]; rec == nil || rec.Runs != 2 || rec.BestScore != 700 {
+				t.Errorf("endless record %+v, want 2 runs with a best of 700", rec)
+			}
+			if len(p.Levels) != 0 || p.HighestLevel != 1 {
+				t.Errorf("an endless run touched the campaign: %+v", p)
+			}
+		}},
+		{"versus files nothing", ModeVersus, 1, []end{{false, 1200, 0}, {true, 50, 0}}, func(t *testing.T, p *Profile) {
+			if len(p.Modes) != 0 || len(p.Levels) != 0 {
+				t.Errorf("versus filed records: modes %v, levels %v", p.Modes, p.Levels)
+			}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewProfile()
+			eb := NewEventBus()
+			session := NewGameSession()
+			session.Mode = tc.mode
+			p.Subscribe(eb, session)
+			for _, e := range tc.ends {
+				session.Score, session.Elapsed = e.score, e.time
+				// The day clock runs on from wherever the level started it.
+				session.LevelTimer = 0.3*DayCyclePeriod + e.time
+				eb.Emit(Event{Type: EventLevelStarted, Data: tc.level})
+				typ := EventLevelComplete
+				if e.failed {
+					typ = EventLevelFailed
+				}
+				eb.Emit(Event{Type: typ, Data: tc.level})
+			}
+			tc.check(t, p)
+		})
+	}
+}
+
+func TestProfileSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", ProfileFileName)
+	p := NewProfile()
+	p.HighestLevel = 5
+	p.Level(2).BestScore = 1234
+	p.Mode(ModeScoreAttack).DailyBest = 99
+	p.Kills.Tanks = 3
+	if err := p.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadProfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("loaded %+v, saved %+v", got, p)
+	}
+
+	fresh, err := LoadProfile(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || !reflect.DeepEqual(fresh, NewProfile()) {
+		t.Errorf("missing file gave %+v, %v; want a fresh profile", fresh, err)
+	}
+}
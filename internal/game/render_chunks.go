@@ -1,4 +1,4 @@
-//go:build !android
+//go:build !android && !headless
 
 package game
 
@@ -36,7 +36,33 @@ func (r *Renderer) UploadChunk(c *Chunk) {
 	c.NeedsUpload = false
 }
 
-// DrawChunk renders a single chunk (assumes chunk program is active).
+// UploadDecals creates or refreshes the texture of a chunk's decal layer.
+func (r *Renderer) UploadDecals(c *Chunk) {
+	if c.DecalTex == 0 {
+		gl.GenTextures(1, &c.DecalTex)
+		gl.BindTexture(gl.TEXTURE_2D, c.DecalTex)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		gl.TexImage2D(
+			gl.TEXTURE_2D, 0, gl.RGBA8,
+			ChunkSize, ChunkSize, 0,
+			gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(c.Decals.Pix),
+		)
+	} else {
+		gl.BindTexture(gl.TEXTURE_2D, c.DecalTex)
+		gl.TexSubImage2D(
+			gl.TEXTURE_2D, 0, 0, 0,
+			ChunkSize, ChunkSize,
+			gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(c.Decals.Pix),
+		)
+	}
+	c.Decals.dirty = false
+}
+
+// DrawChunk renders a single chunk with its decals (assumes chunk program
+// is active).
 func (r *Renderer) DrawChunk(c *Chunk) {
 	if c == nil || c.Tex == 0 {
 		return
@@ -44,6 +70,14 @@ func (r *Renderer) DrawChunk(c *Chunk) {
 	baseX, baseY := c.WorldOrigin()
 	gl.Uniform2f(r.uChunkOrigin, float32(baseX), float32(baseY))
 	gl.Uniform1f(r.uRotation, 0)
+	if c.DecalTex != 0 {
+		gl.ActiveTexture(gl.TEXTURE2)
+		gl.BindTexture(gl.TEXTURE_2D, c.DecalTex)
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.Uniform1f(r.uDecalMix, 1)
+	} else {
+		gl.Uniform1f(r.uDecalMix, 0)
+	}
 	gl.BindTexture(gl.TEXTURE_2D, c.Tex)
 	gl.DrawArrays(gl.TRIANGLES, 0, 6)
 }
@@ -57,6 +91,12 @@ func (r *Renderer) DrawChunks(w *World, cam Camera, fbW, fbH int) {
 		X1: cam.X + halfW, Y1: cam.Y + halfH,
 	}
 
+	// Free the textures of chunks the world has dropped.
+	if len(w.freedTex) > 0 {
+		gl.DeleteTextures(int32(len(w.freedTex)), &w.freedTex[0])
+		w.freedTex = w.freedTex[:0]
+	}
+
 	var keys []ChunkKey
 	keys = w.VisibleChunks(view, keys)
 
@@ -77,6 +117,11 @@ func (r *Renderer) DrawChunks(w *World, cam Camera, fbW, fbH int) {
 		} else {
 			r.EnsureTexture(c)
 		}
+		if c.Decals != nil && (c.DecalTex == 0 || c.Decals.dirty) {
+			r.UploadDecals(c)
+		}
 		r.DrawChunk(c)
 	}
+	// Other draws with the chunk program have no decals.
+	gl.Uniform1f(r.uDecalMix, 0)
 }
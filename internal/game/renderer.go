@@ -1,81 +1,14 @@
-//go:build !android
+//go:build !android && !headless
 
 package game
 
 import (
 	"fmt"
-	"math"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
-const (
-	DayCyclePeriod = 90.0 // seconds of game time per full day/night cycle
-	SunAmbientMin  = 0.38 // midnight ambient floor (raised from 0.30)
-	SunAmbientMax  = 1.00 // noon ambient
-	SunNightStart  = 0.65 // ambient threshold where night lighting kicks in
-)
-
-// SunCycleLight computes ambient light level and color tint from game time.
-// Returns ambient (SunAmbientMin..SunAmbientMax), and tint RGB multipliers.
-func SunCycleLight(gameTime float64) (ambient, tintR, tintG, tintB float32) {
-	phase := math.Mod(gameTime, DayCyclePeriod) / DayCyclePeriod // 0..1
-	sunHeight := math.Sin(phase * 2 * math.Pi)                   // -1 (midnight) to 1 (noon)
-
-	// Ambient: SunAmbientMin (midnight) to SunAmbientMax (noon).
-	mid := float64(SunAmbientMin+SunAmbientMax) * 0.5
-	amp := float64(SunAmbientMax-SunAmbientMin) * 0.5
-	ambient = float32(mid + amp*sunHeight)
-
-	// Warm orange tint near horizon (sunHeight near 0 = sunset/sunrise).
-	horizonFactor := 1.0 - math.Abs(sunHeight)
-	warmth := horizonFactor * horizonFactor * 0.35
-	tintR = float32(1.0 + warmth*0.4)
-	tintG = float32(1.0 - warmth*0.15)
-	tintB = float32(1.0 - warmth*0.5)
-
-	// Slight blue tint at night.
-	if sunHeight < -0.3 {
-		nightFactor := float32((-sunHeight - 0.3) / 0.7)
-		// Slightly gentler night tint to keep visibility higher.
-		tintR -= nightFactor * 0.07
-		tintG -= nightFactor * 0.035
-		tintB += nightFactor * 0.10
-	}
-
-	return
-}
-
-// NightIntensityFromAmbient maps ambient light to a 0..1 night factor.
-// 0 at/above SunNightStart, 1 at SunAmbientMin.
-func NightIntensityFromAmbient(ambient float32) float32 {
-	denom := float64(SunNightStart - SunAmbientMin)
-	if denom <= 0 {
-		return 0
-	}
-	return float32(clampF((float64(SunNightStart)-float64(ambient))/denom, 0, 1))
-}
-
-// SunCycleShadow computes a continuous sun angle and shadow slope from game time.
-// The angle rotates smoothly so shadows sweep around as the sun crosses the sky.
-func SunCycleShadow(gameTime float64) (angle, slope float64) {
-	phase := math.Mod(gameTime, DayCyclePeriod) / DayCyclePeriod
-	sunHeight := math.Sin(phase * 2 * math.Pi)
-
-	// Sun angle rotates clockwise: dawn=east(0), noon=north(-π/2), dusk=west(-π), midnight=south(-3π/2).
-	angle = -phase * 2 * math.Pi
-
-	// Shadow slope: higher = shorter shadows.
-	// Daytime: 1.0 (horizon) to 3.0 (noon). Night: 1.0 (long shadows).
-	if sunHeight > 0 {
-		slope = 1.0 + sunHeight*2.0
-	} else {
-		slope = 1.0
-	}
-	return
-}
-
 // glOffset converts a byte offset to unsafe.Pointer for OpenGL VBO offset params.
 func glOffset(n int) unsafe.Pointer { return unsafe.Pointer(uintptr(n)) }
 
@@ -92,6 +25,8 @@ type Renderer struct {
 	uZoom         int32
 	uResolution   int32
 	uTex          int32
+	uDecals       int32
+	uDecalMix     int32
 	chunkUAmbient int32
 	chunkUSunTint int32
 
@@ -213,6 +148,10 @@ func NewRenderer() (*Renderer, error) {
 	r.uResolution = gl.GetUniformLocation(chunkProg, gl.Str("uResolution\x00"))
 	r.uTex = gl.GetUniformLocation(chunkProg, gl.Str("uTex\x00"))
 	gl.Uniform1i(r.uTex, 0)
+	r.uDecals = gl.GetUniformLocation(chunkProg, gl.Str("uDecals\x00"))
+	gl.Uniform1i(r.uDecals, 2)
+	r.uDecalMix = gl.GetUniformLocation(chunkProg, gl.Str("uDecalMix\x00"))
+	gl.Uniform1f(r.uDecalMix, 0)
 	r.chunkUAmbient = gl.GetUniformLocation(chunkProg, gl.Str("uAmbient\x00"))
 	r.chunkUSunTint = gl.GetUniformLocation(chunkProg, gl.Str("uSunTint\x00"))
 	gl.Uniform1f(r.chunkUAmbient, 1.0)
@@ -0,0 +1,245 @@
+package game
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Replay file layout (little endian):
+//
+//	magic "SNKR", u16 version
+//	u64 seed, i32 level, u64 theme roll, i32 last theme index, f64 dt,
+//	u8 game mode, u64 daily seed
+//	u32 frame count, then per frame:
+//	  u8 flags (bit0 idle, bit1 click, bit2 rivals), f64 steer,
+//	  [i16 click x, i16 click y], [per rival: u8 idle, f64 steer]
+const (
+	replayMagic   = "SNKR"
+	ReplayVersion = 3
+
+	replayFlagIdle  = 1 << 0
+	replayFlagClick = 1 << 1
+	replayFlagRival = 1 << 2
+
+	// replayPrealloc caps the frames reserved up front from the header's
+	// count (ten minutes of ticks); a longer replay grows as it is read, so
+	// a corrupt count cannot allocate more than the file holds.
+	replayPrealloc = 10 * 60 * 60
+)
+
+// ReplayHeader captures everything needed to rebuild a level start exactly.
+type ReplayHeader struct {
+	Seed         uint64
+	Level        int
+	ThemeRoll    uint64 // GameSession.ThemeRoll before the level started
+	LastThemeIdx int    // GameSession.LastThemeIdx before the level started
+	DT           float64
+	Mode         GameMode
+	DailySeed    uint64 // GameSession.DailySeed (score attack map)
+}
+
+// Replay is one recorded level attempt: a header plus one input per tick.
+type Replay struct {
+	Header ReplayHeader
+	Frames []InputFrame
+}
+
+// Write encodes the replay in the binary replay format.
+func (rep *Replay) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	h := rep.Header
+	put := func(v any) {
+		_ = binary.Write(bw, binary.LittleEndian, v)
+	}
+	bw.WriteString(replayMagic)
+	put(uint16(ReplayVersion))
+	put(h.Seed)
+	put(int32(h.Level))
+	put(h.ThemeRoll)
+	put(int32(h.LastThemeIdx))
+	put(h.DT)
+	put(uint8(h.Mode))
+	put(h.DailySeed)
+	put(uint32(len(rep.Frames)))
+	for _, f := range rep.Frames {
+		var flags uint8
+		if f.Idle {
+			flags |= replayFlagIdle
+		}
+		if f.Click {
+			flags |= replayFlagClick
+		}
+		if f.Rivals != ([MaxPlayers - 1]PlayerInput{}) {
+			flags |= replayFlagRival
+		}
+		put(flags)
+		put(math.Float64bits(f.Steer))
+		if f.Click {
+			put(int16(f.ClickX))
+			put(int16(f.ClickY))
+		}
+		if flags&replayFlagRival != 0 {
+			for _, p := range f.Rivals {
+				var idle uint8
+				if p.Idle {
+					idle = 1
+				}
+				put(idle)
+				put(math.Float64bits(p.Steer))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadReplay decodes a replay written by Replay.Write.
+func ReadReplay(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("replay header: %w", err)
+	}
+	if string(magic) != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+
+	var hdr struct {
+		Version      uint16
+		Seed         uint64
+		Level        int32
+		ThemeRoll    uint64
+		LastThemeIdx int32
+		DT           float64
+		Mode         uint8
+		DailySeed    uint64
+		Frames       uint32
+	}
+	if err := binary.Read(br, binary.LittleEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("replay header: %w", err)
+	}
+	if hdr.Version != ReplayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d", hdr.Version)
+	}
+
+	rep := &Replay{
+		Header: ReplayHeader{
+			Seed:         hdr.Seed,
+			Level:        int(hdr.Level),
+			ThemeRoll:    hdr.ThemeRoll,
+			LastThemeIdx: int(hdr.LastThemeIdx),
+			DT:           hdr.DT,
+			Mode:         GameMode(hdr.Mode),
+			DailySeed:    hdr.DailySeed,
+		},
+		Frames: make([]InputFrame, 0, min(hdr.Frames, replayPrealloc)),
+	}
+	for i := uint32(0); i < hdr.Frames; i++ {
+		var fr struct {
+			Flags uint8
+			Steer uint64
+		}
+		if err := binary.Read(br, binary.LittleEndian, &fr); err != nil {
+			return nil, fmt.Errorf("replay frame %d: %w", i, err)
+		}
+		f := InputFrame{
+			Steer: math.Float64frombits(fr.Steer),
+			Idle:  fr.Flags&replayFlagIdle != 0,
+			Click: fr.Flags&replayFlagClick != 0,
+		}
+		if f.Click {
+			var xy [2]int16
+			if err := binary.Read(br, binary.LittleEndian, &xy); err != nil {
+				return nil, fmt.Errorf("replay frame %d: %w", i, err)
+			}
+			f.ClickX, f.ClickY = int(xy[0]), int(xy[1])
+		}
+		if fr.Flags&replayFlagRival != 0 {
+			for j := range f.Rivals {
+				var rv struct {
+					Idle  uint8
+					Steer uint64
+				}
+				if err := binary.Read(br, binary.LittleEndian, &rv); err != nil {
+					return nil, fmt.Errorf("replay frame %d: %w", i, err)
+				}
+				f.Rivals[j] = PlayerInput{Steer: math.Float64frombits(rv.Steer), Idle: rv.Idle != 0}
+			}
+		}
+		rep.Frames = append(rep.Frames, f)
+	}
+	return rep, nil
+}
+
+// SaveReplayFile writes a replay to path.
+func SaveReplayFile(path string, rep *Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := rep.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadReplayFile reads a replay from path.
+func LoadReplayFile(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+// NewReplaySimulation builds a simulation positioned at the recorded level start.
+func NewReplaySimulation(rep *Replay) *Simulation {
+	sim := NewSimulation(rep.Header.Seed)
+	sim.Session.ThemeRoll = rep.Header.ThemeRoll
+	sim.Session.LastThemeIdx = rep.Header.LastThemeIdx
+	sim.Session.Mode = rep.Header.Mode
+	sim.Session.DailySeed = rep.Header.DailySeed
+	sim.StartLevel(rep.Header.Level)
+	return sim
+}
+
+// ReplayPlayer hands out recorded input frames in order.
+type ReplayPlayer struct {
+	Replay *Replay
+	next   int
+}
+
+// Next returns the next recorded frame, or false when the replay is exhausted.
+func (p *ReplayPlayer) Next() (InputFrame, bool) {
+	if p.next >= len(p.Replay.Frames) {
+		return InputFrame{}, false
+	}
+	f := p.Replay.Frames[p.next]
+	p.next++
+	return f, true
+}
+
+// Done reports whether every frame has been played.
+func (p *ReplayPlayer) Done() bool {
+	return p.next >= len(p.Replay.Frames)
+}
+
+// RunReplay plays a whole replay headlessly and returns the final simulation.
+func RunReplay(rep *Replay) *Simulation {
+	sim := NewReplaySimulation(rep)
+	player := &ReplayPlayer{Replay: rep}
+	for {
+		in, ok := player.Next()
+		if !ok {
+			break
+		}
+		sim.Step(rep.Header.DT, in)
+	}
+	return sim
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"testing"
+)
+
+// simState summarises a simulation for comparing two runs: the session,
+// every snake, and hashes of where the peds, cars, cops and troops are.
+func simState(sim *Simulation) string {
+	var b strings.Builder
+	s := sim.Session
+	fmt.Fprintf(&b, "state %d level %d score %d timer %.6f\n", s.State, s.CurrentLevel, s.Score, s.LevelTimer)
+	for i, sn := range sim.Snakes {
+		if sn == nil || len(sn.Path) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "snake %d %.6f,%.6f len %.4f hp %.4f score %d\n",
+			i, sn.Path[0].X, sn.Path[0].Y, sn.Length, sn.HP.Current, sn.Score)
+	}
+	h := fnv.New64a()
+	put := func(vs ...float64) {
+		for _, v := range vs {
+			binary.Write(h, binary.LittleEndian, math.Float64bits(v))
+		}
+	}
+	alive := 0
+	for _, p := range sim.Peds.P {
+		if p.Alive {
+			alive++
+			put(p.X, p.Y)
+		}
+	}
+	for _, c := range sim.Traffic.Cars {
+		if c.Alive {
+			put(c.X, c.Y, c.Heading)
+		}
+	}
+	for _, c := range sim.Cops.Cars {
+		if c.Alive {
+			put(c.X, c.Y)
+		}
+	}
+	for _, c := range sim.Cops.Peds {
+		if c.Alive {
+			put(c.X, c.Y)
+		}
+	}
+	for _, t := range sim.Mil.Tanks {
+		if t.Alive {
+			put(t.X, t.Y)
+		}
+	}
+	for _, t := range sim.Mil.Troops {
+		if t.Alive {
+			put(t.X, t.Y)
+		}
+	}
+	fmt.Fprintf(&b, "peds %d entities %016x\n", alive, h.Sum64())
+	return b.String()
+}
+
+// steerFor is scripted input for tick n: a slow weave, idle now and then.
+func steerFor(n int) InputFrame {
+	return InputFrame{Steer: math.Sin(float64(n)*0.013) * 2.5, Idle: n%97 < 12}
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		seed  uint64
+		level int
+		mode  GameMode
+		ticks int
+	}{
+		{"campaign", 3, 1, ModeCampaign, 600},
+		{"later level", 11, 4, ModeCampaign, 400},
+		{"endless", 99, 1, ModeEndless, 400},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := NewSimulation(tc.seed)
+			sim.Session.Mode = tc.mode
+			sim.RecordReplay = true
+			sim.StartLevel(tc.level)
+			for n := 0; n < tc.ticks && sim.Session.State == StatePlaying; n++ {
+				sim.Step(SimTickDT, steerFor(n))
+			}
+			rep := sim.Replay
+			if rep == nil {
+				t.Fatal("level ended before the replay could be checked")
+			}
+
+			var buf bytes.Buffer
+			if err := rep.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadReplay(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Header != rep.Header || len(got.Frames) != len(rep.Frames) {
+				t.Fatalf("header %+v, %d frames; want %+v, %d frames", got.Header, len(got.Frames), rep.Header, len(rep.Frames))
+			}
+			for i := range got.Frames {
+				if got.Frames[i] != rep.Frames[i] {
+					t.Fatalf("frame %d: %+v, want %+v", i, got.Frames[i], rep.Frames[i])
+				}
+			}
+
+			if a, b := simState(RunReplay(got)), simState(sim); a != b {
+				t.Errorf("replayed run ended\n%s\nrecorded run ended\n%s", a, b)
+			}
+		})
+	}
+}
+
+func TestReadReplayRejectsBadInput(t *testing.T) {
+	var good bytes.Buffer
+	rep := &Replay{Header: ReplayHeader{Seed: 5, Level: 2, DT: SimTickDT}, Frames: []InputFrame{{Steer: 1}, {Click: true, ClickX: 3, ClickY: 4}}}
+	if err := rep.Write(&good); err != nil {
+		t.Fatal(err)
+	}
+	// The header's frame count sits just before the first frame.
+	countAt := len(replayMagic) + 2 + 8 + 4 + 8 + 4 + 8 + 1 + 8
+	huge := bytes.Clone(good.Bytes()[:countAt])
+	huge = binary.LittleEndian.AppendUint32(huge, math.MaxUint32)
+	old := bytes.Clone(good.Bytes())
+	old[len(replayMagic)] = ReplayVersion - 1
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "replay header"},
+		{"bad magic", []byte("SNKX\x03\x00"), "not a replay file"},
+		{"old version", old, "unsupported replay version"},
+		{"truncated frames", good.Bytes()[:good.Len()-3], "replay frame 1"},
+		{"huge frame count", huge, "replay frame 0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadReplay(bytes.NewReader(tc.data))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tc.want)
+			}
+		})
+	}
+}
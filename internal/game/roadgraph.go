@@ -0,0 +1,642 @@
+package game
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Road graph. The streets of a generated city as a graph cars plan their
+// way by: a node wherever a road running north-south crosses one running
+// east-west, the ring road included, and an edge along each stretch of road
+// between neighbouring nodes. Damage that leaves no road across a stretch
+// cuts its edge, so routes go round it. Cars drive from trip to trip: to a
+// parking lot, out to the ring road or across town. Cop cars route to the
+// snake.
+// Hand-drawn maps have no graph and their cars wander as they always have.
+
+// roadEdge is a stretch of road between two neighbouring nodes.
+type roadEdge struct {
+	road   bool    // generation laid a road here
+	dirty  bool    // its pixels changed since cut was worked out
+	cut    bool    // no road left right across it somewhere
+	lo, hi float64 // where along it the cuts start and end
+}
+
+// roadLot is a parking lot: where in it cars park and the stretch of road,
+// from node a to b, it opens onto.
+type roadLot struct {
+	x, y float64
+	a, b int
+}
+
+type roadGraph struct {
+	xs, ys []float64  // centre lines of the north-south and east-west roads
+	east   []roadEdge // by node: the stretch to the next node east
+	south  []roadEdge // and to the next node south
+	lots   []roadLot
+	exits  []int // nodes where a street meets the ring road
+
+	// A* scratch, by node.
+	cost  []float64
+	prev  []int32
+	stamp []uint32 // cost and prev are current where stamp is gen
+	gen   uint32
+}
+
+const (
+	routeJitter = 0.3 // how much dearer a car may find a stretch, so cars don't all take one way
+	parkSpeed   = 8.0 // px/s, pulling into a lot
+	laneOffset  = 1.2 // how far right of the middle of the road cars keep
+	parkReach   = RoadWidth/2 + SidewalkWidth + 3
+)
+
+// routes returns the world's road graph, building it on first use. It is
+// nil for a hand-drawn map.
+func (w *World) routes() *roadGraph {
+	if w.Map != nil {
+		return nil
+	}
+	if w.graph == nil {
+		w.graph = newRoadGraph(w)
+	}
+	return w.graph
+}
+
+// newRoadGraph lays the graph over the roads generation solved: the ring
+// road and each grid road clear of it. Every edge starts dirty, so the
+// first route over it looks at what is left of the road.
+func newRoadGraph(w *World) *roadGraph {
+	rn := w.roadLayout()
+	ring := float64(BorderThickness) + RoadWidth*0.5
+	inner := BorderThickness + RoadWidth
+	g := &roadGraph{}
+	lines := func(size int) []float64 {
+		out := []float64{ring}
+		for k := 1; !w.Theme.NoRoads && k*Pattern+RoadWidth <= size-inner; k++ {
+			out = append(out, float64(k*Pattern)+RoadWidth*0.5)
+		}
+		return append(out, float64(size)-ring)
+	}
+	g.xs, g.ys = lines(w.Width), lines(w.Height)
+	cols, rows := len(g.xs), len(g.ys)
+	n := cols * rows
+	g.east = make([]roadEdge, n)
+	g.south = make([]roadEdge, n)
+	g.cost = make([]float64, n)
+	g.prev = make([]int32, n)
+	g.stamp = make([]uint32, n)
+
+	// A stretch of grid road is there if none of the cells it runs through
+	// closed it, by the same rule the painter follows.
+	vertOpen := func(k, y0, y1 int) bool {
+		for cy := floorDiv(y0, Pattern); y0 < y1 && cy <= floorDiv(y1-1, Pattern); cy++ {
+			if k > 0 && k < rn.gridW && cy >= 0 && cy < rn.gridH && !rn.vertOpen[k][cy] {
+				return false
+			}
+		}
+		return true
+	}
+	horzOpen := func(k, x0, x1 int) bool {
+		for cx := floorDiv(x0, Pattern); x0 < x1 && cx <= floorDiv(x1-1, Pattern); cx++ {
+			if k > 0 && k < rn.gridH && cx >= 0 && cx < rn.gridW && !rn.horzOpen[cx][k] {
+				return false
+			}
+		}
+		return true
+	}
+	for r := range rows {
+		for c := range cols {
+			id := r*cols + c
+			ringCol := c == 0 || c == cols-1
+			ringRow := r == 0 || r == rows-1
+			if c+1 < cols {
+				x0, x1 := int(g.xs[c]+RoadWidth*0.5), int(g.xs[c+1]-RoadWidth*0.5)
+				g.east[id] = roadEdge{road: ringRow || horzOpen(r, x0, x1), dirty: true}
+			}
+			if r+1 < rows {
+				y0, y1 := int(g.ys[r]+RoadWidth*0.5), int(g.ys[r+1]-RoadWidth*0.5)
+				g.south[id] = roadEdge{road: ringCol || vertOpen(c, y0, y1), dirty: true}
+			}
+		}
+	}
+
+	for r := range rows {
+		for c := range cols {
+			if (c == 0 || c == cols-1 || r == 0 || r == rows-1) && g.degree(r*cols+c) > 2 {
+				g.exits = append(g.exits, r*cols+c)
+			}
+		}
+	}
+
+	// Parking lots fill whole blocks; cars pull in from the middle of a side
+	// with a road along it. Lots are looked for in the terrain as generated,
+	// not as it is now, so the graph is the same whenever it is first built,
+	// in a loaded game too. The terrain is generated aside and dropped, so
+	// building the graph loads no chunks.
+	if !w.Theme.NoRoads {
+		parcels := newParcelLookup(w.seed, w.Theme, w.Width, w.Height)
+		tp := buildThemePalette(w.Theme)
+		pristine := map[ChunkKey]*Chunk{}
+		lot := func(x, y int) bool {
+			if !w.InBounds(x, y) {
+				return false
+			}
+			key := ChunkKey{X: x / ChunkSize, Y: y / ChunkSize}
+			c, ok := pristine[key]
+			if !ok {
+				c = w.generateRaw(key.X, key.Y)
+				pristine[key] = c
+			}
+			return parkingLotAt(c, tp, (y-key.Y*ChunkSize)*ChunkSize+x-key.X*ChunkSize)
+		}
+		mid := float64(Pattern+RoadWidth) * 0.5
+		near := float64(RoadWidth + SidewalkWidth + 2)
+		far := float64(Pattern - SidewalkWidth - 2)
+		for by := 0; by+1 < rows; by++ {
+			for bx := 0; bx+1 < cols; bx++ {
+				if parcels.get(bx, by).Kind != parcelParking {
+					continue
+				}
+				ox, oy := float64(bx*Pattern), float64(by*Pattern)
+				g.addLot(lot, ox+near, oy+mid, g.node(bx, by), g.node(bx, by+1))
+				g.addLot(lot, ox+far, oy+mid, g.node(bx+1, by), g.node(bx+1, by+1))
+				g.addLot(lot, ox+mid, oy+near, g.node(bx, by), g.node(bx+1, by))
+				g.addLot(lot, ox+mid, oy+far, g.node(bx, by+1), g.node(bx+1, by+1))
+			}
+		}
+	}
+	return g
+}
+
+// addLot adds a way into a parking lot at x, y from the stretch a to b, if
+// lot finds the lot there and the road runs close by.
+func (g *roadGraph) addLot(lot func(x, y int) bool, x, y float64, a, b int) {
+	e := g.edge(a, b)
+	if e == nil || !e.road || !lot(int(x), int(y)) {
+		return
+	}
+	ax, ay := g.pos(a)
+	bx, by := g.pos(b)
+	if ax == bx && math.Abs(x-ax) > parkReach || ay == by && math.Abs(y-ay) > parkReach {
+		return
+	}
+	g.lots = append(g.lots, roadLot{x: x, y: y, a: a, b: b})
+}
+
+func (g *roadGraph) node(c, r int) int { return r*len(g.xs) + c }
+
+// pos is the centre of node n.
+func (g *roadGraph) pos(n int) (float64, float64) {
+	return g.xs[n%len(g.xs)], g.ys[n/len(g.xs)]
+}
+
+// edge is the stretch between neighbouring nodes a and b, or nil if they
+// aren't neighbours.
+func (g *roadGraph) edge(a, b int) *roadEdge {
+	cols := len(g.xs)
+	if a > b {
+		a, b = b, a
+	}
+	switch {
+	case b == a+1 && b%cols != 0:
+		return &g.east[a]
+	case b == a+cols:
+		return &g.south[a]
+	}
+	return nil
+}
+
+// degree is how many stretches of road meet at node n.
+func (g *roadGraph) degree(n int) int {
+	d := 0
+	for _, m := range g.neighbours(n) {
+		if m >= 0 && g.edge(n, m).road {
+			d++
+		}
+	}
+	return d
+}
+
+// neighbours are the nodes east, south, west and north of n, -1 past the
+// edge of the graph.
+func (g *roadGraph) neighbours(n int) [4]int {
+	cols, rows := len(g.xs), len(g.ys)
+	c, r := n%cols, n/cols
+	out := [4]int{-1, -1, -1, -1}
+	if c+1 < cols {
+		out[0] = n + 1
+	}
+	if r+1 < rows {
+		out[1] = n + cols
+	}
+	if c > 0 {
+		out[2] = n - 1
+	}
+	if r > 0 {
+		out[3] = n - cols
+	}
+	return out
+}
+
+// open reports whether cars can drive the stretch from a to b.
+func (g *roadGraph) open(w *World, a, b int) bool {
+	e := g.edge(a, b)
+	if e == nil || !e.road {
+		return false
+	}
+	g.refresh(w, a, b, e)
+	return !e.cut
+}
+
+// refresh works out again whether a dirty stretch is cut: somewhere along
+// it, no pixel right across the road is road any more.
+func (g *roadGraph) refresh(w *World, a, b int, e *roadEdge) {
+	if !e.dirty {
+		return
+	}
+	e.dirty, e.cut = false, false
+	tp := buildThemePalette(w.Theme)
+	ax, ay := g.pos(min(a, b))
+	bx, by := g.pos(max(a, b))
+	horiz := ay == by
+	p0, p1, across := int(ay), int(by), int(ax-RoadWidth*0.5)
+	if horiz {
+		p0, p1, across = int(ax), int(bx), int(ay-RoadWidth*0.5)
+	}
+	for p := p0; p <= p1; p++ {
+		clear := false
+		for q := across; q < across+RoadWidth && !clear; q++ {
+			if horiz {
+				clear = isRoadPixel(w, tp, p, q)
+			} else {
+				clear = isRoadPixel(w, tp, q, p)
+			}
+		}
+		if clear {
+			continue
+		}
+		if !e.cut {
+			e.cut, e.lo = true, float64(p)
+		}
+		e.hi = float64(p) + 1
+	}
+}
+
+// roadsChanged marks the stretches of road over x0..x1, y0..y1 to be
+// looked at again.
+func (w *World) roadsChanged(x0, y0, x1, y1 int) {
+	g := w.graph
+	if g == nil {
+		return
+	}
+	const half = RoadWidth * 0.5
+	cols := len(g.xs)
+	for r, y := range g.ys {
+		if float64(y1) < y-half || float64(y0) >= y+half {
+			continue
+		}
+		for c := 0; c+1 < cols; c++ {
+			if float64(x1) >= g.xs[c]-half && float64(x0) < g.xs[c+1]+half {
+				g.east[g.node(c, r)].dirty = true
+			}
+		}
+	}
+	for c, x := range g.xs {
+		if float64(x1) < x-half || float64(x0) >= x+half {
+			continue
+		}
+		for r := 0; r+1 < len(g.ys); r++ {
+			if float64(y1) >= g.ys[r]-half && float64(y0) < g.ys[r+1]+half {
+				g.south[g.node(c, r)].dirty = true
+			}
+		}
+	}
+}
+
+// cutAhead reports whether the stretch from a to b is cut further along
+// than x, y, going towards b.
+func (g *roadGraph) cutAhead(w *World, a, b int, x, y float64) bool {
+	e := g.edge(a, b)
+	if e == nil {
+		return false
+	}
+	g.refresh(w, a, b, e)
+	if !e.cut {
+		return false
+	}
+	ax, ay := g.pos(a)
+	bx, by := g.pos(b)
+	p, pa, pb := y, ay, by
+	if ay == by {
+		p, pa, pb = x, ax, bx
+	}
+	if pb > pa {
+		return e.hi > p+1
+	}
+	return e.lo < p-1
+}
+
+// lane is the line cars from node a to its neighbour b keep to, off to
+// the right of the middle of the road: a y if the road runs east-west
+// (horiz), else an x.
+func (g *roadGraph) lane(a, b int, off float64) (v float64, horiz bool) {
+	ax, ay := g.pos(a)
+	bx, by := g.pos(b)
+	switch {
+	case ay == by && bx > ax:
+		return ay + off, true
+	case ay == by:
+		return ay - off, true
+	case by > ay:
+		return ax - off, false
+	}
+	return ax + off, false
+}
+
+// heading is the direction from node a to its neighbour b.
+func (g *roadGraph) heading(a, b int) float64 {
+	ax, ay := g.pos(a)
+	bx, by := g.pos(b)
+	return math.Atan2(by-ay, bx-ax)
+}
+
+// roadLine finds the road of lines within half a road width of v, or -1.
+func roadLine(lines []float64, v float64) int {
+	for i, l := range lines {
+		if math.Abs(v-l) <= RoadWidth*0.5 {
+			return i
+		}
+	}
+	return -1
+}
+
+// nodeAt is the node whose junction box holds x, y, or -1.
+func (g *roadGraph) nodeAt(x, y float64) int {
+	c, r := roadLine(g.xs, x), roadLine(g.ys, y)
+	if c < 0 || r < 0 {
+		return -1
+	}
+	return g.node(c, r)
+}
+
+// locate finds where on the graph something at x, y facing heading is: in
+// a junction box, a and b are both its node; on a stretch of road, a is
+// the node behind and b the one ahead.
+func (g *roadGraph) locate(x, y, heading float64) (a, b int, ok bool) {
+	c, r := roadLine(g.xs, x), roadLine(g.ys, y)
+	switch {
+	case c >= 0 && r >= 0:
+		n := g.node(c, r)
+		return n, n, true
+	case c >= 0:
+		for r = 0; r+1 < len(g.ys) && g.ys[r+1] < y; r++ {
+		}
+		a, b = g.node(c, r), g.node(c, r+1)
+		if math.Sin(heading) < 0 {
+			a, b = b, a
+		}
+	case r >= 0:
+		for c = 0; c+1 < len(g.xs) && g.xs[c+1] < x; c++ {
+		}
+		a, b = g.node(c, r), g.node(c+1, r)
+		if math.Cos(heading) < 0 {
+			a, b = b, a
+		}
+	default:
+		return 0, 0, false
+	}
+	if e := g.edge(a, b); e == nil || !e.road {
+		return 0, 0, false
+	}
+	return a, b, true
+}
+
+// onStretch reports whether x, y is still on or beside the road from a to b.
+func (g *roadGraph) onStretch(a, b int, x, y float64) bool {
+	ax, ay := g.pos(a)
+	bx, by := g.pos(b)
+	const reach = RoadWidth*0.5 + SidewalkWidth
+	return x >= min(ax, bx)-reach && x <= max(ax, bx)+reach &&
+		y >= min(ay, by)-reach && y <= max(ay, by)+reach
+}
+
+// nearest is the node with a road to it closest to x, y, or -1.
+func (g *roadGraph) nearest(x, y float64) int {
+	best, bestD := -1, math.Inf(1)
+	for n := range g.east {
+		nx, ny := g.pos(n)
+		if d := math.Abs(nx-x) + math.Abs(ny-y); d < bestD && g.degree(n) > 0 {
+			best, bestD = n, d
+		}
+	}
+	return best
+}
+
+// length is the distance between neighbouring nodes a and b.
+func (g *roadGraph) length(a, b int) float64 {
+	ax, ay := g.pos(a)
+	bx, by := g.pos(b)
+	return math.Abs(bx-ax) + math.Abs(by-ay)
+}
+
+// routeItem is a node on the A* frontier.
+type routeItem struct {
+	n int
+	f float64
+}
+
+type routeQueue []routeItem
+
+func (q routeQueue) Len() int           { return len(q) }
+func (q routeQueue) Less(i, j int) bool { return q[i].f < q[j].f }
+func (q routeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x any)        { *q = append(*q, x.(routeItem)) }
+func (q *routeQueue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
+
+// path is the shortest way over open roads from node from to node to, both
+// included, or nil if there is none. seed varies what each stretch costs a
+// little, so different drivers take different ways.
+func (g *roadGraph) path(w *World, from, to int, seed uint64) []int {
+	if from == to {
+		return []int{from}
+	}
+	g.gen++
+	tx, ty := g.pos(to)
+	estimate := func(n int) float64 {
+		x, y := g.pos(n)
+		return math.Abs(tx-x) + math.Abs(ty-y)
+	}
+	g.cost[from], g.prev[from], g.stamp[from] = 0, -1, g.gen
+	q := routeQueue{{n: from, f: estimate(from)}}
+	for q.Len() > 0 {
+		it := heap.Pop(&q).(routeItem)
+		n := it.n
+		if n == to {
+			break
+		}
+		if it.f > g.cost[n]+estimate(n) {
+			continue // stale
+		}
+		for dir, m := range g.neighbours(n) {
+			if m < 0 || !g.open(w, n, m) {
+				continue
+			}
+			jitter := float64(hash2D(seed, min(n, m), dir%2)>>11) / (1 << 53)
+			cost := g.cost[n] + g.length(n, m)*(1+routeJitter*jitter)
+			if g.stamp[m] == g.gen && g.cost[m] <= cost {
+				continue
+			}
+			g.cost[m], g.prev[m], g.stamp[m] = cost, int32(n), g.gen
+			heap.Push(&q, routeItem{n: m, f: cost + estimate(m)})
+		}
+	}
+	if g.stamp[to] != g.gen {
+		return nil
+	}
+	var out []int
+	for n := to; n >= 0; n = int(g.prev[n]) {
+		out = append(out, n)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// routeHeading is the way out of the junction box at x, y that leads over
+// open roads towards tx, ty. ok is false off the graph, away from the
+// junctions or with no way there.
+func (w *World) routeHeading(x, y, tx, ty float64, seed uint64) (heading float64, ok bool) {
+	g := w.routes()
+	if g == nil {
+		return 0, false
+	}
+	from, goal := g.nodeAt(x, y), g.nearest(tx, ty)
+	if from < 0 || goal < 0 || from == goal {
+		return 0, false
+	}
+	p := g.path(w, from, goal, seed)
+	if len(p) < 2 {
+		return 0, false
+	}
+	return g.heading(p[0], p[1]), true
+}
+
+// planTrip sends car i somewhere new from where it is on the graph: into a
+// parking lot or out to the ring road. It reports whether the car has a
+// route.
+func (ts *TrafficSystem) planTrip(i int, w *World, g *roadGraph) bool {
+	c := &ts.Cars[i]
+	c.Route, c.ToLot = nil, false
+	a, b, ok := g.locate(c.X, c.Y, c.Heading)
+	if !ok {
+		return false
+	}
+	c.TurnCount++
+	r := NewRand(ts.seed ^ uint64(i+1)*0x7219 ^ uint64(c.TurnCount)*0x3C5)
+	seed := ts.seed ^ uint64(i+1)*0x9E37
+
+	// route is the way from where the car is to node goal: on from b, or
+	// back through a if that's the only way.
+	route := func(goal int) []int {
+		if p := g.path(w, b, goal, seed); p != nil {
+			if a == b {
+				return p
+			}
+			return append([]int{a}, p...)
+		}
+		if p := g.path(w, a, goal, seed); p != nil && a != b {
+			return append([]int{b}, p...)
+		}
+		return nil
+	}
+
+	if len(g.lots) > 0 && r.Intn(100) < 35 {
+		lot := g.lots[r.Intn(len(g.lots))]
+		// Come along the lot's stretch of road from whichever end is nearer.
+		via, past := lot.a, lot.b
+		pa, pb := route(lot.a), route(lot.b)
+		if pb != nil && (pa == nil || len(pb) < len(pa)) {
+			via, past, pa = lot.b, lot.a, pb
+		}
+		if pa != nil && g.open(w, via, past) {
+			c.Route = append(pa, past)
+			c.ToLot = true
+			c.LotX, c.LotY = lot.x, lot.y
+			return true
+		}
+	}
+
+	goal := g.nearest(r.RangeF(0, float64(w.Width)), r.RangeF(0, float64(w.Height)))
+	if len(g.exits) > 0 && r.Intn(100) < 50 {
+		goal = g.exits[r.Intn(len(g.exits))]
+	}
+	if goal < 0 {
+		return false
+	}
+	c.Route = route(goal)
+	return len(c.Route) >= 2
+}
+
+// followRoute steers car i along its route, planning a new trip when it
+// has none, and parks it when it reaches its lot. It reports whether the
+// car is on a route; one that isn't wanders.
+func (ts *TrafficSystem) followRoute(i int, w *World, g *roadGraph) bool {
+	c := &ts.Cars[i]
+	if c.Parking {
+		dx, dy := c.LotX-c.X, c.LotY-c.Y
+		if math.Hypot(dx, dy) < 1.2 {
+			r := NewRand(ts.seed ^ uint64(i+1)*0x9A4C ^ uint64(c.TurnCount))
+			c.Parking, c.ToLot, c.Route = false, false, nil
+			c.Parked, c.LotParked = true, true
+			c.ParkTimer = r.RangeF(25, 70)
+			c.Speed, c.VX, c.VY = 0, 0, 0
+			return true
+		}
+		c.TurnTarget = math.Atan2(dy, dx)
+		c.Speed = min(c.Speed, parkSpeed)
+		return true
+	}
+
+	if len(c.Route) >= 2 && !g.onStretch(c.Route[0], c.Route[1], c.X, c.Y) {
+		c.Route = nil // knocked off its way
+	}
+	if len(c.Route) < 2 && !ts.planTrip(i, w, g) {
+		return false
+	}
+	a, b := c.Route[0], c.Route[1]
+	bx, by := g.pos(b)
+	if math.Abs(c.X-bx) <= RoadWidth*0.5 && math.Abs(c.Y-by) <= RoadWidth*0.5 {
+		// Through the junction and on, or somewhere new when the trip is
+		// over or the way ahead has gone.
+		c.Route = c.Route[1:]
+		if len(c.Route) < 2 || !g.open(w, c.Route[0], c.Route[1]) {
+			if !ts.planTrip(i, w, g) {
+				return false
+			}
+		}
+		a, b = c.Route[0], c.Route[1]
+	} else if g.cutAhead(w, a, b, c.X, c.Y) {
+		// The road ahead is gone: turn back and think again at the last
+		// junction.
+		c.Route, c.ToLot = []int{b, a}, false
+		a, b = b, a
+	}
+
+	if c.ToLot && len(c.Route) == 2 {
+		ax, ay := g.pos(a)
+		bx, by := g.pos(b)
+		if (bx-ax)*(c.X-c.LotX)+(by-ay)*(c.Y-c.LotY) >= 0 {
+			c.Parking = true
+			c.TurnTarget = math.Atan2(c.LotY-c.Y, c.LotX-c.X)
+			return true
+		}
+	}
+	c.TurnTarget = g.heading(a, b)
+	return true
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func themeNamed(t *testing.T, name string) ThemeConfig {
+	t.Helper()
+	for _, th := range Themes {
+		if th.Name == name {
+			return th
+		}
+	}
+	t.Fatalf("no theme %q", name)
+	return ThemeConfig{}
+}
+
+// burnRect turns x0..x1, y0..y1 to rubble.
+func burnRect(w *World, x0, y0, x1, y1 int) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			w.BurnPixel(x, y)
+		}
+	}
+}
+
+// TestRoadGraphIgnoresDamage builds the graph before and after the city is
+// wrecked: its lots are the same either way.
+func TestRoadGraphIgnoresDamage(t *testing.T) {
+	for _, tc := range []struct {
+		theme string
+		seed  uint64
+	}{
+		{"City", 1},
+		{"City", 99},
+		{"Suburban", 7},
+		{"Neon", 1234},
+	} {
+		t.Run(tc.theme, func(t *testing.T) {
+			th := themeNamed(t, tc.theme)
+			fresh := newRoadGraph(newTestWorld(tc.seed, th, 660, 660))
+			if len(fresh.lots) == 0 {
+				t.Skip("no parking lots")
+			}
+			w := newTestWorld(tc.seed, th, 660, 660)
+			for _, l := range fresh.lots[:len(fresh.lots)/2+1] {
+				burnRect(w, int(l.x)-4, int(l.y)-4, int(l.x)+4, int(l.y)+4)
+			}
+			if got := newRoadGraph(w); !slices.Equal(got.lots, fresh.lots) {
+				t.Errorf("%d lots on the wrecked city, %d on the fresh one", len(got.lots), len(fresh.lots))
+			}
+		})
+	}
+}
+
+func TestRoadGraphPath(t *testing.T) {
+	w := newTestWorld(1, themeNamed(t, "City"), 660, 660)
+	g := w.routes()
+	cols, rows := len(g.xs), len(g.ys)
+	corner := g.node(cols-1, rows-1)
+	// A stretch of grid road clear of the ring, to burn out.
+	cutA := -1
+	for n := g.node(1, 1); cutA < 0 && n < g.node(0, rows-1); n++ {
+		if c := n % cols; c > 0 && c < cols-2 && g.east[n].road {
+			cutA = n
+		}
+	}
+	if cutA < 0 {
+		t.Fatal("no grid road to cut")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		from, to int
+		cut      [2]int // a stretch burnt out first; zero for none
+	}{
+		{"same node", 0, 0, [2]int{}},
+		{"along the ring", 0, g.node(cols-1, 0), [2]int{}},
+		{"across town", 0, corner, [2]int{}},
+		{"round a burnt-out street", cutA, cutA + 1, [2]int{cutA, cutA + 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.cut != [2]int{} {
+				ax, ay := g.pos(tc.cut[0])
+				bx, by := g.pos(tc.cut[1])
+				mx, my := int(ax+bx)/2, int(ay+by)/2
+				burnRect(w, mx-RoadWidth, my-RoadWidth, mx+RoadWidth, my+RoadWidth)
+				if g.open(w, tc.cut[0], tc.cut[1]) {
+					t.Fatal("burning the road did not cut it")
+				}
+			}
+			p := g.path(w, tc.from, tc.to, 5)
+			if len(p) == 0 || p[0] != tc.from || p[len(p)-1] != tc.to {
+				t.Fatalf("path %v does not run from %d to %d", p, tc.from, tc.to)
+			}
+			length := 0.0
+			for i := 1; i < len(p); i++ {
+				if !g.open(w, p[i-1], p[i]) {
+					t.Fatalf("path %v takes the closed stretch %d-%d", p, p[i-1], p[i])
+				}
+				length += g.length(p[i-1], p[i])
+			}
+			fx, fy := g.pos(tc.from)
+			tx, ty := g.pos(tc.to)
+			if direct := math.Abs(tx-fx) + math.Abs(ty-fy); length > direct*(1+routeJitter)+3*Pattern {
+				t.Errorf("path %v is %.0f px for %.0f px as the crow drives", p, length, direct)
+			}
+		})
+	}
+}
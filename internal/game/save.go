@@ -0,0 +1,476 @@
+package game
+
+import (
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Save file layout: magic "SNKS", u16 version, then a gzip-compressed gob
+// of saveState. The world is stored as deltas against the level's freshly
+// generated terrain, so a save only grows with the damage actually done.
+//
+// SaveVersion goes up whenever saveState or anything saved whole in it
+// changes. Gob would quietly load an older save with the new state zeroed,
+// so a save from another version is refused instead and the menu drops its
+// Continue.
+const (
+	saveMagic   = "SNKS"
+	SaveVersion = 4
+
+	// SaveFileName is the in-progress level save inside DataDir.
+	SaveFileName = "level.snks"
+)
+
+// saveState is everything StartLevel does not rebuild on its own.
+type saveState struct {
+	Seed              uint64
+	Level             int
+	StartThemeRoll    uint64 // GameSession.ThemeRoll before the level started
+	StartLastThemeIdx int    // GameSession.LastThemeIdx before the level started
+	Now               float64
+
+	Session GameSession
+	Snake   *Snake
+	Rivals  []*Snake // versus players two and up
+
+	Peds           []Pedestrian
+	PedNextGroupID uint64
+
+	Cars        []NPCCar
+	NightFactor float32
+
+	Cops copSave
+	Mil  milSave
+
+	Bonuses       []BonusBox
+	BonusTimer    float64
+	BonusSpawnSeq uint64
+	BonusLastKind int
+
+	Particles   []Particle
+	ParticleOvr int
+
+	Weather weatherSave
+	World   worldSave
+}
+
+type copSave struct {
+	Cars        []CopCar
+	Peds        []CopPed
+	Helis       []Helicopter
+	Shots       []HeliShot
+	SpawnTimer  float64
+	NextCarID   uint32
+	WantedStars int
+}
+
+type milSave struct {
+	Tanks       []Tank
+	Helis       []MilHeli
+	Missiles    []Missile
+	Mines       []Mine
+	Troops      []MilTroop
+	Active      bool
+	ActiveTimer float64
+	SpawnTimer  float64
+	MineTimer   float64
+	NextGroupID uint32
+}
+
+type weatherSave struct {
+	Intensity float64
+	WindX     float64
+	SpawnAcc  float64
+	GustAcc   float64
+	SpawnSeq  uint64
+}
+
+// chunkDelta lists the pixels of one chunk that differ from generation.
+// Shade is derived from height and the sun, so it is recomputed on load.
+type chunkDelta struct {
+	CX, CY int
+	Idx    []uint16 // pixel index inside the chunk
+	RGB    []uint8  // 3 bytes per index
+	Height []uint8
+}
+
+type treeBurnSave struct {
+	X, Y         int
+	Pixels       []struct{ X, Y int }
+	Rng          uint64
+	Timer        float64
+	DropInterval float64
+}
+
+type buildingBurnSave struct {
+	X0, Y0, X1, Y1  int
+	Pixels          []struct{ X, Y int }
+	Rng             uint64
+	Timer           float64
+	StepInterval    float64
+	Smolder         bool
+	SmolderTimer    float64
+	SmolderStep     float64
+	SmolderDuration float64
+	SmolderTotal    float64
+	PendingBurst    int
+}
+
+type worldSave struct {
+	Chunks        []chunkDelta
+	TreeBurns     []treeBurnSave
+	BuildingBurns []buildingBurnSave
+	Checks        []structureCheck // damage not yet tested for collapse
+	Fires         []fireBurn       // burning fire cells
+	Burnt         []int32          // fire cells already burnt out
+	FireTick      uint64
+	Decals        []decalSave
+	Signals       float64 // traffic light clock
+}
+
+// GobEncode lets structs holding a bus be saved. Subscriptions are runtime
+// wiring, not state, so nothing is written.
+func (eb *EventBus) GobEncode() ([]byte, error) { return nil, nil }
+
+// GobDecode is the no-op counterpart of GobEncode; loaders re-attach the bus.
+func (eb *EventBus) GobDecode([]byte) error { return nil }
+
+// WriteSave encodes the level in progress. Only a playing level can be saved.
+func (sim *Simulation) WriteSave(w io.Writer) error {
+	if sim.Session.State != StatePlaying || sim.Snake == nil {
+		return errors.New("no level in progress")
+	}
+	st := saveState{
+		Seed:              sim.Seed,
+		Level:             sim.Session.CurrentLevel,
+		StartThemeRoll:    sim.levelThemeRoll,
+		StartLastThemeIdx: sim.levelLastThemeIdx,
+		Now:               sim.Now,
+		Session:           *sim.Session,
+		Snake:             sim.Snake,
+		Rivals:            sim.Snakes[1:],
+
+		Peds:           sim.Peds.P,
+		PedNextGroupID: sim.Peds.nextGroupID,
+		Cars:           sim.Traffic.Cars,
+		NightFactor:    sim.Traffic.NightFactor,
+
+		Cops: copSave{
+			Cars:        sim.Cops.Cars,
+			Peds:        sim.Cops.Peds,
+			Helis:       sim.Cops.Helis,
+			Shots:       sim.Cops.Shots,
+			SpawnTimer:  sim.Cops.SpawnTimer,
+			NextCarID:   sim.Cops.nextCarID,
+			WantedStars: sim.Cops.wantedStars,
+		},
+		Mil: milSave{
+			Tanks:       sim.Mil.Tanks,
+			Helis:       sim.Mil.Helis,
+			Missiles:    sim.Mil.Missiles,
+			Mines:       sim.Mil.Mines,
+			Troops:      sim.Mil.Troops,
+			Active:      sim.Mil.Active,
+			ActiveTimer: sim.Mil.ActiveTimer,
+			SpawnTimer:  sim.Mil.SpawnTimer,
+			MineTimer:   sim.Mil.MineTimer,
+			NextGroupID: sim.Mil.nextGroupID,
+		},
+
+		Bonuses:       sim.Bonuses.Boxes,
+		BonusTimer:    sim.Bonuses.SpawnTimer,
+		BonusSpawnSeq: sim.Bonuses.spawnSeq,
+		BonusLastKind: sim.Bonuses.lastKind,
+
+		Particles:   sim.Particles.P,
+		ParticleOvr: sim.Particles.ovrIdx,
+
+		Weather: weatherSave{
+			Intensity: sim.Weather.intensity,
+			WindX:     sim.Weather.windX,
+			SpawnAcc:  sim.Weather.spawnAcc,
+			GustAcc:   sim.Weather.gustAcc,
+			SpawnSeq:  sim.Weather.spawnSeq,
+		},
+		World: sim.World.saveState(),
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(saveMagic)
+	_ = binary.Write(bw, binary.LittleEndian, uint16(SaveVersion))
+	zw := gzip.NewWriter(bw)
+	if err := gob.NewEncoder(zw).Encode(&st); err != nil {
+		return fmt.Errorf("save encode: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadSave rebuilds a simulation from a save written by WriteSave.
+// The level is regenerated from its seed first, then the saved state is
+// laid over it.
+func ReadSave(r io.Reader) (*Simulation, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(saveMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("save header: %w", err)
+	}
+	if string(magic) != saveMagic {
+		return nil, errors.New("not a save file")
+	}
+	var version uint16
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("save header: %w", err)
+	}
+	if version != SaveVersion {
+		return nil, fmt.Errorf("unsupported save version %d", version)
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("save body: %w", err)
+	}
+	var st saveState
+	if err := gob.NewDecoder(zr).Decode(&st); err != nil {
+		return nil, fmt.Errorf("save body: %w", err)
+	}
+	if st.Snake == nil {
+		return nil, errors.New("save has no snake")
+	}
+
+	sim := NewSimulation(st.Seed)
+	sim.Session.ThemeRoll = st.StartThemeRoll
+	sim.Session.LastThemeIdx = st.StartLastThemeIdx
+	sim.Session.Mode = st.Session.Mode
+	sim.Session.DailySeed = st.Session.DailySeed
+	sim.StartLevel(st.Level)
+	if err := sim.World.loadState(&st.World); err != nil {
+		return nil, err
+	}
+
+	sim.Now = st.Now
+	events := sim.Session.Events
+	*sim.Session = st.Session
+	sim.Session.Events = events
+	sim.Snake = st.Snake
+	sim.Snakes = append([]*Snake{st.Snake}, st.Rivals...)
+	for _, s := range sim.Snakes {
+		s.Events = sim.Events
+	}
+
+	sim.Peds.P = st.Peds
+	sim.Peds.nextGroupID = st.PedNextGroupID
+	sim.Traffic.Cars = st.Cars
+	sim.Traffic.NightFactor = st.NightFactor
+	sim.Traffic.RebuildGrid()
+
+	cs := sim.Cops
+	cs.Cars, cs.Peds, cs.Helis, cs.Shots = st.Cops.Cars, st.Cops.Peds, st.Cops.Helis, st.Cops.Shots
+	cs.SpawnTimer = st.Cops.SpawnTimer
+	cs.nextCarID = st.Cops.NextCarID
+	cs.wantedStars = st.Cops.WantedStars
+
+	ms := sim.Mil
+	ms.Tanks, ms.Helis, ms.Missiles, ms.Mines, ms.Troops = st.Mil.Tanks, st.Mil.Helis, st.Mil.Missiles, st.Mil.Mines, st.Mil.Troops
+	ms.Active = st.Mil.Active
+	ms.ActiveTimer = st.Mil.ActiveTimer
+	ms.SpawnTimer = st.Mil.SpawnTimer
+	ms.MineTimer = st.Mil.MineTimer
+	ms.nextGroupID = st.Mil.NextGroupID
+
+	sim.Bonuses.Boxes = st.Bonuses
+	sim.Bonuses.SpawnTimer = st.BonusTimer
+	sim.Bonuses.spawnSeq = st.BonusSpawnSeq
+	sim.Bonuses.lastKind = st.BonusLastKind
+
+	sim.Particles.P = append(sim.Particles.P[:0], st.Particles...)
+	sim.Particles.ovrIdx = st.ParticleOvr
+
+	ws := sim.Weather
+	ws.intensity = st.Weather.Intensity
+	ws.windX = st.Weather.WindX
+	ws.spawnAcc = st.Weather.SpawnAcc
+	ws.gustAcc = st.Weather.GustAcc
+	ws.spawnSeq = st.Weather.SpawnSeq
+
+	// A resumed attempt no longer starts at a level checkpoint.
+	sim.Replay = nil
+	return sim, nil
+}
+
+// saveState diffs the world against its freshly generated terrain: the
+// loaded chunks that were edited, plus the deltas of unloaded ones.
+func (w *World) saveState() worldSave {
+	var ws worldSave
+	keys := make([]ChunkKey, 0, len(w.chunks)+len(w.deltas))
+	for key := range w.chunks {
+		keys = append(keys, key)
+	}
+	for key := range w.deltas {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b ChunkKey) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	// A half-built chunk may have a delta still waiting for it.
+	for _, key := range slices.Compact(keys) {
+		if d, ok := w.deltas[key]; ok {
+			ws.Chunks = append(ws.Chunks, d)
+			continue
+		}
+		c := w.chunks[key]
+		if !c.ready || !c.edited {
+			continue
+		}
+		if d := diffChunk(c, w.pristineChunk(c.CX, c.CY)); len(d.Idx) > 0 {
+			ws.Chunks = append(ws.Chunks, d)
+		}
+	}
+
+	ws.Checks = w.checks
+	ws.Fires, ws.FireTick = w.fire.burning, w.fire.tick
+	for k, st := range w.fire.state {
+		if st == cellBurnt {
+			ws.Burnt = append(ws.Burnt, int32(k))
+		}
+	}
+	ws.Decals = w.saveDecals()
+	ws.Signals = w.signalClock
+	for _, key := range sortedBurnKeys(w.burningTrees) {
+		tb := w.burningTrees[key]
+		ws.TreeBurns = append(ws.TreeBurns, treeBurnSave{
+			X: tb.X, Y: tb.Y, Pixels: tb.Pixels,
+			Rng: tb.rng, Timer: tb.timer, DropInterval: tb.dropInterval,
+		})
+	}
+	for _, key := range sortedBurnKeys(w.burningBuildings) {
+		bb := w.burningBuildings[key]
+		ws.BuildingBurns = append(ws.BuildingBurns, buildingBurnSave{
+			X0: bb.X0, Y0: bb.Y0, X1: bb.X1, Y1: bb.Y1, Pixels: bb.Pixels,
+			Rng: bb.rng, Timer: bb.timer, StepInterval: bb.stepInterval,
+			Smolder: bb.smolder, SmolderTimer: bb.smolderTimer, SmolderStep: bb.smolderStep,
+			SmolderDuration: bb.smolderDuration, SmolderTotal: bb.smolderTotal,
+			PendingBurst: bb.pendingBurst,
+		})
+	}
+	return ws
+}
+
+// loadState applies saved deltas to a world regenerated from the same seed.
+// Deltas of chunks that aren't loaded wait until they are.
+func (w *World) loadState(ws *worldSave) error {
+	for _, d := range ws.Chunks {
+		if d.CX < 0 || d.CY < 0 || d.CX > w.maxCx || d.CY > w.maxCy {
+			return fmt.Errorf("save chunk %d,%d does not match the world", d.CX, d.CY)
+		}
+		if err := d.check(); err != nil {
+			return fmt.Errorf("save %w", err)
+		}
+		if c := w.loadedChunk(d.CX, d.CY); c != nil {
+			d.apply(c)
+			c.edited = true
+			continue
+		}
+		w.deltas[ChunkKey{X: d.CX, Y: d.CY}] = d
+	}
+
+	w.checks = append(w.checks[:0], ws.Checks...)
+	for _, b := range ws.Fires {
+		if b.Cell < 0 || int(b.Cell) >= len(w.fire.state) {
+			return fmt.Errorf("save fire cell %d does not match the world", b.Cell)
+		}
+		w.fire.state[b.Cell] = cellBurning
+	}
+	for _, k := range ws.Burnt {
+		if k < 0 || int(k) >= len(w.fire.state) {
+			return fmt.Errorf("save fire cell %d does not match the world", k)
+		}
+		w.fire.state[k] = cellBurnt
+	}
+	w.fire.burning = append(w.fire.burning[:0], ws.Fires...)
+	w.fire.tick = ws.FireTick
+	w.signalClock = ws.Signals
+	if err := w.loadDecals(ws.Decals); err != nil {
+		return err
+	}
+	for _, tb := range ws.TreeBurns {
+		w.burningTrees[coordKey(tb.X, tb.Y)] = &TreeBurn{
+			X: tb.X, Y: tb.Y, Pixels: tb.Pixels,
+			rng: tb.Rng, timer: tb.Timer, dropInterval: tb.DropInterval,
+		}
+	}
+	for _, bb := range ws.BuildingBurns {
+		// Building burns are keyed by their ignition point, the box centre.
+		key := coordKey((bb.X0+bb.X1)/2, (bb.Y0+bb.Y1)/2)
+		w.burningBuildings[key] = &BuildingBurn{
+			X0: bb.X0, Y0: bb.Y0, X1: bb.X1, Y1: bb.Y1, Pixels: bb.Pixels,
+			rng: bb.Rng, timer: bb.Timer, stepInterval: bb.StepInterval,
+			smolder: bb.Smolder, smolderTimer: bb.SmolderTimer, smolderStep: bb.SmolderStep,
+			smolderDuration: bb.SmolderDuration, smolderTotal: bb.SmolderTotal,
+			pendingBurst: bb.PendingBurst,
+		}
+	}
+	return nil
+}
+
+// SaveGameFile writes the level in progress to path. The file is written
+// next to path first and renamed, so a kill mid-write keeps the old save.
+func SaveGameFile(path string, sim *Simulation) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := sim.WriteSave(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadGameFile reads a save from path.
+func LoadGameFile(path string) (*Simulation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSave(f)
+}
+
+// SavePath returns where the in-progress level is kept, or "" when there
+// is no writable data directory.
+func SavePath() string {
+	dir, err := DataDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, SaveFileName)
+}
+
+// HasSave reports whether a level save exists at path.
+func HasSave(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestSaveRoundTrip saves a level part way through, loads it back and
+// checks the loaded game goes on exactly as the original does.
+func TestSaveRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		seed         uint64
+		level        int
+		mode         GameMode
+		before, then int // ticks before saving and after loading
+	}{
+		{"campaign", 3, 1, ModeCampaign, 300, 600},
+		{"later level", 11, 4, ModeCampaign, 400, 600},
+		{"endless", 99, 1, ModeEndless, 300, 900},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := NewSimulation(tc.seed)
+			sim.Session.Mode = tc.mode
+			sim.StartLevel(tc.level)
+			for n := 0; n < tc.before; n++ {
+				sim.Step(SimTickDT, steerFor(n))
+			}
+			if sim.Session.State != StatePlaying {
+				t.Fatal("level ended before it could be saved")
+			}
+
+			var buf bytes.Buffer
+			if err := sim.WriteSave(&buf); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := ReadSave(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a, b := simState(loaded), simState(sim); a != b {
+				t.Fatalf("loaded\n%s\nsaved\n%s", a, b)
+			}
+			for n := tc.before; n < tc.before+tc.then; n++ {
+				sim.Step(SimTickDT, steerFor(n))
+				loaded.Step(SimTickDT, steerFor(n))
+				if a, b := simState(loaded), simState(sim); a != b {
+					t.Fatalf("%d ticks after loading the games differ:\nloaded\n%s\noriginal\n%s", n-tc.before+1, a, b)
+				}
+			}
+		})
+	}
+}
+
+func TestReadSaveRejectsBadInput(t *testing.T) {
+	sim := NewSimulation(5)
+	sim.StartLevel(1)
+	var good bytes.Buffer
+	if err := sim.WriteSave(&good); err != nil {
+		t.Fatal(err)
+	}
+	old := bytes.Clone(good.Bytes())
+	old[len(saveMagic)] = SaveVersion - 1
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "save header"},
+		{"bad magic", []byte("SNKR\x01\x00"), "not a save file"},
+		{"old version", old, "unsupported save version"},
+		{"truncated body", good.Bytes()[:good.Len()/2], "save body"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadSave(bytes.NewReader(tc.data))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tc.want)
+			}
+		})
+	}
+	if err := NewSimulation(5).WriteSave(&good); err == nil {
+		t.Error("saved a simulation with no level in progress")
+	}
+}
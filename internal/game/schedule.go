@@ -0,0 +1,231 @@
+package game
+
+import "math"
+
+// Daily routine. Pedestrians keep to the time of day. In the morning they
+// commute, each making for the door of a building a few blocks off; at noon
+// they crowd into the nearest park; in the evening they wander. At night
+// most of those out alone near a building go in, to come out again in the
+// morning, and the streets thin out to groups and stragglers, except where
+// the theme has night life: there the night belongs to party crowds packed
+// into the parks and car parks. A group's followers go where their leader
+// goes.
+
+// DayPart is a part of the day a pedestrian plans for.
+type DayPart uint8
+
+const (
+	DayNone DayPart = iota // nothing planned yet
+	DayMorning
+	DayNoon
+	DayEvening
+	DayNight
+)
+
+const (
+	commuteBlocks = 3   // how many blocks off work may be
+	venueBlocks   = 4   // how far off a park or party draws a crowd
+	goalPull      = 2.5 // how much each 12 px further from the goal counts against a step
+	goalCrossing  = 5   // times as likely to cross a road towards the goal, and as unlikely away
+	goalReach     = 3.0 // how near a commuter's door counts as there
+	parkGather    = 8.0 // how near a park's middle the lunch crowd keeps
+	partyGather   = 3.5
+	nightIndoors  = 80 // percent of those out alone by a building who go in for the night
+	partyIndoors  = 20 // the same where there is night life
+)
+
+// dayPartAt is the part of the day at phase 0..1 of the day cycle (0
+// dawn, 0.25 noon, 0.5 dusk, 0.75 midnight). Night runs while the
+// streets are dark.
+func dayPartAt(phase float64) DayPart {
+	switch {
+	case phase < 0.15:
+		return DayMorning
+	case phase < 0.35:
+		return DayNoon
+	case phase < 0.52:
+		return DayEvening
+	case phase < 0.97:
+		return DayNight
+	}
+	return DayMorning
+}
+
+// venue is an open block crowds gather in: a park or a car park.
+type venue struct {
+	x, y float64 // its middle
+	park bool
+}
+
+// venueList lists the parks and car parks of a generated city, laying them
+// out on first use.
+func (w *World) venueList() []venue {
+	if w.venues != nil || w.Map != nil || w.Theme.NoRoads {
+		return w.venues
+	}
+	w.venues = []venue{}
+	tp := buildThemePalette(w.Theme)
+	for by := 0; by <= floorDiv(w.Height-1, Pattern); by++ {
+		for bx := 0; bx <= floorDiv(w.Width-1, Pattern); bx++ {
+			x0 := bx*Pattern + RoadWidth + SidewalkWidth
+			y0 := by*Pattern + RoadWidth + SidewalkWidth
+			if x0+BlockInner > w.Width-BorderThickness || y0+BlockInner > w.Height-BorderThickness {
+				continue
+			}
+			feat := genBlockFeatures(w.seed, bx, by, w.Theme, tp)
+			v := venue{x: float64(x0) + BlockInner/2, y: float64(y0) + BlockInner/2, park: feat.IsPark}
+			if feat.IsPark && len(feat.ParkRects) > 0 {
+				pr := feat.ParkRects[0]
+				v.x, v.y = float64(x0)+float64(pr.X0+pr.X1)/2, float64(y0)+float64(pr.Y0+pr.Y1)/2
+			}
+			if (feat.IsPark || feat.IsParking) && !w.IsWater(int(v.x), int(v.y)) {
+				w.venues = append(w.venues, v)
+			}
+		}
+	}
+	return w.venues
+}
+
+// nearestVenue is the venue nearest x, y within venueBlocks, parks only
+// unless carParks is set.
+func (w *World) nearestVenue(x, y float64, carParks bool) (venue, bool) {
+	best, bestD, ok := venue{}, float64(venueBlocks*Pattern), false
+	for _, v := range w.venueList() {
+		if d := math.Hypot(v.x-x, v.y-y); d < bestD && (v.park || carParks) {
+			best, bestD, ok = v, d, true
+		}
+	}
+	return best, ok
+}
+
+// keepRoutine plans pedestrian i's day as it comes round and takes it in
+// for the night and out in the morning. Its goal only sways where it
+// walks; fear, the snake and its group come first.
+func (ps *PedestrianSystem) keepRoutine(i int, w *World) {
+	p := &ps.P[i]
+	part := dayPartAt(ps.DayPhase)
+	if p.InForNight {
+		if p.Hiding && part == DayNight {
+			return
+		}
+		// Morning, or flushed out: on with the day.
+		p.InForNight = false
+		if p.Hiding {
+			p.Hiding = false
+			if in := w.interiorAt(p.X, p.Y); in != nil {
+				p.TargetX, p.TargetY = in.exitTarget(p.X, p.Y, p.X, p.Y)
+			}
+		}
+		return
+	}
+	if p.Hiding || p.Infection == StateZombie || p.Panic >= panicFlee || !p.IsLeader && p.GroupID != 0 {
+		return
+	}
+	if p.Plan != part {
+		p.Plan = part
+		ps.planDay(i, w)
+	}
+	if !p.Goal {
+		return
+	}
+
+	if in := w.interiorAt(p.GoalX, p.GoalY); in != nil {
+		// In for the night: round to the door and through it, or stay out
+		// if the way there is lost.
+		switch {
+		case in.inside(p.X, p.Y):
+			p.Goal, p.InForNight, p.Hiding = false, true, true
+			p.Crossing, p.Waiting = false, false
+		case p.Crossing:
+		case p.StuckTimer > 1.5:
+			p.Goal = false
+		default:
+			p.TargetX, p.TargetY = in.breachTarget(p.X, p.Y, 0, 0, false)
+		}
+		return
+	}
+	if part == DayMorning && math.Hypot(p.GoalX-p.X, p.GoalY-p.Y) < goalReach {
+		p.Goal = false // at work
+	}
+}
+
+// planDay picks where pedestrian i makes for in the part of the day it
+// has just planned for: a door to commute to, a park, a bed or a party.
+func (ps *PedestrianSystem) planDay(i int, w *World) {
+	p := &ps.P[i]
+	r := NewRand(ps.seed ^ uint64(i+1)*0xDA7 ^ uint64(p.Plan)*0x5EED)
+	p.Goal = false
+	switch p.Plan {
+	case DayMorning:
+		bx, by := floorDiv(int(p.X), Pattern), floorDiv(int(p.Y), Pattern)
+		for range 6 {
+			list := w.blockInteriors(bx+r.Range(-commuteBlocks, commuteBlocks), by+r.Range(-commuteBlocks, commuteBlocks))
+			if len(list) == 0 {
+				continue
+			}
+			in := &list[r.Intn(len(list))]
+			d := in.doors[r.Intn(len(in.doors))]
+			p.GoalX, p.GoalY, p.Goal = d.x+d.nx*interiorDoorWidth, d.y+d.ny*interiorDoorWidth, true
+			return
+		}
+	case DayNoon:
+		if v, ok := w.nearestVenue(p.X, p.Y, false); ok {
+			p.GoalX, p.GoalY, p.Goal = v.x, v.y, true
+		}
+	case DayNight:
+		indoors := nightIndoors
+		if w.Theme.NightLife {
+			indoors = partyIndoors
+		}
+		if p.GroupID == 0 && r.Intn(100) < indoors {
+			if in := homeNear(w, p.X, p.Y); in != nil {
+				p.GoalX = float64(in.rect.X0+in.rect.X1) / 2
+				p.GoalY = float64(in.rect.Y0+in.rect.Y1) / 2
+				p.Goal = true
+				return
+			}
+		}
+		if w.Theme.NightLife {
+			if v, ok := w.nearestVenue(p.X, p.Y, true); ok {
+				p.GoalX, p.GoalY, p.Goal = v.x, v.y, true
+			}
+		}
+	}
+}
+
+// homeNear is the building on the block x, y is on with the door nearest
+// x, y, or nil if the block has none to go into.
+func homeNear(w *World, x, y float64) *interior {
+	var best *interior
+	bestD := math.Inf(1)
+	list := w.blockInteriors(floorDiv(int(math.Floor(x)), Pattern), floorDiv(int(math.Floor(y)), Pattern))
+	for k := range list {
+		for _, d := range list[k].doors {
+			if dd := math.Hypot(d.x-x, d.y-y); dd < bestD {
+				best, bestD = &list[k], dd
+			}
+		}
+	}
+	return best
+}
+
+// gather is how near its goal pedestrian p keeps once there.
+func (p *Pedestrian) gather() float64 {
+	switch p.Plan {
+	case DayNoon:
+		return parkGather
+	case DayNight:
+		return partyGather
+	}
+	return goalReach
+}
+
+// goalScore is what walking to x, y is worth to pedestrian p for where it
+// is making for: less the further outside its gathering place that is, and
+// nothing without a goal.
+func (p *Pedestrian) goalScore(x, y float64) float64 {
+	if !p.Goal {
+		return 0
+	}
+	return -goalPull * max(0, math.Hypot(p.GoalX-x, p.GoalY-y)-p.gather()) / 12
+}
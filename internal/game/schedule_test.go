@@ -0,0 +1,144 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDayPartAt(t *testing.T) {
+	for _, tc := range []struct {
+		phase float64
+		want  DayPart
+	}{
+		{0, DayMorning},
+		{0.149, DayMorning},
+		{0.15, DayNoon},
+		{0.25, DayNoon},
+		{0.35, DayEvening},
+		{0.5, DayEvening},
+		{0.52, DayNight},
+		{0.75, DayNight},
+		{0.97, DayMorning},
+		{0.999, DayMorning},
+	} {
+		if got := dayPartAt(tc.phase); got != tc.want {
+			t.Errorf("phase %.3f: part %d, want %d", tc.phase, got, tc.want)
+		}
+	}
+}
+
+// TestPlanDay plans each part of the day for a crowd and checks where
+// they make for.
+func TestPlanDay(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		theme  string
+		part   DayPart
+		check  func(w *World, p *Pedestrian) string // what is wrong with p's plan, if anything
+		goals  float64                              // least share of the crowd with somewhere to go
+		indoor [2]float64                           // share of those alone by a building going in, min and max
+	}{
+		{"morning commute", "City", DayMorning, func(w *World, p *Pedestrian) string {
+			reach := float64((commuteBlocks + 1) * Pattern)
+			if p.Goal && (math.Abs(p.GoalX-p.X) > reach || math.Abs(p.GoalY-p.Y) > reach) {
+				return "commutes too far"
+			}
+			return ""
+		}, 0.6, [2]float64{0, 0}},
+		{"lunch in the park", "City", DayNoon, func(w *World, p *Pedestrian) string {
+			v, ok := w.nearestVenue(p.X, p.Y, false)
+			if p.Goal != ok || ok && (!v.park || p.GoalX != v.x || p.GoalY != v.y) {
+				return "not off to the nearest park"
+			}
+			return ""
+		}, 0.5, [2]float64{0, 0}},
+		{"evening stroll", "City", DayEvening, func(w *World, p *Pedestrian) string {
+			if p.Goal {
+				return "has somewhere to be"
+			}
+			return ""
+		}, 0, [2]float64{0, 0}},
+		{"home for the night", "City", DayNight, func(w *World, p *Pedestrian) string {
+			if p.Goal && w.interiorAt(p.GoalX, p.GoalY) == nil {
+				return "out for the night in a city without night life"
+			}
+			return ""
+		}, 0, [2]float64{0.6, 0.95}},
+		{"party night", "Neon", DayNight, func(w *World, p *Pedestrian) string {
+			if !p.Goal || w.interiorAt(p.GoalX, p.GoalY) != nil {
+				return ""
+			}
+			if v, ok := w.nearestVenue(p.X, p.Y, true); !ok || p.GoalX != v.x || p.GoalY != v.y {
+				return "not off to the nearest party"
+			}
+			return ""
+		}, 0.5, [2]float64{0.05, 0.4}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newTestWorld(3, themeNamed(t, tc.theme), 660, 660)
+			ps := NewPedestrianSystem(300, 3)
+			ps.resizeGrid(w.Width, w.Height)
+			ps.SpawnRandom(w, 300)
+			goals, alone, home, in := 0, 0, 0, 0
+			for i := range ps.P {
+				p := &ps.P[i]
+				p.Plan = tc.part
+				ps.planDay(i, w)
+				if p.Goal {
+					goals++
+				}
+				if msg := tc.check(w, p); msg != "" {
+					t.Fatalf("ped %d at %.0f,%.0f: %s (goal %v at %.0f,%.0f)", i, p.X, p.Y, msg, p.Goal, p.GoalX, p.GoalY)
+				}
+				if p.GroupID != 0 {
+					if p.Goal && w.interiorAt(p.GoalX, p.GoalY) != nil {
+						t.Fatalf("ped %d goes in with its group out", i)
+					}
+					continue
+				}
+				alone++
+				if homeNear(w, p.X, p.Y) != nil {
+					home++
+					if p.Goal && w.interiorAt(p.GoalX, p.GoalY) != nil {
+						in++
+					}
+				}
+			}
+			if share := float64(goals) / float64(len(ps.P)); share < tc.goals {
+				t.Errorf("%.2f of the crowd has somewhere to go, want at least %.2f", share, tc.goals)
+			}
+			if alone == 0 || tc.indoor[1] > 0 && home == 0 {
+				t.Fatalf("%d alone, %d by a building", alone, home)
+			}
+			if share := float64(in) / float64(max(home, 1)); share < tc.indoor[0] || share > tc.indoor[1] {
+				t.Errorf("%.2f of those alone by a building go in, want %.2f..%.2f", share, tc.indoor[0], tc.indoor[1])
+			}
+		})
+	}
+}
+
+// TestKeepRoutine checks a ped in for the night stays in until morning
+// or until it is flushed out.
+func TestKeepRoutine(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		phase    float64
+		hiding   bool
+		wantIn   bool
+		wantHide bool
+	}{
+		{"asleep", 0.75, true, true, true},
+		{"morning", 0.05, true, false, false},
+		{"flushed out", 0.75, false, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newTestWorld(3, themeNamed(t, "City"), 660, 660)
+			ps := crowd(Pedestrian{X: 100, Y: 100, Alive: true, InForNight: true, Hiding: tc.hiding, Plan: DayNight})
+			ps.DayPhase = tc.phase
+			ps.keepRoutine(0, w)
+			if p := ps.P[0]; p.InForNight != tc.wantIn || p.Hiding != tc.wantHide {
+				t.Errorf("in for the night %v hiding %v, want %v and %v", p.InForNight, p.Hiding, tc.wantIn, tc.wantHide)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-//go:build !android
+//go:build !android && !headless
 
 package game
 
@@ -38,10 +38,13 @@ void main() {
 }
 ` + "\x00"
 
-// Chunk fragment shader: sample texture, multiply RGB by alpha shade factor + sun cycle.
+// Chunk fragment shader: sample texture, lay the decals over it, multiply
+// RGB by alpha shade factor + sun cycle.
 const chunkFragSrc = `#version 410 core
 
 uniform sampler2D uTex;
+uniform sampler2D uDecals;
+uniform float uDecalMix; // 1 while uDecals holds the chunk's decals
 uniform float uAmbient;
 uniform vec3 uSunTint;
 
@@ -50,8 +53,10 @@ out vec4 FragColor;
 
 void main() {
     vec4 t = texture(uTex, vUV);
+    vec4 d = texture(uDecals, vUV);
     float shade = t.a;
-    FragColor = vec4(t.rgb * shade * uAmbient * uSunTint, 1.0);
+    vec3 rgb = mix(t.rgb, d.rgb, d.a * uDecalMix);
+    FragColor = vec4(rgb * shade * uAmbient * uSunTint, 1.0);
 }
 ` + "\x00"
 
@@ -0,0 +1,284 @@
+package game
+
+import "math"
+
+// Traffic lights and right of way. Every junction of the city grid where
+// four roads meet has lights. They run green, amber, then all red for the
+// north-south roads, then the same for the east-west ones, each junction
+// offset from its neighbours. Cars stop for red and, if they can, for
+// amber, queue behind the car ahead and give way to pedestrians crossing
+// in front of them. Where three roads meet, cars on the road that ends
+// give way to those on the one going through. Pedestrians cross at the
+// lights while the road they cross is held at red.
+
+type lightState uint8
+
+const (
+	lightNone lightState = iota // no lights here
+	lightGreen
+	lightAmber
+	lightRed
+)
+
+const (
+	lightGreenTime = 7.0 // seconds
+	lightAmberTime = 2.0
+	lightClearTime = 1.5 // all red, clearing the junction
+	lightCycle     = 2 * (lightGreenTime + lightAmberTime + lightClearTime)
+)
+
+// Junction arms, by the side of the junction they leave from.
+const (
+	armNorth = iota
+	armEast
+	armSouth
+	armWest
+)
+
+const (
+	carBrake     = 60.0 // px/s², how hard cars brake for what is ahead
+	carStopLook  = 14.0 // how far ahead cars look for lights and queues
+	carQueueGap  = 1.5  // space left to the car ahead
+	carLaneHalf  = 1.8  // how far to the side a car ahead still counts as in the lane
+	carGiveWayAt = 7.0  // how near a T-junction a car on the through road has to be to be given way to
+	walkReach    = SidewalkWidth + 2
+)
+
+// junctionArms reports which roads meet at the grid junction whose box has
+// its top-left corner at block corner bx, by. ok is false where there is no
+// such junction: on a hand-drawn map, without roads, on the ring road and
+// outside the grid.
+func (w *World) junctionArms(bx, by int) (arms [4]bool, ok bool) {
+	if w.Map != nil || w.Theme.NoRoads {
+		return arms, false
+	}
+	rn := w.roadLayout()
+	if bx < 1 || by < 1 || bx >= rn.gridW || by >= rn.gridH {
+		return arms, false
+	}
+	inner := BorderThickness + RoadWidth
+	if x, y := bx*Pattern, by*Pattern; x < inner || y < inner ||
+		x+RoadWidth > w.Width-inner || y+RoadWidth > w.Height-inner {
+		return arms, false
+	}
+	arms[armNorth] = rn.vertOpen[bx][by-1]
+	arms[armSouth] = rn.vertOpen[bx][by]
+	arms[armWest] = rn.horzOpen[bx-1][by]
+	arms[armEast] = rn.horzOpen[bx][by]
+	return arms, true
+}
+
+// signalled reports whether the junction at bx, by has lights.
+func (w *World) signalled(bx, by int) bool {
+	arms, ok := w.junctionArms(bx, by)
+	return ok && arms[armNorth] && arms[armEast] && arms[armSouth] && arms[armWest]
+}
+
+// lightAt is the light the junction at bx, by shows to traffic moving
+// north or south (ns) or east or west.
+func (w *World) lightAt(bx, by int, ns bool) lightState {
+	if !w.signalled(bx, by) {
+		return lightNone
+	}
+	offset := float64(hash2D(w.seed^0x519A1, bx, by)>>11) / (1 << 53) * lightCycle
+	t := math.Mod(w.signalClock+offset, lightCycle)
+	if !ns {
+		t = math.Mod(t+lightCycle/2, lightCycle)
+	}
+	switch {
+	case t < lightGreenTime:
+		return lightGreen
+	case t < lightGreenTime+lightAmberTime:
+		return lightAmber
+	}
+	return lightRed
+}
+
+// walkSignal reports, for a pedestrian at wx, wy about to step onto the
+// road in direction dx, dy, whether the crossing is at a junction's lights
+// and, if so, whether the road it crosses is held at red.
+func (w *World) walkSignal(wx, wy, dx, dy int) (signalled, walk bool) {
+	var bx, by int
+	var along int // the pedestrian's position along the road it crosses
+	if dx != 0 {
+		bx = floorDiv(wx+dx, Pattern)
+		by = floorDiv(wy+Pattern/2, Pattern)
+		along = wy - by*Pattern
+	} else {
+		bx = floorDiv(wx+Pattern/2, Pattern)
+		by = floorDiv(wy+dy, Pattern)
+		along = wx - bx*Pattern
+	}
+	if along < -walkReach || along >= RoadWidth+walkReach || !w.signalled(bx, by) {
+		return false, false
+	}
+	return true, w.lightAt(bx, by, dx != 0) == lightRed
+}
+
+// stopDistance is how far car i may still drive before it has to stop: at
+// a red light, behind the car ahead, to give way at a T-junction or for
+// someone crossing or stampeding in front of it. It is +Inf with nothing in
+// the way.
+func (ts *TrafficSystem) stopDistance(i int, w *World, peds *PedestrianSystem) float64 {
+	c := &ts.Cars[i]
+	stop := math.Inf(1)
+	h := snapToCardinal(c.Heading)
+	if math.Abs(angDiff(c.Heading, h)) > 0.3 {
+		return stop // turning
+	}
+	fx, fy := math.Round(math.Cos(h)), math.Round(math.Sin(h))
+	half := float64(c.Size) * 0.5
+
+	// Queue behind the car ahead in the lane.
+	ts.QueryNeighbors(c.X, c.Y, carStopLook+half+CarSize, func(j int) {
+		o := &ts.Cars[j]
+		if j == i || !o.Alive || o.Parked || math.Abs(angDiff(o.Heading, c.Heading)) > 0.6 {
+			return
+		}
+		rx, ry := o.X-c.X, o.Y-c.Y
+		ahead := rx*fx + ry*fy
+		if ahead <= 0 || math.Abs(ry*fx-rx*fy) > carLaneHalf {
+			return
+		}
+		stop = min(stop, ahead-half-float64(o.Size)*0.5-carQueueGap)
+	})
+
+	// Someone crossing in front.
+	if peds != nil {
+		px, py := c.X+fx*(half+carStopLook/2), c.Y+fy*(half+carStopLook/2)
+		bx := clamp(int(px)/peds.bucketSize, 0, peds.bucketCols-1)
+		by := clamp(int(py)/peds.bucketSize, 0, peds.bucketRows-1)
+		for yy := max(by-1, 0); yy <= min(by+1, peds.bucketRows-1); yy++ {
+			for xx := max(bx-1, 0); xx <= min(bx+1, peds.bucketCols-1); xx++ {
+				for _, j := range peds.buckets[yy*peds.bucketCols+xx] {
+					p := &peds.P[j]
+					stampeding := p.Panic >= panicFlee && rgbEq(w.ColorAt(int(p.X), int(p.Y)), Palette.Road)
+					if !p.Alive || !stampeding && (!p.Crossing || math.Abs(p.VY*fx-p.VX*fy) < 1) {
+						continue // not walking across or running about the road
+					}
+					rx, ry := p.X-c.X, p.Y-c.Y
+					ahead := rx*fx + ry*fy - half
+					if ahead > 0 && ahead < carStopLook && math.Abs(ry*fx-rx*fy) < RoadWidth {
+						stop = min(stop, ahead-carQueueGap)
+					}
+				}
+			}
+		}
+	}
+
+	if c.InIntersection {
+		return stop
+	}
+
+	// The next junction: its box, the distance to its stop line and the arm
+	// the car comes in on.
+	var bx, by int
+	var dist float64
+	var from int
+	if fy == 0 {
+		by = floorDiv(int(math.Floor(c.Y)), Pattern)
+		if int(math.Floor(c.Y))-by*Pattern >= RoadWidth {
+			return stop
+		}
+		if front := c.X + fx*half; fx > 0 {
+			bx = int(math.Ceil(front / Pattern))
+			dist, from = float64(bx*Pattern)-front, armWest
+		} else {
+			bx = floorDiv(int(math.Floor(front))-RoadWidth, Pattern)
+			dist, from = front-float64(bx*Pattern+RoadWidth), armEast
+		}
+	} else {
+		bx = floorDiv(int(math.Floor(c.X)), Pattern)
+		if int(math.Floor(c.X))-bx*Pattern >= RoadWidth {
+			return stop
+		}
+		if front := c.Y + fy*half; fy > 0 {
+			by = int(math.Ceil(front / Pattern))
+			dist, from = float64(by*Pattern)-front, armNorth
+		} else {
+			by = floorDiv(int(math.Floor(front))-RoadWidth, Pattern)
+			dist, from = front-float64(by*Pattern+RoadWidth), armSouth
+		}
+	}
+	if dist > carStopLook {
+		return stop
+	}
+	arms, ok := w.junctionArms(bx, by)
+	if !ok {
+		return stop
+	}
+
+	switch w.lightAt(bx, by, fy != 0) {
+	case lightRed:
+		return min(stop, dist)
+	case lightAmber:
+		// Stop if there's room to; the bold go through.
+		if c.Speed*c.Speed/(2*carBrake) < dist && c.Aggression < 0.8 {
+			return min(stop, dist)
+		}
+		return stop
+	case lightGreen:
+		return stop
+	}
+
+	// Three roads: the one that ends gives way.
+	n := 0
+	for _, a := range arms {
+		if a {
+			n++
+		}
+	}
+	if n != 3 || !arms[from] || arms[(from+2)%4] {
+		return stop
+	}
+	mx := float64(bx*Pattern) + RoadWidth*0.5
+	my := float64(by*Pattern) + RoadWidth*0.5
+	ts.QueryNeighbors(mx, my, RoadWidth*0.5+carGiveWayAt, func(j int) {
+		o := &ts.Cars[j]
+		if j == i || !o.Alive || o.Parked || o.Speed < 1 {
+			return
+		}
+		if math.Abs(angDiff(o.Heading, c.Heading)) < 0.5 && (o.X-c.X)*fx+(o.Y-c.Y)*fy < 0 {
+			return // queued behind
+		}
+		stop = min(stop, dist)
+	})
+	return stop
+}
+
+// TrafficLightSprites appends glow sprites for the lights of every
+// junction in view: one lamp on the kerb at the right of each road coming
+// in, where its traffic stops.
+func TrafficLightSprites(w *World, view RectF, buf []float32) []float32 {
+	if w.Map != nil || w.Theme.NoRoads {
+		return buf
+	}
+	for by := max(floorDiv(int(view.Y0), Pattern), 1); by*Pattern <= int(view.Y1); by++ {
+		for bx := max(floorDiv(int(view.X0), Pattern), 1); bx*Pattern <= int(view.X1); bx++ {
+			if !w.signalled(bx, by) {
+				continue
+			}
+			x0, y0 := float32(bx*Pattern)-1.5, float32(by*Pattern)-1.5
+			x1, y1 := float32(bx*Pattern+RoadWidth)+0.5, float32(by*Pattern+RoadWidth)+0.5
+			ns, ew := w.lightAt(bx, by, true), w.lightAt(bx, by, false)
+			buf = appendLamp(buf, x0, y1, ew) // eastbound
+			buf = appendLamp(buf, x1, y0, ew) // westbound
+			buf = appendLamp(buf, x1, y1, ns) // northbound
+			buf = appendLamp(buf, x0, y0, ns) // southbound
+		}
+	}
+	return buf
+}
+
+func appendLamp(buf []float32, x, y float32, s lightState) []float32 {
+	r, g, b := float32(1), float32(0.1), float32(0.08)
+	switch s {
+	case lightGreen:
+		r, g, b = 0.15, 1, 0.35
+	case lightAmber:
+		r, g, b = 1, 0.65, 0.05
+	}
+	return append(buf,
+		x, y, 4.5, r*0.45, g*0.45, b*0.45, 1, 0,
+		x, y, 1.6, r, g, b, 1, 0)
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// signalledJunctions lists the junctions of w with lights.
+func signalledJunctions(w *World) [][2]int {
+	var out [][2]int
+	for by := 1; by*Pattern < w.Height; by++ {
+		for bx := 1; bx*Pattern < w.Width; bx++ {
+			if w.signalled(bx, by) {
+				out = append(out, [2]int{bx, by})
+			}
+		}
+	}
+	return out
+}
+
+// TestLightCycle runs every junction's lights through a cycle: each way
+// shows green, amber and red for their times in turn, never green or amber
+// both ways at once, and neighbouring junctions are out of step.
+func TestLightCycle(t *testing.T) {
+	w := newTestWorld(1, themeNamed(t, "City"), 660, 660)
+	junctions := signalledJunctions(w)
+	if len(junctions) < 4 {
+		t.Fatalf("%d junctions with lights", len(junctions))
+	}
+	const step = 0.01
+	next := map[lightState]lightState{lightGreen: lightAmber, lightAmber: lightRed, lightRed: lightGreen}
+	starts := map[[2]lightState]bool{}
+	for _, j := range junctions {
+		bx, by := j[0], j[1]
+		w.signalClock = 0
+		starts[[2]lightState{w.lightAt(bx, by, true), w.lightAt(bx, by, false)}] = true
+		for _, ns := range []bool{true, false} {
+			var spent [4]float64
+			prev := w.lightAt(bx, by, ns)
+			for n := range int(lightCycle / step) {
+				w.signalClock = float64(n) * step
+				s := w.lightAt(bx, by, ns)
+				if s != prev && s != next[prev] {
+					t.Fatalf("junction %v: light went %d to %d", j, prev, s)
+				}
+				if s != lightRed && w.lightAt(bx, by, !ns) != lightRed {
+					t.Fatalf("junction %v: both ways open at %.2fs", j, w.signalClock)
+				}
+				spent[s] += step
+				prev = s
+			}
+			for _, want := range []struct {
+				s    lightState
+				time float64
+			}{
+				{lightGreen, lightGreenTime},
+				{lightAmber, lightAmberTime},
+				{lightRed, lightCycle - lightGreenTime - lightAmberTime},
+			} {
+				if math.Abs(spent[want.s]-want.time) > 2*step {
+					t.Errorf("junction %v ns %v: %.2fs of light %d, want %.2fs", j, ns, spent[want.s], want.s, want.time)
+				}
+			}
+		}
+	}
+	if len(starts) < 2 {
+		t.Error("every junction's lights run in step")
+	}
+}
+
+func TestLightNone(t *testing.T) {
+	city := newTestWorld(1, themeNamed(t, "City"), 660, 660)
+	forest := newTestWorld(1, themeNamed(t, "Forest"), 660, 660)
+	drawn := newTestWorld(1, themeNamed(t, "City"), MinWorldSize, MinWorldSize)
+	m, err := DecodeWorldMap(mapImage(MinWorldSize, MinWorldSize), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drawn.Map = m
+	drawn.Resize(m.Width, m.Height)
+	for _, tc := range []struct {
+		name   string
+		w      *World
+		bx, by int
+	}{
+		{"ring road", city, 0, 3},
+		{"past the grid", city, 3, 660 / Pattern},
+		{"no roads", forest, 3, 3},
+		{"hand-drawn map", drawn, 2, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, ns := range []bool{true, false} {
+				if s := tc.w.lightAt(tc.bx, tc.by, ns); s != lightNone {
+					t.Errorf("light %d, want none", s)
+				}
+			}
+		})
+	}
+}
+
+// TestStopAtLights drives a car up to a junction at each light and checks
+// where it means to stop.
+func TestStopAtLights(t *testing.T) {
+	w := newTestWorld(1, themeNamed(t, "City"), 660, 660)
+	j := signalledJunctions(w)[0]
+	bx, by := j[0], j[1]
+	// clockFor sets the clock to show s to eastbound traffic.
+	clockFor := func(s lightState) {
+		for w.signalClock = 0; w.lightAt(bx, by, false) != s; w.signalClock += 0.05 {
+		}
+	}
+	const gap = 5.0
+	for _, tc := range []struct {
+		name       string
+		light      lightState
+		speed, agg float64
+		want       float64
+	}{
+		{"red", lightRed, 20, 0.9, gap},
+		{"green", lightGreen, 20, 0.1, math.Inf(1)},
+		{"amber, room to stop", lightAmber, 5, 0.1, gap},
+		{"amber, too fast to stop", lightAmber, 40, 0.1, math.Inf(1)},
+		{"amber, bold", lightAmber, 5, 0.9, math.Inf(1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clockFor(tc.light)
+			ts := NewTrafficSystem(1)
+			ts.resizeGrid(w.Width, w.Height)
+			ts.Cars = []NPCCar{{
+				X: float64(bx*Pattern) - gap - CarSize*0.5, Y: float64(by*Pattern) + RoadWidth*0.5 + laneOffset,
+				Alive: true, Size: CarSize, Speed: tc.speed, Aggression: tc.agg,
+			}}
+			ts.RebuildGrid()
+			if got := ts.stopDistance(0, w, nil); got != tc.want && math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("stops in %.2f, want %.2f", got, tc.want)
+			}
+		})
+	}
+}
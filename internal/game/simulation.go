@@ -0,0 +1,341 @@
+package game
+
+import (
+	"math"
+	"strings"
+)
+
+// InputFrame is the player intent for one simulation step.
+// Frontends translate platform input (mouse/keys, touch) into world space
+// before handing it to Simulation.Step.
+type InputFrame struct {
+	Steer float64 // desired heading in radians
+	Idle  bool    // true when the player is not steering this step
+
+	// Click requests a targeting strike at (ClickX, ClickY) in world pixels.
+	// Ignored unless a target ability is waiting for input.
+	Click          bool
+	ClickX, ClickY int
+
+	// Advance starts the game from the menu, continues after a level is
+	// complete and retries after a failed level.
+	Advance bool
+
+	// Rivals steer the other snakes in versus; Rivals[0] is player two.
+	Rivals [MaxPlayers - 1]PlayerInput
+}
+
+// Simulation owns the world and every gameplay system. It has no dependency
+// on windowing, input or GL so both frontends (and tests) drive the same code.
+type Simulation struct {
+	Seed uint64
+
+	World     *World
+	Peds      *PedestrianSystem
+	Traffic   *TrafficSystem
+	Cops      *CopSystem
+	Mil       *MilitarySystem
+	Bonuses   *BonusSystem
+	Particles *ParticleSystem
+	Weather   *WeatherSystem
+	Session   *GameSession
+	Snake     *Snake // nil until the first level starts
+
+	// Snakes holds every player's snake; Snakes[0] is Snake. Only versus
+	// has more than one.
+	Snakes []*Snake
+
+	// Events carries typed gameplay events from every system. Audio is
+	// subscribed by default; frontends add HUD, stats and the like.
+	Events *EventBus
+
+	// Cam receives screen shake from explosions; frontends own zoom/position.
+	Cam Camera
+
+	// Now is accumulated simulation time in seconds.
+	Now float64
+
+	// Fixed-step state (see Advance). Alpha is the render blend factor
+	// between the previous and the current tick.
+	Alpha       float64
+	stepAcc     float64
+	lastHead    [MaxPlayers]PathPoint
+	lastHeadOK  [MaxPlayers]bool
+	interpSaved []PathPoint
+
+	// ClickVerb replaces "CLICK" in targeting prompts (e.g. "TAP" on touch).
+	ClickVerb string
+
+	// Replay recording: while RecordReplay is set every level attempt is
+	// captured into Replay and handed to ReplayDone once the level ends.
+	RecordReplay bool
+	Replay       *Replay
+	ReplayDone   func(*Replay)
+
+	// Session theme roll before the current level started; saves rebuild
+	// the level from these.
+	levelThemeRoll    uint64
+	levelLastThemeIdx int
+}
+
+// NewSimulation sets up the opening world and creates all systems.
+func NewSimulation(seed uint64) *Simulation {
+	world := NewWorld(seed, DefaultWorldWidth, DefaultWorldHeight)
+	startTheme, _ := PickLevelTheme(seed, 1, 0, -1)
+	world.Theme = startTheme
+	world.BuildSpatialIndex()
+
+	events := NewEventBus()
+	SubscribeAudio(events)
+	world.Events = events
+
+	sim := &Simulation{
+		Seed:      seed,
+		Events:    events,
+		World:     world,
+		Peds:      NewPedestrianSystem(400, seed^0xFED),
+		Traffic:   NewTrafficSystem(seed ^ 0xCAFE),
+		Particles: NewParticleSystem(MaxParticles, seed^0xBEAD),
+		Weather:   NewWeatherSystem(seed ^ 0x57A7),
+		Bonuses:   NewBonusSystem(seed^0xB0B, 5),
+		Cops:      NewCopSystem(seed ^ 0xC095),
+		Mil:       NewMilitarySystem(seed ^ 0xA7A1),
+		Session:   NewGameSession(),
+		Cam: Camera{
+			X:    float64(world.Width) / 2,
+			Y:    float64(world.Height) / 2,
+			Zoom: DefaultZoom,
+		},
+	}
+	sim.Peds.Events = events
+	sim.Traffic.Events = events
+	sim.Bonuses.Events = events
+	sim.Cops.Events = events
+	sim.Mil.Events = events
+	sim.Session.Events = events
+	sim.Session.subscribeObjective(events)
+	sim.Peds.subscribePanic(events, world)
+	return sim
+}
+
+// StartLevel resets every system and begins the given level.
+// A level start is a clean checkpoint: the same seed, level and theme roll
+// always reproduce the same state, which is what replays rely on.
+func (sim *Simulation) StartLevel(level int) {
+	sim.levelThemeRoll = sim.Session.ThemeRoll
+	sim.levelLastThemeIdx = sim.Session.LastThemeIdx
+	if sim.RecordReplay {
+		sim.Replay = &Replay{Header: ReplayHeader{
+			Seed:         sim.Seed,
+			Level:        level,
+			ThemeRoll:    sim.Session.ThemeRoll,
+			LastThemeIdx: sim.Session.LastThemeIdx,
+			DT:           SimTickDT,
+			Mode:         sim.Session.Mode,
+			DailySeed:    sim.Session.DailySeed,
+		}}
+	}
+	sim.Now = 0
+	sim.Cam.ShakeTimer, sim.Cam.ShakeIntensity = 0, 0
+	StartLevelMusic(level)
+	sim.Session.StartLevel(level, sim.World, sim.Peds, sim.Traffic, sim.Bonuses, sim.Cops, sim.Mil, &sim.Snake, sim.Particles, sim.Seed)
+	sim.Weather.Configure(sim.Session.Weather, sim.Session.WeatherSeed)
+	sim.Snakes = append(sim.Snakes[:0], sim.Snake)
+	if sim.Session.Mode == ModeVersus {
+		sim.spawnRivals(level)
+	}
+}
+
+// focus is the point the view centres on: player one's head, or the middle
+// of the living heads in versus.
+func (sim *Simulation) focus() (float64, float64) {
+	box, ok := HeadBounds(sim.Snakes)
+	if !ok {
+		return float64(sim.World.Width) / 2, float64(sim.World.Height) / 2
+	}
+	return (box.X0 + box.X1) / 2, (box.Y0 + box.Y1) / 2
+}
+
+// TargetSelecting reports whether a target ability is waiting for a click.
+func (sim *Simulation) TargetSelecting() bool {
+	return sim.Session.State == StatePlaying &&
+		sim.Snake != nil &&
+		sim.Snake.Alive &&
+		sim.Snake.TargetNukeTimer > 0
+}
+
+// Step advances the simulation by dt seconds using the given input.
+func (sim *Simulation) Step(dt float64, in InputFrame) {
+	sim.Now += dt
+
+	switch sim.Session.State {
+	case StateMenu:
+		if in.Advance {
+			PlaySound(SoundMenuSelect)
+			sim.Session.RunScore = 0
+			sim.StartLevel(sim.Session.menuStartLevel())
+		}
+
+	case StatePlaying:
+		if sim.Replay != nil {
+			sim.Replay.Frames = append(sim.Replay.Frames, in)
+		}
+		sim.stepPlaying(dt, in)
+		if sim.Replay != nil && sim.Session.State != StatePlaying {
+			if sim.ReplayDone != nil {
+				sim.ReplayDone(sim.Replay)
+			}
+			sim.Replay = nil
+		}
+
+	case StateLevelComplete:
+		if in.Advance {
+			if sim.Session.Mode == ModeCampaign {
+				sim.StartLevel(sim.Session.CurrentLevel + 1)
+			} else {
+				sim.StartLevel(sim.Session.CurrentLevel)
+			}
+		}
+		sim.Particles.Update(dt, sim.World)
+
+	case StateLevelFailed:
+		if in.Advance {
+			sim.StartLevel(sim.Session.CurrentLevel)
+		}
+		sim.Particles.Update(dt, sim.World)
+	}
+}
+
+func (sim *Simulation) stepPlaying(dt float64, in InputFrame) {
+	snake := sim.Snake
+
+	// Targeting mode freezes the world until a click lands or time runs out.
+	if sim.TargetSelecting() {
+		snake.TargetNukeTimer -= dt
+		rem := snake.TargetNukeTimer
+		if rem < 0 {
+			rem = 0
+		}
+		snake.PowerupTimer = 1.1
+		snake.PowerupMsg, snake.PowerupCol = snake.TargetingPrompt(rem)
+		if sim.ClickVerb != "" {
+			snake.PowerupMsg = strings.ReplaceAll(snake.PowerupMsg, "CLICK", sim.ClickVerb)
+		}
+
+		if in.Click {
+			wx := clamp(in.ClickX, 0, sim.World.Width-1)
+			wy := clamp(in.ClickY, 0, sim.World.Height-1)
+			snake.ActivateTargetAbilityAt(wx, wy, sim.World, sim.Peds, sim.Traffic, sim.Particles, &sim.Cam, sim.Cops, sim.Mil)
+		} else if snake.TargetNukeTimer <= 0 {
+			snake.TargetNukeTimer = 0
+			snake.PowerupMsg, snake.PowerupCol = snake.TargetingLockLostPrompt()
+			snake.PowerupTimer = 1.6
+		}
+		return
+	}
+
+	// Steer snakes (player input only when AI mode is not active).
+	for i, s := range sim.Snakes {
+		if s == nil || !s.Alive {
+			continue
+		}
+		steer, idle := in.Steer, in.Idle
+		if i > 0 {
+			steer, idle = in.Rivals[i-1].Steer, in.Rivals[i-1].Idle
+		}
+		if s.AITimer <= 0 {
+			sim.steerSnake(s, dt, steer, idle)
+		} else {
+			s.Idle = false
+		}
+		// Flamethrower is automatic, no input needed.
+		s.Update(dt, sim.World, sim.Peds, sim.Traffic, sim.Bonuses, sim.Particles, &sim.Cam, sim.Cops, sim.Mil)
+	}
+	if len(sim.Snakes) > 1 {
+		resolveSnakeCollisions(sim.Snakes, sim.Particles)
+	}
+
+	// Update systems.
+	sim.Session.Update(dt)
+	sim.Cam.UpdateShake(dt, sim.Seed^uint64(sim.Now*1000))
+	sim.World.Update(dt)
+	sim.World.EvictIdleChunks(sim.World.ViewArea(sim.focus()))
+	UpdateBurnVisuals(sim.World, sim.Particles, dt)
+	UpdateCollapses(sim.World, sim.Particles, sim.Peds, sim.Traffic, &sim.Cam, sim.Cops, sim.Mil)
+	UpdateFire(sim.World, sim.Weather, sim.Particles, sim.World.ViewArea(sim.focus()), sim.Snakes, sim.Peds, sim.Traffic, sim.Cops, sim.Mil, dt)
+	sim.Peds.DayPhase = DayPhase(sim.Session.LevelTimer)
+	sim.Peds.Update(dt, sim.World, sim.Snakes, sim.Particles)
+	sunAmbNow, _, _, _ := SunCycleLight(sim.Session.LevelTimer)
+	sim.Traffic.NightFactor = NightIntensityFromAmbient(sunAmbNow)
+	sim.Traffic.Update(dt, sim.World, sim.Particles, sim.Peds, &sim.Cam)
+	sim.Weather.UpdateAndSpawn(sim.Particles, sim.World.ViewArea(sim.focus()), dt)
+	sim.Particles.UpdateWithShockwaveDamage(dt, sim.World, sim.Peds, sim.Cops, sim.Mil)
+	snakeHP := 1.0
+	if snake != nil {
+		snakeHP = snake.HP.Fraction()
+	}
+	sim.Bonuses.Update(sim.World, dt, sim.Peds.AliveCount(), snakeHP)
+	sim.Bonuses.SpawnSparks(sim.Particles, dt)
+	sim.Session.Objective.SpawnMarker(sim.Particles, dt, sim.Now, snake)
+
+	target := sim.wantedTarget()
+	sim.Cops.Update(dt, target, sim.World, sim.Particles, &sim.Cam, sim.Now)
+	sim.Mil.Update(dt, target, sim.World, sim.Particles, &sim.Cam, sim.Now)
+	for _, s := range sim.Snakes {
+		if s != nil && s.WantedLevel > sim.Session.WantedCap {
+			s.WantedLevel = sim.Session.WantedCap
+		}
+	}
+
+	// Cleanup dead entities.
+	sim.Peds.RemoveDead()
+	sim.Traffic.RemoveDead()
+	sim.Cops.RemoveDead()
+	sim.Mil.RemoveDead()
+
+	if sim.Session.Mode == ModeEndless {
+		sim.updateEndless(dt)
+	}
+
+	if sim.Session.Mode == ModeVersus {
+		sim.Session.CheckVersusEnd(sim.Snakes)
+	} else {
+		sim.Session.CheckLevelEnd(sim.Peds, snake)
+	}
+}
+
+// steerSnake applies player steering plus the shared assists: bonus-box
+// attraction, bounce hold after wall escapes, and proactive wall avoidance.
+func (sim *Simulation) steerSnake(snake *Snake, dt, steer float64, idle bool) {
+	hx, hy := snake.Head()
+
+	// Auto-steer toward nearby bonus boxes.
+	bestDist := 5.0 // attraction range
+	for i := range sim.Bonuses.Boxes {
+		b := &sim.Bonuses.Boxes[i]
+		if !b.Alive {
+			continue
+		}
+		d := math.Hypot(b.X-hx, b.Y-hy)
+		if d < bestDist {
+			bestDist = d
+			steer = math.Atan2(b.Y-hy, b.X-hx)
+			idle = false
+		}
+	}
+
+	// Bounce override: while escaping a wall, hold the escape
+	// heading so Steer() can't immediately re-enter the obstacle.
+	if snake.BounceTimer > 0 {
+		steer = snake.BounceDir
+		idle = false
+	} else if !idle {
+		// Proactive wall avoidance: look ahead and steer around
+		// buildings before the snake reaches them.
+		steer = WallAvoidAngle(hx, hy, steer, sim.World)
+	}
+	snake.Idle = idle
+	if !idle {
+		snake.Steer(steer, dt)
+	}
+}
@@ -269,6 +269,10 @@ type Snake struct {
 	BounceDir   float64 // heading to hold during bounce
 
 	Alive bool
+
+	Player int // 0 for player one; versus rivals count up from 1
+
+	Events *EventBus // receives gameplay events; may be nil
 }
 
 func NewSnake(x, y float64, speed float64) *Snake {
@@ -309,6 +313,17 @@ func (s *Snake) evoHeadColor() RGB {
 		{R: 145, G: 185, B: 245}, // ice blue
 		{R: 225, G: 120, B: 255}, // violet apex
 	}
+	if s.Player > 0 {
+		// Versus rival: warm colors so the two snakes never look alike.
+		palette = [6]RGB{
+			{R: 230, G: 150, B: 20},  // amber
+			{R: 240, G: 175, B: 40},  // brighter amber
+			{R: 250, G: 200, B: 70},  // gold
+			{R: 255, G: 140, B: 90},  // coral
+			{R: 255, G: 100, B: 130}, // pink
+			{R: 255, G: 80, B: 200},  // magenta apex
+		}
+	}
 	return palette[clamp(s.EvoLevel, 0, len(palette)-1)]
 }
 
@@ -473,7 +488,7 @@ func strikeWormWalkable(world *World, x, y float64) bool {
 	}
 	ix := int(math.Round(x))
 	iy := int(math.Round(y))
-	if ix < 0 || iy < 0 || ix >= WorldWidth || iy >= WorldHeight {
+	if ix < 0 || iy < 0 || ix >= world.Width || iy >= world.Height {
 		return false
 	}
 	return world.HeightAt(ix, iy) == 0
@@ -481,18 +496,18 @@ func strikeWormWalkable(world *World, x, y float64) bool {
 
 func nearestWalkablePoint(world *World, x, y float64, maxRadius int) (float64, float64, bool) {
 	if world == nil {
-		return clampF(x, 0, float64(WorldWidth-1)), clampF(y, 0, float64(WorldHeight-1)), true
+		return clampF(x, 0, float64(world.Width-1)), clampF(y, 0, float64(world.Height-1)), true
 	}
-	ix := clamp(int(math.Round(x)), 0, WorldWidth-1)
-	iy := clamp(int(math.Round(y)), 0, WorldHeight-1)
+	ix := clamp(int(math.Round(x)), 0, world.Width-1)
+	iy := clamp(int(math.Round(y)), 0, world.Height-1)
 	if world.HeightAt(ix, iy) == 0 {
 		return float64(ix) + 0.5, float64(iy) + 0.5, true
 	}
 	for r := 1; r <= maxRadius; r++ {
-		minX := clamp(ix-r, 0, WorldWidth-1)
-		maxX := clamp(ix+r, 0, WorldWidth-1)
-		minY := clamp(iy-r, 0, WorldHeight-1)
-		maxY := clamp(iy+r, 0, WorldHeight-1)
+		minX := clamp(ix-r, 0, world.Width-1)
+		maxX := clamp(ix+r, 0, world.Width-1)
+		minY := clamp(iy-r, 0, world.Height-1)
+		maxY := clamp(iy+r, 0, world.Height-1)
 		for px := minX; px <= maxX; px++ {
 			if world.HeightAt(px, minY) == 0 {
 				return float64(px) + 0.5, float64(minY) + 0.5, true
@@ -675,13 +690,13 @@ func (s *Snake) ActivateTargetAbilityAt(wx, wy int, world *World, peds *Pedestri
 	case BonusTargetWorms:
 		s.activateTargetWormStrike(wx, wy, world, particles)
 	case BonusTargetGunship:
-		s.activateTargetGunshipStrike(wx, wy, particles)
+		s.activateTargetGunshipStrike(wx, wy, world, particles)
 	case BonusTargetHeliMissile:
-		s.activateTargetHeliMissileStrike(wx, wy, particles)
+		s.activateTargetHeliMissileStrike(wx, wy, world, particles)
 	case BonusTargetBombBelt:
 		s.activateTargetBombBelt(wx, wy, world, particles)
 	case BonusTargetAirSupport:
-		s.activateTargetAirSupport(wx, wy, particles)
+		s.activateTargetAirSupport(wx, wy, world, particles)
 	case BonusTargetPigs:
 		s.activateTargetTimedExploder(wx, wy, world, particles, TimedExploderPig)
 	case BonusTargetCars:
@@ -716,8 +731,8 @@ func (s *Snake) activateTargetWormStrike(wx, wy int, world *World, particles *Pa
 	for i := 0; i < count; i++ {
 		ang := r.RangeF(0, 2*math.Pi)
 		dist := r.RangeF(0, 6.0)
-		sx := clampF(float64(wx)+math.Cos(ang)*dist, 0, float64(WorldWidth-1))
-		sy := clampF(float64(wy)+math.Sin(ang)*dist, 0, float64(WorldHeight-1))
+		sx := clampF(float64(wx)+math.Cos(ang)*dist, 0, float64(world.Width-1))
+		sy := clampF(float64(wy)+math.Sin(ang)*dist, 0, float64(world.Height-1))
 		if nx, ny, ok := nearestWalkablePoint(world, sx, sy, 18); ok {
 			sx, sy = nx, ny
 		}
@@ -742,7 +757,7 @@ func (s *Snake) activateTargetWormStrike(wx, wy int, world *World, particles *Pa
 	}
 }
 
-func (s *Snake) activateTargetHeliStrike(wx, wy int, particles *ParticleSystem, missileMode bool) {
+func (s *Snake) activateTargetHeliStrike(wx, wy int, world *World, particles *ParticleSystem, missileMode bool) {
 	r := NewRand(uint64(wx*131+wy*61) ^ 0xBADC0DE5EED)
 	leaders := 3
 	escorts := 2 + r.Range(0, 2) // 2-4 support helicopters
@@ -753,11 +768,11 @@ func (s *Snake) activateTargetHeliStrike(wx, wy int, particles *ParticleSystem,
 		orbitR := 8.0 + r.RangeF(0, 10.0)
 		attackSpread := 10.0 + r.RangeF(0, 18.0)
 		attackA := r.RangeF(0, 2*math.Pi)
-		ax := clampF(float64(wx)+math.Cos(attackA)*attackSpread, 2, float64(WorldWidth-2))
-		ay := clampF(float64(wy)+math.Sin(attackA)*attackSpread, 2, float64(WorldHeight-2))
+		ax := clampF(float64(wx)+math.Cos(attackA)*attackSpread, 2, float64(world.Width-2))
+		ay := clampF(float64(wy)+math.Sin(attackA)*attackSpread, 2, float64(world.Height-2))
 		s.StrikeHelis = append(s.StrikeHelis, StrikeHeli{
-			CenterX: clampF(ax+r.RangeF(-5.0, 5.0), 2, float64(WorldWidth-2)),
-			CenterY: clampF(ay+r.RangeF(-5.0, 5.0), 2, float64(WorldHeight-2)),
+			CenterX: clampF(ax+r.RangeF(-5.0, 5.0), 2, float64(world.Width-2)),
+			CenterY: clampF(ay+r.RangeF(-5.0, 5.0), 2, float64(world.Height-2)),
 			AttackX: ax, AttackY: ay,
 			OrbitA:      baseA + r.RangeF(-0.4, 0.4),
 			OrbitR:      orbitR,
@@ -800,18 +815,18 @@ func (s *Snake) activateTargetHeliStrike(wx, wy int, particles *ParticleSystem,
 	}
 }
 
-func (s *Snake) activateTargetGunshipStrike(wx, wy int, particles *ParticleSystem) {
-	s.activateTargetHeliStrike(wx, wy, particles, false)
+func (s *Snake) activateTargetGunshipStrike(wx, wy int, world *World, particles *ParticleSystem) {
+	s.activateTargetHeliStrike(wx, wy, world, particles, false)
 }
 
-func (s *Snake) activateTargetHeliMissileStrike(wx, wy int, particles *ParticleSystem) {
-	s.activateTargetHeliStrike(wx, wy, particles, true)
+func (s *Snake) activateTargetHeliMissileStrike(wx, wy int, world *World, particles *ParticleSystem) {
+	s.activateTargetHeliStrike(wx, wy, world, particles, true)
 }
 
 func (s *Snake) activateTargetBombBelt(wx, wy int, world *World, particles *ParticleSystem) {
 	r := NewRand(uint64(wx*187+wy*97) ^ 0xB0B0B37)
-	x := clampF(float64(wx), 0, float64(WorldWidth-1))
-	y := clampF(float64(wy), 0, float64(WorldHeight-1))
+	x := clampF(float64(wx), 0, float64(world.Width-1))
+	y := clampF(float64(wy), 0, float64(world.Height-1))
 	if nx, ny, ok := nearestWalkablePoint(world, x, y, 20); ok {
 		x, y = nx, ny
 	}
@@ -835,14 +850,15 @@ func (s *Snake) activateTargetBombBelt(wx, wy int, world *World, particles *Part
 	}
 }
 
-func (s *Snake) activateTargetAirSupport(wx, wy int, particles *ParticleSystem) {
+func (s *Snake) activateTargetAirSupport(wx, wy int, world *World, particles *ParticleSystem) {
 	r := NewRand(uint64(wx*223+wy*149) ^ 0xA1A5A1F0)
-	tx := clampF(float64(wx), 0, float64(WorldWidth-1))
-	ty := clampF(float64(wy), 0, float64(WorldHeight-1))
+	tx := clampF(float64(wx), 0, float64(world.Width-1))
+	ty := clampF(float64(wy), 0, float64(world.Height-1))
 	a := r.RangeF(0, 2*math.Pi)
 	dx := math.Cos(a)
 	dy := math.Sin(a)
-	span := math.Hypot(float64(WorldWidth), float64(WorldHeight)) + 60.0
+	// Fly across the view, not the whole world.
+	span := math.Hypot(world.ViewSize()) + 60.0
 	startX := tx - dx*span*0.5
 	startY := ty - dy*span*0.5
 	speed := 115.0 + r.RangeF(0, 40.0)
@@ -878,8 +894,8 @@ func (s *Snake) activateTargetTimedExploder(wx, wy int, world *World, particles
 		uint64(len(s.TimedExploders)+1)*0xC2B2AE3D27D4EB4F ^
 		s.TargetRoll*0x94D049BB133111EB
 	r := NewRand(seed)
-	x := clampF(float64(wx), 0, float64(WorldWidth-1))
-	y := clampF(float64(wy), 0, float64(WorldHeight-1))
+	x := clampF(float64(wx), 0, float64(world.Width-1))
+	y := clampF(float64(wy), 0, float64(world.Height-1))
 	if nx, ny, ok := nearestWalkablePoint(world, x, y, 20); ok {
 		x, y = nx, ny
 	}
@@ -1044,8 +1060,8 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 				if r.Float64() < 0.4 {
 					bx := int(math.Round(hx - math.Cos(s.Heading)*3))
 					by := int(math.Round(hy - math.Sin(s.Heading)*3))
-					if bx >= 0 && by >= 0 && bx < WorldWidth && by < WorldHeight {
-						world.AddTempPaint(bx, by, RGB{R: 100, G: 15, B: 15}, 4.0)
+					if bx >= 0 && by >= 0 && bx < world.Width && by < world.Height {
+						world.AddDecal(bx, by, DecalBlood, RGB{R: 100, G: 15, B: 15}, 0.5)
 					}
 				}
 			}
@@ -1068,6 +1084,8 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 	}
 	if newLevel > s.EvoLevel {
 		s.EvoLevel = newLevel
+		hx, hy := s.Head()
+		s.Events.Emit(Event{Type: EventEvolved, X: hx, Y: hy, Data: newLevel})
 		// Flash effect on level up.
 		if particles != nil {
 			hx, hy := s.Head()
@@ -1251,7 +1269,7 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 			px := hx + math.Cos(s.Heading)*dist
 			py := hy + math.Sin(s.Heading)*dist
 			wx, wy := int(math.Round(px)), int(math.Round(py))
-			if wx >= 0 && wy >= 0 && wx < WorldWidth && wy < WorldHeight {
+			if wx >= 0 && wy >= 0 && wx < world.Width && wy < world.Height {
 				if world.HeightAt(wx, wy) > 0 {
 					world.StartBuildingBurn(wx, wy)
 				}
@@ -1353,8 +1371,8 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 				}
 				// Phase through walls during return (move direct, clamp to bounds).
 				g.Heading = math.Atan2(dy, dx)
-				g.X = clampF(g.X+math.Cos(g.Heading)*returnSpeed*dt, 0, float64(WorldWidth-1))
-				g.Y = clampF(g.Y+math.Sin(g.Heading)*returnSpeed*dt, 0, float64(WorldHeight-1))
+				g.X = clampF(g.X+math.Cos(g.Heading)*returnSpeed*dt, 0, float64(world.Width-1))
+				g.Y = clampF(g.Y+math.Sin(g.Heading)*returnSpeed*dt, 0, float64(world.Height-1))
 				continue
 			}
 
@@ -1398,8 +1416,8 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 					g.TargetX, g.TargetY = nearX, nearY
 				} else {
 					for tries := 0; tries < 40; tries++ {
-						tx := r.Range(0, WorldWidth-1)
-						ty := r.Range(0, WorldHeight-1)
+						tx := r.Range(0, world.Width-1)
+						ty := r.Range(0, world.Height-1)
 						if world.HeightAt(tx, ty) == 0 {
 							g.TargetX = float64(tx) + 0.5
 							g.TargetY = float64(ty) + 0.5
@@ -1433,33 +1451,33 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 			nxi := int(math.Round(newX))
 			nyi := int(math.Round(newY))
 
-			if nxi >= 0 && nyi >= 0 && nxi < WorldWidth && nyi < WorldHeight && world.HeightAt(nxi, nyi) == 0 {
+			if nxi >= 0 && nyi >= 0 && nxi < world.Width && nyi < world.Height && world.HeightAt(nxi, nyi) == 0 {
 				g.X = newX
 				g.Y = newY
 			} else {
 				if s.BashTimer > 0 {
-					bx := clamp(nxi, 0, WorldWidth-1)
-					by := clamp(nyi, 0, WorldHeight-1)
+					bx := clamp(nxi, 0, world.Width-1)
+					by := clamp(nyi, 0, world.Height-1)
 					s.ExplodeAt(bx, by, 3, world, particles, peds, traffic, cam, cops, mil)
-					g.X = clampF(newX, 0, float64(WorldWidth-1))
-					g.Y = clampF(newY, 0, float64(WorldHeight-1))
+					g.X = clampF(newX, 0, float64(world.Width-1))
+					g.Y = clampF(newY, 0, float64(world.Height-1))
 				} else {
 					// Bounce: same axis-reflection as the main snake.
-					xBlocked := nxi < 0 || nxi >= WorldWidth || world.HeightAt(clamp(nxi, 0, WorldWidth-1), int(math.Round(g.Y))) > 0
-					yBlocked := nyi < 0 || nyi >= WorldHeight || world.HeightAt(int(math.Round(g.X)), clamp(nyi, 0, WorldHeight-1)) > 0
+					xBlocked := nxi < 0 || nxi >= world.Width || world.HeightAt(clamp(nxi, 0, world.Width-1), int(math.Round(g.Y))) > 0
+					yBlocked := nyi < 0 || nyi >= world.Height || world.HeightAt(int(math.Round(g.X)), clamp(nyi, 0, world.Height-1)) > 0
 					if xBlocked && !yBlocked {
 						g.Heading = math.Pi - g.Heading // vertical wall: flip X
-						g.X = clampF(g.X+math.Cos(g.Heading)*ghostSpeed*dt, 0, float64(WorldWidth-1))
+						g.X = clampF(g.X+math.Cos(g.Heading)*ghostSpeed*dt, 0, float64(world.Width-1))
 					} else if yBlocked && !xBlocked {
 						g.Heading = -g.Heading // horizontal wall: flip Y
-						g.Y = clampF(g.Y+math.Sin(g.Heading)*ghostSpeed*dt, 0, float64(WorldHeight-1))
+						g.Y = clampF(g.Y+math.Sin(g.Heading)*ghostSpeed*dt, 0, float64(world.Height-1))
 					} else {
 						g.Heading += math.Pi // corner: reverse
 						bx := g.X + math.Cos(g.Heading)*ghostSpeed*dt
 						by := g.Y + math.Sin(g.Heading)*ghostSpeed*dt
 						bxi := int(math.Round(bx))
 						byi := int(math.Round(by))
-						if bxi >= 0 && byi >= 0 && bxi < WorldWidth && byi < WorldHeight && world.HeightAt(bxi, byi) == 0 {
+						if bxi >= 0 && byi >= 0 && bxi < world.Width && byi < world.Height && world.HeightAt(bxi, byi) == 0 {
 							g.X = bx
 							g.Y = by
 						}
@@ -1481,8 +1499,8 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 				if g.StuckTimer > 1.2 {
 					// Teleport to a random walkable spot.
 					for tries := 0; tries < 50; tries++ {
-						tx := r.Range(0, WorldWidth-1)
-						ty := r.Range(0, WorldHeight-1)
+						tx := r.Range(0, world.Width-1)
+						ty := r.Range(0, world.Height-1)
 						if world.HeightAt(tx, ty) == 0 {
 							g.X = float64(tx) + 0.5
 							g.Y = float64(ty) + 0.5
@@ -1495,7 +1513,7 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 					for tries := 0; tries < 20; tries++ {
 						tx := int(math.Round(g.X)) + r.Range(-20, 20)
 						ty := int(math.Round(g.Y)) + r.Range(-20, 20)
-						if tx >= 0 && ty >= 0 && tx < WorldWidth && ty < WorldHeight && world.HeightAt(tx, ty) == 0 {
+						if tx >= 0 && ty >= 0 && tx < world.Width && ty < world.Height && world.HeightAt(tx, ty) == 0 {
 							g.TargetX = float64(tx) + 0.5
 							g.TargetY = float64(ty) + 0.5
 							break
@@ -1629,6 +1647,31 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 		}
 	}
 
+	// Swimming: slower in water, with a wake off both sides of the head.
+	if hx, hy := s.Head(); !s.Idle && world.IsWater(int(math.Round(hx)), int(math.Round(hy))) {
+		effectiveSpeed *= SnakeSwimSpeed
+		if particles != nil {
+			r := NewRand(uint64(hx*17+hy*31) ^ 0x5717)
+			foam := world.ColorAt(int(math.Round(hx)), int(math.Round(hy))).Add(80, 86, 80)
+			for _, side := range [2]float64{-1, 1} {
+				ang := s.Heading + side*r.RangeF(1.9, 2.4)
+				spd := r.RangeF(6, 14)
+				particles.Add(Particle{
+					X: hx, Y: hy,
+					VX: math.Cos(ang) * spd, VY: math.Sin(ang) * spd,
+					Z: 0.5, VZ: r.RangeF(8, 18),
+					Size: 0.5, MaxLife: r.RangeF(0.3, 0.5),
+					Col: foam, Kind: ParticleSplash,
+				})
+			}
+		}
+	}
+
+	// The body slides a glistening slime trail over dry ground.
+	if hx, hy := s.Head(); !s.Idle {
+		world.AddDecal(int(math.Round(hx)), int(math.Round(hy)), DecalSlime, Palette.Slime, 0.2)
+	}
+
 	// Move head forward, or trace a figure-8 when idle.
 	hx, hy := s.Head()
 	if s.Idle {
@@ -1647,8 +1690,8 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 		s.RattlePhase = 0
 		hx += math.Cos(s.Heading) * effectiveSpeed * dt
 		hy += math.Sin(s.Heading) * effectiveSpeed * dt
-		hx = clampF(hx, 0, float64(WorldWidth-1))
-		hy = clampF(hy, 0, float64(WorldHeight-1))
+		hx = clampF(hx, 0, float64(world.Width-1))
+		hy = clampF(hy, 0, float64(world.Height-1))
 		s.IdleBaseX, s.IdleBaseY = hx, hy // anchor for next idle phase
 	}
 
@@ -1758,7 +1801,7 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 				if ea, ok := s.findClearDir(hx, hy, s.Heading, step, world); ok {
 					nx := hx + math.Cos(ea)*step
 					ny := hy + math.Sin(ea)*step
-					if ix, iy := int(math.Round(nx)), int(math.Round(ny)); ix >= 0 && iy >= 0 && ix < WorldWidth && iy < WorldHeight && world.HeightAt(ix, iy) == 0 {
+					if ix, iy := int(math.Round(nx)), int(math.Round(ny)); ix >= 0 && iy >= 0 && ix < world.Width && iy < world.Height && world.HeightAt(ix, iy) == 0 {
 						hx = nx
 						hy = ny
 						escaped = true
@@ -1826,8 +1869,9 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 			continue
 		}
 		p.Alive = false
-		if p.Infection == StateSymptomatic {
-			// Infected: puke, shrink.
+		s.Events.Emit(Event{Type: EventPedEaten, X: p.X, Y: p.Y, Variant: p.Variant, Infection: p.Infection, Armed: p.Armed})
+		if p.Infection.Sick() {
+			// Sick or zombie: puke, shrink.
 			s.Length -= 3
 			s.PukeTimer = 1.5
 			green := RGB{R: 60, G: 180, B: 40}
@@ -1844,9 +1888,9 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 			// Green ground paint.
 			bx := int(math.Round(hx))
 			by := int(math.Round(hy))
-			world.AddTempPaint(bx, by, green, 3.0)
-			world.AddTempPaint(bx+1, by, green, 3.0)
-			world.AddTempPaint(bx, by+1, green, 3.0)
+			world.AddDecal(bx, by, DecalSlime, green, 1)
+			world.AddDecal(bx+1, by, DecalSlime, green, 1)
+			world.AddDecal(bx, by+1, DecalSlime, green, 1)
 		} else {
 			// Healthy (armed or not): eat, grow.
 			scoreAdd := 100
@@ -1900,7 +1944,7 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 			for ddx := -3; ddx <= 3; ddx++ {
 				for ddy := -3; ddy <= 3; ddy++ {
 					if abs(ddx)+abs(ddy) <= 3 {
-						world.PaintRGB(clamp(bx+ddx, 0, WorldWidth-1), clamp(by+ddy, 0, WorldHeight-1), blood)
+						world.PaintRGB(clamp(bx+ddx, 0, world.Width-1), clamp(by+ddy, 0, world.Height-1), blood)
 					}
 				}
 			}
@@ -2024,8 +2068,8 @@ func (s *Snake) Update(dt float64, world *World, peds *PedestrianSystem, traffic
 	for ci := range s.Clones {
 		c := &s.Clones[ci]
 		c.Heading = s.Heading // mirrors player direction exactly
-		nx := clampF(c.X+math.Cos(c.Heading)*effectiveSpeed*dt, 0, float64(WorldWidth-1))
-		ny := clampF(c.Y+math.Sin(c.Heading)*effectiveSpeed*dt, 0, float64(WorldHeight-1))
+		nx := clampF(c.X+math.Cos(c.Heading)*effectiveSpeed*dt, 0, float64(world.Width-1))
+		ny := clampF(c.Y+math.Sin(c.Heading)*effectiveSpeed*dt, 0, float64(world.Height-1))
 		c.X, c.Y = nx, ny
 		// Prepend to path.
 		c.Path = append(c.Path, PathPoint{})
@@ -2143,7 +2187,7 @@ func runFireBolts(cx, cy float64, timer, angle *float64, dt float64, s *Snake, w
 					continue
 				}
 				px, py := ibx+dx, iby+dy
-				if px < 0 || py < 0 || px >= WorldWidth || py >= WorldHeight {
+				if px < 0 || py < 0 || px >= world.Width || py >= world.Height {
 					continue
 				}
 				if world.HeightAt(px, py) > 0 {
@@ -2282,7 +2326,7 @@ func (s *Snake) updateVacuumBubbles(dt float64, world *World, peds *PedestrianSy
 				fpy := vb.Y + math.Sin(ang)*dist
 				ipx := int(math.Round(fpx))
 				ipy := int(math.Round(fpy))
-				if ipx < 0 || ipy < 0 || ipx >= WorldWidth || ipy >= WorldHeight {
+				if ipx < 0 || ipy < 0 || ipx >= world.Width || ipy >= world.Height {
 					continue
 				}
 				col := world.ColorAt(ipx, ipy)
@@ -2435,8 +2479,8 @@ func (s *Snake) updateGhostBombRun(dt float64, world *World, peds *PedestrianSys
 				continue
 			}
 			g.Heading = math.Atan2(dy, dx)
-			g.X = clampF(g.X+math.Cos(g.Heading)*returnSpeed*dt, 0, float64(WorldWidth-1))
-			g.Y = clampF(g.Y+math.Sin(g.Heading)*returnSpeed*dt, 0, float64(WorldHeight-1))
+			g.X = clampF(g.X+math.Cos(g.Heading)*returnSpeed*dt, 0, float64(world.Width-1))
+			g.Y = clampF(g.Y+math.Sin(g.Heading)*returnSpeed*dt, 0, float64(world.Height-1))
 			continue
 		}
 
@@ -2460,8 +2504,8 @@ func (s *Snake) updateGhostBombRun(dt float64, world *World, peds *PedestrianSys
 		if step > dist {
 			step = dist
 		}
-		g.X = clampF(g.X+math.Cos(g.Heading)*step, 0, float64(WorldWidth-1))
-		g.Y = clampF(g.Y+math.Sin(g.Heading)*step, 0, float64(WorldHeight-1))
+		g.X = clampF(g.X+math.Cos(g.Heading)*step, 0, float64(world.Width-1))
+		g.Y = clampF(g.Y+math.Sin(g.Heading)*step, 0, float64(world.Height-1))
 	}
 
 	if len(s.Ghosts) == 0 {
@@ -2573,8 +2617,8 @@ func (s *Snake) updateStrikeWorms(dt float64, world *World, peds *PedestrianSyst
 			if world != nil {
 				w.Heading += math.Pi * 0.75
 			} else {
-				w.X = clampF(nx, 0, float64(WorldWidth-1))
-				w.Y = clampF(ny, 0, float64(WorldHeight-1))
+				w.X = clampF(nx, 0, float64(world.Width-1))
+				w.Y = clampF(ny, 0, float64(world.Height-1))
 			}
 		}
 
@@ -2626,14 +2670,14 @@ func (s *Snake) updateStrikeHelis(dt float64, world *World, peds *PedestrianSyst
 					if step > d {
 						step = d
 					}
-					h.X = clampF(h.X+dx/d*step, 0, float64(WorldWidth-1))
-					h.Y = clampF(h.Y+dy/d*step, 0, float64(WorldHeight-1))
+					h.X = clampF(h.X+dx/d*step, 0, float64(world.Width-1))
+					h.Y = clampF(h.Y+dy/d*step, 0, float64(world.Height-1))
 				}
 			} else {
 				h.Life -= dt
 				h.OrbitA += h.OrbitSpd * dt
-				h.X = clampF(h.CenterX+math.Cos(h.OrbitA)*h.OrbitR, 0, float64(WorldWidth-1))
-				h.Y = clampF(h.CenterY+math.Sin(h.OrbitA)*h.OrbitR, 0, float64(WorldHeight-1))
+				h.X = clampF(h.CenterX+math.Cos(h.OrbitA)*h.OrbitR, 0, float64(world.Width-1))
+				h.Y = clampF(h.CenterY+math.Sin(h.OrbitA)*h.OrbitR, 0, float64(world.Height-1))
 				h.Heading = h.OrbitA + math.Pi/2
 
 				h.FireTimer -= dt
@@ -2684,8 +2728,8 @@ func (s *Snake) updateStrikeHelis(dt float64, world *World, peds *PedestrianSyst
 			h.Y += h.ExitVY * dt
 			h.Heading = math.Atan2(h.ExitVY, h.ExitVX)
 			const exitMargin = 20.0
-			outOfBounds := h.X < -exitMargin || h.X > float64(WorldWidth)+exitMargin ||
-				h.Y < -exitMargin || h.Y > float64(WorldHeight)+exitMargin
+			outOfBounds := h.X < -exitMargin || h.X > float64(world.Width)+exitMargin ||
+				h.Y < -exitMargin || h.Y > float64(world.Height)+exitMargin
 			if outOfBounds || h.ExitTimer <= 0 {
 				s.StrikeHelis[i] = s.StrikeHelis[len(s.StrikeHelis)-1]
 				s.StrikeHelis = s.StrikeHelis[:len(s.StrikeHelis)-1]
@@ -2705,7 +2749,7 @@ func (s *Snake) updateStrikeHelis(dt float64, world *World, peds *PedestrianSyst
 
 		b.X += b.VX * dt
 		b.Y += b.VY * dt
-		if b.X < 0 || b.Y < 0 || b.X >= float64(WorldWidth) || b.Y >= float64(WorldHeight) {
+		if b.X < 0 || b.Y < 0 || b.X >= float64(world.Width) || b.Y >= float64(world.Height) {
 			s.StrikeHeliShots[i] = s.StrikeHeliShots[len(s.StrikeHeliShots)-1]
 			s.StrikeHeliShots = s.StrikeHeliShots[:len(s.StrikeHeliShots)-1]
 			continue
@@ -2758,7 +2802,7 @@ func (s *Snake) updateStrikeHelis(dt float64, world *World, peds *PedestrianSyst
 			})
 		}
 
-		if m.X < 0 || m.Y < 0 || m.X >= float64(WorldWidth) || m.Y >= float64(WorldHeight) {
+		if m.X < 0 || m.Y < 0 || m.X >= float64(world.Width) || m.Y >= float64(world.Height) {
 			s.StrikeHeliMiss[i] = s.StrikeHeliMiss[len(s.StrikeHeliMiss)-1]
 			s.StrikeHeliMiss = s.StrikeHeliMiss[:len(s.StrikeHeliMiss)-1]
 			continue
@@ -2857,7 +2901,7 @@ func (s *Snake) updateStrikePlanes(dt float64, world *World, peds *PedestrianSys
 		}
 		ix := int(math.Round(b.X))
 		iy := int(math.Round(b.Y))
-		if ix >= 0 && iy >= 0 && ix < WorldWidth && iy < WorldHeight {
+		if ix >= 0 && iy >= 0 && ix < world.Width && iy < world.Height {
 			s.ExplodeAt(ix, iy, b.Radius, world, particles, peds, traffic, cam, cops, mil)
 		}
 		s.StrikePlaneBombs[i] = s.StrikePlaneBombs[len(s.StrikePlaneBombs)-1]
@@ -2871,7 +2915,7 @@ func timedExploderWalkable(world *World, x, y float64) bool {
 	}
 	ix := int(math.Round(x))
 	iy := int(math.Round(y))
-	if ix < 0 || iy < 0 || ix >= WorldWidth || iy >= WorldHeight {
+	if ix < 0 || iy < 0 || ix >= world.Width || iy >= world.Height {
 		return false
 	}
 	return world.HeightAt(ix, iy) == 0
@@ -2981,7 +3025,7 @@ func (s *Snake) updateHomingMissiles(dt, hx, hy float64, world *World, peds *Ped
 
 		mx := int(math.Round(m.X))
 		my := int(math.Round(m.Y))
-		if mx < 0 || my < 0 || mx >= WorldWidth || my >= WorldHeight {
+		if mx < 0 || my < 0 || mx >= world.Width || my >= world.Height {
 			s.Missiles[i] = s.Missiles[len(s.Missiles)-1]
 			s.Missiles = s.Missiles[:len(s.Missiles)-1]
 			continue
@@ -3036,7 +3080,7 @@ func (s *Snake) updateGatling(dt, hx, hy float64, world *World, peds *Pedestrian
 			sy := py + (ny-py)*t
 			ix := int(math.Round(sx))
 			iy := int(math.Round(sy))
-			if ix < 0 || iy < 0 || ix >= WorldWidth || iy >= WorldHeight {
+			if ix < 0 || iy < 0 || ix >= world.Width || iy >= world.Height {
 				hit = true
 				break
 			}
@@ -3704,7 +3748,7 @@ func WallAvoidAngle(hx, hy, targetAngle float64, world *World) float64 {
 		px := hx + math.Cos(targetAngle)*d
 		py := hy + math.Sin(targetAngle)*d
 		if int(math.Round(px)) < 0 || int(math.Round(py)) < 0 ||
-			int(math.Round(px)) >= WorldWidth || int(math.Round(py)) >= WorldHeight {
+			int(math.Round(px)) >= world.Width || int(math.Round(py)) >= world.Height {
 			break
 		}
 		if world.HeightAt(int(math.Round(px)), int(math.Round(py))) > 0 {
@@ -3729,7 +3773,7 @@ func WallAvoidAngle(hx, hy, targetAngle float64, world *World) float64 {
 		lx := hitX + math.Cos(leftAngle)*e
 		ly := hitY + math.Sin(leftAngle)*e
 		lxi, lyi := int(math.Round(lx)), int(math.Round(ly))
-		if lxi < 0 || lyi < 0 || lxi >= WorldWidth || lyi >= WorldHeight {
+		if lxi < 0 || lyi < 0 || lxi >= world.Width || lyi >= world.Height {
 			break
 		}
 		if world.HeightAt(lxi, lyi) == 0 {
@@ -3743,7 +3787,7 @@ func WallAvoidAngle(hx, hy, targetAngle float64, world *World) float64 {
 		rx := hitX + math.Cos(rightAngle)*e
 		ry := hitY + math.Sin(rightAngle)*e
 		rxi, ryi := int(math.Round(rx)), int(math.Round(ry))
-		if rxi < 0 || ryi < 0 || rxi >= WorldWidth || ryi >= WorldHeight {
+		if rxi < 0 || ryi < 0 || rxi >= world.Width || ryi >= world.Height {
 			break
 		}
 		if world.HeightAt(rxi, ryi) == 0 {
@@ -3800,7 +3844,7 @@ func (s *Snake) findClearDir(x, y, fromAngle, step float64, world *World) (float
 		ex := x + math.Cos(a)*step
 		ey := y + math.Sin(a)*step
 		exi, eyi := int(math.Round(ex)), int(math.Round(ey))
-		if exi < 0 || eyi < 0 || exi >= WorldWidth || eyi >= WorldHeight || world.HeightAt(exi, eyi) > 0 {
+		if exi < 0 || eyi < 0 || exi >= world.Width || eyi >= world.Height || world.HeightAt(exi, eyi) > 0 {
 			continue
 		}
 
@@ -3810,7 +3854,7 @@ func (s *Snake) findClearDir(x, y, fromAngle, step float64, world *World) (float
 			px := x + math.Cos(a)*d
 			py := y + math.Sin(a)*d
 			pxi, pyi := int(math.Round(px)), int(math.Round(py))
-			if pxi < 0 || pyi < 0 || pxi >= WorldWidth || pyi >= WorldHeight || world.HeightAt(pxi, pyi) > 0 {
+			if pxi < 0 || pyi < 0 || pxi >= world.Width || pyi >= world.Height || world.HeightAt(pxi, pyi) > 0 {
 				allClear = false
 				break
 			}
@@ -3832,8 +3876,8 @@ func (s *Snake) forceUnstuckWithRadius(hx, hy float64, world *World, maxRadius i
 	if world == nil {
 		return hx, hy, false
 	}
-	ix := clamp(int(math.Round(hx)), 0, WorldWidth-1)
-	iy := clamp(int(math.Round(hy)), 0, WorldHeight-1)
+	ix := clamp(int(math.Round(hx)), 0, world.Width-1)
+	iy := clamp(int(math.Round(hy)), 0, world.Height-1)
 
 	// If already on walkable ground, keep position and only refresh path.
 	if world.HeightAt(ix, iy) == 0 {
@@ -3843,10 +3887,10 @@ func (s *Snake) forceUnstuckWithRadius(hx, hy float64, world *World, maxRadius i
 
 	// Search outward in a square ring to find the closest walkable tile.
 	for r := 1; r <= maxRadius; r++ {
-		minX := clamp(ix-r, 0, WorldWidth-1)
-		maxX := clamp(ix+r, 0, WorldWidth-1)
-		minY := clamp(iy-r, 0, WorldHeight-1)
-		maxY := clamp(iy+r, 0, WorldHeight-1)
+		minX := clamp(ix-r, 0, world.Width-1)
+		maxX := clamp(ix+r, 0, world.Width-1)
+		minY := clamp(iy-r, 0, world.Height-1)
+		maxY := clamp(iy+r, 0, world.Height-1)
 
 		for x := minX; x <= maxX; x++ {
 			if world.HeightAt(x, minY) == 0 {
@@ -9,6 +9,11 @@ type ThemeConfig struct {
 	TreeCount     [2]int // min/max trees in parks.
 	TreeChance    int    // percent chance of trees on sidewalk/lot areas.
 	NoRoads       bool   // pure wilderness — no roads, sidewalks, or cars.
+	Rivers        [2]int // min/max rivers crossing the world.
+	Lakes         [2]int // min/max lakes per default-sized world.
+	Coast         bool   // sea along one side of the world, behind a beach.
+	Interiors     int    // percent of plain rectangular buildings with a walkable inside.
+	NightLife     bool   // crowds party in the parks and car parks at night.
 }
 
 var (
@@ -19,6 +24,8 @@ var (
 		BuildingSize:  [2]int{20, 68},
 		TreeCount:     [2]int{5, 20},
 		TreeChance:    10,
+		Rivers:        [2]int{0, 1},
+		Interiors:     30,
 	}
 	ThemeSuburban = ThemeConfig{
 		Name:          "Suburban",
@@ -27,6 +34,7 @@ var (
 		BuildingSize:  [2]int{15, 44},
 		TreeCount:     [2]int{15, 40},
 		TreeChance:    25,
+		Interiors:     40,
 	}
 	ThemeForest = ThemeConfig{
 		Name:          "Forest",
@@ -36,6 +44,8 @@ var (
 		TreeCount:     [2]int{30, 64},
 		TreeChance:    40,
 		NoRoads:       true,
+		Lakes:         [2]int{0, 2},
+		Interiors:     35,
 	}
 	ThemeRural = ThemeConfig{
 		Name:          "Rural",
@@ -44,6 +54,9 @@ var (
 		BuildingSize:  [2]int{15, 36},
 		TreeCount:     [2]int{10, 35},
 		TreeChance:    30,
+		Rivers:        [2]int{0, 1},
+		Lakes:         [2]int{0, 2},
+		Interiors:     40,
 	}
 	// ThemeArctic: sparse icy settlement — fewer trees, larger open lanes.
 	ThemeArctic = ThemeConfig{
@@ -53,6 +66,7 @@ var (
 		BuildingSize:  [2]int{16, 40},
 		TreeCount:     [2]int{2, 10},
 		TreeChance:    5,
+		Interiors:     35,
 	}
 	// ThemeDesert: dry low-rise blocks — wide open space, almost no trees.
 	ThemeDesert = ThemeConfig{
@@ -62,6 +76,7 @@ var (
 		BuildingSize:  [2]int{14, 42},
 		TreeCount:     [2]int{0, 4},
 		TreeChance:    2,
+		Interiors:     35,
 	}
 	// ThemeSand: wind-swept sandy district with sparse vegetation.
 	ThemeSand = ThemeConfig{
@@ -71,6 +86,7 @@ var (
 		BuildingSize:  [2]int{14, 44},
 		TreeCount:     [2]int{0, 5},
 		TreeChance:    2,
+		Interiors:     30,
 	}
 	// ThemeWinter: snowy city blocks with icy open lanes.
 	ThemeWinter = ThemeConfig{
@@ -80,6 +96,7 @@ var (
 		BuildingSize:  [2]int{14, 42},
 		TreeCount:     [2]int{4, 16},
 		TreeChance:    8,
+		Interiors:     30,
 	}
 	// ThemeBeach: bright coastal district with open beach zones.
 	ThemeBeach = ThemeConfig{
@@ -89,6 +106,9 @@ var (
 		BuildingSize:  [2]int{12, 34},
 		TreeCount:     [2]int{4, 16},
 		TreeChance:    14,
+		Lakes:         [2]int{0, 1},
+		Coast:         true,
+		Interiors:     35,
 	}
 	// Forest seasonal palettes.
 	ThemeForestSpring = ThemeConfig{
@@ -99,6 +119,7 @@ var (
 		TreeCount:     [2]int{34, 72},
 		TreeChance:    58,
 		NoRoads:       true,
+		Interiors:     35,
 	}
 	ThemeForestSummer = ThemeConfig{
 		Name:          "Forest Summer",
@@ -108,6 +129,7 @@ var (
 		TreeCount:     [2]int{38, 78},
 		TreeChance:    68,
 		NoRoads:       true,
+		Interiors:     35,
 	}
 	ThemeForestAutumn = ThemeConfig{
 		Name:          "Forest Autumn",
@@ -117,6 +139,7 @@ var (
 		TreeCount:     [2]int{26, 58},
 		TreeChance:    36,
 		NoRoads:       true,
+		Interiors:     35,
 	}
 	ThemeForestWinter = ThemeConfig{
 		Name:          "Forest Winter",
@@ -126,6 +149,7 @@ var (
 		TreeCount:     [2]int{18, 44},
 		TreeChance:    18,
 		NoRoads:       true,
+		Interiors:     35,
 	}
 	// ThemeSpace: outpost biome — wilderness style map with sparse structures.
 	ThemeSpace = ThemeConfig{
@@ -136,6 +160,7 @@ var (
 		TreeCount:     [2]int{0, 1},
 		TreeChance:    0,
 		NoRoads:       true,
+		Interiors:     30,
 	}
 	// ThemeUnderwater: reef labyrinth — no roads and scattered obstacle clusters.
 	ThemeUnderwater = ThemeConfig{
@@ -146,6 +171,8 @@ var (
 		TreeCount:     [2]int{0, 2},
 		TreeChance:    0,
 		NoRoads:       true,
+		Rivers:        [2]int{1, 2},
+		Lakes:         [2]int{2, 4},
 	}
 	// ThemeMegacity: towering urban canyon — massive building footprints, almost no parks.
 	ThemeMegacity = ThemeConfig{
@@ -155,6 +182,7 @@ var (
 		BuildingSize:  [2]int{45, 95},
 		TreeCount:     [2]int{2, 8},
 		TreeChance:    4,
+		Interiors:     20,
 	}
 	// ThemeParkCity: green city — large open parks dominate, small scattered buildings.
 	ThemeParkCity = ThemeConfig{
@@ -164,6 +192,8 @@ var (
 		BuildingSize:  [2]int{12, 32},
 		TreeCount:     [2]int{25, 60},
 		TreeChance:    38,
+		Lakes:         [2]int{1, 2},
+		Interiors:     35,
 	}
 	// ThemeVillage: small town — tiny buildings, lots of greenery, open feel.
 	ThemeVillage = ThemeConfig{
@@ -173,6 +203,8 @@ var (
 		BuildingSize:  [2]int{8, 26},
 		TreeCount:     [2]int{12, 30},
 		TreeChance:    32,
+		Rivers:        [2]int{0, 1},
+		Interiors:     45,
 	}
 	// ThemeIndustrial: grimy district — medium-large blocky buildings, sparse trees.
 	ThemeIndustrial = ThemeConfig{
@@ -182,6 +214,7 @@ var (
 		BuildingSize:  [2]int{28, 72},
 		TreeCount:     [2]int{3, 12},
 		TreeChance:    6,
+		Interiors:     45,
 	}
 	// ThemeWasteland: scorched district with sparse growth and battered structures.
 	ThemeWasteland = ThemeConfig{
@@ -191,6 +224,7 @@ var (
 		BuildingSize:  [2]int{14, 40},
 		TreeCount:     [2]int{0, 8},
 		TreeChance:    4,
+		Interiors:     35,
 	}
 	// ThemeJungle: overgrown ruins with dense foliage and almost no roads.
 	ThemeJungle = ThemeConfig{
@@ -201,6 +235,9 @@ var (
 		TreeCount:     [2]int{38, 74},
 		TreeChance:    62,
 		NoRoads:       true,
+		Rivers:        [2]int{1, 1},
+		Lakes:         [2]int{0, 1},
+		Interiors:     30,
 	}
 	// ThemeCanyon: rocky mesa district with sparse trees and broader corridors.
 	ThemeCanyon = ThemeConfig{
@@ -210,6 +247,7 @@ var (
 		BuildingSize:  [2]int{16, 44},
 		TreeCount:     [2]int{1, 8},
 		TreeChance:    5,
+		Interiors:     25,
 	}
 	// ThemeVolcanic: ashlands with low vegetation and compact structures.
 	ThemeVolcanic = ThemeConfig{
@@ -219,6 +257,7 @@ var (
 		BuildingSize:  [2]int{14, 38},
 		TreeCount:     [2]int{0, 3},
 		TreeChance:    1,
+		Interiors:     20,
 	}
 	// ThemeSwamp: murky lowlands with uneven structure density.
 	ThemeSwamp = ThemeConfig{
@@ -228,6 +267,9 @@ var (
 		BuildingSize:  [2]int{10, 30},
 		TreeCount:     [2]int{8, 28},
 		TreeChance:    24,
+		Rivers:        [2]int{1, 1},
+		Lakes:         [2]int{3, 6},
+		Interiors:     30,
 	}
 	// ThemeNeon: high-energy district with tight streets and low greenery.
 	ThemeNeon = ThemeConfig{
@@ -237,6 +279,8 @@ var (
 		BuildingSize:  [2]int{18, 58},
 		TreeCount:     [2]int{1, 8},
 		TreeChance:    3,
+		Interiors:     25,
+		NightLife:     true,
 	}
 	// ThemeRuins: broken city blocks mixed with reclaimed vegetation.
 	ThemeRuins = ThemeConfig{
@@ -246,6 +290,7 @@ var (
 		BuildingSize:  [2]int{12, 34},
 		TreeCount:     [2]int{10, 26},
 		TreeChance:    20,
+		Interiors:     50,
 	}
 	// ThemeFarmland: open agricultural town with sparse low-rise buildings.
 	ThemeFarmland = ThemeConfig{
@@ -255,6 +300,8 @@ var (
 		BuildingSize:  [2]int{12, 28},
 		TreeCount:     [2]int{8, 24},
 		TreeChance:    18,
+		Lakes:         [2]int{0, 2},
+		Interiors:     40,
 	}
 	// ThemeHighlands: windy plateau settlements with medium structures.
 	ThemeHighlands = ThemeConfig{
@@ -264,6 +311,7 @@ var (
 		BuildingSize:  [2]int{14, 36},
 		TreeCount:     [2]int{6, 20},
 		TreeChance:    14,
+		Interiors:     35,
 	}
 
 	Themes = []ThemeConfig{
@@ -385,6 +433,15 @@ func themePickWeight(t ThemeConfig) int {
 	}
 }
 
+// PickFixedLevelTheme rolls a variant of a theme pinned by the level
+// definition. It draws from the same stream as PickLevelTheme so retries
+// still vary.
+func PickFixedLevelTheme(base ThemeConfig, seed uint64, level int, roll uint64) (ThemeConfig, int) {
+	r := NewRand(seed ^ uint64(level)*0xBAD5EED ^ (roll+1)*0x9E3779B185EBCA87 ^ 0xC0FFEE55AA55C0DE)
+	_, idx, _ := themeByName(base.Name)
+	return ThemeVariant(base, r, level), idx
+}
+
 func PickLevelTheme(seed uint64, level int, roll uint64, lastIdx int) (ThemeConfig, int) {
 	if len(Themes) == 0 {
 		return ThemeCity, -1
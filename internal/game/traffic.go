@@ -15,16 +15,26 @@ type NPCCar struct {
 	StuckTimer     float64
 	TurnCount      int  // incremented each intersection decision, ensures varied routing
 	InIntersection bool // true while traversing a junction; prevents re-rolling turns
+	Held           bool // braking for a light, a queue or right of way (see signals.go)
 	Alive          bool
 	HP             Health
 	OnFire         bool
 	FireTimer      float64
 	Parked         bool
 	LotParked      bool
+	ParkTimer      float64 // how long a car that parked itself stays in the lot; 0 for good
+
+	// Route over the road graph (see roadgraph.go): the node passed last,
+	// then those ahead. A trip to a parking lot ends at LotX, LotY.
+	Route      []int
+	ToLot      bool
+	LotX, LotY float64
+	Parking    bool // pulling in to the lot
 
 	// Visual.
-	R, G, B float32
-	Size    float32
+	R, G, B      float32
+	Size         float32
+	LastX, LastY float64 // position at the previous simulation tick
 }
 
 type TrafficSystem struct {
@@ -37,6 +47,8 @@ type TrafficSystem struct {
 	gridW, gridH int
 	cellSize     int
 	cells        [][]int
+
+	Events *EventBus // receives gameplay events; may be nil
 }
 
 func NewTrafficSystem(seed uint64) *TrafficSystem {
@@ -49,12 +61,17 @@ func NewTrafficSystem(seed uint64) *TrafficSystem {
 		Env:      ThemeCity.Name,
 		cellSize: 32,
 	}
-	ts.gridW = (WorldWidth + ts.cellSize - 1) / ts.cellSize
-	ts.gridH = (WorldHeight + ts.cellSize - 1) / ts.cellSize
-	ts.cells = make([][]int, ts.gridW*ts.gridH)
+	ts.resizeGrid(DefaultWorldWidth, DefaultWorldHeight)
 	return ts
 }
 
+// resizeGrid sizes the spatial grid for a world of the given size.
+func (ts *TrafficSystem) resizeGrid(width, height int) {
+	ts.gridW = (width + ts.cellSize - 1) / ts.cellSize
+	ts.gridH = (height + ts.cellSize - 1) / ts.cellSize
+	ts.cells = make([][]int, ts.gridW*ts.gridH)
+}
+
 func (ts *TrafficSystem) SetEnvironment(name string) {
 	if name == "" {
 		name = ThemeCity.Name
@@ -63,10 +80,10 @@ func (ts *TrafficSystem) SetEnvironment(name string) {
 }
 
 func isRoadPixel(w *World, tp themePalette, x, y int) bool {
-	if x < 0 || y < 0 || x >= WorldWidth || y >= WorldHeight {
+	if x < 0 || y < 0 || x >= w.Width || y >= w.Height {
 		return false
 	}
-	if w.HeightAt(x, y) > 0 {
+	if w.HeightAt(x, y) > 0 || w.IsWater(x, y) {
 		return false
 	}
 	col := w.ColorAt(x, y)
@@ -74,14 +91,20 @@ func isRoadPixel(w *World, tp themePalette, x, y int) bool {
 }
 
 func isParkingLotPixel(w *World, tp themePalette, x, y int) bool {
-	if x < 0 || y < 0 || x >= WorldWidth || y >= WorldHeight {
+	if x < 0 || y < 0 || x >= w.Width || y >= w.Height {
 		return false
 	}
-	if w.HeightAt(x, y) > 0 {
+	cx, cy := x/ChunkSize, y/ChunkSize
+	return parkingLotAt(w.GetChunk(cx, cy), tp, (y-cy*ChunkSize)*ChunkSize+x-cx*ChunkSize)
+}
+
+// parkingLotAt reports whether pixel i of chunk c is parking lot tarmac.
+func parkingLotAt(c *Chunk, tp themePalette, i int) bool {
+	if c == nil || c.Height[i] > 0 {
 		return false
 	}
-	col := w.ColorAt(x, y)
-	if isRoadPixel(w, tp, x, y) {
+	col := chunkColorAt(c, i)
+	if c.Water[i] == 0 && (rgbEq(col, tp.Road) || rgbEq(col, roadStripeColor(tp))) {
 		return false
 	}
 	return rgbEq(col, tp.Road.Add(-10, -10, -10)) ||
@@ -220,66 +243,32 @@ func snapToCardinal(h float64) float64 {
 	return best
 }
 
+// carSpot picks where a new car starts and which way it faces: in a
+// parking lot if lot asks for one and there is one, else on a road. ok is
+// false if it found nowhere.
+type carSpot func(lot bool) (x, y, heading float64, inLot, ok bool)
+
 func (ts *TrafficSystem) SpawnRandom(w *World, n int) {
 	if n <= 0 || w == nil {
 		return
 	}
 	r := NewRand(ts.seed ^ 0xBEEF)
 	tp := buildThemePalette(w.Theme)
-	roadPts := make([][2]int, 0, 1024)
-	parkingPts := make([][2]int, 0, 512)
-	for y := 1; y < WorldHeight-1; y++ {
-		for x := 1; x < WorldWidth-1; x++ {
-			if isRoadPixel(w, tp, x, y) {
-				roadPts = append(roadPts, [2]int{x, y})
-			}
-			if isParkingLotPixel(w, tp, x, y) {
-				parkingPts = append(parkingPts, [2]int{x, y})
-			}
-		}
-	}
-	if len(roadPts) == 0 {
+	var spot carSpot
+	switch g := w.routes(); {
+	case g == nil:
+		spot = scanCarSpots(w, tp, r)
+	case !w.Theme.NoRoads:
+		spot = routeCarSpots(w, g, tp, r)
+	}
+	if spot == nil {
 		return
 	}
 
-	cardinals := [4]float64{0, math.Pi / 2, math.Pi, -math.Pi / 2}
 	for i := 0; i < n; i++ {
-		useParkingLot := len(parkingPts) > 0 && r.Intn(100) < 28
-		pt := roadPts[r.Intn(len(roadPts))]
-		jitter := 0.35
-		if useParkingLot {
-			pt = parkingPts[r.Intn(len(parkingPts))]
-			jitter = 0.15
-		}
-		fx := float64(pt[0]) + 0.5 + r.RangeF(-jitter, jitter)
-		fy := float64(pt[1]) + 0.5 + r.RangeF(-jitter, jitter)
-		fx = clampF(fx, 2, float64(WorldWidth-3))
-		fy = clampF(fy, 2, float64(WorldHeight-3))
-
-		heading := cardinals[r.Intn(4)]
-		if useParkingLot {
-			if rx, ry, ok := nearestRoadCenterCar(w, tp, fx, fy); ok {
-				heading = snapToCardinal(math.Atan2(ry-fy, rx-fx))
-			}
-		} else {
-			horiz, vert := roadAxisAt(w, tp, pt[0], pt[1])
-			switch {
-			case horiz && !vert:
-				if r.Intn(2) == 0 {
-					heading = 0
-				} else {
-					heading = math.Pi
-				}
-			case vert && !horiz:
-				if r.Intn(2) == 0 {
-					heading = math.Pi / 2
-				} else {
-					heading = -math.Pi / 2
-				}
-			}
-			if opts := roadCardinalOptions(w, tp, pt[0], pt[1]); len(opts) > 0 && !hasHeadingOption(opts, heading) {
-				heading = opts[r.Intn(len(opts))]
-			}
+		fx, fy, heading, useParkingLot, ok := spot(r.Intn(100) < 28)
+		if !ok {
+			continue
 		}
 
 		hp := 5.0 + r.RangeF(0, 10.0)
@@ -360,6 +349,125 @@ func (ts *TrafficSystem) SpawnRandom(w *World, n int) {
 	}
 }
 
+// routeCarSpots picks spots off the road graph: a stretch of road and a
+// lane along it, or a parking lot. Only the chunks the cars land in load,
+// however big the world.
+func routeCarSpots(w *World, g *roadGraph, tp themePalette, r *Rand) carSpot {
+	var stretches [][2]int
+	cols := len(g.xs)
+	for n := range g.east {
+		if g.east[n].road {
+			stretches = append(stretches, [2]int{n, n + 1})
+		}
+		if g.south[n].road {
+			stretches = append(stretches, [2]int{n, n + cols})
+		}
+	}
+	if len(stretches) == 0 {
+		return nil
+	}
+	return func(lot bool) (x, y, heading float64, inLot, ok bool) {
+		if lot && len(g.lots) > 0 {
+			l := g.lots[r.Intn(len(g.lots))]
+			x, y = l.x+r.RangeF(-0.15, 0.15), l.y+r.RangeF(-0.15, 0.15)
+			if isParkingLotPixel(w, tp, int(x), int(y)) {
+				// Face the road the lot opens onto.
+				ax, ay := g.pos(l.a)
+				if _, by := g.pos(l.b); ay == by {
+					heading = math.Atan2(ay-y, 0)
+				} else {
+					heading = math.Atan2(0, ax-x)
+				}
+				return x, y, heading, true, true
+			}
+		}
+		// A few tries, in case the road has been wrecked where one lands.
+		for range 8 {
+			s := stretches[r.Intn(len(stretches))]
+			a, b := s[0], s[1]
+			if r.Intn(2) == 0 {
+				a, b = b, a
+			}
+			ax, ay := g.pos(a)
+			bx, by := g.pos(b)
+			clear := RoadWidth*0.5 + 1
+			d := r.RangeF(clear, g.length(a, b)-clear)
+			v, horiz := g.lane(a, b, laneOffset)
+			if horiz {
+				x, y = ax+math.Copysign(d, bx-ax), v
+			} else {
+				x, y = v, ay+math.Copysign(d, by-ay)
+			}
+			if isRoadPixel(w, tp, int(x), int(y)) {
+				return x, y, g.heading(a, b), false, true
+			}
+		}
+		return 0, 0, 0, false, false
+	}
+}
+
+// scanCarSpots picks spots on a hand-drawn map, which has no road graph,
+// from every road and parking lot pixel.
+func scanCarSpots(w *World, tp themePalette, r *Rand) carSpot {
+	roadPts := make([][2]int, 0, 1024)
+	parkingPts := make([][2]int, 0, 512)
+	for y := 1; y < w.Height-1; y++ {
+		for x := 1; x < w.Width-1; x++ {
+			if isRoadPixel(w, tp, x, y) {
+				roadPts = append(roadPts, [2]int{x, y})
+			}
+			if isParkingLotPixel(w, tp, x, y) {
+				parkingPts = append(parkingPts, [2]int{x, y})
+			}
+		}
+	}
+	if len(roadPts) == 0 {
+		return nil
+	}
+
+	cardinals := [4]float64{0, math.Pi / 2, math.Pi, -math.Pi / 2}
+	return func(lot bool) (fx, fy, heading float64, inLot, ok bool) {
+		useParkingLot := lot && len(parkingPts) > 0
+		pt := roadPts[r.Intn(len(roadPts))]
+		jitter := 0.35
+		if useParkingLot {
+			pt = parkingPts[r.Intn(len(parkingPts))]
+			jitter = 0.15
+		}
+		fx = float64(pt[0]) + 0.5 + r.RangeF(-jitter, jitter)
+		fy = float64(pt[1]) + 0.5 + r.RangeF(-jitter, jitter)
+		fx = clampF(fx, 2, float64(w.Width-3))
+		fy = clampF(fy, 2, float64(w.Height-3))
+
+		heading = cardinals[r.Intn(4)]
+		if useParkingLot {
+			if rx, ry, ok := nearestRoadCenterCar(w, tp, fx, fy); ok {
+				heading = snapToCardinal(math.Atan2(ry-fy, rx-fx))
+			}
+		} else {
+			horiz, vert := roadAxisAt(w, tp, pt[0], pt[1])
+			switch {
+			case horiz && !vert:
+				if r.Intn(2) == 0 {
+					heading = 0
+				} else {
+					heading = math.Pi
+				}
+			case vert && !horiz:
+				if r.Intn(2) == 0 {
+					heading = math.Pi / 2
+				} else {
+					heading = -math.Pi / 2
+				}
+			}
+			if opts := roadCardinalOptions(w, tp, pt[0], pt[1]); len(opts) > 0 && !hasHeadingOption(opts, heading) {
+				heading = opts[r.Intn(len(opts))]
+			}
+		}
+		return fx, fy, heading, useParkingLot, true
+	}
+}
+
 func (ts *TrafficSystem) RebuildGrid() {
 	for i := range ts.cells {
 		ts.cells[i] = ts.cells[i][:0]
@@ -394,11 +502,19 @@ func (ts *TrafficSystem) QueryNeighbors(x, y, radius float64, fn func(int)) {
 	}
 }
 
+// npcCarCollides reports whether a car at x, y would hit terrain or drive
+// into water or the border. Its footprint fits a road's width with a pixel
+// to spare.
 func npcCarCollides(w *World, x, y float64) bool {
-	h := int(math.Round(CarSize * 0.5))
-	offs := [8][2]int{{-h, -h}, {0, -h}, {h, -h}, {-h, 0}, {h, 0}, {-h, h}, {0, h}, {h, h}}
+	if w.IsWater(int(math.Round(x)), int(math.Round(y))) {
+		return true
+	}
+	const h = CarSize*0.5 - 0.5
+	offs := [8][2]float64{{-h, -h}, {0, -h}, {h, -h}, {-h, 0}, {h, 0}, {-h, h}, {0, h}, {h, h}}
 	for _, o := range offs {
-		if w.IsBlocked(int(math.Round(x))+o[0], int(math.Round(y))+o[1]) {
+		px, py := int(math.Floor(x+o[0])), int(math.Floor(y+o[1]))
+		if w.IsBlocked(px, py) || px < BorderThickness || py < BorderThickness ||
+			px >= w.Width-BorderThickness || py >= w.Height-BorderThickness {
 			return true
 		}
 	}
@@ -410,6 +526,7 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 		return
 	}
 	tp := buildThemePalette(w.Theme)
+	graph := w.routes()
 
 	ts.RebuildGrid()
 
@@ -419,9 +536,31 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 			continue
 		}
 
-		// Parked cars: at night they gradually rejoin traffic.
+		// Fire damage over time, parked or not; dying car explodes.
+		if c.OnFire {
+			c.FireTimer += dt
+			c.HP.Damage(dt * 2.0)
+			if c.HP.IsDead() {
+				c.Alive = false
+				ExplodeAt(int(math.Round(c.X)), int(math.Round(c.Y)), 8, w, ps, peds, ts, cam, nil, nil)
+				continue
+			}
+		}
+
+		// Parked cars: at night they gradually rejoin traffic; those that
+		// parked in a lot themselves drive off again after a while.
 		if c.Parked {
 			if c.LotParked {
+				if c.ParkTimer > 0 {
+					if c.ParkTimer -= dt; c.ParkTimer <= 0 {
+						c.Parked, c.LotParked = false, false
+						if rx, ry, ok := nearestRoadCenterCar(w, tp, c.X, c.Y); ok {
+							c.Heading = snapToCardinal(math.Atan2(ry-c.Y, rx-c.X))
+						}
+						c.TurnTarget = c.Heading
+						c.Speed = c.TargetSpeed * 0.3
+					}
+				}
 				continue
 			}
 			if ts.NightFactor > 0.15 {
@@ -436,20 +575,18 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 			continue
 		}
 
-		// Fire damage over time; dying car explodes.
-		if c.OnFire {
-			c.FireTimer += dt
-			c.HP.Damage(dt * 2.0)
-			if c.HP.IsDead() {
-				c.Alive = false
-				ExplodeAt(int(math.Round(c.X)), int(math.Round(c.Y)), 8, w, ps, peds, ts, cam, nil, nil)
-				continue
-			}
-		}
-
 		// Approach target speed; night cars drive slightly faster.
 		nightMult := 1.0 + float64(ts.NightFactor)*0.4
 		c.Speed = approach(c.Speed, c.TargetSpeed*nightMult, 30.0*dt)
+
+		// Lights, queues and right of way: brake to stop short of them.
+		c.Held = false
+		if stop := ts.stopDistance(i, w, peds); !math.IsInf(stop, 1) {
+			if vmax := math.Sqrt(2 * carBrake * max(0, stop)); c.Speed > vmax {
+				c.Speed = vmax
+				c.Held = true
+			}
+		}
 		c.VX = math.Cos(c.Heading) * c.Speed
 		c.VY = math.Sin(c.Heading) * c.Speed
 
@@ -480,6 +617,10 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 			}
 		})
 		if minAvoidSpeed < c.Speed {
+			// Stamping on the brakes leaves rubber on the road.
+			if c.Speed > 15 && minAvoidSpeed < c.Speed*0.7 {
+				w.tyreMarks(c.X, c.Y, c.Heading, c.Size, 0.5)
+			}
 			c.Speed = max(0, minAvoidSpeed)
 			c.VX = math.Cos(c.Heading) * c.Speed
 			c.VY = math.Sin(c.Heading) * c.Speed
@@ -516,37 +657,64 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 			}
 		}
 
-		// Direction-aware lane offset on actual generated roads.
-		// Fast path: if already on a road pixel, skip the spiral search.
-		snappedH := snapToCardinal(c.Heading)
-		off := 1.6
-		nxi, nyi := int(math.Round(nx)), int(math.Round(ny))
-		var roadX, roadY float64
-		var roadOk bool
-		if isRoadPixel(w, tp, nxi, nyi) {
-			roadX, roadY, roadOk = float64(nxi)+0.5, float64(nyi)+0.5, true
-		} else {
-			roadX, roadY, roadOk = nearestRoadCenterCar(w, tp, nx, ny)
-		}
-		if roadOk {
-			horiz, vert := roadAxisAt(w, tp, int(math.Round(roadX)), int(math.Round(roadY)))
-			switch {
-			case horiz && !vert:
-				desiredY := roadY + off
-				if snappedH > math.Pi/2 || snappedH < -math.Pi/2 {
-					desiredY = roadY - off
+		// Routed cars steer by their route and keep to the right of it; the
+		// rest wander, choosing a way at each junction.
+		routed := graph != nil && ts.followRoute(i, w, graph)
+		switch {
+		case c.Parking:
+			// Leaving the road for the lot.
+		case routed:
+			// Where the road is too tight for a lane each way, keep to the
+			// middle; a car pushed into a wall makes for the middle anyway.
+			stuck := npcCarCollides(w, nx, ny)
+			if len(c.Route) < 2 || graph.nodeAt(c.X, c.Y) >= 0 && !stuck {
+				break
+			}
+			for _, off := range [2]float64{laneOffset, 0} {
+				lx, ly := nx, ny
+				if v, horiz := graph.lane(c.Route[0], c.Route[1], off); horiz {
+					ly += clampF((v-ny)*0.35, -2.0, 2.0)
+				} else {
+					lx += clampF((v-nx)*0.35, -2.0, 2.0)
 				}
-				ny += clampF((desiredY-ny)*0.35, -2.0, 2.0)
-			case vert && !horiz:
-				desiredX := roadX + off
-				if snappedH > 0 {
-					desiredX = roadX - off
+				if off == 0 && stuck || !npcCarCollides(w, lx, ly) {
+					nx, ny = lx, ly
+					break
+				}
+			}
+		default:
+			// Direction-aware lane offset on actual generated roads.
+			// Fast path: if already on a road pixel, skip the spiral search.
+			snappedH := snapToCardinal(c.Heading)
+			off := 1.6
+			nxi, nyi := int(math.Round(nx)), int(math.Round(ny))
+			var roadX, roadY float64
+			var roadOk bool
+			if isRoadPixel(w, tp, nxi, nyi) {
+				roadX, roadY, roadOk = float64(nxi)+0.5, float64(nyi)+0.5, true
+			} else {
+				roadX, roadY, roadOk = nearestRoadCenterCar(w, tp, nx, ny)
+			}
+			if roadOk {
+				horiz, vert := roadAxisAt(w, tp, int(math.Round(roadX)), int(math.Round(roadY)))
+				switch {
+				case horiz && !vert:
+					desiredY := roadY + off
+					if snappedH > math.Pi/2 || snappedH < -math.Pi/2 {
+						desiredY = roadY - off
+					}
+					ny += clampF((desiredY-ny)*0.35, -2.0, 2.0)
+				case vert && !horiz:
+					desiredX := roadX + off
+					if snappedH > 0 {
+						desiredX = roadX - off
+					}
+					nx += clampF((desiredX-nx)*0.35, -2.0, 2.0)
+				default:
+					// At intersections, softly recentre.
+					nx += clampF((roadX-nx)*0.15, -1.5, 1.5)
+					ny += clampF((roadY-ny)*0.15, -1.5, 1.5)
 				}
-				nx += clampF((desiredX-nx)*0.35, -2.0, 2.0)
-			default:
-				// At intersections, softly recentre.
-				nx += clampF((roadX-nx)*0.15, -1.5, 1.5)
-				ny += clampF((roadY-ny)*0.15, -1.5, 1.5)
 			}
 		}
 
@@ -597,20 +765,22 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 				}
 			}
 
-			currentCardinal := snapToCardinal(c.Heading)
-			r := NewRand(ts.seed ^ uint64(i)*0x5678 ^ uint64(c.TurnCount)*0xF00D)
-			if opts := roadCardinalOptions(w, tp, ix, iy); len(opts) > 0 {
-				c.TurnTarget = chooseTurnTarget(r, currentCardinal, opts)
-			} else {
-				roll := r.RangeF(0, 1)
-				if roll < 0.45 {
-					c.TurnTarget = currentCardinal
-				} else if roll < 0.70 {
-					c.TurnTarget = currentCardinal + math.Pi/2
-				} else if roll < 0.95 {
-					c.TurnTarget = currentCardinal - math.Pi/2
+			if !routed {
+				currentCardinal := snapToCardinal(c.Heading)
+				r := NewRand(ts.seed ^ uint64(i)*0x5678 ^ uint64(c.TurnCount)*0xF00D)
+				if opts := roadCardinalOptions(w, tp, ix, iy); len(opts) > 0 {
+					c.TurnTarget = chooseTurnTarget(r, currentCardinal, opts)
 				} else {
-					c.TurnTarget = currentCardinal + math.Pi // rare U-turn
+					roll := r.RangeF(0, 1)
+					if roll < 0.45 {
+						c.TurnTarget = currentCardinal
+					} else if roll < 0.70 {
+						c.TurnTarget = currentCardinal + math.Pi/2
+					} else if roll < 0.95 {
+						c.TurnTarget = currentCardinal - math.Pi/2
+					} else {
+						c.TurnTarget = currentCardinal + math.Pi // rare U-turn
+					}
 				}
 			}
 		} else if !atIntersection && c.InIntersection {
@@ -622,11 +792,17 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 		if c.WaitTimer > 0 {
 			c.WaitTimer -= dt
 			minSpeed := c.TargetSpeed * 0.35
+			if c.Held {
+				minSpeed = 0
+			}
 			c.Speed = max(minSpeed, c.Speed*0.85)
 			c.VX = math.Cos(c.Heading) * c.Speed
 			c.VY = math.Sin(c.Heading) * c.Speed
-			nx = c.X + c.VX*dt
-			ny = c.Y + c.VY*dt
+			if mx, my := c.X+c.VX*dt, c.Y+c.VY*dt; !npcCarCollides(w, mx, my) {
+				nx, ny = mx, my
+			} else {
+				nx, ny = c.X, c.Y
+			}
 		}
 
 		// Heading blend toward TurnTarget with a capped turn rate.
@@ -660,7 +836,7 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 		// This catches building-blocked cars regardless of speed value.
 		wx := int(math.Round(c.X))
 		wy := int(math.Round(c.Y))
-		offRoad := !isRoadPixel(w, tp, wx, wy)
+		offRoad := !c.Parking && !isRoadPixel(w, tp, wx, wy)
 		displacement := math.Hypot(nx-c.X, ny-c.Y)
 		blockedWhileTryingToMove := displacement < 0.03 && c.WaitTimer <= 0 && c.Speed > c.TargetSpeed*0.45
 		isStuck := offRoad || blockedWhileTryingToMove
@@ -671,27 +847,17 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 			if offRoad && foundRoad {
 				c.TurnTarget = math.Atan2(ry-c.Y, rx-c.X)
 			}
-			if c.StuckTimer > 1.2 && foundRoad {
-				// Teleport to nearest road center with a fresh random heading.
-				r := NewRand(ts.seed ^ uint64(i)*0xDEAD ^ uint64(c.StuckTimer*1000))
-				cardinals := [4]float64{0, math.Pi / 2, math.Pi, -math.Pi / 2}
-				c.X = rx
-				c.Y = ry
-				c.Speed = c.TargetSpeed * 0.6
-				rxi := int(math.Round(rx))
-				ryi := int(math.Round(ry))
-				opts := roadCardinalOptions(w, tp, rxi, ryi)
-				if len(opts) > 0 {
-					c.Heading = opts[r.Intn(len(opts))]
+			if c.StuckTimer > 1.2 && blockedWhileTryingToMove {
+				// Boxed in: back out the way it came and find another way
+				// from there.
+				if len(c.Route) >= 2 {
+					c.Route = []int{c.Route[1], c.Route[0]}
 				} else {
-					c.Heading = cardinals[r.Intn(4)]
+					c.TurnTarget = snapToCardinal(c.Heading + math.Pi)
 				}
-				c.TurnTarget = c.Heading
+				c.Parking, c.ToLot = false, false
 				c.TurnCount++
 				c.StuckTimer = 0
-				// Recompute next position after teleport.
-				nx = c.X + math.Cos(c.Heading)*c.Speed*dt
-				ny = c.Y + math.Sin(c.Heading)*c.Speed*dt
 			} else if offRoad && c.StuckTimer > 0.5 {
 				// Nudge heading toward nearest open road ahead.
 				if rx2, ry2, ok2 := nearestRoadCenterCar(w, tp, c.X+math.Cos(c.Heading)*5, c.Y+math.Sin(c.Heading)*5); ok2 {
@@ -711,8 +877,8 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 			c.Heading += 2 * math.Pi
 		}
 
-		c.X = clampF(nx, 0, float64(WorldWidth-1))
-		c.Y = clampF(ny, 0, float64(WorldHeight-1))
+		c.X = clampF(nx, 0, float64(w.Width-1))
+		c.Y = clampF(ny, 0, float64(w.Height-1))
 	}
 
 	// Car-car overlap pass: gently separate and slow down so cars can pass.
@@ -749,18 +915,24 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 			rvy := ci.VY - cj.VY
 			closing := rvx*nx + rvy*ny
 
-			// Always separate to resolve overlap.
+			// Always separate to resolve overlap, short of pushing either
+			// into a wall.
 			overlap := (minDist - d) * 0.5
-			ci.X -= nx * overlap
-			ci.Y -= ny * overlap
-			cj.X += nx * overlap
-			cj.Y += ny * overlap
+			if !npcCarCollides(w, ci.X-nx*overlap, ci.Y-ny*overlap) {
+				ci.X -= nx * overlap
+				ci.Y -= ny * overlap
+			}
+			if !npcCarCollides(w, cj.X+nx*overlap, cj.Y+ny*overlap) {
+				cj.X += nx * overlap
+				cj.Y += ny * overlap
+			}
 
 			// Slow both cars rather than crashing/exploding.
 			// More head-on overlap means stronger slowdown.
+			// Cars held at a light or in a queue stay stopped.
 			slowFactor := clampF(0.9-closing*0.08, 0.28, 0.9)
-			ci.Speed = max(ci.TargetSpeed*0.25, ci.Speed*slowFactor)
-			cj.Speed = max(cj.TargetSpeed*0.25, cj.Speed*slowFactor)
+			ci.Speed = max(heldMinSpeed(ci), ci.Speed*slowFactor)
+			cj.Speed = max(heldMinSpeed(cj), cj.Speed*slowFactor)
 			ci.VX = math.Cos(ci.Heading) * ci.Speed
 			ci.VY = math.Sin(ci.Heading) * ci.Speed
 			cj.VX = math.Cos(cj.Heading) * cj.Speed
@@ -773,6 +945,14 @@ func (ts *TrafficSystem) Update(dt float64, w *World, ps *ParticleSystem, peds *
 	}
 }
 
+// heldMinSpeed is the least speed an overlap slows a car to.
+func heldMinSpeed(c *NPCCar) float64 {
+	if c.Held {
+		return 0
+	}
+	return c.TargetSpeed * 0.25
+}
+
 // AliveCount returns the number of living cars.
 func (ts *TrafficSystem) AliveCount() int {
 	n := 0
@@ -787,7 +967,8 @@ func (ts *TrafficSystem) AliveCount() int {
 // RemoveDead removes dead cars using swap-remove.
 func (ts *TrafficSystem) RemoveDead() {
 	for i := 0; i < len(ts.Cars); {
-		if !ts.Cars[i].Alive {
+		if c := &ts.Cars[i]; !c.Alive {
+			ts.Events.Emit(Event{Type: EventCarDestroyed, X: c.X, Y: c.Y})
 			ts.Cars[i] = ts.Cars[len(ts.Cars)-1]
 			ts.Cars = ts.Cars[:len(ts.Cars)-1]
 		} else {
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// TestTrafficSpawnStreams spawns cars over worlds up to the largest and
+// checks each lands on a road or in a lot, facing along the road, and that
+// only the chunks they landed in, and the neighbours those were fitted
+// to, were loaded.
+func TestTrafficSpawnStreams(t *testing.T) {
+	for _, tc := range []struct {
+		theme string
+		seed  uint64
+		size  int
+		cars  int
+	}{
+		{"City", 1, DefaultWorldWidth, 12},
+		{"City", 99, MaxWorldSize, 28},
+		{"Suburban", 7, MaxWorldSize, 20},
+		{"Neon", 1234, 33 * Pattern, 16},
+	} {
+		t.Run(tc.theme, func(t *testing.T) {
+			th := themeNamed(t, tc.theme)
+			w := newTestWorld(tc.seed, th, tc.size, tc.size)
+			ts := NewTrafficSystem(tc.seed)
+			ts.resizeGrid(w.Width, w.Height)
+			ts.SpawnRandom(w, tc.cars)
+			if len(ts.Cars) != tc.cars {
+				t.Fatalf("spawned %d cars, want %d", len(ts.Cars), tc.cars)
+			}
+			if len(w.chunks) > 9*tc.cars {
+				t.Errorf("%d of %d chunks loaded for %d cars", len(w.chunks), (w.maxCx+1)*(w.maxCy+1), tc.cars)
+			}
+			tp := buildThemePalette(th)
+			for i, c := range ts.Cars {
+				x, y := int(c.X), int(c.Y)
+				if c.LotParked {
+					if !isParkingLotPixel(w, tp, x, y) {
+						t.Errorf("car %d parked off the lot at %d,%d", i, x, y)
+					}
+					continue
+				}
+				if !isRoadPixel(w, tp, x, y) {
+					t.Errorf("car %d off the road at %d,%d", i, x, y)
+				}
+				if snap := snapToCardinal(c.Heading); math.Abs(angDiff(snap, c.Heading)) > 1e-9 {
+					t.Errorf("car %d faces %.3f, not along the road", i, c.Heading)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
-//go:build !android
+//go:build !android && !headless
 
 package game
 
 import "fmt"
 
 // RenderHUD draws all in-game UI elements using the font atlas.
-func RenderHUD(r *Renderer, session *GameSession, peds *PedestrianSystem, snake *Snake, fbW, fbH int) {
+// snakes[0] is player one; versus passes the rivals after it.
+func RenderHUD(r *Renderer, session *GameSession, profile *Profile, peds *PedestrianSystem, snakes []*Snake, fbW, fbH int) {
+	var snake *Snake
+	if len(snakes) > 0 {
+		snake = snakes[0]
+	}
 	white := RGB{R: 255, G: 255, B: 255}
 	green := RGB{R: 100, G: 255, B: 100}
 	red := RGB{R: 255, G: 80, B: 80}
@@ -22,7 +27,27 @@ func RenderHUD(r *Renderer, session *GameSession, peds *PedestrianSystem, snake
 		msgScale := float32(1.0)
 		r.DrawString(msg, fbW/2-TextWidth(msg, msgScale)/2, fbH/2+20, msgScale, white)
 
+		mode := fmt.Sprintf("Mode: %s  (UP/DOWN)", session.Mode)
+		r.DrawString(mode, fbW/2-TextWidth(mode, 0.75)/2, fbH/2+120, 0.75, white)
+		if session.Mode == ModeCampaign && session.MaxStartLevel > 1 {
+			lvl := fmt.Sprintf("< Start at level %d >", session.MenuLevel)
+			r.DrawString(lvl, fbW/2-TextWidth(lvl, 0.75)/2, fbH/2+150, 0.75, white)
+		}
+		if profile != nil {
+			if line := profile.MenuSummary(session); line != "" {
+				r.DrawString(line, fbW/2-TextWidth(line, 0.6)/2, fbH/2+180, 0.6, yellow)
+			}
+		}
+
+		if session.CanContinue {
+			cont := "Press C to Continue"
+			r.DrawString(cont, fbW/2-TextWidth(cont, 0.75)/2, fbH/2+90, 0.75, green)
+		}
+
 		hint := "Eat humans to grow"
+		if session.Mode == ModeVersus {
+			hint = "P1 steers with the mouse, P2 with WASD"
+		}
 		hintScale := float32(0.65)
 		r.DrawString(hint, fbW/2-TextWidth(hint, hintScale)/2, fbH/2+55, hintScale, yellow)
 
@@ -31,56 +56,73 @@ func RenderHUD(r *Renderer, session *GameSession, peds *PedestrianSystem, snake
 		hs := func(v float32) float32 { return v * hudMul }
 		s := hs(0.75)
 
-		// Top-left: score only (no level/theme name).
+		// Top-left: score only (no level/theme name); one per player in versus.
 		scoreStr := fmt.Sprintf("Score: %d", session.Score)
+		if len(snakes) > 1 {
+			scoreStr = ""
+			for i, sn := range snakes {
+				scoreStr += fmt.Sprintf("P%d: %d  ", i+1, sn.Score)
+			}
+		}
 		r.DrawString(scoreStr, 8, 8, s, white)
 
 		// Top-center: timer.
 		timeStr := fmt.Sprintf("%.1fs", session.LevelTimer)
 		r.DrawString(timeStr, fbW/2-TextWidth(timeStr, s)/2, 8, s, white)
 
-		// Top-right: peds remaining.
-		if peds != nil {
-			remaining := peds.AliveCount()
-			pedStr := fmt.Sprintf("Humans: %d", remaining)
-			r.DrawString(pedStr, fbW-TextWidth(pedStr, s)-8, 8, s, green)
+		// Top-right: objective progress in the campaign, the clock in the other modes.
+		objStr := session.ObjectiveLabel(peds, snake)
+		r.DrawString(objStr, fbW-TextWidth(objStr, s)-8, 8, s, green)
+		if peds != nil && infectedAliveCount(peds) > 0 {
+			meter := outbreakMeter(peds.Outbreak())
+			r.DrawString(meter, fbW-TextWidth(meter, hs(0.65))-8, 8+int(30*s), hs(0.65), sickTint)
 		}
 
-		// Bottom: wanted stars + HP bar.
-		if snake != nil {
+		// Bottom: wanted stars + HP bar per player; player two's on the right.
+		for i, sn := range snakes {
 			barScale := hs(0.85)
 			const barChars = 16
 			barY := fbH - 50
 			barX := 10
 
 			// Wanted level: 5 stars, filled based on WantedLevel.
-			filledStars := int(snake.WantedLevel)
+			filledStars := int(sn.WantedLevel)
 			wantedStr := repeatChar('*', filledStars) + repeatChar('.', 5-filledStars)
 			wantedLabel := fmt.Sprintf("WANTED [%s]", wantedStr)
+			hpFrac := sn.HP.Fraction()
+			hpBar := fmt.Sprintf("[%-*s]", barChars, repeatChar('#', int(float64(barChars)*hpFrac)))
+			if i > 0 {
+				barX = fbW - 10 - TextWidth(wantedLabel, barScale) - 8 - TextWidth(hpBar, barScale)
+			}
 			wantedCol := blue
-			if snake.WantedLevel >= WantedMax {
+			if sn.WantedLevel >= WantedMax {
 				wantedCol = red
-			} else if snake.WantedLevel >= WantedTier2 {
+			} else if sn.WantedLevel >= WantedTier2 {
 				wantedCol = yellow
 			}
-			r.DrawString("WANTED", barX, barY-22, hs(0.65), blue)
+			wantedTitle := "WANTED"
+			if len(snakes) > 1 {
+				wantedTitle = fmt.Sprintf("P%d WANTED", i+1)
+			}
+			r.DrawString(wantedTitle, barX, barY-22, hs(0.65), blue)
 			r.DrawString(fmt.Sprintf("[%s]", wantedStr), barX, barY, barScale, wantedCol)
 
 			// HP bar.
-			hpFrac := snake.HP.Fraction()
-			hpBar := fmt.Sprintf("[%-*s]", barChars, repeatChar('#', int(float64(barChars)*hpFrac)))
 			hpCol := HealthBarColor(hpFrac)
 			hpBarX := barX + TextWidth(wantedLabel, barScale) + 8
 			r.DrawString("HP", hpBarX, barY-22, hs(0.65), white)
 			r.DrawString(hpBar, hpBarX, barY, barScale, hpCol)
 
-			// Evolution level indicator.
-			if snake.EvoLevel > 0 {
-				evoStr := fmt.Sprintf("EVO %d", snake.EvoLevel)
-				evoCol := snake.evoHeadColor()
+			// Evolution level indicator (player one only; rivals have no room).
+			if i == 0 && sn.EvoLevel > 0 {
+				evoStr := fmt.Sprintf("EVO %d", sn.EvoLevel)
+				evoCol := sn.evoHeadColor()
 				evoX := hpBarX + TextWidth(hpBar, barScale) + 16
 				r.DrawString(evoStr, evoX, barY, hs(0.75), evoCol)
 			}
+		}
+
+		if snake != nil {
 
 			// Flamethrower indicator.
 			if snake.FlamethrowerTimer > 0 {
@@ -131,12 +173,44 @@ func RenderHUD(r *Renderer, session *GameSession, peds *PedestrianSystem, snake
 		}
 
 	case StateLevelComplete:
+		if session.Mode == ModeVersus {
+			msg1 := "DRAW!"
+			if session.Winner > 0 {
+				msg1 = fmt.Sprintf("PLAYER %d WINS!", session.Winner)
+			}
+			r.DrawString(msg1, fbW/2-TextWidth(msg1, 1.5)/2, fbH/2-80, 1.5, green)
+
+			msg2 := ""
+			for i, sn := range snakes {
+				msg2 += fmt.Sprintf("P%d: %d   ", i+1, sn.Score)
+			}
+			r.DrawString(msg2, fbW/2-TextWidth(msg2, 0.9)/2, fbH/2-20, 0.9, yellow)
+
+			next := "Press SPACE for a rematch"
+			r.DrawString(next, fbW/2-TextWidth(next, 0.75)/2, fbH/2+40, 0.75, white)
+			break
+		}
+		if session.Mode == ModeScoreAttack {
+			msg1 := "TIME UP!"
+			r.DrawString(msg1, fbW/2-TextWidth(msg1, 1.5)/2, fbH/2-80, 1.5, green)
+
+			msg2 := fmt.Sprintf("Score: %d", session.Score)
+			r.DrawString(msg2, fbW/2-TextWidth(msg2, 0.9)/2, fbH/2-20, 0.9, yellow)
+
+			next := "Press SPACE to play again"
+			r.DrawString(next, fbW/2-TextWidth(next, 0.75)/2, fbH/2+40, 0.75, white)
+			break
+		}
+
 		msg1 := "LEVEL COMPLETE!"
 		r.DrawString(msg1, fbW/2-TextWidth(msg1, 1.5)/2, fbH/2-80, 1.5, green)
 
-		msg2 := fmt.Sprintf("Level %d — Score: %d   Time: %.1fs", session.CurrentLevel, session.Score, session.LevelTimer)
+		msg2 := fmt.Sprintf("Level %d — Score: %d   Time: %.1fs", session.CurrentLevel, session.Score, session.Elapsed)
 		r.DrawString(msg2, fbW/2-TextWidth(msg2, 0.75)/2, fbH/2-20, 0.75, white)
 
+		run := fmt.Sprintf("Run Score: %d", session.RunScore)
+		r.DrawString(run, fbW/2-TextWidth(run, 0.75)/2, fbH/2+10, 0.75, yellow)
+
 		next := "Press SPACE for next level"
 		r.DrawString(next, fbW/2-TextWidth(next, 0.75)/2, fbH/2+40, 0.75, white)
 
@@ -144,7 +218,7 @@ func RenderHUD(r *Renderer, session *GameSession, peds *PedestrianSystem, snake
 		msg1 := "GAME OVER"
 		r.DrawString(msg1, fbW/2-TextWidth(msg1, 2.0)/2, fbH/2-60, 2.0, red)
 
-		msg2 := fmt.Sprintf("Final Score: %d", session.Score)
+		msg2 := fmt.Sprintf("Final Score: %d", session.RunScore+session.Score)
 		r.DrawString(msg2, fbW/2-TextWidth(msg2, 0.9)/2, fbH/2, 0.9, yellow)
 
 		msg3 := "Press SPACE to retry"
@@ -154,14 +228,15 @@ func RenderHUD(r *Renderer, session *GameSession, peds *PedestrianSystem, snake
 	r.FlushText(fbW, fbH)
 }
 
-// repeatChar returns a string of n copies of ch.
-func repeatChar(ch byte, n int) string {
-	if n <= 0 {
-		return ""
-	}
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = ch
+// RenderMinimap draws the minimap in the top-right corner with the camera's
+// view outlined on it. Call it only when the world does not fit on screen.
+func RenderMinimap(r *Renderer, m *Minimap, cam Camera, obj *Objective, snakes []*Snake, buf []float32, fbW, fbH int) []float32 {
+	px := float32(max(2, fbH/300))
+	w, _ := m.Size(px)
+	buf = m.Sprites(buf[:0], float32(fbW)-w-12, 40, px, cam.View(fbW, fbH), obj, snakes)
+	if len(buf) > 0 {
+		r.SetSpriteAmbient(1.0, 1.0, 1.0, 1.0)
+		r.DrawSprites(buf, ScreenCamera(fbW, fbH), fbW, fbH, false)
 	}
-	return string(b)
+	return buf
 }
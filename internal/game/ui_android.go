@@ -211,21 +211,10 @@ func mobileUISp(px int) int {
 	return int(float32(px)*mobileTextScaleBoost + 0.5)
 }
 
-func repeatChar(ch byte, n int) string {
-	if n <= 0 {
-		return ""
-	}
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = ch
-	}
-	return string(b)
-}
-
 func (g *mobileGame) renderHUDMobile(glctx gl.Context, fbW, fbH int) {
-	session := g.session
-	peds := g.peds
-	snake := g.snake
+	session := g.sim.Session
+	peds := g.sim.Peds
+	snake := g.sim.Snake
 	white := RGB{R: 255, G: 255, B: 255}
 	green := RGB{R: 100, G: 255, B: 100}
 	red := RGB{R: 255, G: 80, B: 80}
@@ -260,6 +249,26 @@ func (g *mobileGame) renderHUDMobile(glctx gl.Context, fbW, fbH int) {
 		g.drawStringMobile(msg, fbW/2-TextWidth(msg, msgScale)/2, msgY, msgScale, white)
 		g.drawStringMobile(hint, fbW/2-TextWidth(hint, hintScale)/2, hintY, hintScale, yellow)
 
+		// Top eighth: mode; second eighth: start level (see handleTouch).
+		lineScale := float32(0.75)
+		mode := fmt.Sprintf("MODE: %s (TAP)", session.Mode)
+		g.drawStringMobile(mode, fbW/2-TextWidth(mode, lineScale)/2, fbH/16-TextHeight(mode, lineScale)/2, lineScale, white)
+		summaryY := fbH/16 + TextHeight(mode, lineScale)
+		if session.Mode == ModeCampaign && session.MaxStartLevel > 1 {
+			lvl := fmt.Sprintf("START AT LEVEL %d (TAP)", session.MenuLevel)
+			g.drawStringMobile(lvl, fbW/2-TextWidth(lvl, lineScale)/2, fbH*3/16-TextHeight(lvl, lineScale)/2, lineScale, white)
+			summaryY = fbH*3/16 + TextHeight(lvl, lineScale)
+		}
+		if line := g.profile.MenuSummary(session); line != "" {
+			g.drawStringMobile(line, fbW/2-TextWidth(line, 0.6)/2, summaryY, 0.6, yellow)
+		}
+		if session.CanContinue {
+			cont := "CONTINUE"
+			contScale := float32(1.0)
+			contY := fbH*7/8 - TextHeight(cont, contScale)/2
+			g.drawStringMobile(cont, fbW/2-TextWidth(cont, contScale)/2, contY, contScale, green)
+		}
+
 	case StatePlaying:
 		hudMul := float32(1.30)
 		hs := func(v float32) float32 { return v * hudMul }
@@ -269,10 +278,11 @@ func (g *mobileGame) renderHUDMobile(glctx gl.Context, fbW, fbH int) {
 		g.drawStringMobile(scoreStr, topPad, topPad, s, white)
 		timeStr := fmt.Sprintf("%.1fs", session.LevelTimer)
 		g.drawStringMobile(timeStr, fbW/2-TextWidth(timeStr, s)/2, topPad, s, white)
-		if peds != nil {
-			remaining := peds.AliveCount()
-			pedStr := fmt.Sprintf("Humans: %d", remaining)
-			g.drawStringMobile(pedStr, fbW-TextWidth(pedStr, s)-topPad, topPad, s, green)
+		objStr := session.ObjectiveLabel(peds, snake)
+		g.drawStringMobile(objStr, fbW-TextWidth(objStr, s)-topPad, topPad, s, green)
+		if peds != nil && infectedAliveCount(peds) > 0 {
+			meter := outbreakMeter(peds.Outbreak())
+			g.drawStringMobile(meter, fbW-TextWidth(meter, hs(0.65))-topPad, topPad+TextHeight(objStr, s)+mobileUISp(6), hs(0.65), sickTint)
 		}
 		if snake != nil {
 			barScale := hs(0.85)
@@ -346,8 +356,13 @@ func (g *mobileGame) renderHUDMobile(glctx gl.Context, fbW, fbH int) {
 
 	case StateLevelComplete:
 		msg1 := "LEVEL COMPLETE!"
-		msg2 := fmt.Sprintf("Level %d - Score: %d   Time: %.1fs", session.CurrentLevel, session.Score, session.LevelTimer)
+		msg2 := fmt.Sprintf("Level %d - Score: %d   Run: %d   Time: %.1fs", session.CurrentLevel, session.Score, session.RunScore, session.Elapsed)
 		next := "Tap for next level"
+		if session.Mode == ModeScoreAttack {
+			msg1 = "TIME UP!"
+			msg2 = fmt.Sprintf("Score: %d", session.Score)
+			next = "Tap to play again"
+		}
 		s1 := float32(1.5)
 		s2 := float32(0.75)
 		s3 := float32(0.75)
@@ -366,7 +381,7 @@ func (g *mobileGame) renderHUDMobile(glctx gl.Context, fbW, fbH int) {
 
 	case StateLevelFailed:
 		msg1 := "GAME OVER"
-		msg2 := fmt.Sprintf("Final Score: %d", session.Score)
+		msg2 := fmt.Sprintf("Final Score: %d", session.RunScore+session.Score)
 		msg3 := "Tap to retry"
 		s1 := float32(2.0)
 		s2 := float32(0.9)
@@ -0,0 +1,147 @@
+package game
+
+import "math"
+
+// MaxPlayers is how many snakes can share one world in versus.
+const MaxPlayers = 2
+
+const (
+	VersusDuration = 180.0
+	versusLevel    = 4
+
+	// A head running into a rival's body bites it.
+	versusBodyRadius   = 1.6
+	versusBiteDamage   = 1.5
+	versusBiteLength   = 2.0
+	versusBiteScore    = 150
+	versusBiteCooldown = 0.4 // bounce hold, also stops one contact biting every tick
+)
+
+// PlayerInput is one extra player's steering for a tick. Player one uses the
+// fields of InputFrame itself.
+type PlayerInput struct {
+	Steer float64
+	Idle  bool
+}
+
+// versusLevelConfig is the versus arena. Target abilities wait for a pointer
+// click that only player one has, so they never spawn.
+func versusLevelConfig() LevelConfig {
+	cfg := LevelConfig{
+		Peds:         120,
+		Cars:         16,
+		ArmedPeds:    8,
+		InfectedPeds: 10,
+		BonusBoxes:   5,
+		Theme:        ThemeCity,
+		WantedMax:    WantedMax,
+		BonusWeights: make([]int, BonusKindCount),
+	}
+	for k := range cfg.BonusWeights {
+		if BonusKind(k) < BonusTargetNuke {
+			cfg.BonusWeights[k] = 1
+		}
+	}
+	return cfg
+}
+
+// spawnRivals adds the other versus players two blocks east of player one,
+// facing the opposite way.
+func (sim *Simulation) spawnRivals(level int) {
+	hx, hy := sim.Snake.Head()
+	for p := 1; p < MaxPlayers; p++ {
+		x := clampF(hx+float64(2*p*Pattern), RoadWidth, float64(sim.World.Width-RoadWidth))
+		rival := NewSnake(x, hy, LevelSpeed(level))
+		rival.Heading = math.Pi
+		rival.Player = p
+		rival.Events = sim.Events
+		sim.Snakes = append(sim.Snakes, rival)
+	}
+}
+
+// wantedTarget is the snake cops and military chase: the living one with the
+// highest wanted level, player one on ties.
+func (sim *Simulation) wantedTarget() *Snake {
+	target := sim.Snake
+	for _, s := range sim.Snakes {
+		if !s.Alive {
+			continue
+		}
+		if target == nil || !target.Alive || s.WantedLevel > target.WantedLevel {
+			target = s
+		}
+	}
+	return target
+}
+
+// resolveSnakeCollisions lets a snake bite a rival by running its head into
+// the rival's body: the rival takes damage and loses length, the biter scores
+// and bounces off. Heads meeting bite each other.
+func resolveSnakeCollisions(snakes []*Snake, particles *ParticleSystem) {
+	for _, a := range snakes {
+		if !a.Alive || a.BounceTimer > 0 || len(a.Ghosts) > 0 {
+			continue
+		}
+		hx, hy := a.Head()
+		for _, b := range snakes {
+			if b == a || !b.Alive || len(b.Ghosts) > 0 {
+				continue
+			}
+			reach := versusBodyRadius * math.Max(b.SizeMult, 1)
+			for _, seg := range b.Segments() {
+				dx, dy := seg.X-hx, seg.Y-hy
+				if dx*dx+dy*dy > reach*reach {
+					continue
+				}
+				b.HP.Damage(versusBiteDamage)
+				b.Length = math.Max(b.Length-versusBiteLength, 0)
+				a.Score += versusBiteScore
+				a.BounceDir = math.Atan2(-dy, -dx)
+				a.BounceTimer = versusBiteCooldown
+				particles.SpawnBlood(seg.X, seg.Y, dx, dy, 10, 1.0)
+				a.Events.Emit(Event{Type: EventSnakeCollision, X: seg.X, Y: seg.Y})
+				break
+			}
+			if a.BounceTimer > 0 {
+				break
+			}
+		}
+	}
+}
+
+// CheckVersusEnd ends a versus match when at most one snake is left or the
+// clock runs out, in which case the higher score wins.
+func (s *GameSession) CheckVersusEnd(snakes []*Snake) {
+	if s.State != StatePlaying {
+		return
+	}
+	alive, last := 0, 0
+	s.Score = 0
+	for i, sn := range snakes {
+		s.Scores[i] = sn.Score
+		s.Score = max(s.Score, sn.Score)
+		if sn.Alive {
+			alive++
+			last = i + 1
+		}
+	}
+
+	switch {
+	case alive <= 1:
+		s.Winner = last // 0 when both went down on the same tick
+	case s.Elapsed >= VersusDuration:
+		s.Winner = 0
+		best := -1
+		for i, sc := range s.Scores[:len(snakes)] {
+			if sc > best {
+				best, s.Winner = sc, i+1
+			} else if sc == best {
+				s.Winner = 0
+			}
+		}
+	default:
+		return
+	}
+	s.State = StateLevelComplete
+	s.Events.Emit(Event{Type: EventLevelComplete, Data: s.CurrentLevel})
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// laidSnake is a snake with its head at x,y and its body stretched out
+// straight behind it.
+func laidSnake(x, y, heading float64) *Snake {
+	s := NewSnake(x, y, SnakeBaseSpeed)
+	s.Heading = heading
+	for d := 0.5; d <= s.Length+1; d += 0.5 {
+		s.Path = append(s.Path, PathPoint{X: x - math.Cos(heading)*d, Y: y - math.Sin(heading)*d})
+	}
+	return s
+}
+
+func TestResolveSnakeCollisions(t *testing.T) {
+	east, west, north := 0.0, math.Pi, -math.Pi/2
+	for _, tc := range []struct {
+		name  string
+		a, b  *Snake
+		setup func(a, b *Snake)
+		bites [2]bool // a bit b, b bit a
+	}{
+		{"head into body", laidSnake(50, 50, east), laidSnake(51, 44, north), nil, [2]bool{true, false}},
+		{"head on", laidSnake(50, 50, east), laidSnake(51, 50, west), nil, [2]bool{true, true}},
+		{"out of reach", laidSnake(50, 50, east), laidSnake(50+versusBodyRadius+0.2, 44, north), nil, [2]bool{false, false}},
+		{"bigger body reaches further", laidSnake(50, 50, east), laidSnake(52.5, 44, north), func(a, b *Snake) {
+			b.SizeMult = 2
+		}, [2]bool{true, false}},
+		{"still bouncing", laidSnake(50, 50, east), laidSnake(51, 44, north), func(a, b *Snake) {
+			a.BounceTimer = 0.1
+		}, [2]bool{false, false}},
+		{"dead rival", laidSnake(50, 50, east), laidSnake(51, 44, north), func(a, b *Snake) {
+			b.Alive = false
+		}, [2]bool{false, false}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := tc.a, tc.b
+			if tc.setup != nil {
+				tc.setup(a, b)
+			}
+			lenA, lenB := a.Length, b.Length
+			resolveSnakeCollisions([]*Snake{a, b}, NewParticleSystem(64, 1))
+			for i, s := range []struct {
+				biter, bitten *Snake
+				length        float64
+			}{{a, b, lenB}, {b, a, lenA}} {
+				bit := s.biter.Score == versusBiteScore
+				if bit != tc.bites[i] {
+					t.Fatalf("snake %d bit %v, want %v", i+1, bit, tc.bites[i])
+				}
+				if !bit {
+					continue
+				}
+				if s.bitten.HP.Current != s.bitten.HP.Max-versusBiteDamage || s.bitten.Length != s.length-versusBiteLength {
+					t.Errorf("bitten snake at hp %v length %v", s.bitten.HP.Current, s.bitten.Length)
+				}
+				if s.biter.BounceTimer != versusBiteCooldown {
+					t.Errorf("biter bounce %v, want %v", s.biter.BounceTimer, versusBiteCooldown)
+				}
+			}
+		})
+	}
+	// The bite bounces the biter back the way it came.
+	a, b := laidSnake(50, 50, east), laidSnake(51, 44, north)
+	resolveSnakeCollisions([]*Snake{a, b}, NewParticleSystem(64, 1))
+	if math.Cos(a.BounceDir) >= 0 {
+		t.Errorf("bounced off at %.2f rad, into the body", a.BounceDir)
+	}
+}
+
+func TestCheckVersusEnd(t *testing.T) {
+	type player struct {
+		alive bool
+		score int
+	}
+	for _, tc := range []struct {
+		name    string
+		players [2]player
+		elapsed float64
+		over    bool
+		winner  int
+	}{
+		{"under way", [2]player{{true, 300}, {true, 100}}, 60, false, 0},
+		{"player two down", [2]player{{true, 100}, {false, 300}}, 60, true, 1},
+		{"player one down", [2]player{{false, 300}, {true, 100}}, 60, true, 2},
+		{"both down at once", [2]player{{false, 300}, {false, 100}}, 60, true, 0},
+		{"time up, one ahead", [2]player{{true, 300}, {true, 100}}, VersusDuration, true, 1},
+		{"time up, two ahead", [2]player{{true, 100}, {true, 450}}, VersusDuration, true, 2},
+		{"time up, level", [2]player{{true, 200}, {true, 200}}, VersusDuration, true, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewGameSession()
+			s.Mode, s.State, s.Elapsed = ModeVersus, StatePlaying, tc.elapsed
+			var snakes []*Snake
+			for _, p := range tc.players {
+				sn := NewSnake(0, 0, SnakeBaseSpeed)
+				sn.Alive, sn.Score = p.alive, p.score
+				snakes = append(snakes, sn)
+			}
+			s.CheckVersusEnd(snakes)
+			if over := s.State == StateLevelComplete; over != tc.over {
+				t.Fatalf("match over %v, want %v", over, tc.over)
+			}
+			if tc.over && s.Winner != tc.winner {
+				t.Errorf("winner %d, want %d", s.Winner, tc.winner)
+			}
+			if want := max(tc.players[0].score, tc.players[1].score); s.Score != want || s.Scores != [MaxPlayers]int{tc.players[0].score, tc.players[1].score} {
+				t.Errorf("score %d scores %v", s.Score, s.Scores)
+			}
+		})
+	}
+}
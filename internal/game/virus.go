@@ -1,9 +1,180 @@
 package game
 
-// InfectionState marks whether a pedestrian is visually infected (won't spread, just distinct & bad to eat).
+import (
+	"fmt"
+	"math"
+)
+
+// Infection. Infected humans spread the virus to those near them. A caught
+// infection incubates unseen, then shows as green and sick; the sick either
+// recover, immune, or turn into zombies that hunt the snake. Sick humans and
+// zombies are bad to eat.
+
+// InfectionState is where a pedestrian is in the course of the infection.
 type InfectionState int
 
 const (
 	StateHealthy     InfectionState = iota
-	StateSymptomatic                // green tint — dangerous to eat (shrinks snake)
+	StateSymptomatic                // green tint — dangerous to eat (shrinks snake), spreads it
+	StateIncubating                 // caught it; looks healthy until symptoms show
+	StateZombie                     // hunts and bites the snake, spreads it
+	StateImmune                     // recovered or resistant; never catches it
 )
+
+const (
+	infectRadius     = 3.0  // how close the sick must come to pass it on
+	infectRate       = 0.35 // catches per second from one sick neighbour
+	zombieInfectRate = 0.8  // from a zombie, which bites
+	immuneChance     = 0.15 // share of those exposed who resist it
+	zombieChance     = 0.7  // share of the sick who turn rather than recover
+	zombieSpeed      = 1.15 // times the walking speed
+	zombieSight      = 45.0 // how far off a zombie notices a snake
+	zombieBiteRange  = 2.2
+	zombieBiteDamage = 0.35
+	zombieBiteEvery  = 1.2 // seconds
+)
+
+var zombieTint = RGB{R: 112, G: 140, B: 96} // grey-green rot
+
+// Sick reports whether the state shows: sick or zombie, and bad to eat.
+func (s InfectionState) Sick() bool {
+	return s == StateSymptomatic || s == StateZombie
+}
+
+// Infected reports whether the state carries the virus.
+func (s InfectionState) Infected() bool {
+	return s.Sick() || s == StateIncubating
+}
+
+// incubation and sickness are how long the stages last, varied per ped.
+func incubation(r *Rand) float64 { return r.RangeF(10, 16) }
+func sickness(r *Rand) float64   { return r.RangeF(18, 28) }
+
+// spreadInfection advances every pedestrian's infection by dt and lets the
+// sick infect those near them. The spatial buckets must be current.
+func (ps *PedestrianSystem) spreadInfection(dt float64) {
+	cols, rows := ps.bucketCols, ps.bucketRows
+	for i := range ps.P {
+		p := &ps.P[i]
+		if !p.Alive || !p.Infection.Infected() {
+			continue
+		}
+		r := NewRand(ps.seed ^ uint64(i+1)*0x51C4 ^ math.Float64bits(p.InfectionTimer))
+		p.InfectionTimer -= dt
+		if p.InfectionTimer <= 0 {
+			switch p.Infection {
+			case StateIncubating:
+				p.Infection = StateSymptomatic
+				p.InfectionTimer = sickness(r)
+				p.Col = lerpRGB(p.OrigCol, sickTint, 0.85)
+			case StateSymptomatic:
+				if r.Float64() < zombieChance {
+					p.turnZombie()
+				} else {
+					p.Infection = StateImmune
+					p.Col = p.OrigCol
+				}
+			}
+		}
+		if !p.Infection.Sick() {
+			continue
+		}
+
+		rate := infectRate
+		if p.Infection == StateZombie {
+			rate = zombieInfectRate
+		}
+		bx := clamp(int(p.X)/ps.bucketSize, 0, cols-1)
+		by := clamp(int(p.Y)/ps.bucketSize, 0, rows-1)
+		for yy := max(by-1, 0); yy <= min(by+1, rows-1); yy++ {
+			for xx := max(bx-1, 0); xx <= min(bx+1, cols-1); xx++ {
+				for _, j := range ps.buckets[yy*cols+xx] {
+					o := &ps.P[j]
+					if !o.Alive || o.Infection != StateHealthy ||
+						math.Hypot(o.X-p.X, o.Y-p.Y) > infectRadius || r.Float64() >= rate*dt {
+						continue
+					}
+					if r.Float64() < immuneChance {
+						o.Infection = StateImmune
+						continue
+					}
+					o.Infection = StateIncubating
+					o.InfectionTimer = incubation(r)
+				}
+			}
+		}
+	}
+}
+
+// turnZombie makes a sick pedestrian a zombie for good.
+func (p *Pedestrian) turnZombie() {
+	p.Infection = StateZombie
+	p.InfectionTimer = 0
+	p.Col = lerpRGB(p.OrigCol, zombieTint, 0.9)
+	p.Speed = p.BaseSpeed * zombieSpeed
+	p.Armed = false
+	p.Hiding = false
+	p.Crossing = false
+	p.Waiting = false
+	p.Panic = 0
+}
+
+// hunt steers a zombie at the snake when it sees one and bites it within
+// reach. It reports whether the zombie has a snake to chase.
+func (p *Pedestrian) hunt(dt float64, snake *Snake, hx, hy float64) bool {
+	p.ShootCooldown -= dt
+	if snake == nil || !snake.Alive {
+		return false
+	}
+	d := math.Hypot(hx-p.X, hy-p.Y)
+	if d > zombieSight {
+		return false
+	}
+	p.TargetX, p.TargetY = hx, hy
+	if d < zombieBiteRange && p.ShootCooldown <= 0 {
+		p.ShootCooldown = zombieBiteEvery
+		if len(snake.Ghosts) == 0 {
+			snake.HP.Damage(zombieBiteDamage)
+		}
+	}
+	return true
+}
+
+// zombieWalkable is where a zombie goes: anywhere open and dry, roads too.
+func zombieWalkable(w *World, wx, wy int) bool {
+	return w.InBounds(wx, wy) && w.HeightAt(wx, wy) == 0 && !w.IsWater(wx, wy)
+}
+
+// Outbreak is the share of the living pedestrians carrying the virus, 0..1.
+func (ps *PedestrianSystem) Outbreak() float64 {
+	alive, infected := 0, 0
+	for i := range ps.P {
+		if p := &ps.P[i]; p.Alive {
+			alive++
+			if p.Infection.Infected() {
+				infected++
+			}
+		}
+	}
+	if alive == 0 {
+		return 0
+	}
+	return float64(infected) / float64(alive)
+}
+
+// outbreakMeter is the HUD's infection meter for an outbreak share.
+func outbreakMeter(share float64) string {
+	const cells = 10
+	return fmt.Sprintf("OUTBREAK [%-*s] %2.0f%%", cells, repeatChar('#', int(math.Ceil(share*cells))), share*100)
+}
+
+// infectedAliveCount is how many living pedestrians carry the virus.
+func infectedAliveCount(peds *PedestrianSystem) int {
+	n := 0
+	for i := range peds.P {
+		if peds.P[i].Alive && peds.P[i].Infection.Infected() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+package game
+
+import "testing"
+
+// crowd is a pedestrian system holding peds, bucketed as Update does
+// before it spreads anything.
+func crowd(peds ...Pedestrian) *PedestrianSystem {
+	ps := NewPedestrianSystem(len(peds), 9)
+	ps.P = append(ps.P, peds...)
+	for j, p := range ps.P {
+		bx := clamp(int(p.X)/ps.bucketSize, 0, ps.bucketCols-1)
+		by := clamp(int(p.Y)/ps.bucketSize, 0, ps.bucketRows-1)
+		ps.buckets[by*ps.bucketCols+bx] = append(ps.buckets[by*ps.bucketCols+bx], j)
+	}
+	return ps
+}
+
+func TestSpreadInfection(t *testing.T) {
+	ped := func(x, y float64, s InfectionState, timer float64) Pedestrian {
+		return Pedestrian{X: x, Y: y, Alive: true, Infection: s, InfectionTimer: timer}
+	}
+	caught := func(s InfectionState) bool { return s.Infected() || s == StateImmune }
+	for _, tc := range []struct {
+		name    string
+		peds    []Pedestrian
+		seconds float64
+		want    []func(InfectionState) bool
+	}{
+		{"sick infects a neighbour", []Pedestrian{ped(50, 50, StateSymptomatic, 100), ped(51, 51, StateHealthy, 0)}, 20,
+			[]func(InfectionState) bool{InfectionState.Sick, caught}},
+		{"zombie bites across buckets", []Pedestrian{ped(47.5, 50, StateZombie, 0), ped(48.5, 50, StateHealthy, 0)}, 20,
+			[]func(InfectionState) bool{is(StateZombie), caught}},
+		{"out of reach", []Pedestrian{ped(50, 50, StateZombie, 0), ped(50+infectRadius+0.5, 50, StateHealthy, 0)}, 20,
+			[]func(InfectionState) bool{is(StateZombie), is(StateHealthy)}},
+		{"incubating does not spread", []Pedestrian{ped(50, 50, StateIncubating, 100), ped(50.5, 50, StateHealthy, 0)}, 20,
+			[]func(InfectionState) bool{is(StateIncubating), is(StateHealthy)}},
+		{"immune stay immune", []Pedestrian{ped(50, 50, StateZombie, 0), ped(50.5, 50, StateImmune, 0)}, 20,
+			[]func(InfectionState) bool{is(StateZombie), is(StateImmune)}},
+		{"the dead catch nothing", []Pedestrian{ped(50, 50, StateZombie, 0), {X: 50.5, Y: 50, Infection: StateHealthy}}, 20,
+			[]func(InfectionState) bool{is(StateZombie), is(StateHealthy)}},
+		{"symptoms show", []Pedestrian{ped(50, 50, StateIncubating, 0.5)}, 1,
+			[]func(InfectionState) bool{is(StateSymptomatic)}},
+		{"the sick turn or recover", []Pedestrian{ped(50, 50, StateSymptomatic, 0.5), ped(90, 90, StateSymptomatic, 0.5)}, 1,
+			[]func(InfectionState) bool{turnedOrRecovered, turnedOrRecovered}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := crowd(tc.peds...)
+			for range int(tc.seconds / SimTickDT) {
+				ps.spreadInfection(SimTickDT)
+			}
+			for i, ok := range tc.want {
+				if !ok(ps.P[i].Infection) {
+					t.Errorf("ped %d ended in state %d", i, ps.P[i].Infection)
+				}
+			}
+		})
+	}
+}
+
+func is(want InfectionState) func(InfectionState) bool {
+	return func(s InfectionState) bool { return s == want }
+}
+
+func turnedOrRecovered(s InfectionState) bool { return s == StateZombie || s == StateImmune }
+
+// TestInfectionOutcomes spreads the virus from zombies through a packed
+// crowd and checks roughly immuneChance of those exposed resist it.
+func TestInfectionOutcomes(t *testing.T) {
+	var peds []Pedestrian
+	for k := range 400 {
+		x, y := 20+float64(k%20)*4, 20+float64(k/20)*4
+		peds = append(peds, Pedestrian{X: x + 1, Y: y, Alive: true, Infection: StateZombie},
+			Pedestrian{X: x, Y: y, Alive: true})
+	}
+	ps := crowd(peds...)
+	for range int(20 / SimTickDT) {
+		ps.spreadInfection(SimTickDT)
+	}
+	immune, caught := 0, 0
+	for _, p := range ps.P {
+		switch p.Infection {
+		case StateImmune:
+			immune++
+		case StateIncubating, StateSymptomatic:
+			caught++
+		}
+	}
+	if caught+immune != 400 {
+		t.Fatalf("%d of 400 exposed caught it or resisted", caught+immune)
+	}
+	if share := float64(immune) / 400; share < immuneChance/2 || share > immuneChance*2 {
+		t.Errorf("%.2f resisted, want about %.2f", share, immuneChance)
+	}
+	if got := ps.Outbreak(); got < 0.5 {
+		t.Errorf("outbreak %.2f with every zombie alive", got)
+	}
+}
@@ -0,0 +1,270 @@
+package game
+
+import "math"
+
+// Rivers, lakes and the sea. They are laid out once per world from its seed
+// and theme (see ThemeConfig.Rivers, Lakes and Coast), like the road
+// network. Each body of water is a field over world pixels: positive inside
+// it and roughly the distance to its shore. A chunk can therefore paint its
+// share without looking at its neighbours. generateChunk lays the water over
+// the city blocks, then draws the streets across it as bridges.
+
+// Water sizes in world pixels.
+const (
+	riverHalfWidthMin = 4.0
+	riverHalfWidthMax = 7.0
+	lakeRadiusMin     = 8.0
+	lakeRadiusMax     = 18.0
+	lakeWobble        = 1.24 // widest a lake reaches, as a multiple of its radius
+	shoreWidth        = 2.0  // bank around rivers and lakes
+	beachWidth        = 14.0 // sand between the sea and the town
+	shallowDepth      = 1.5
+	deepDepth         = 6.0
+)
+
+type river struct {
+	vertical  bool    // flows along y
+	course    float64 // mean position across the flow
+	halfWidth float64
+	amp       [2]float64 // two meanders on top of the course
+	freq      [2]float64
+	phase     [2]float64
+}
+
+func (r *river) depth(x, y float64) float64 {
+	along, across := x, y
+	if r.vertical {
+		along, across = y, x
+	}
+	c := r.course
+	for k := range r.amp {
+		c += r.amp[k] * math.Sin(along*r.freq[k]+r.phase[k])
+	}
+	return r.halfWidth - math.Abs(across-c)
+}
+
+type lake struct {
+	x, y, radius float64
+	lobes        [2]float64 // phases of the three- and five-lobed wobble
+}
+
+func (l *lake) depth(x, y float64) float64 {
+	dx, dy := x-l.x, y-l.y
+	d := math.Hypot(dx, dy)
+	if reach := l.radius * lakeWobble; d > reach+beachWidth {
+		return reach - d
+	}
+	th := math.Atan2(dy, dx)
+	return l.radius*(1+0.16*math.Sin(3*th+l.lobes[0])+0.08*math.Sin(5*th+l.lobes[1])) - d
+}
+
+// coast is the sea along one side of the world.
+type coast struct {
+	side  int     // 0 top, 1 right, 2 bottom, 3 left
+	reach float64 // mean distance of the shoreline from that side
+	amp   [2]float64
+	freq  [2]float64
+	phase [2]float64
+}
+
+func (c *coast) depth(x, y float64, worldW, worldH int) float64 {
+	var along, in float64
+	switch c.side {
+	case 0:
+		along, in = x, y
+	case 1:
+		along, in = y, float64(worldW)-x
+	case 2:
+		along, in = x, float64(worldH)-y
+	default:
+		along, in = y, x
+	}
+	line := c.reach
+	for k := range c.amp {
+		line += c.amp[k] * math.Sin(along*c.freq[k]+c.phase[k])
+	}
+	return line - in
+}
+
+// waterLayout is the water of one world.
+type waterLayout struct {
+	worldW, worldH int
+	rivers         []river
+	lakes          []lake
+	coast          *coast
+}
+
+func newWaterLayout(worldSeed uint64, theme ThemeConfig, worldW, worldH int) *waterLayout {
+	wl := &waterLayout{worldW: worldW, worldH: worldH}
+	r := NewRand(worldSeed ^ 0x5EA51DE57A7E0001)
+	meander := func(span float64) (amp, freq, phase [2]float64) {
+		amp = [2]float64{r.RangeF(0.03, 0.08) * span, r.RangeF(4, 9)}
+		freq = [2]float64{2 * math.Pi / r.RangeF(150, 320), 2 * math.Pi / r.RangeF(40, 90)}
+		phase = [2]float64{r.RangeF(0, 2*math.Pi), r.RangeF(0, 2*math.Pi)}
+		return
+	}
+
+	if theme.Coast {
+		c := &coast{side: r.Intn(4)}
+		span, length := float64(worldH), float64(worldW)
+		if c.side == 1 || c.side == 3 {
+			span, length = length, span
+		}
+		c.reach = max(span*r.RangeF(0.14, 0.2), 28)
+		c.amp, c.freq, c.phase = meander(length)
+		c.amp[0] = min(c.amp[0], c.reach/3)
+		wl.coast = c
+	}
+
+	for range r.Range(theme.Rivers[0], theme.Rivers[1]) {
+		rv := river{vertical: r.Intn(2) == 0, halfWidth: r.RangeF(riverHalfWidthMin, riverHalfWidthMax)}
+		span, length := float64(worldH), float64(worldW)
+		if rv.vertical {
+			span, length = length, span
+		}
+		rv.course = span * r.RangeF(0.3, 0.7)
+		rv.amp, rv.freq, rv.phase = meander(length)
+		wl.rivers = append(wl.rivers, rv)
+	}
+
+	// Lake counts are per default-sized world, so bigger worlds get more.
+	area := worldW * worldH
+	defArea := DefaultWorldWidth * DefaultWorldHeight
+	n := (r.Range(theme.Lakes[0], theme.Lakes[1])*area + defArea/2) / defArea
+	margin := float64(BorderThickness + RoadWidth + 4)
+	for range n {
+		l := lake{radius: r.RangeF(lakeRadiusMin, lakeRadiusMax)}
+		reach := l.radius*lakeWobble + margin
+		l.x = r.RangeF(reach, float64(worldW)-reach)
+		l.y = r.RangeF(reach, float64(worldH)-reach)
+		l.lobes = [2]float64{r.RangeF(0, 2*math.Pi), r.RangeF(0, 2*math.Pi)}
+		wl.lakes = append(wl.lakes, l)
+	}
+	return wl
+}
+
+func (wl *waterLayout) empty() bool {
+	return wl == nil || (len(wl.rivers) == 0 && len(wl.lakes) == 0 && wl.coast == nil)
+}
+
+// at is how deep the pixel wx, wy lies in water, about its distance from
+// the shore, and whether it is dry land on a shore, bank or beach.
+func (wl *waterLayout) at(wx, wy int) (depth float64, shore bool) {
+	x, y := float64(wx)+0.5, float64(wy)+0.5
+	depth = math.Inf(-1)
+	if wl.coast != nil {
+		depth = wl.coast.depth(x, y, wl.worldW, wl.worldH)
+		shore = depth > -beachWidth
+	}
+	for k := range wl.rivers {
+		d := wl.rivers[k].depth(x, y)
+		depth = max(depth, d)
+		shore = shore || d > -shoreWidth
+	}
+	for k := range wl.lakes {
+		d := wl.lakes[k].depth(x, y)
+		depth = max(depth, d)
+		shore = shore || d > -shoreWidth
+	}
+	return depth, shore && depth <= 0
+}
+
+// near is the water with only the lakes whose shores reach r; big worlds
+// have hundreds.
+func (wl *waterLayout) near(r rectI) *waterLayout {
+	local := *wl
+	local.lakes = nil
+	for _, l := range wl.lakes {
+		reach := l.radius*lakeWobble + shoreWidth
+		if l.x+reach >= float64(r.X0) && l.x-reach < float64(r.X1) &&
+			l.y+reach >= float64(r.Y0) && l.y-reach < float64(r.Y1) {
+			local.lakes = append(local.lakes, l)
+		}
+	}
+	return &local
+}
+
+// dry reports whether neither water nor shore reaches into r.
+func (wl *waterLayout) dry(r rectI) bool {
+	if wl.empty() {
+		return true
+	}
+	local := wl.near(r)
+	for y := r.Y0; y < r.Y1; y++ {
+		for x := r.X0; x < r.X1; x++ {
+			if d, shore := local.at(x, y); d > 0 || shore {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// paint lays the water and its shores into c, inside the ring road. Shores
+// cover open ground only; water covers everything.
+func (wl *waterLayout) paint(c *Chunk, worldSeed uint64, tp themePalette) {
+	if wl.empty() {
+		return
+	}
+	baseX, baseY := c.WorldOrigin()
+	local := wl.near(rectI{X0: baseX, Y0: baseY, X1: baseX + ChunkSize, Y1: baseY + ChunkSize})
+
+	in0 := BorderThickness + RoadWidth
+	for y := 0; y < ChunkSize; y++ {
+		wy := baseY + y
+		if wy < in0 || wy >= c.worldH-in0 {
+			continue
+		}
+		for x := 0; x < ChunkSize; x++ {
+			wx := baseX + x
+			if wx < in0 || wx >= c.worldW-in0 {
+				continue
+			}
+			i := c.idx(x, y)
+			d, shore := local.at(wx, wy)
+			switch {
+			case d > 0:
+				c.setWater(i, waterColor(worldSeed, wx, wy, d, tp))
+			case shore && c.Height[i] == 0:
+				n := int(hash2D(worldSeed^0x5A4D, wx, wy)>>61) - 4
+				c.set(i, tp.Shore.Add(2*n, 2*n, n), 0, ShadeLit, 0)
+			}
+		}
+	}
+}
+
+// waterColor is the water at wx, wy, depth pixels from its shore: light
+// in the shallows, darker further out, with a rippled grain.
+func waterColor(worldSeed uint64, wx, wy int, depth float64, tp themePalette) RGB {
+	col := tp.Water
+	switch {
+	case depth < shallowDepth:
+		col = col.Add(26, 30, 22)
+	case depth > deepDepth:
+		col = col.Add(-12, -12, -6)
+	}
+	n := int(hash2D(worldSeed^0x3A7E5, wx>>1, wy) >> 61)
+	return col.Add(n, n, 2*n)
+}
+
+// bridgeSideColor is a street's sidewalk where it crosses water: planking,
+// with a railing along the side away from the road.
+func bridgeSideColor(xm, ym int, xSide, ySide bool, tp themePalette) RGB {
+	rail := func(m int) bool {
+		return m == RoadWidth+SidewalkWidth-1 || m == RoadWidth+SidewalkWidth+BlockInner
+	}
+	if (xSide && rail(xm)) || (ySide && rail(ym)) {
+		return tp.BuildingDark
+	}
+	return tp.Sidewalk.Add(-26, -28, -30)
+}
+
+// IsWater reports whether a world pixel is open water.
+func (w *World) IsWater(wx, wy int) bool {
+	if !w.InBounds(wx, wy) {
+		return false
+	}
+	cx, cy := wx/ChunkSize, wy/ChunkSize
+	c := w.GetChunk(cx, cy)
+	return c != nil && c.Water[(wy-cy*ChunkSize)*ChunkSize+wx-cx*ChunkSize] != 0
+}
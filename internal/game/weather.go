@@ -90,7 +90,32 @@ func (ws *WeatherSystem) Configure(mode WeatherType, seed uint64) {
 	ws.windX = r.RangeF(-14.0, 14.0)
 }
 
-func (ws *WeatherSystem) UpdateAndSpawn(ps *ParticleSystem, dt float64) {
+// Wind is the wind along x in pixels per second; positive blows east.
+func (ws *WeatherSystem) Wind() float64 {
+	if ws == nil {
+		return 0
+	}
+	return ws.windX
+}
+
+// Wetness is how much the weather damps fire, from 0 when dry to about 0.85
+// in the heaviest rain. Snow damps it less.
+func (ws *WeatherSystem) Wetness() float64 {
+	if ws == nil {
+		return 0
+	}
+	switch ws.mode {
+	case WeatherRain:
+		return 0.6 * ws.intensity
+	case WeatherSnow:
+		return 0.35 * ws.intensity
+	}
+	return 0
+}
+
+// UpdateAndSpawn drifts the wind and spawns drops or flakes over area, the
+// part of the world in view.
+func (ws *WeatherSystem) UpdateAndSpawn(ps *ParticleSystem, area RectF, dt float64) {
 	if ws == nil || ps == nil || dt <= 0 || ws.mode == WeatherNone {
 		return
 	}
@@ -123,8 +148,8 @@ func (ws *WeatherSystem) UpdateAndSpawn(ps *ParticleSystem, dt float64) {
 	for i := 0; i < count; i++ {
 		ws.spawnSeq++
 		r := NewRand(ws.seed ^ ws.spawnSeq*0x9E3779B185EBCA87)
-		x := r.RangeF(-10.0, float64(WorldWidth)+10.0)
-		y := r.RangeF(-10.0, float64(WorldHeight)+10.0)
+		x := r.RangeF(area.X0-10.0, area.X1+10.0)
+		y := r.RangeF(area.Y0-10.0, area.Y1+10.0)
 
 		switch ws.mode {
 		case WeatherRain:
@@ -1,47 +1,62 @@
 package game
 
-import "math"
-
-// World is a fixed-size 2D pixel world split into chunks.
+import (
+	"math"
+	"slices"
+)
+
+// World is a 2D pixel world split into chunks. Its size is set per level.
+// Chunks are generated when first used and unloaded again once idle (see
+// EvictIdleChunks).
 type World struct {
 	seed  uint64
 	Theme ThemeConfig
+	Map   *WorldMap // hand-authored terrain; nil generates it
+
+	Width, Height int // world pixels
 
+	// Last chunk column and row. Worlds are bounded (at most MaxWorldSize
+	// a side); chunks stream in and out only within these.
 	maxCx int
 	maxCy int
 
-	chunks []*Chunk
+	chunks    map[ChunkKey]*Chunk     // loaded and half-built chunks
+	roads     *roadNetwork            // solved on first generation
+	water     *waterLayout            // laid out with roads
+	graph     *roadGraph              // built from roads when cars first route
+	interiors map[int][]interior      // by city block, as looked up
+	venues    []venue                 // parks and car parks, laid out when peds first plan their day
+	deltas    map[ChunkKey]chunkDelta // edits of unloaded chunks
 
-	spatial *QuadNode
+	// Structural collapse (see collapse.go).
+	built     map[ChunkKey][]bool // per pixel, what generation built; see builtMask
+	checks    []structureCheck    // damage to test on the next Update
+	collapses []collapse          // buildings that fell in the last Update
 
-	// Temporary pixel changes (restored after TTL).
-	temp []TempPaint
+	clock     float64  // seconds of Update, stamps chunk use
+	lastEvict float64  // clock of the last eviction pass
+	freedTex  []uint32 // textures of dropped chunks, for the renderer to free
 
-	// Scheduled temporary paints: applied after Delay seconds.
-	scheduled []ScheduledPaint
+	spatial *QuadNode
 
 	// Burning trees and buildings.
 	burningTrees     map[int64]*TreeBurn
 	burningBuildings map[int64]*BuildingBurn
 
+	fire fireField // wildfire spreading over the ground
+
+	decals     map[ChunkKey]*decalLayer // kept while their chunks unload
+	decalClock float64                  // seconds towards the next fade
+
+	signalClock float64 // seconds the traffic lights have run (see signals.go)
+
 	// Dynamic sun parameters for shadows (continuous angle).
 	sunAngle float64 // radians, 0=east, -π/2=north
 	sunSlope float64 // height drop per pixel of sun-ray travel
 	sunCosA  float64 // precomputed cos(sunAngle)
 	sunSinA  float64 // precomputed sin(sunAngle)
-}
-
-type TempPaint struct {
-	X, Y int
-	Orig RGB
-	TTL  float64
-}
 
-type ScheduledPaint struct {
-	X, Y  int
-	Col   RGB
-	Delay float64
-	TTL   float64
+	Events *EventBus // receives gameplay events; may be nil
 }
 
 type TreeBurn struct {
@@ -66,18 +81,12 @@ type BuildingBurn struct {
 	pendingBurst    int
 }
 
-func NewWorld(seed uint64) *World {
+func NewWorld(seed uint64, width, height int) *World {
 	if seed == 0 {
 		seed = 1
 	}
-	maxCx := floorDiv(WorldWidth-1, ChunkSize)
-	maxCy := floorDiv(WorldHeight-1, ChunkSize)
-	count := (maxCx + 1) * (maxCy + 1)
-	return &World{
+	w := &World{
 		seed:             seed,
-		maxCx:            maxCx,
-		maxCy:            maxCy,
-		chunks:           make([]*Chunk, count),
 		burningTrees:     make(map[int64]*TreeBurn),
 		burningBuildings: make(map[int64]*BuildingBurn),
 		sunAngle:         math.Atan2(float64(SunDy), float64(SunDx)),
@@ -85,6 +94,50 @@ func NewWorld(seed uint64) *World {
 		sunCosA:          float64(SunDx),
 		sunSinA:          float64(SunDy),
 	}
+	w.Resize(width, height)
+	return w
+}
+
+// Resize sets the world size and drops every chunk, its edits, the road
+// network, the interiors and venues, pending collapses, the fire and the
+// decals; chunks regenerate from the current seed, theme and map.
+func (w *World) Resize(width, height int) {
+	for key := range w.chunks {
+		w.dropChunk(key)
+	}
+	w.Width, w.Height = width, height
+	w.maxCx = floorDiv(width-1, ChunkSize)
+	w.maxCy = floorDiv(height-1, ChunkSize)
+	w.chunks = make(map[ChunkKey]*Chunk)
+	w.roads, w.water, w.graph, w.venues = nil, nil, nil, nil
+	w.interiors = make(map[int][]interior)
+	w.deltas = make(map[ChunkKey]chunkDelta)
+	w.built = make(map[ChunkKey][]bool)
+	w.checks, w.collapses = nil, nil
+	w.fire = newFireField(width, height)
+	w.decals = make(map[ChunkKey]*decalLayer)
+	w.spatial = nil
+}
+
+// InBounds reports whether a world pixel lies inside the world.
+func (w *World) InBounds(wx, wy int) bool {
+	return wx >= 0 && wy >= 0 && wx < w.Width && wy < w.Height
+}
+
+// ViewSize is how much of the world the camera shows at its normal zoom: a
+// default-sized world, or less when the world itself is smaller.
+func (w *World) ViewSize() (float64, float64) {
+	return math.Min(DefaultWorldWidth, float64(w.Width)), math.Min(DefaultWorldHeight, float64(w.Height))
+}
+
+// ViewArea is the ViewSize area centred as close to cx, cy as the world
+// allows: what the camera shows around that point. It is the whole world
+// for worlds no larger than the default.
+func (w *World) ViewArea(cx, cy float64) RectF {
+	vw, vh := w.ViewSize()
+	x0 := clampF(cx-vw/2, 0, float64(w.Width)-vw)
+	y0 := clampF(cy-vh/2, 0, float64(w.Height)-vh)
+	return RectF{X0: x0, Y0: y0, X1: x0 + vw, Y1: y0 + vh}
 }
 
 // UpdateSun updates sun parameters and invalidates chunk shadows when the sun has
@@ -106,118 +159,47 @@ func (w *World) UpdateSun(angle, slope float64) {
 	w.sunCosA = math.Cos(angle)
 	w.sunSinA = math.Sin(angle)
 	for _, c := range w.chunks {
-		if c != nil {
-			c.NeedsShadow = true
-		}
+		c.NeedsShadow = true
 	}
 }
 
-func (w *World) chunkIndex(cx, cy int) int {
-	return cy*(w.maxCx+1) + cx
-}
-
+// GetChunk returns the chunk at cx, cy, loading it on first use, or nil
+// outside the world.
 func (w *World) GetChunk(cx, cy int) *Chunk {
 	if cx < 0 || cy < 0 || cx > w.maxCx || cy > w.maxCy {
 		return nil
 	}
-	idx := w.chunkIndex(cx, cy)
-	if c := w.chunks[idx]; c != nil {
-		return c
+	c := w.chunks[ChunkKey{X: cx, Y: cy}]
+	if c == nil || !c.ready {
+		c = w.loadChunk(cx, cy)
 	}
-	c := NewChunk(cx, cy)
-	generateChunk(c, w.seed, w.Theme)
-	w.chunks[idx] = c
+	c.lastUsed = w.clock
 	return c
 }
 
+// GenerateAll loads every chunk. The game streams chunks in as it uses
+// them; this is for tools that want the whole map at once.
 func (w *World) GenerateAll() {
 	for cy := 0; cy <= w.maxCy; cy++ {
 		for cx := 0; cx <= w.maxCx; cx++ {
 			_ = w.GetChunk(cx, cy)
 		}
 	}
-	w.repairRoadNetwork()
-}
-
-// repairRoadNetwork does a final global pass after all chunks are generated:
-// 1) enforce striped center lines on perimeter roads and
-// 2) fill tiny one-pixel road gaps so corners/crossings stay connected.
-func (w *World) repairRoadNetwork() {
-	if w.Theme.NoRoads {
-		return
-	}
-	tp := buildThemePalette(w.Theme)
-	roadCol := tp.Road
-
-	isRoad := func(x, y int) bool {
-		if x < 0 || y < 0 || x >= WorldWidth || y >= WorldHeight {
-			return false
-		}
-		if w.HeightAt(x, y) > 0 {
-			return false
-		}
-		return isRoadSurfaceColor(w.ColorAt(x, y), tp)
-	}
-	isCorridor := func(x, y int) bool {
-		if isPerimeterRoad(x, y) {
-			return true
-		}
-		xm := x % Pattern
-		ym := y % Pattern
-		return xm < RoadWidth || ym < RoadWidth
-	}
-
-	// Pass A: repaint perimeter ring roads with dashed center lines.
-	for y := BorderThickness; y < WorldHeight-BorderThickness; y++ {
-		for x := BorderThickness; x < WorldWidth-BorderThickness; x++ {
-			if !isPerimeterRoad(x, y) || w.HeightAt(x, y) > 0 {
-				continue
-			}
-			_ = w.PaintRGB(x, y, perimeterRoadColor(x, y, tp))
-		}
-	}
-
-	// Pass B: bridge tiny missed links (including chunk seams).
-	type pt struct{ X, Y int }
-	for iter := 0; iter < 2; iter++ {
-		bridges := make([]pt, 0, 256)
-		for y := BorderThickness + 1; y < WorldHeight-BorderThickness-1; y++ {
-			for x := BorderThickness + 1; x < WorldWidth-BorderThickness-1; x++ {
-				if !isCorridor(x, y) || isRoad(x, y) || w.HeightAt(x, y) > 0 {
-					continue
-				}
-				l := isRoad(x-1, y)
-				r := isRoad(x+1, y)
-				u := isRoad(x, y-1)
-				d := isRoad(x, y+1)
-				// Fill only likely single-pixel misses.
-				if (l && r) || (u && d) || ((l || r) && (u || d)) {
-					bridges = append(bridges, pt{X: x, Y: y})
-				}
-			}
-		}
-		if len(bridges) == 0 {
-			break
-		}
-		for _, b := range bridges {
-			_ = w.PaintRGB(b.X, b.Y, roadCol)
-		}
-	}
 }
 
 func (w *World) BuildSpatialIndex() {
-	root := NewQuadNode(RectF{X0: 0, Y0: 0, X1: float64(WorldWidth), Y1: float64(WorldHeight)}, 0)
+	root := NewQuadNode(RectF{X0: 0, Y0: 0, X1: float64(w.Width), Y1: float64(w.Height)}, 0)
 	for cy := 0; cy <= w.maxCy; cy++ {
 		for cx := 0; cx <= w.maxCx; cx++ {
 			x0 := float64(cx * ChunkSize)
 			y0 := float64(cy * ChunkSize)
 			x1 := x0 + float64(ChunkSize)
 			y1 := y0 + float64(ChunkSize)
-			if x1 > float64(WorldWidth) {
-				x1 = float64(WorldWidth)
+			if x1 > float64(w.Width) {
+				x1 = float64(w.Width)
 			}
-			if y1 > float64(WorldHeight) {
-				y1 = float64(WorldHeight)
+			if y1 > float64(w.Height) {
+				y1 = float64(w.Height)
 			}
 			root.Insert(ChunkKey{X: cx, Y: cy}, RectF{X0: x0, Y0: y0, X1: x1, Y1: y1})
 		}
@@ -245,7 +227,7 @@ func (w *World) VisibleChunks(view RectF, out []ChunkKey) []ChunkKey {
 
 // HeightAt returns the height at a world coordinate.
 func (w *World) HeightAt(wx, wy int) uint8 {
-	if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
+	if wx < 0 || wy < 0 || wx >= w.Width || wy >= w.Height {
 		return 255
 	}
 	cx := wx / ChunkSize
@@ -261,7 +243,7 @@ func (w *World) HeightAt(wx, wy int) uint8 {
 
 // ColorAt returns the current RGB at a world coordinate.
 func (w *World) ColorAt(wx, wy int) RGB {
-	if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
+	if wx < 0 || wy < 0 || wx >= w.Width || wy >= w.Height {
 		return Palette.Border
 	}
 	cx := wx / ChunkSize
@@ -282,6 +264,12 @@ func (w *World) IsBlocked(wx, wy int) bool {
 	return w.HeightAt(wx, wy) > 0
 }
 
+// IsWalkable reports whether troops and cops, on foot or in their cars, can
+// be at the world pixel: inside the world, at ground level and not in water.
+func (w *World) IsWalkable(wx, wy int) bool {
+	return w.HeightAt(wx, wy) == 0 && !w.IsWater(wx, wy)
+}
+
 // HasLineOfSight returns true if a straight line from (ax,ay) to (bx,by) is clear of obstacles.
 // Uses Bresenham integer line algorithm to avoid per-step float arithmetic.
 func HasLineOfSight(ax, ay, bx, by float64, w *World) bool {
@@ -329,7 +317,7 @@ func HasLineOfSight(ax, ay, bx, by float64, w *World) bool {
 
 // PaintRGB overwrites only the RGB channels, keeping height and shade.
 func (w *World) PaintRGB(wx, wy int, col RGB) bool {
-	if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
+	if wx < 0 || wy < 0 || wx >= w.Width || wy >= w.Height {
 		return false
 	}
 	cx := wx / ChunkSize
@@ -346,6 +334,8 @@ func (w *World) PaintRGB(wx, wy int, col RGB) bool {
 	}
 	c.setRGBKeepHeight(i, col)
 	c.NeedsUpload = true
+	c.edited = true
+	w.roadsChanged(wx, wy, wx, wy)
 	return true
 }
 
@@ -356,7 +346,7 @@ func (w *World) BurnPixel(wx, wy int) bool {
 
 // BurnPixelWithColor permanently clears collision height at a pixel and repaints it.
 func (w *World) BurnPixelWithColor(wx, wy int, col RGB) bool {
-	if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
+	if wx < 0 || wy < 0 || wx >= w.Width || wy >= w.Height {
 		return false
 	}
 	cx := wx / ChunkSize
@@ -371,8 +361,13 @@ func (w *World) BurnPixelWithColor(wx, wy int, col RGB) bool {
 	if c.Unbreakable[i] != 0 {
 		return false
 	}
+	if c.Height[i] > rubbleHeight {
+		w.queueStructureCheck(wx, wy, 1)
+	}
 	c.set(i, col, 0, ShadeLit, 0)
 	c.NeedsUpload = true
+	c.edited = true
+	w.roadsChanged(wx, wy, wx, wy)
 	return true
 }
 
@@ -385,46 +380,16 @@ func (w *World) burnedGroundColorAt(wx, wy int) RGB {
 	return base.Add(n/2, n/3, n/4)
 }
 
-// AddTempPaint temporarily paints a pixel for ttl seconds then restores original.
-func (w *World) AddTempPaint(wx, wy int, col RGB, ttl float64) bool {
-	if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
-		return false
-	}
-	cx := wx / ChunkSize
-	cy := wy / ChunkSize
-	c := w.GetChunk(cx, cy)
-	if c == nil {
-		return false
-	}
-	lx := wx - cx*ChunkSize
-	ly := wy - cy*ChunkSize
-	i := ly*ChunkSize + lx
-	orig := RGB{R: c.Pixels[i*4+0], G: c.Pixels[i*4+1], B: c.Pixels[i*4+2]}
-	c.setRGBKeepHeight(i, col)
-	c.NeedsUpload = true
-	w.temp = append(w.temp, TempPaint{X: wx, Y: wy, Orig: orig, TTL: ttl})
-	return true
-}
-
-// AddScheduledPaint schedules a temp paint to be applied after delay seconds.
-func (w *World) AddScheduledPaint(wx, wy int, col RGB, ttl, delay float64) bool {
-	if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
-		return false
-	}
-	w.scheduled = append(w.scheduled, ScheduledPaint{X: wx, Y: wy, Col: col, Delay: delay, TTL: ttl})
-	return true
-}
-
 // Explode destroys a circular area of the world.
 func (w *World) Explode(wx, wy, radius int) {
 	if radius <= 0 {
 		return
 	}
 
-	minX := clamp(wx-radius, 0, WorldWidth-1)
-	maxX := clamp(wx+radius, 0, WorldWidth-1)
-	minY := clamp(wy-radius, 0, WorldHeight-1)
-	maxY := clamp(wy+radius, 0, WorldHeight-1)
+	minX := clamp(wx-radius, 0, w.Width-1)
+	maxX := clamp(wx+radius, 0, w.Width-1)
+	minY := clamp(wy-radius, 0, w.Height-1)
+	maxY := clamp(wy+radius, 0, w.Height-1)
 
 	cx0 := floorDiv(minX, ChunkSize)
 	cx1 := floorDiv(maxX, ChunkSize)
@@ -478,52 +443,64 @@ func (w *World) Explode(wx, wy, radius int) {
 			if changed {
 				c.NeedsShadow = true
 				c.NeedsUpload = true
+				c.edited = true
 			}
 		}
 	}
 
-	// Invalidate shadow chunks in the down-shadow direction.
+	w.scorchDecal(wx, wy, radius)
+	w.roadsChanged(minX, minY, maxX, maxY)
+	w.queueStructureCheck(wx, wy, radius)
+	w.markShadows(wx-radius, wy-radius, wx+radius, wy+radius, radius+MaxShadowDist)
+}
+
+// markShadows flags the loaded chunks whose shadows change with the terrain
+// in x0..x1, y0..y1: those it covers and those up to reach pixels down-sun.
+func (w *World) markShadows(x0, y0, x1, y1, reach int) {
 	shadowDirX := -w.sunCosA
 	shadowDirY := -w.sunSinA
-	reach := radius + MaxShadowDist
-	fx0, fx1 := wx-radius, wx+radius
-	fy0, fy1 := wy-radius, wy+radius
 	if shadowDirX > 0 {
-		fx1 += reach
+		x1 += reach
 	} else if shadowDirX < 0 {
-		fx0 -= reach
+		x0 -= reach
 	}
 	if shadowDirY > 0 {
-		fy1 += reach
+		y1 += reach
 	} else if shadowDirY < 0 {
-		fy0 -= reach
-	}
-	fx0 = clamp(fx0, 0, WorldWidth-1)
-	fx1 = clamp(fx1, 0, WorldWidth-1)
-	fy0 = clamp(fy0, 0, WorldHeight-1)
-	fy1 = clamp(fy1, 0, WorldHeight-1)
-
-	dcx0 := floorDiv(fx0, ChunkSize)
-	dcx1 := floorDiv(fx1, ChunkSize)
-	dcy0 := floorDiv(fy0, ChunkSize)
-	dcy1 := floorDiv(fy1, ChunkSize)
-	for cy := dcy0; cy <= dcy1; cy++ {
-		for cx := dcx0; cx <= dcx1; cx++ {
-			c := w.GetChunk(cx, cy)
-			if c == nil {
-				continue
+		y0 -= reach
+	}
+	x0 = clamp(x0, 0, w.Width-1)
+	x1 = clamp(x1, 0, w.Width-1)
+	y0 = clamp(y0, 0, w.Height-1)
+	y1 = clamp(y1, 0, w.Height-1)
+
+	for cy := floorDiv(y0, ChunkSize); cy <= floorDiv(y1, ChunkSize); cy++ {
+		for cx := floorDiv(x0, ChunkSize); cx <= floorDiv(x1, ChunkSize); cx++ {
+			// Chunks that aren't loaded get their shadows when they load.
+			if c := w.loadedChunk(cx, cy); c != nil {
+				c.NeedsShadow = true
 			}
-			c.NeedsShadow = true
 		}
 	}
 }
 
+// sortedBurnKeys returns the keys of a burn map in ascending order.
+func sortedBurnKeys[T any](m map[int64]T) []int64 {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 func coordKey(x, y int) int64 {
 	return (int64(x) << 32) | int64(uint32(y))
 }
 
 // StartTreeBurn initializes burn for a tree canopy near (wx,wy).
 func (w *World) StartTreeBurn(wx, wy int) {
+	w.igniteFire(wx, wy)
 	key := coordKey(wx, wy)
 	if _, ok := w.burningTrees[key]; ok {
 		return
@@ -532,7 +509,7 @@ func (w *World) StartTreeBurn(wx, wy int) {
 	r := 5
 	for yy := wy - r; yy <= wy+r; yy++ {
 		for xx := wx - r; xx <= wx+r; xx++ {
-			if xx < 0 || yy < 0 || xx >= WorldWidth || yy >= WorldHeight {
+			if xx < 0 || yy < 0 || xx >= w.Width || yy >= w.Height {
 				continue
 			}
 			dx := xx - wx
@@ -559,6 +536,7 @@ func (w *World) StartTreeBurn(wx, wy int) {
 
 // StartBuildingBurn begins destructively burning a building region.
 func (w *World) StartBuildingBurn(wx, wy int) {
+	w.igniteFire(wx, wy)
 	key := coordKey(wx, wy)
 	if _, ok := w.burningBuildings[key]; ok {
 		return
@@ -567,7 +545,7 @@ func (w *World) StartBuildingBurn(wx, wy int) {
 	pixels := make([]struct{ X, Y int }, 0, 256)
 	for yy := wy - r; yy <= wy+r; yy++ {
 		for xx := wx - r; xx <= wx+r; xx++ {
-			if xx < 0 || yy < 0 || xx >= WorldWidth || yy >= WorldHeight {
+			if xx < 0 || yy < 0 || xx >= w.Width || yy >= w.Height {
 				continue
 			}
 			dx := xx - wx
@@ -595,62 +573,24 @@ func (w *World) StartBuildingBurn(wx, wy int) {
 	}
 }
 
-// Update processes temp paints and burning entities.
+// Update fades decals and processes burning entities.
 func (w *World) Update(dt float64) {
 	if dt <= 0 {
 		return
 	}
-
-	// Process scheduled paints.
-	if len(w.scheduled) > 0 {
-		out := w.scheduled[:0]
-		for _, s := range w.scheduled {
-			s.Delay -= dt
-			if s.Delay <= 0 {
-				cx := s.X / ChunkSize
-				cy := s.Y / ChunkSize
-				c := w.GetChunk(cx, cy)
-				if c != nil {
-					lx := s.X - cx*ChunkSize
-					ly := s.Y - cy*ChunkSize
-					i := ly*ChunkSize + lx
-					orig := RGB{R: c.Pixels[i*4+0], G: c.Pixels[i*4+1], B: c.Pixels[i*4+2]}
-					c.setRGBKeepHeight(i, s.Col)
-					c.NeedsUpload = true
-					w.temp = append(w.temp, TempPaint{X: s.X, Y: s.Y, Orig: orig, TTL: s.TTL})
-				}
-			} else {
-				out = append(out, s)
-			}
-		}
-		w.scheduled = out
-	}
-
-	// Process temp paints.
-	if len(w.temp) > 0 {
-		out := w.temp[:0]
-		for _, t := range w.temp {
-			t.TTL -= dt
-			if t.TTL <= 0 {
-				cx := t.X / ChunkSize
-				cy := t.Y / ChunkSize
-				c := w.GetChunk(cx, cy)
-				if c != nil {
-					lx := t.X - cx*ChunkSize
-					ly := t.Y - cy*ChunkSize
-					i := ly*ChunkSize + lx
-					c.setRGBKeepHeight(i, t.Orig)
-					c.NeedsUpload = true
-				}
-				continue
-			}
-			out = append(out, t)
-		}
-		w.temp = out
+	w.clock += dt
+	w.signalClock += dt
+	w.collapses = w.collapses[:0]
+	w.decalClock += dt
+	if w.decalClock >= decalFadeStep {
+		w.decalClock -= decalFadeStep
+		w.fadeDecals()
 	}
 
-	// Process burning trees.
-	for key, tb := range w.burningTrees {
+	// Process burning trees. Keys are visited in sorted order so overlapping
+	// burns resolve the same way every run (replays depend on it).
+	for _, key := range sortedBurnKeys(w.burningTrees) {
+		tb := w.burningTrees[key]
 		tb.timer -= dt
 		if tb.timer <= 0 {
 			dropCount := 1
@@ -672,7 +612,8 @@ func (w *World) Update(dt float64) {
 	}
 
 	// Process burning buildings.
-	for key, bb := range w.burningBuildings {
+	for _, key := range sortedBurnKeys(w.burningBuildings) {
+		bb := w.burningBuildings[key]
 		if bb.smolder {
 			bb.smolderTimer -= dt
 			bb.smolderDuration -= dt
@@ -684,7 +625,7 @@ func (w *World) Update(dt float64) {
 					d := int(bb.rng & 3)
 					nx := px.X + dirs[d][0]
 					ny := px.Y + dirs[d][1]
-					if nx >= 0 && ny >= 0 && nx < WorldWidth && ny < WorldHeight {
+					if nx >= 0 && ny >= 0 && nx < w.Width && ny < w.Height {
 						col := w.ColorAt(nx, ny)
 						if (col.R >= 90 && col.R <= 210) && (col.G >= 80 && col.G <= 180) {
 							if w.BurnPixelWithColor(nx, ny, w.burnedGroundColorAt(nx, ny)) {
@@ -721,7 +662,14 @@ func (w *World) Update(dt float64) {
 			bb.timer = bb.stepInterval
 		}
 		if len(bb.Pixels) == 0 {
+			cx, cy := (bb.X0+bb.X1)/2, (bb.Y0+bb.Y1)/2
+			w.Events.Emit(Event{
+				Type: EventBuildingBurned, X: float64(cx), Y: float64(cy),
+				Building: w.buildingAt(cx, cy, (bb.X1-bb.X0)/2),
+			})
 			delete(w.burningBuildings, key)
 		}
 	}
+
+	w.settleStructures()
 }
@@ -23,6 +23,7 @@ type buildingSpec struct {
 	UnitAddH   uint8
 	HasYard    bool
 	ExtraTrees bool
+	Interior   *interiorSpec // walkable inside; nil for a solid building
 }
 
 type blockFeatures struct {
@@ -49,6 +50,8 @@ type themePalette struct {
 	TreeBase     RGB
 	TreeMid      RGB
 	TreeTop      RGB
+	Water        RGB
+	Shore        RGB // river banks, lake shores and beaches
 }
 
 func roadStripeColor(tp themePalette) RGB {
@@ -79,6 +82,8 @@ func buildThemePalette(theme ThemeConfig) themePalette {
 		TreeBase:     Palette.TreeBase,
 		TreeMid:      Palette.TreeMid,
 		TreeTop:      Palette.TreeTop,
+		Water:        Palette.Water,
+		Shore:        Palette.Shore,
 	}
 	shiftTerrain := func(dr, dg, db int) {
 		tp.Road = tp.Road.Add(dr, dg, db)
@@ -181,6 +186,8 @@ func buildThemePalette(theme ThemeConfig) themePalette {
 		shiftTerrain(28, 14, 4)
 		shiftBuildings(18, 10, 8)
 		shiftGreen(6, 18, 16)
+		tp.Water = RGB{R: 42, G: 128, B: 180}
+		tp.Shore = RGB{R: 234, G: 214, B: 162}
 	case ThemeSpace.Name:
 		shiftTerrain(-22, -24, 26)
 		shiftBuildings(-18, -24, 30)
@@ -189,6 +196,8 @@ func buildThemePalette(theme ThemeConfig) themePalette {
 		shiftTerrain(-22, 4, 22)
 		shiftBuildings(-24, 0, 26)
 		shiftGreen(-16, 12, 24)
+		tp.Water = RGB{R: 22, G: 56, B: 104}
+		tp.Shore = RGB{R: 122, G: 132, B: 118}
 	case ThemeMegacity.Name:
 		shiftTerrain(-20, -16, -12)
 		shiftBuildings(-18, -14, -10)
@@ -213,6 +222,8 @@ func buildThemePalette(theme ThemeConfig) themePalette {
 		shiftTerrain(-10, 0, -8)
 		shiftBuildings(-18, -4, -12)
 		shiftGreen(-8, 30, -6)
+		tp.Water = RGB{R: 48, G: 98, B: 94}
+		tp.Shore = RGB{R: 122, G: 104, B: 70}
 	case ThemeCanyon.Name:
 		shiftTerrain(24, 4, -18)
 		shiftBuildings(30, 6, -20)
@@ -225,6 +236,8 @@ func buildThemePalette(theme ThemeConfig) themePalette {
 		shiftTerrain(-8, 4, -10)
 		shiftBuildings(-6, 2, -8)
 		shiftGreen(-18, 18, -12)
+		tp.Water = RGB{R: 74, G: 106, B: 86}
+		tp.Shore = RGB{R: 94, G: 84, B: 56}
 	case ThemeNeon.Name:
 		shiftTerrain(0, -10, 24)
 		shiftBuildings(16, -12, 34)
@@ -256,32 +269,32 @@ func isWinterTheme(theme ThemeConfig) bool {
 
 // isPerimeterRoad reports whether a world pixel is in the ring road
 // just inside the unbreakable border.
-func isPerimeterRoad(wx, wy int) bool {
-	if wx < BorderThickness || wy < BorderThickness || wx >= WorldWidth-BorderThickness || wy >= WorldHeight-BorderThickness {
+func isPerimeterRoad(wx, wy, worldW, worldH int) bool {
+	if wx < BorderThickness || wy < BorderThickness || wx >= worldW-BorderThickness || wy >= worldH-BorderThickness {
 		return false
 	}
 	return wx < BorderThickness+RoadWidth ||
 		wy < BorderThickness+RoadWidth ||
-		wx >= WorldWidth-BorderThickness-RoadWidth ||
-		wy >= WorldHeight-BorderThickness-RoadWidth
+		wx >= worldW-BorderThickness-RoadWidth ||
+		wy >= worldH-BorderThickness-RoadWidth
 }
 
 func roadStripeDash(pos int) bool {
 	return (pos % 7) < 2 // slightly longer dashes than before
 }
 
-func perimeterRoadColor(wx, wy int, tp themePalette) RGB {
+func perimeterRoadColor(wx, wy, worldW, worldH int, tp themePalette) RGB {
 	col := tp.Road
 	topCenter := BorderThickness + RoadWidth/2
-	bottomCenter := WorldHeight - BorderThickness - RoadWidth + RoadWidth/2
+	bottomCenter := worldH - BorderThickness - RoadWidth + RoadWidth/2
 	leftCenter := BorderThickness + RoadWidth/2
-	rightCenter := WorldWidth - BorderThickness - RoadWidth + RoadWidth/2
+	rightCenter := worldW - BorderThickness - RoadWidth + RoadWidth/2
 
 	// In corner overlap zones, use one axis only so stripes stay straight.
 	dTop := wy - BorderThickness
-	dBottom := (WorldHeight - BorderThickness - 1) - wy
+	dBottom := (worldH - BorderThickness - 1) - wy
 	dLeft := wx - BorderThickness
-	dRight := (WorldWidth - BorderThickness - 1) - wx
+	dRight := (worldW - BorderThickness - 1) - wx
 	hBandDist := min(dTop, dBottom)
 	vBandDist := min(dLeft, dRight)
 
@@ -296,188 +309,38 @@ func perimeterRoadColor(wx, wy int, tp themePalette) RGB {
 	return col
 }
 
-// generateChunk fills a chunk with deterministic city content.
-func generateChunk(c *Chunk, worldSeed uint64, theme ThemeConfig) {
+// grassColor is open grass at wx, wy: patches of the theme's grass tones
+// with a fine per-pixel grain.
+func grassColor(worldSeed uint64, wx, wy int, tp themePalette) RGB {
+	col := tp.Grass
+	pv := uint8(hash2D(worldSeed^0xBADC0FFEE0DDF00D, wx>>4, wy>>4) >> 56)
+	if pv < 18 {
+		col = tp.GrassTorn
+	} else if pv < 60 {
+		col = tp.GrassPatch
+	}
+	hv := uint8(hash2D(worldSeed^0x1234567, wx, wy) >> 56)
+	switch (wx + 2*wy + int(hv&3)) & 3 {
+	case 1:
+		col = col.Add(6, 6, 4)
+	case 2:
+		col = col.Add(-6, -6, -4)
+	}
+	return col
+}
+
+// generateChunk fills a chunk with deterministic city content. roads is
+// the world's solved road graph (see newRoadNetwork) and water its rivers,
+// lakes and sea (see newWaterLayout).
+func generateChunk(c *Chunk, worldSeed uint64, theme ThemeConfig, roads *roadNetwork, water *waterLayout) {
 	tp := buildThemePalette(theme)
 	baseX, baseY := c.WorldOrigin()
 	maxX := baseX + ChunkSize - 1
 	maxY := baseY + ChunkSize - 1
-	maxBX := floorDiv(WorldWidth-1, Pattern)
-	maxBY := floorDiv(WorldHeight-1, Pattern)
-
-	parcelCache := make(map[int64]blockParcel, 64)
-	pKey := func(bx, by int) int64 {
-		return (int64(by) << 32) ^ int64(uint32(bx))
-	}
-	getParcel := func(bx, by int) blockParcel {
-		if bx < 0 || by < 0 || bx > maxBX || by > maxBY {
-			return blockParcel{Kind: parcelNone}
-		}
-		key := pKey(bx, by)
-		if p, ok := parcelCache[key]; ok {
-			return p
-		}
-		profile := blockProfileFor(worldSeed, bx, by)
-		p := blockParcelFor(worldSeed, bx, by, theme, profile)
-		parcelCache[key] = p
-		return p
-	}
-	sharedParcelKind := func(ax, ay, bx, by int) parcelKind {
-		pa := getParcel(ax, ay)
-		pb := getParcel(bx, by)
-		if pa.Kind == parcelNone || pb.Kind == parcelNone || pa.Kind != pb.Kind {
-			return parcelNone
-		}
-		if pa.AX != pb.AX || pa.AY != pb.AY || pa.W != pb.W || pa.H != pb.H {
-			return parcelNone
-		}
-		return pa.Kind
-	}
-
-	gridW := maxBX + 1
-	gridH := maxBY + 1
-	vertRoadOpen := make([][]bool, gridW+1)
-	for x := 0; x <= gridW; x++ {
-		vertRoadOpen[x] = make([]bool, gridH)
-		for y := 0; y < gridH; y++ {
-			vertRoadOpen[x][y] = true
-		}
-	}
-	horzRoadOpen := make([][]bool, gridW)
-	for x := 0; x < gridW; x++ {
-		horzRoadOpen[x] = make([]bool, gridH+1)
-		for y := 0; y <= gridH; y++ {
-			horzRoadOpen[x][y] = true
-		}
-	}
 
-	type roadCloseCandidate struct {
-		Vertical bool
-		K, C     int
-		Score    uint64
-	}
-	candidates := make([]roadCloseCandidate, 0, (gridW-1)*gridH+gridW*(gridH-1))
-	for y := 0; y < gridH; y++ {
-		for k := 1; k < gridW; k++ {
-			if sharedParcelKind(k-1, y, k, y) == parcelNone {
-				continue
-			}
-			candidates = append(candidates, roadCloseCandidate{
-				Vertical: true,
-				K:        k,
-				C:        y,
-				Score:    hash2D(worldSeed^0xA1B2C3D4E5F60718, k, y),
-			})
-		}
-	}
-	for x := 0; x < gridW; x++ {
-		for k := 1; k < gridH; k++ {
-			if sharedParcelKind(x, k-1, x, k) == parcelNone {
-				continue
-			}
-			candidates = append(candidates, roadCloseCandidate{
-				Vertical: false,
-				K:        k,
-				C:        x,
-				Score:    hash2D(worldSeed^0x1029384756ABCDEF, x, k),
-			})
-		}
-	}
-	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
-
-	nodeID := func(ix, iy int) int { return iy*(gridW+1) + ix }
-	nodeDegree := func(ix, iy int) int {
-		d := 0
-		if iy > 0 && vertRoadOpen[ix][iy-1] {
-			d++
-		}
-		if iy < gridH && vertRoadOpen[ix][iy] {
-			d++
-		}
-		if ix > 0 && horzRoadOpen[ix-1][iy] {
-			d++
-		}
-		if ix < gridW && horzRoadOpen[ix][iy] {
-			d++
-		}
-		return d
-	}
-	hasLooseEnds := func() bool {
-		for iy := 0; iy <= gridH; iy++ {
-			for ix := 0; ix <= gridW; ix++ {
-				if nodeDegree(ix, iy) == 1 {
-					return true
-				}
-			}
-		}
-		return false
-	}
-	allRoadsConnected := func() bool {
-		total := (gridW + 1) * (gridH + 1)
-		vis := make([]bool, total)
-		q := make([]int, 0, total)
-		start := 0
-		vis[start] = true
-		q = append(q, start)
-		for qi := 0; qi < len(q); qi++ {
-			n := q[qi]
-			x := n % (gridW + 1)
-			y := n / (gridW + 1)
-			if y > 0 && vertRoadOpen[x][y-1] {
-				nn := nodeID(x, y-1)
-				if !vis[nn] {
-					vis[nn] = true
-					q = append(q, nn)
-				}
-			}
-			if y < gridH && vertRoadOpen[x][y] {
-				nn := nodeID(x, y+1)
-				if !vis[nn] {
-					vis[nn] = true
-					q = append(q, nn)
-				}
-			}
-			if x > 0 && horzRoadOpen[x-1][y] {
-				nn := nodeID(x-1, y)
-				if !vis[nn] {
-					vis[nn] = true
-					q = append(q, nn)
-				}
-			}
-			if x < gridW && horzRoadOpen[x][y] {
-				nn := nodeID(x+1, y)
-				if !vis[nn] {
-					vis[nn] = true
-					q = append(q, nn)
-				}
-			}
-		}
-		for i := range vis {
-			if !vis[i] {
-				return false
-			}
-		}
-		return true
-	}
-	for _, cand := range candidates {
-		if cand.Vertical {
-			if !vertRoadOpen[cand.K][cand.C] {
-				continue
-			}
-			vertRoadOpen[cand.K][cand.C] = false
-			if !allRoadsConnected() || hasLooseEnds() {
-				vertRoadOpen[cand.K][cand.C] = true
-			}
-		} else {
-			if !horzRoadOpen[cand.C][cand.K] {
-				continue
-			}
-			horzRoadOpen[cand.C][cand.K] = false
-			if !allRoadsConnected() || hasLooseEnds() {
-				horzRoadOpen[cand.C][cand.K] = true
-			}
-		}
-	}
+	sharedParcelKind := newParcelLookup(worldSeed, theme, c.worldW, c.worldH).shared
+	gridW, gridH := roads.gridW, roads.gridH
+	vertRoadOpen, horzRoadOpen := roads.vertOpen, roads.horzOpen
 
 	// Base classification: border/road/sidewalk/lot (or all grass when NoRoads).
 	for y := 0; y < ChunkSize; y++ {
@@ -486,35 +349,21 @@ func generateChunk(c *Chunk, worldSeed uint64, theme ThemeConfig) {
 			wx := baseX + x
 			i := c.idx(x, y)
 
-			if wx < 0 || wy < 0 || wx >= WorldWidth || wy >= WorldHeight {
+			if wx < 0 || wy < 0 || wx >= c.worldW || wy >= c.worldH {
 				c.set(i, Palette.Border, BorderHeight, ShadeLit, 1)
 				continue
 			}
-			if wx < BorderThickness || wy < BorderThickness || wx >= WorldWidth-BorderThickness || wy >= WorldHeight-BorderThickness {
+			if wx < BorderThickness || wy < BorderThickness || wx >= c.worldW-BorderThickness || wy >= c.worldH-BorderThickness {
 				c.set(i, Palette.Border, BorderHeight, ShadeLit, 1)
 				continue
 			}
-			if isPerimeterRoad(wx, wy) {
-				c.set(i, perimeterRoadColor(wx, wy, tp), 0, ShadeLit, 0)
+			if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
+				c.set(i, perimeterRoadColor(wx, wy, c.worldW, c.worldH, tp), 0, ShadeLit, 0)
 				continue
 			}
 
 			if theme.NoRoads {
-				col := tp.Grass
-				pv := uint8(hash2D(worldSeed^0xBADC0FFEE0DDF00D, wx>>4, wy>>4) >> 56)
-				if pv < 18 {
-					col = tp.GrassTorn
-				} else if pv < 60 {
-					col = tp.GrassPatch
-				}
-				hv := uint8(hash2D(worldSeed^0x1234567, wx, wy) >> 56)
-				switch (wx + 2*wy + int(hv&3)) & 3 {
-				case 1:
-					col = col.Add(6, 6, 4)
-				case 2:
-					col = col.Add(-6, -6, -4)
-				}
-				c.set(i, col, 0, ShadeLit, 0)
+				c.set(i, grassColor(worldSeed, wx, wy, tp), 0, ShadeLit, 0)
 				continue
 			}
 
@@ -632,26 +481,41 @@ func generateChunk(c *Chunk, worldSeed uint64, theme ThemeConfig) {
 	bxMax := floorDiv(maxX, Pattern)
 	byMax := floorDiv(maxY, Pattern)
 
+	feats := make([]blockFeatures, 0, (bxMax-bxMin+1)*(byMax-byMin+1))
 	for by := byMin; by <= byMax; by++ {
 		for bx := bxMin; bx <= bxMax; bx++ {
 			feat := genBlockFeatures(worldSeed, bx, by, theme, tp)
 			applyBlockFeatures(c, bx, by, feat, tp, theme)
+			feats = append(feats, feat)
+		}
+	}
+
+	// Building interiors go over whatever the blocks and merged parcels
+	// drew, so nothing else covers them.
+	k := 0
+	for by := byMin; by <= byMax; by++ {
+		for bx := bxMin; bx <= bxMax; bx++ {
+			drawInteriors(c, bx, by, feats[k], tp, theme, water)
+			k++
 		}
 	}
 
+	water.paint(c, worldSeed, tp)
+
 	// Final road pass: roads are authored last from the solved road graph so
-	// parks/buildings/trees can never overwrite or disconnect them.
+	// parks/buildings/trees can never overwrite or disconnect them, and
+	// streets cross water on bridges.
 	if !theme.NoRoads {
 		for y := 0; y < ChunkSize; y++ {
 			wy := baseY + y
 			for x := 0; x < ChunkSize; x++ {
 				wx := baseX + x
 				i := c.idx(x, y)
-				if wx < BorderThickness || wy < BorderThickness || wx >= WorldWidth-BorderThickness || wy >= WorldHeight-BorderThickness {
+				if wx < BorderThickness || wy < BorderThickness || wx >= c.worldW-BorderThickness || wy >= c.worldH-BorderThickness {
 					continue
 				}
-				if isPerimeterRoad(wx, wy) {
-					c.set(i, perimeterRoadColor(wx, wy, tp), 0, ShadeLit, 0)
+				if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
+					c.set(i, perimeterRoadColor(wx, wy, c.worldW, c.worldH, tp), 0, ShadeLit, 0)
 					continue
 				}
 
@@ -721,7 +585,11 @@ func generateChunk(c *Chunk, worldSeed uint64, theme ThemeConfig) {
 					}
 					c.set(i, col, 0, ShadeLit, 0)
 				case xSideOpen || ySideOpen:
-					c.set(i, tp.Sidewalk, 0, ShadeLit, 0)
+					col := tp.Sidewalk
+					if c.Water[i] != 0 {
+						col = bridgeSideColor(xm, ym, xSideOpen, ySideOpen, tp)
+					}
+					c.set(i, col, 0, ShadeLit, 0)
 				}
 			}
 		}
@@ -731,6 +599,215 @@ func generateChunk(c *Chunk, worldSeed uint64, theme ThemeConfig) {
 	c.NeedsUpload = true
 }
 
+// parcelLookup caches the block parcels of one world during generation.
+type parcelLookup struct {
+	seed         uint64
+	theme        ThemeConfig
+	maxBX, maxBY int
+	cache        map[int64]blockParcel
+}
+
+func newParcelLookup(worldSeed uint64, theme ThemeConfig, worldW, worldH int) *parcelLookup {
+	return &parcelLookup{
+		seed:  worldSeed,
+		theme: theme,
+		maxBX: floorDiv(worldW-1, Pattern),
+		maxBY: floorDiv(worldH-1, Pattern),
+		cache: make(map[int64]blockParcel, 64),
+	}
+}
+
+func (pl *parcelLookup) get(bx, by int) blockParcel {
+	if bx < 0 || by < 0 || bx > pl.maxBX || by > pl.maxBY {
+		return blockParcel{Kind: parcelNone}
+	}
+	key := (int64(by) << 32) ^ int64(uint32(bx))
+	if p, ok := pl.cache[key]; ok {
+		return p
+	}
+	profile := blockProfileFor(pl.seed, bx, by)
+	p := blockParcelFor(pl.seed, bx, by, pl.theme, profile)
+	pl.cache[key] = p
+	return p
+}
+
+// shared returns the kind of the parcel spanning both blocks, or parcelNone
+// when they belong to different parcels.
+func (pl *parcelLookup) shared(ax, ay, bx, by int) parcelKind {
+	pa := pl.get(ax, ay)
+	pb := pl.get(bx, by)
+	if pa.Kind == parcelNone || pb.Kind == parcelNone || pa.Kind != pb.Kind {
+		return parcelNone
+	}
+	if pa.AX != pb.AX || pa.AY != pb.AY || pa.W != pb.W || pa.H != pb.H {
+		return parcelNone
+	}
+	return pa.Kind
+}
+
+// roadNetwork is the solved road graph of a world: which road segments
+// between block corners stay open after merged parcels close some of them
+// off. Keeping every road connected makes it depend on the whole world, so
+// it is solved once per world and shared by all of its chunks.
+type roadNetwork struct {
+	gridW, gridH int
+	vertOpen     [][]bool // [k][cellY]: road between block columns k-1 and k
+	horzOpen     [][]bool // [cellX][k]: road between block rows k-1 and k
+}
+
+func newRoadNetwork(worldSeed uint64, theme ThemeConfig, worldW, worldH int) *roadNetwork {
+	parcels := newParcelLookup(worldSeed, theme, worldW, worldH)
+	sharedParcelKind := parcels.shared
+	maxBX, maxBY := parcels.maxBX, parcels.maxBY
+
+	gridW := maxBX + 1
+	gridH := maxBY + 1
+	vertRoadOpen := make([][]bool, gridW+1)
+	for x := 0; x <= gridW; x++ {
+		vertRoadOpen[x] = make([]bool, gridH)
+		for y := 0; y < gridH; y++ {
+			vertRoadOpen[x][y] = true
+		}
+	}
+	horzRoadOpen := make([][]bool, gridW)
+	for x := 0; x < gridW; x++ {
+		horzRoadOpen[x] = make([]bool, gridH+1)
+		for y := 0; y <= gridH; y++ {
+			horzRoadOpen[x][y] = true
+		}
+	}
+
+	type roadCloseCandidate struct {
+		Vertical bool
+		K, C     int
+		Score    uint64
+	}
+	candidates := make([]roadCloseCandidate, 0, (gridW-1)*gridH+gridW*(gridH-1))
+	for y := 0; y < gridH; y++ {
+		for k := 1; k < gridW; k++ {
+			if sharedParcelKind(k-1, y, k, y) == parcelNone {
+				continue
+			}
+			candidates = append(candidates, roadCloseCandidate{
+				Vertical: true,
+				K:        k,
+				C:        y,
+				Score:    hash2D(worldSeed^0xA1B2C3D4E5F60718, k, y),
+			})
+		}
+	}
+	for x := 0; x < gridW; x++ {
+		for k := 1; k < gridH; k++ {
+			if sharedParcelKind(x, k-1, x, k) == parcelNone {
+				continue
+			}
+			candidates = append(candidates, roadCloseCandidate{
+				Vertical: false,
+				K:        k,
+				C:        x,
+				Score:    hash2D(worldSeed^0x1029384756ABCDEF, x, k),
+			})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+
+	nodeID := func(ix, iy int) int { return iy*(gridW+1) + ix }
+	nodeDegree := func(ix, iy int) int {
+		d := 0
+		if iy > 0 && vertRoadOpen[ix][iy-1] {
+			d++
+		}
+		if iy < gridH && vertRoadOpen[ix][iy] {
+			d++
+		}
+		if ix > 0 && horzRoadOpen[ix-1][iy] {
+			d++
+		}
+		if ix < gridW && horzRoadOpen[ix][iy] {
+			d++
+		}
+		return d
+	}
+	hasLooseEnds := func() bool {
+		for iy := 0; iy <= gridH; iy++ {
+			for ix := 0; ix <= gridW; ix++ {
+				if nodeDegree(ix, iy) == 1 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	allRoadsConnected := func() bool {
+		total := (gridW + 1) * (gridH + 1)
+		vis := make([]bool, total)
+		q := make([]int, 0, total)
+		start := 0
+		vis[start] = true
+		q = append(q, start)
+		for qi := 0; qi < len(q); qi++ {
+			n := q[qi]
+			x := n % (gridW + 1)
+			y := n / (gridW + 1)
+			if y > 0 && vertRoadOpen[x][y-1] {
+				nn := nodeID(x, y-1)
+				if !vis[nn] {
+					vis[nn] = true
+					q = append(q, nn)
+				}
+			}
+			if y < gridH && vertRoadOpen[x][y] {
+				nn := nodeID(x, y+1)
+				if !vis[nn] {
+					vis[nn] = true
+					q = append(q, nn)
+				}
+			}
+			if x > 0 && horzRoadOpen[x-1][y] {
+				nn := nodeID(x-1, y)
+				if !vis[nn] {
+					vis[nn] = true
+					q = append(q, nn)
+				}
+			}
+			if x < gridW && horzRoadOpen[x][y] {
+				nn := nodeID(x+1, y)
+				if !vis[nn] {
+					vis[nn] = true
+					q = append(q, nn)
+				}
+			}
+		}
+		for i := range vis {
+			if !vis[i] {
+				return false
+			}
+		}
+		return true
+	}
+	for _, cand := range candidates {
+		if cand.Vertical {
+			if !vertRoadOpen[cand.K][cand.C] {
+				continue
+			}
+			vertRoadOpen[cand.K][cand.C] = false
+			if !allRoadsConnected() || hasLooseEnds() {
+				vertRoadOpen[cand.K][cand.C] = true
+			}
+		} else {
+			if !horzRoadOpen[cand.C][cand.K] {
+				continue
+			}
+			horzRoadOpen[cand.C][cand.K] = false
+			if !allRoadsConnected() || hasLooseEnds() {
+				horzRoadOpen[cand.C][cand.K] = true
+			}
+		}
+	}
+
+	return &roadNetwork{gridW: gridW, gridH: gridH, vertOpen: vertRoadOpen, horzOpen: horzRoadOpen}
+}
+
 type blockProfile struct {
 	ParkBias         int
 	BuildingBias     int
@@ -1211,6 +1288,7 @@ func genBlockFeatures(worldSeed uint64, bx, by int, theme ThemeConfig, tp themeP
 
 		buildings = append(buildings, b)
 	}
+	planInteriors(buildings, seed, theme)
 
 	lotTreeMax := theme.TreeChance / 3
 	if lotTreeMax < 2 {
@@ -1252,7 +1330,7 @@ func drawParkingLot(c *Chunk, bx, by int, seed uint64, tp themePalette) {
 	blockX1 := blockX0 + BlockInner
 	blockY1 := blockY0 + BlockInner
 	if blockX0 < BorderThickness || blockY0 < BorderThickness ||
-		blockX1 > WorldWidth-BorderThickness || blockY1 > WorldHeight-BorderThickness {
+		blockX1 > c.worldW-BorderThickness || blockY1 > c.worldH-BorderThickness {
 		return
 	}
 
@@ -1269,7 +1347,7 @@ func drawParkingLot(c *Chunk, bx, by int, seed uint64, tp themePalette) {
 	lineCol := roadStripeColor(tp).Add(18, 18, 10)
 	for wy := y0; wy < y1; wy++ {
 		for wx := x0; wx < x1; wx++ {
-			if isPerimeterRoad(wx, wy) {
+			if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 				continue
 			}
 			i := c.idx(wx-chunkX0, wy-chunkY0)
@@ -1293,7 +1371,7 @@ func drawParkingLot(c *Chunk, bx, by int, seed uint64, tp themePalette) {
 		aisleY1 := aisleY0 + 4
 		for wy := max(y0, aisleY0); wy < min(y1, aisleY1); wy++ {
 			for wx := x0; wx < x1; wx++ {
-				if isPerimeterRoad(wx, wy) {
+				if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 					continue
 				}
 				i := c.idx(wx-chunkX0, wy-chunkY0)
@@ -1329,7 +1407,7 @@ func drawParkingLot(c *Chunk, bx, by int, seed uint64, tp themePalette) {
 		aisleX1 := aisleX0 + 4
 		for wx := max(x0, aisleX0); wx < min(x1, aisleX1); wx++ {
 			for wy := y0; wy < y1; wy++ {
-				if isPerimeterRoad(wx, wy) {
+				if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 					continue
 				}
 				i := c.idx(wx-chunkX0, wy-chunkY0)
@@ -1376,7 +1454,7 @@ func drawMergedParkParcel(c *Chunk, p blockParcel, seed uint64, tp themePalette,
 		return
 	}
 	x0, y0, x1, y1 := parcelInnerBounds(p)
-	if x0 < BorderThickness || y0 < BorderThickness || x1 > WorldWidth-BorderThickness || y1 > WorldHeight-BorderThickness {
+	if x0 < BorderThickness || y0 < BorderThickness || x1 > c.worldW-BorderThickness || y1 > c.worldH-BorderThickness {
 		return
 	}
 
@@ -1392,7 +1470,7 @@ func drawMergedParkParcel(c *Chunk, p blockParcel, seed uint64, tp themePalette,
 	fy1 := clamp(y1, chunkY0, chunkY1)
 	for wy := fy0; wy < fy1; wy++ {
 		for wx := fx0; wx < fx1; wx++ {
-			if isPerimeterRoad(wx, wy) {
+			if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 				continue
 			}
 			i := c.idx(wx-chunkX0, wy-chunkY0)
@@ -1446,7 +1524,7 @@ func drawMergedBuildingParcel(c *Chunk, p blockParcel, seed uint64, tp themePale
 		return
 	}
 	x0, y0, x1, y1 := parcelInnerBounds(p)
-	if x0 < BorderThickness || y0 < BorderThickness || x1 > WorldWidth-BorderThickness || y1 > WorldHeight-BorderThickness {
+	if x0 < BorderThickness || y0 < BorderThickness || x1 > c.worldW-BorderThickness || y1 > c.worldH-BorderThickness {
 		return
 	}
 
@@ -1462,7 +1540,7 @@ func drawMergedBuildingParcel(c *Chunk, p blockParcel, seed uint64, tp themePale
 	fy1 := clamp(y1, chunkY0, chunkY1)
 	for wy := fy0; wy < fy1; wy++ {
 		for wx := fx0; wx < fx1; wx++ {
-			if isPerimeterRoad(wx, wy) {
+			if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 				continue
 			}
 			i := c.idx(wx-chunkX0, wy-chunkY0)
@@ -1549,7 +1627,7 @@ func drawMergedBuildingParcel(c *Chunk, p blockParcel, seed uint64, tp themePale
 		dy1 := clamp(wy1, chunkY0, chunkY1)
 		for wy := dy0; wy < dy1; wy++ {
 			for wx := dx0; wx < dx1; wx++ {
-				if isPerimeterRoad(wx, wy) {
+				if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 					continue
 				}
 				i := c.idx(wx-chunkX0, wy-chunkY0)
@@ -1610,7 +1688,7 @@ func applyBlockFeatures(c *Chunk, bx, by int, feat blockFeatures, tp themePalett
 
 	// Avoid partial edge lots; they produce cut-off buildings/parks at world borders.
 	if blockX0 < BorderThickness || blockY0 < BorderThickness ||
-		blockX1 > WorldWidth-BorderThickness || blockY1 > WorldHeight-BorderThickness {
+		blockX1 > c.worldW-BorderThickness || blockY1 > c.worldH-BorderThickness {
 		return
 	}
 
@@ -1632,7 +1710,7 @@ func applyBlockFeatures(c *Chunk, bx, by int, feat blockFeatures, tp themePalett
 			for wy := y0; wy < y1; wy++ {
 				ly := wy - chunkY0
 				for wx := x0; wx < x1; wx++ {
-					if isPerimeterRoad(wx, wy) {
+					if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 						continue
 					}
 					lx := wx - chunkX0
@@ -1715,8 +1793,8 @@ func applyBlockFeatures(c *Chunk, bx, by int, feat blockFeatures, tp themePalett
 		// Keep the outer ring road clear and avoid edge-clipped buildings.
 		if rx0 < BorderThickness+RoadWidth ||
 			ry0 < BorderThickness+RoadWidth ||
-			rx1 > WorldWidth-BorderThickness-RoadWidth ||
-			ry1 > WorldHeight-BorderThickness-RoadWidth {
+			rx1 > c.worldW-BorderThickness-RoadWidth ||
+			ry1 > c.worldH-BorderThickness-RoadWidth {
 			continue
 		}
 
@@ -1736,7 +1814,7 @@ func applyBlockFeatures(c *Chunk, bx, by int, feat blockFeatures, tp themePalett
 			}
 			rowOff := lyBlock * BlockInner
 			for wx := x0; wx < x1; wx++ {
-				if isPerimeterRoad(wx, wy) {
+				if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 					continue
 				}
 				lxChunk := wx - chunkX0
@@ -1803,7 +1881,7 @@ func applyBlockFeatures(c *Chunk, bx, by int, feat blockFeatures, tp themePalett
 							if wxn < chunkX0 || wxn >= chunkX1 || wyn < chunkY0 || wyn >= chunkY1 {
 								continue
 							}
-							if isPerimeterRoad(wxn, wyn) {
+							if isPerimeterRoad(wxn, wyn, c.worldW, c.worldH) {
 								continue
 							}
 							lxChunkN := wxn - chunkX0
@@ -1905,7 +1983,7 @@ func applyBlockFeatures(c *Chunk, bx, by int, feat blockFeatures, tp themePalett
 					if wx < chunkX0 || wx >= chunkX1 || wy < chunkY0 || wy >= chunkY1 {
 						continue
 					}
-					if isPerimeterRoad(wx, wy) {
+					if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 						continue
 					}
 					ci := c.idx(wx-chunkX0, wy-chunkY0)
@@ -1927,7 +2005,7 @@ func applyBlockFeatures(c *Chunk, bx, by int, feat blockFeatures, tp themePalett
 				wx := blockX0 + tx
 				wy := blockY0 + ty
 				if wx >= chunkX0 && wx < chunkX1 && wy >= chunkY0 && wy < chunkY1 {
-					if isPerimeterRoad(wx, wy) {
+					if isPerimeterRoad(wx, wy, c.worldW, c.worldH) {
 						continue
 					}
 					ci := c.idx(wx-chunkX0, wy-chunkY0)
@@ -2014,7 +2092,7 @@ func drawTreeSized(c *Chunk, wx, wy int, radius int, tp themePalette, winter boo
 				if c.Unbreakable[i] != 0 {
 					continue
 				}
-				if isPerimeterRoad(tx, ty) {
+				if isPerimeterRoad(tx, ty, c.worldW, c.worldH) {
 					continue
 				}
 				if isRoadSurfaceColor(chunkColorAt(c, i), tp) {
@@ -0,0 +1,309 @@
+package game
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// go test ./internal/game -run TestWorldgenGolden -update rewrites the
+// goldens after an intended change to generation.
+var updateGolden = flag.Bool("update", false, "rewrite testdata/worldgen.golden")
+
+const worldgenGoldenPath = "testdata/worldgen.golden"
+
+type goldenWorld struct {
+	seed  uint64
+	theme ThemeConfig
+	w, h  int
+}
+
+// goldenWorlds is the generation matrix: every theme at the default size
+// for a few seeds, and at a larger size for one.
+func goldenWorlds() []goldenWorld {
+	var out []goldenWorld
+	for _, theme := range Themes {
+		for _, seed := range []uint64{1, 7, 1234} {
+			out = append(out, goldenWorld{seed, theme, DefaultWorldWidth, DefaultWorldHeight})
+		}
+		out = append(out, goldenWorld{99, theme, 495, 363})
+	}
+	return out
+}
+
+func newTestWorld(seed uint64, theme ThemeConfig, width, height int) *World {
+	w := NewWorld(seed, width, height)
+	w.Theme = theme
+	w.Resize(width, height)
+	return w
+}
+
+// chunkHashes hashes a chunk's colours, and its heights with the
+// indestructible flags.
+func chunkHashes(c *Chunk) (pix, height uint64) {
+	hp, hh := fnv.New64a(), fnv.New64a()
+	for i := range c.Height {
+		o := c.pixOff(i)
+		hp.Write(c.Pixels[o : o+3])
+		hh.Write([]byte{c.Height[i], c.Unbreakable[i]})
+	}
+	return hp.Sum64(), hh.Sum64()
+}
+
+func goldenLines() []string {
+	var lines []string
+	for _, g := range goldenWorlds() {
+		w := newTestWorld(g.seed, g.theme, g.w, g.h)
+		w.GenerateAll()
+		for cy := 0; cy <= w.maxCy; cy++ {
+			for cx := 0; cx <= w.maxCx; cx++ {
+				pix, height := chunkHashes(w.GetChunk(cx, cy))
+				lines = append(lines, fmt.Sprintf("%s %d %dx%d %d,%d %016x %016x",
+					strings.ReplaceAll(g.theme.Name, " ", "_"), g.seed, g.w, g.h, cx, cy, pix, height))
+			}
+		}
+	}
+	return lines
+}
+
+func TestWorldgenGolden(t *testing.T) {
+	got := goldenLines()
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(worldgenGoldenPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		data := strings.Join(got, "\n") + "\n"
+		if err := os.WriteFile(worldgenGoldenPath, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(worldgenGoldenPath)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	defer f.Close()
+	var want []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		want = append(want, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("%d chunks generated, golden has %d", len(got), len(want))
+	}
+	bad := 0
+	for i := range got {
+		if got[i] != want[i] {
+			if bad < 10 {
+				t.Errorf("chunk changed:\n got  %s\n want %s", got[i], want[i])
+			}
+			bad++
+		}
+	}
+	if bad > 0 {
+		t.Errorf("%d of %d chunks differ from the golden; run with -update if the change is intended", bad, len(got))
+	}
+}
+
+// TestWorldgenLoadOrder checks that a chunk comes out the same whichever
+// order its neighbours stream in.
+func TestWorldgenLoadOrder(t *testing.T) {
+	for _, theme := range Themes {
+		a := newTestWorld(5, theme, 495, 363)
+		a.GenerateAll()
+		b := newTestWorld(5, theme, 495, 363)
+		for cy := b.maxCy; cy >= 0; cy-- {
+			for cx := b.maxCx; cx >= 0; cx-- {
+				b.GetChunk(cx, cy)
+			}
+		}
+		for cy := 0; cy <= a.maxCy; cy++ {
+			for cx := 0; cx <= a.maxCx; cx++ {
+				pa, ha := chunkHashes(a.GetChunk(cx, cy))
+				pb, hb := chunkHashes(b.GetChunk(cx, cy))
+				if pa != pb || ha != hb {
+					t.Errorf("%s: chunk %d,%d depends on load order", theme.Name, cx, cy)
+				}
+			}
+		}
+	}
+}
+
+func TestWorldgenBorderUnbreakable(t *testing.T) {
+	for _, g := range goldenWorlds() {
+		w := newTestWorld(g.seed, g.theme, g.w, g.h)
+		for y := 0; y < w.Height; y++ {
+			for x := 0; x < w.Width; x++ {
+				if x >= BorderThickness && y >= BorderThickness && x < w.Width-BorderThickness && y < w.Height-BorderThickness {
+					continue
+				}
+				c := w.GetChunk(x/ChunkSize, y/ChunkSize)
+				i := c.idx(x%ChunkSize, y%ChunkSize)
+				if c.Unbreakable[i] == 0 || c.Height[i] == 0 {
+					t.Fatalf("%s seed %d: border pixel %d,%d is breakable or open", g.theme.Name, g.seed, x, y)
+				}
+			}
+		}
+		if w.BurnPixel(0, w.Height/2) || w.PaintRGB(w.Width-1, 0, Palette.Rubble) {
+			t.Fatalf("%s seed %d: border pixel changed", g.theme.Name, g.seed)
+		}
+	}
+}
+
+// TestWorldgenRoadsConnected checks that after road repair every street
+// pixel can be reached from the ring road, so cars can drive everywhere.
+// Parking lot markings share the road colours but are not streets.
+func TestWorldgenRoadsConnected(t *testing.T) {
+	for _, g := range goldenWorlds() {
+		if g.theme.NoRoads {
+			continue
+		}
+		w := newTestWorld(g.seed, g.theme, g.w, g.h)
+		tp := buildThemePalette(w.Theme)
+		street := func(x, y int) bool {
+			return isRoadPixel(w, tp, x, y) &&
+				(isPerimeterRoad(x, y, w.Width, w.Height) || x%Pattern < RoadWidth || y%Pattern < RoadWidth)
+		}
+		seen := make([]bool, w.Width*w.Height)
+		start := BorderThickness*w.Width + BorderThickness
+		if !street(BorderThickness, BorderThickness) {
+			t.Fatalf("%s seed %d: no ring road at the corner", g.theme.Name, g.seed)
+		}
+		seen[start] = true
+		stack := []int{start}
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%w.Width, i/w.Width
+			for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nx, ny := x+d[0], y+d[1]
+				if !w.InBounds(nx, ny) || seen[ny*w.Width+nx] || !street(nx, ny) {
+					continue
+				}
+				seen[ny*w.Width+nx] = true
+				stack = append(stack, ny*w.Width+nx)
+			}
+		}
+		for y := 0; y < w.Height; y++ {
+			for x := 0; x < w.Width; x++ {
+				if street(x, y) && !seen[y*w.Width+x] {
+					t.Fatalf("%s seed %d %dx%d: road at %d,%d is cut off from the ring road",
+						g.theme.Name, g.seed, g.w, g.h, x, y)
+				}
+			}
+		}
+	}
+}
+
+// TestWorldgenWater checks that water lies at ground level where nothing
+// can destroy it, and that streets cross it on bridges.
+func TestWorldgenWater(t *testing.T) {
+	for _, g := range goldenWorlds() {
+		w := newTestWorld(g.seed, g.theme, g.w, g.h)
+		tp := buildThemePalette(w.Theme)
+		n := 0
+		for y := 0; y < w.Height; y++ {
+			for x := 0; x < w.Width; x++ {
+				if !w.IsWater(x, y) {
+					continue
+				}
+				n++
+				c := w.GetChunk(x/ChunkSize, y/ChunkSize)
+				i := c.idx(x%ChunkSize, y%ChunkSize)
+				if c.Height[i] != 0 || c.Unbreakable[i] == 0 {
+					t.Fatalf("%s seed %d: water at %d,%d is not indestructible ground", g.theme.Name, g.seed, x, y)
+				}
+				if isPerimeterRoad(x, y, w.Width, w.Height) || isRoadSurfaceColor(w.ColorAt(x, y), tp) {
+					t.Fatalf("%s seed %d: water at %d,%d is under a road", g.theme.Name, g.seed, x, y)
+				}
+			}
+		}
+		if g.theme.Coast && n == 0 {
+			t.Errorf("%s seed %d: coast without water", g.theme.Name, g.seed)
+		}
+		if g.theme.Rivers[1] == 0 && g.theme.Lakes[1] == 0 && !g.theme.Coast && n > 0 {
+			t.Errorf("%s seed %d: %d water pixels in a dry theme", g.theme.Name, g.seed, n)
+		}
+	}
+}
+
+// TestWorldgenInteriors checks that every building interior has open,
+// dry floor and doors, and that all of its floor can be reached through
+// them.
+func TestWorldgenInteriors(t *testing.T) {
+	total := 0
+	for _, g := range goldenWorlds() {
+		w := newTestWorld(g.seed, g.theme, g.w, g.h)
+		for by := 0; by*Pattern < w.Height; by++ {
+			for bx := 0; bx*Pattern < w.Width; bx++ {
+				for _, in := range w.blockInteriors(bx, by) {
+					total++
+					r := in.rect
+					open := func(x, y int) bool { return w.HeightAt(x, y) == 0 && !w.IsWater(x, y) }
+					// Flood the building from just inside its doors.
+					seen := make(map[[2]int]bool)
+					var stack [][2]int
+					for _, d := range in.doors {
+						p := [2]int{int(d.x), int(d.y)}
+						if !open(p[0], p[1]) {
+							t.Fatalf("%s seed %d: door at %.1f,%.1f is blocked", g.theme.Name, g.seed, d.x, d.y)
+						}
+						seen[p] = true
+						stack = append(stack, p)
+					}
+					for len(stack) > 0 {
+						p := stack[len(stack)-1]
+						stack = stack[:len(stack)-1]
+						for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+							q := [2]int{p[0] + d[0], p[1] + d[1]}
+							if q[0] <= r.X0 || q[1] <= r.Y0 || q[0] >= r.X1-1 || q[1] >= r.Y1-1 || seen[q] || !open(q[0], q[1]) {
+								continue
+							}
+							seen[q] = true
+							stack = append(stack, q)
+						}
+					}
+					for y := r.Y0 + 1; y < r.Y1-1; y++ {
+						for x := r.X0 + 1; x < r.X1-1; x++ {
+							if open(x, y) && !seen[[2]int{x, y}] {
+								t.Fatalf("%s seed %d: floor at %d,%d cannot be reached from a door", g.theme.Name, g.seed, x, y)
+							}
+						}
+					}
+					if len(seen) < (r.X1-r.X0-2)*(r.Y1-r.Y0-2)/2 {
+						t.Fatalf("%s seed %d: interior at %d,%d is mostly wall", g.theme.Name, g.seed, r.X0, r.Y0)
+					}
+				}
+			}
+		}
+	}
+	if total == 0 {
+		t.Error("no building interiors generated")
+	}
+}
+
+// TestStartLevelSpawnWalkable checks the snake starts on open ground on
+// every level of the campaign and a few past it.
+func TestStartLevelSpawnWalkable(t *testing.T) {
+	for _, seed := range []uint64{1, 7, 1234, 98765} {
+		sim := NewSimulation(seed)
+		for level := 1; level <= len(Levels.Levels)+3; level++ {
+			sim.StartLevel(level)
+			hx, hy := sim.Snake.Head()
+			if sim.World.HeightAt(int(hx), int(hy)) != 0 {
+				t.Errorf("seed %d level %d (%s): snake spawns inside terrain at %.1f,%.1f",
+					seed, level, sim.World.Theme.Name, hx, hy)
+			}
+		}
+	}
+}